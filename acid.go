@@ -0,0 +1,216 @@
+package orc
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// acidFieldNames are the names of the fields of the struct that Hive ACID tables
+// wrap each row of user data in, the last of which contains the row itself.
+var acidFieldNames = []string{
+	"operation",
+	"originalTransaction",
+	"bucket",
+	"rowId",
+	"currentTransaction",
+	"row",
+}
+
+// AcidEvent holds the ACID metadata that Hive stores alongside each row of an
+// ACID table.
+type AcidEvent struct {
+	Operation           int64
+	OriginalTransaction int64
+	Bucket              int64
+	RowID               int64
+	CurrentTransaction  int64
+}
+
+// isAcidSchema returns true if schema has the layout of a Hive ACID table. Field
+// names are compared case insensitively as they may have been lower cased.
+func isAcidSchema(schema *TypeDescription) bool {
+	if schema.getCategory() != CategoryStruct || len(schema.fieldNames) != len(acidFieldNames) {
+		return false
+	}
+	for i, name := range acidFieldNames {
+		if !strings.EqualFold(schema.fieldNames[i], name) {
+			return false
+		}
+	}
+	return schema.children[len(acidFieldNames)-1].getCategory() == CategoryStruct
+}
+
+// newAcidEvent returns an AcidEvent from the values of the ACID metadata columns.
+func newAcidEvent(values []interface{}) (AcidEvent, error) {
+	var fields [5]int64
+	for i := range fields {
+		v, ok := values[i].(int64)
+		if !ok {
+			return AcidEvent{}, fmt.Errorf("unexpected value for ACID column %s: %v", acidFieldNames[i], values[i])
+		}
+		fields[i] = v
+	}
+	return AcidEvent{
+		Operation:           fields[0],
+		OriginalTransaction: fields[1],
+		Bucket:              fields[2],
+		RowID:               fields[3],
+		CurrentTransaction:  fields[4],
+	}, nil
+}
+
+// The operations of the events of a Hive ACID table.
+const (
+	acidInsert = 0
+	acidUpdate = 1
+	acidDelete = 2
+)
+
+// acidKey identifies a row of a Hive ACID table across its base and delta files.
+type acidKey struct {
+	originalTransaction int64
+	bucket              int64
+	rowID               int64
+}
+
+// acidRow is the latest event of a row of a Hive ACID table, the values of which
+// are nil once the row is deleted.
+type acidRow struct {
+	event  AcidEvent
+	values []interface{}
+}
+
+// MergeAcid merges the events of the delta files of a Hive ACID table into those of
+// its base file, returning a Reader of the current rows of the table. The rows are
+// identified by their original transaction, bucket and row ID, the event of each
+// row with the latest current transaction deciding whether it was inserted,
+// updated or deleted, with deletes taking precedence within a transaction. Every
+// Reader must be opened with WithAcidUnwrap and have the same row schema, and is
+// read to its end. The merged rows are written, ordered by their identifiers, to
+// an ORC file held in memory from which the returned Reader reads, so binary
+// columns, which the Writer does not support, cannot be merged.
+func MergeAcid(base *Reader, deltas []*Reader) (*Reader, error) {
+	if base.acidSchema == nil {
+		return nil, fmt.Errorf("base is not of an ACID table opened with WithAcidUnwrap")
+	}
+	schema := base.schema
+	rows := make(map[acidKey]acidRow)
+	apply := func(r *Reader) error {
+		c := r.Select(schema.fieldNames...)
+		for c.Stripes() {
+			for c.Next() {
+				event := c.AcidEvent()
+				key := acidKey{event.OriginalTransaction, event.Bucket, event.RowID}
+				if current, ok := rows[key]; ok {
+					if current.event.CurrentTransaction > event.CurrentTransaction {
+						continue
+					}
+					if current.event.CurrentTransaction == event.CurrentTransaction && current.event.Operation == acidDelete {
+						continue
+					}
+				}
+				switch event.Operation {
+				case acidInsert, acidUpdate:
+					rows[key] = acidRow{event: event, values: c.Row()}
+				case acidDelete:
+					rows[key] = acidRow{event: event}
+				default:
+					return fmt.Errorf("unknown ACID operation %d of row %+v", event.Operation, key)
+				}
+			}
+		}
+		return c.Err()
+	}
+	if err := apply(base); err != nil {
+		return nil, fmt.Errorf("base: %v", err)
+	}
+	for i, delta := range deltas {
+		if delta.acidSchema == nil {
+			return nil, fmt.Errorf("delta %d is not of an ACID table opened with WithAcidUnwrap", i)
+		}
+		if delta.schema.String() != schema.String() {
+			return nil, fmt.Errorf("delta %d has the row schema %s, expected %s", i, delta.schema, schema)
+		}
+		if err := apply(delta); err != nil {
+			return nil, fmt.Errorf("delta %d: %v", i, err)
+		}
+	}
+
+	keys := make([]acidKey, 0, len(rows))
+	for key, row := range rows {
+		if row.values != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.originalTransaction != b.originalTransaction {
+			return a.originalTransaction < b.originalTransaction
+		}
+		if a.bucket != b.bucket {
+			return a.bucket < b.bucket
+		}
+		return a.rowID < b.rowID
+	})
+
+	// The row schema of an unwrapped Reader keeps the column IDs of the ACID file,
+	// so the merged file is written with a copy numbered from its root.
+	merged, err := ParseSchema(schema.String())
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(merged))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		values := rows[key].values
+		for i, value := range values {
+			values[i] = writableValue(schema.children[i], value)
+		}
+		if err := w.Write(values...); err != nil {
+			return nil, fmt.Errorf("row %+v: %v", key, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return NewReader(bytes.NewReader(buf.Bytes()))
+}
+
+// writableValue returns a value read from a column of type td as accepted by the
+// TreeWriter of the column, the values of structs being read as a Struct but
+// written as a []interface{} of the values of their fields.
+func writableValue(td *TypeDescription, value interface{}) interface{} {
+	switch v := value.(type) {
+	case Struct:
+		values := make([]interface{}, len(td.children))
+		for i, child := range td.children {
+			values[i] = writableValue(child, v[td.fieldNames[i]])
+		}
+		return values
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, elem := range v {
+			values[i] = writableValue(td.children[0], elem)
+		}
+		return values
+	case []MapEntry:
+		entries := make([]MapEntry, len(v))
+		for i, entry := range v {
+			entries[i] = MapEntry{
+				Key:   writableValue(td.children[0], entry.Key),
+				Value: writableValue(td.children[1], entry.Value),
+			}
+		}
+		return entries
+	case UnionValue:
+		if v.Tag >= 0 && v.Tag < len(td.children) {
+			return UnionValue{Tag: v.Tag, Value: writableValue(td.children[v.Tag], v.Value)}
+		}
+	}
+	return value
+}
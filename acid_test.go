@@ -0,0 +1,153 @@
+package orc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// acidFile returns a Reader, opened with WithAcidUnwrap, of an ACID file of the
+// provided events and rows.
+func acidFile(t *testing.T, schema string, events []AcidEvent, rows []interface{}) *Reader {
+	t.Helper()
+	td, err := ParseSchema("struct<operation:int,originalTransaction:bigint,bucket:int,rowId:bigint,currentTransaction:bigint,row:" + schema + ">")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(td))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range events {
+		if err := w.Write(e.Operation, e.OriginalTransaction, e.Bucket, e.RowID, e.CurrentTransaction, rows[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), WithAcidUnwrap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMergeAcid(t *testing.T) {
+
+	schema := "struct<id:int,name:string,point:struct<x:int,y:int>,tags:array<string>>"
+	base := acidFile(t, schema,
+		[]AcidEvent{
+			{Operation: acidInsert, OriginalTransaction: 1, Bucket: 0, RowID: 0, CurrentTransaction: 1},
+			{Operation: acidInsert, OriginalTransaction: 1, Bucket: 0, RowID: 1, CurrentTransaction: 1},
+			{Operation: acidInsert, OriginalTransaction: 1, Bucket: 0, RowID: 2, CurrentTransaction: 1},
+		},
+		[]interface{}{
+			[]interface{}{int64(1), "a", []interface{}{int64(1), int64(2)}, []interface{}{"x"}},
+			[]interface{}{int64(2), "b", nil, nil},
+			[]interface{}{int64(3), "c", []interface{}{int64(5), nil}, []interface{}{}},
+		},
+	)
+	// The delta inserts a row, updates the second row of the base and deletes the
+	// third.
+	delta := acidFile(t, schema,
+		[]AcidEvent{
+			{Operation: acidInsert, OriginalTransaction: 2, Bucket: 0, RowID: 0, CurrentTransaction: 2},
+			{Operation: acidUpdate, OriginalTransaction: 1, Bucket: 0, RowID: 1, CurrentTransaction: 2},
+			{Operation: acidDelete, OriginalTransaction: 1, Bucket: 0, RowID: 2, CurrentTransaction: 2},
+		},
+		[]interface{}{
+			[]interface{}{int64(4), "d", nil, []interface{}{"y", "z"}},
+			[]interface{}{int64(2), "b2", []interface{}{int64(3), int64(4)}, nil},
+			nil,
+		},
+	)
+
+	r, err := MergeAcid(base, []*Reader{delta})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema().String(); s != schema {
+		t.Errorf("Test failed, expected schema %s got %s", schema, s)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{
+		{int64(1), "a", Struct{"x": int64(1), "y": int64(2)}, []interface{}{"x"}},
+		{int64(2), "b2", Struct{"x": int64(3), "y": int64(4)}, nil},
+		{int64(4), "d", nil, []interface{}{"y", "z"}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+	// Events of earlier transactions do not override later ones, whichever file
+	// they are read from.
+	base = acidFile(t, "struct<id:int>",
+		[]AcidEvent{{Operation: acidInsert, OriginalTransaction: 1, RowID: 0, CurrentTransaction: 1}},
+		[]interface{}{[]interface{}{int64(1)}},
+	)
+	deletes := acidFile(t, "struct<id:int>",
+		[]AcidEvent{{Operation: acidDelete, OriginalTransaction: 1, RowID: 0, CurrentTransaction: 3}},
+		[]interface{}{nil},
+	)
+	updates := acidFile(t, "struct<id:int>",
+		[]AcidEvent{{Operation: acidUpdate, OriginalTransaction: 1, RowID: 0, CurrentTransaction: 2}},
+		[]interface{}{[]interface{}{int64(2)}},
+	)
+	r, err = MergeAcid(base, []*Reader{deletes, updates})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := readAllRows(r); err != nil || len(actual) != 0 {
+		t.Errorf("Test failed, expected no rows got %v %v", actual, err)
+	}
+
+}
+
+func TestMergeAcidErrors(t *testing.T) {
+
+	event := []AcidEvent{{Operation: acidInsert, OriginalTransaction: 1, CurrentTransaction: 1}}
+	base := acidFile(t, "struct<id:int>", event, []interface{}{[]interface{}{int64(1)}})
+	other := acidFile(t, "struct<id:bigint>", event, []interface{}{[]interface{}{int64(1)}})
+	unknown := acidFile(t, "struct<id:int>", []AcidEvent{{Operation: 7}}, []interface{}{nil})
+
+	schema, err := ParseSchema("struct<id:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := NewReader(bytes.NewReader(buf.Bytes()), WithAcidUnwrap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		base     *Reader
+		deltas   []*Reader
+		expected string
+	}{
+		{base: plain, expected: "base is not of an ACID table opened with WithAcidUnwrap"},
+		{base: base, deltas: []*Reader{plain}, expected: "delta 0 is not of an ACID table opened with WithAcidUnwrap"},
+		{base: base, deltas: []*Reader{other}, expected: "delta 0 has the row schema struct<id:bigint>, expected struct<id:int>"},
+		{base: base, deltas: []*Reader{unknown}, expected: "delta 0: unknown ACID operation 7"},
+	}
+	for _, tc := range testCases {
+		_, err := MergeAcid(tc.base, tc.deltas)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+			t.Errorf("Test failed, expected error %q got %v", tc.expected, err)
+		}
+	}
+
+}
@@ -0,0 +1,47 @@
+package orc
+
+import "sync"
+
+// Allocator provides the scratch buffers used whilst reading a file, such as the
+// compressed and decompressed chunks of snappy streams and the bytes of stripe
+// footers. Get returns a buffer of length n, and Put returns a buffer obtained
+// from Get once it is no longer used, which may be never should reading stop
+// part way through a chunk.
+type Allocator interface {
+	Get(n int) []byte
+	Put(b []byte)
+}
+
+// defaultAllocator is the Allocator used unless another is set by WithAllocator.
+var defaultAllocator Allocator = &poolAllocator{}
+
+// poolAllocator is an Allocator reusing the buffers returned to it through a
+// sync.Pool.
+type poolAllocator struct {
+	pool sync.Pool
+}
+
+// Get implements the Allocator interface.
+func (a *poolAllocator) Get(n int) []byte {
+	if b, ok := a.pool.Get().(*[]byte); ok {
+		if cap(*b) >= n {
+			return (*b)[:n]
+		}
+		// Return the buffer for a smaller request.
+		a.pool.Put(b)
+	}
+	return make([]byte, n)
+}
+
+// Put implements the Allocator interface.
+func (a *poolAllocator) Put(b []byte) {
+	a.pool.Put(&b)
+}
+
+// getAllocator returns a, or the default Allocator if a is nil.
+func getAllocator(a Allocator) Allocator {
+	if a == nil {
+		return defaultAllocator
+	}
+	return a
+}
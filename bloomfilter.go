@@ -0,0 +1,261 @@
+package orc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/bits"
+
+	"code.simon-critchley.co.uk/orc/proto"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+// writerVersionHive12055 is the writer version from which the BLOOM_FILTER streams
+// of string columns hash the UTF-8 encoding of each string.
+const writerVersionHive12055 = 3
+
+// BloomFilter is the bloom filter of the values of a column within a row group. It
+// reports whether a value might be contained within the row group, for skipping
+// row groups that do not contain the value sought.
+type BloomFilter struct {
+	bitset           []uint64
+	numHashFunctions int
+	// utf8 is false for the bloom filters of string columns written prior to
+	// HIVE-12055, which hashed strings encoded in the default charset of the
+	// writer rather than in UTF-8.
+	utf8 bool
+}
+
+// newBloomFilter returns the BloomFilter decoded from a stream of the provided kind.
+// BLOOM_FILTER_UTF8 streams hold the bitset as bytes rather than as fixed64 values.
+// Bloom filters with unknown fields, such as those written by pre-release versions
+// of Hive, are left empty so that every value might be contained.
+func newBloomFilter(kind proto.Stream_Kind, utf8 bool, pb *proto.BloomFilter) *BloomFilter {
+	if len(pb.XXX_unrecognized) > 0 {
+		return &BloomFilter{}
+	}
+	bitset := pb.GetBitset()
+	if kind == proto.Stream_BLOOM_FILTER_UTF8 {
+		b := pb.GetUtf8Bitset()
+		bitset = make([]uint64, len(b)/8)
+		for i := range bitset {
+			bitset[i] = binary.LittleEndian.Uint64(b[i*8:])
+		}
+	}
+	return &BloomFilter{
+		bitset:           bitset,
+		numHashFunctions: int(pb.GetNumHashFunctions()),
+		utf8:             utf8 || kind == proto.Stream_BLOOM_FILTER_UTF8,
+	}
+}
+
+// MightContain returns false if the value v is definitely not contained within the
+// row group, otherwise true. It accepts integer, boolean, floating point, string
+// and []byte values. Values of other types, nil values and any string that is not
+// ASCII when the bloom filter hashed strings in the default charset of its writer
+// cannot be tested and are reported as possibly contained, as is every value by an
+// empty bloom filter.
+func (b *BloomFilter) MightContain(v interface{}) bool {
+	var hash uint64
+	switch t := v.(type) {
+	case int:
+		hash = longHash(int64(t))
+	case int8:
+		hash = longHash(int64(t))
+	case int16:
+		hash = longHash(int64(t))
+	case int32:
+		hash = longHash(int64(t))
+	case int64:
+		hash = longHash(t)
+	case bool:
+		if t {
+			hash = longHash(1)
+		} else {
+			hash = longHash(0)
+		}
+	case float32:
+		hash = doubleHash(float64(t))
+	case float64:
+		hash = doubleHash(t)
+	case Float:
+		hash = doubleHash(float64(t))
+	case Double:
+		hash = doubleHash(float64(t))
+	case string:
+		if !b.utf8 && !isASCII(t) {
+			return true
+		}
+		hash = murmur3Hash64([]byte(t))
+	case []byte:
+		hash = murmur3Hash64(t)
+	default:
+		return true
+	}
+	return b.testHash(hash)
+}
+
+// testHash returns whether every bit of the bitset set for the 64 bit hash of a
+// value is set, using the combinations of the low and high 32 bits of the hash
+// computed by the Java implementation.
+func (b *BloomFilter) testHash(hash uint64) bool {
+	numBits := int32(len(b.bitset) * 64)
+	if numBits == 0 {
+		return true
+	}
+	hash1 := int32(hash)
+	hash2 := int32(hash >> 32)
+	for i := int32(1); i <= int32(b.numHashFunctions); i++ {
+		combined := hash1 + i*hash2
+		if combined < 0 {
+			combined = ^combined
+		}
+		pos := combined % numBits
+		if b.bitset[pos>>6]&(1<<uint(pos&63)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// longHash returns the hash of an integer value using Thomas Wang's 64 bit hash.
+func longHash(key int64) uint64 {
+	key = ^key + (key << 21)
+	key = key ^ (key >> 24)
+	key = (key + (key << 3)) + (key << 8)
+	key = key ^ (key >> 14)
+	key = (key + (key << 2)) + (key << 4)
+	key = key ^ (key >> 28)
+	key = key + (key << 31)
+	return uint64(key)
+}
+
+// doubleHash returns the hash of a floating point value, being the hash of its IEEE
+// 754 bits with every NaN value having the same bits as in Java.
+func doubleHash(f float64) uint64 {
+	if math.IsNaN(f) {
+		return longHash(0x7ff8000000000000)
+	}
+	return longHash(int64(math.Float64bits(f)))
+}
+
+// murmur3Seed is the seed used to hash the bytes of values added to bloom filters.
+const murmur3Seed = 104729
+
+// murmur3Hash64 returns the 64 bit Murmur3 hash of data as computed by the Java
+// implementation, which uses the first half of the 128 bit x64 variant of Murmur3
+// with a simplified mix of each block.
+func murmur3Hash64(data []byte) uint64 {
+	const (
+		c1 = 0x87c37b91114253d5
+		c2 = 0x4cf5ad432745937f
+		m  = 5
+		n1 = 0x52dce729
+	)
+	hash := uint64(murmur3Seed)
+	blocks := len(data) / 8
+	for i := 0; i < blocks; i++ {
+		k := binary.LittleEndian.Uint64(data[i*8:])
+		k *= c1
+		k = bits.RotateLeft64(k, 31)
+		k *= c2
+		hash ^= k
+		hash = bits.RotateLeft64(hash, 27)*m + n1
+	}
+	tail := data[blocks*8:]
+	if len(tail) > 0 {
+		var k uint64
+		for i := len(tail) - 1; i >= 0; i-- {
+			k ^= uint64(tail[i]) << (8 * uint(i))
+		}
+		k *= c1
+		k = bits.RotateLeft64(k, 31)
+		k *= c2
+		hash ^= k
+	}
+	hash ^= uint64(len(data))
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// BloomFilters returns the bloom filter of each row group of column, which may be
+// the name of a nested field such as "a.b", within the stripe at the provided index,
+// or nil if the column has no bloom filters. The BLOOM_FILTER_UTF8 stream of the
+// column is used when present, otherwise its BLOOM_FILTER stream.
+func (r *Reader) BloomFilters(stripe int, column string) ([]*BloomFilter, error) {
+	td, err := r.schema.GetField(column)
+	if err != nil {
+		return nil, err
+	}
+	stripes, err := r.getStripes()
+	if err != nil {
+		return nil, err
+	}
+	if stripe < 0 || stripe >= len(stripes) {
+		return nil, fmt.Errorf("invalid stripe %d, the file has %d stripes", stripe, len(stripes))
+	}
+	info := stripes[stripe]
+	stripeFooter, err := r.getStripeFooter(info)
+	if err != nil {
+		return nil, err
+	}
+	streams, _, err := r.stripeStreams(stripe, stripeFooter)
+	if err != nil {
+		return nil, err
+	}
+	var bloomFilter *stripeStream
+	for i, stream := range streams {
+		if int(stream.GetColumn()) == td.getID() {
+			switch stream.GetKind() {
+			case proto.Stream_BLOOM_FILTER_UTF8:
+				bloomFilter = &streams[i]
+			case proto.Stream_BLOOM_FILTER:
+				if bloomFilter == nil {
+					bloomFilter = &streams[i]
+				}
+			}
+		}
+	}
+	if bloomFilter == nil {
+		return nil, nil
+	}
+	codec, err := r.getCodec()
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(codec.Decoder(bloomFilter.reader(r.r)))
+	if err != nil {
+		return nil, err
+	}
+	index := &proto.BloomFilterIndex{}
+	if err := gproto.Unmarshal(b, index); err != nil {
+		return nil, err
+	}
+	var utf8 bool
+	switch td.getCategory() {
+	case CategoryString, CategoryVarchar, CategoryChar:
+		utf8 = r.postScript.GetWriterVersion() >= writerVersionHive12055
+	default:
+		// Only the hashing of strings differs between writer versions.
+		utf8 = true
+	}
+	filters := make([]*BloomFilter, len(index.GetBloomFilter()))
+	for i, pb := range index.GetBloomFilter() {
+		filters[i] = newBloomFilter(bloomFilter.GetKind(), utf8, pb)
+	}
+	return filters, nil
+}
@@ -0,0 +1,321 @@
+package orc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"code.simon-critchley.co.uk/orc/proto"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+// testBloomFilter returns a bloom filter of 1024 bits with 3 hash functions and
+// the bits of each of the hashes set as by the Java implementation.
+func testBloomFilter(hashes []uint64) *BloomFilter {
+	b := &BloomFilter{bitset: make([]uint64, 16), numHashFunctions: 3}
+	for _, hash := range hashes {
+		hash1 := int32(hash)
+		hash2 := int32(hash >> 32)
+		for i := int32(1); i <= int32(b.numHashFunctions); i++ {
+			combined := hash1 + i*hash2
+			if combined < 0 {
+				combined = ^combined
+			}
+			pos := combined % int32(len(b.bitset)*64)
+			b.bitset[pos>>6] |= 1 << uint(pos&63)
+		}
+	}
+	return b
+}
+
+// marshalBloomFilters returns the bloom filter index of a stream of the provided
+// kind holding the bloom filters.
+func marshalBloomFilters(t *testing.T, kind proto.Stream_Kind, filters []*BloomFilter) []byte {
+	index := &proto.BloomFilterIndex{}
+	for _, b := range filters {
+		pb := &proto.BloomFilter{NumHashFunctions: ptrUint32(uint32(b.numHashFunctions))}
+		if kind == proto.Stream_BLOOM_FILTER_UTF8 {
+			pb.Utf8Bitset = make([]byte, len(b.bitset)*8)
+			for i, v := range b.bitset {
+				binary.LittleEndian.PutUint64(pb.Utf8Bitset[i*8:], v)
+			}
+		} else {
+			pb.Bitset = b.bitset
+		}
+		index.BloomFilter = append(index.BloomFilter, pb)
+	}
+	byt, err := gproto.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return byt
+}
+
+// withBloomFilters returns the uncompressed ORC file b, which has a single stripe,
+// with the writer version set and the bloom filter streams added to the index of
+// the stripe, in the order of the provided streams.
+func withBloomFilters(t *testing.T, b []byte, writerVersion uint32, streams []*proto.Stream, indexes [][]byte) []byte {
+	psLen := int(b[len(b)-1])
+	postScript := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[len(b)-1-psLen:len(b)-1], postScript); err != nil {
+		t.Fatal(err)
+	}
+	footerOffset := len(b) - 1 - psLen - int(postScript.GetFooterLength())
+	footer := &proto.Footer{}
+	if err := gproto.Unmarshal(b[footerOffset:footerOffset+int(postScript.GetFooterLength())], footer); err != nil {
+		t.Fatal(err)
+	}
+	metadataOffset := footerOffset - int(postScript.GetMetadataLength())
+	stripe := footer.GetStripes()[0]
+	stripeFooterOffset := int(stripe.GetOffset() + stripe.GetIndexLength() + stripe.GetDataLength())
+	stripeFooter := &proto.StripeFooter{}
+	if err := gproto.Unmarshal(b[stripeFooterOffset:stripeFooterOffset+int(stripe.GetFooterLength())], stripeFooter); err != nil {
+		t.Fatal(err)
+	}
+
+	indexEnd := int(stripe.GetOffset() + stripe.GetIndexLength())
+	out := append([]byte{}, b[:indexEnd]...)
+	indexLength := stripe.GetIndexLength()
+	for i, stream := range streams {
+		stream.Length = ptrUint64(uint64(len(indexes[i])))
+		indexLength += uint64(len(indexes[i]))
+		out = append(out, indexes[i]...)
+	}
+	out = append(out, b[indexEnd:stripeFooterOffset]...)
+
+	// The streams of the index precede the data streams.
+	var indexStreams []*proto.Stream
+	for _, stream := range stripeFooter.GetStreams() {
+		if stream.GetKind() == proto.Stream_ROW_INDEX {
+			indexStreams = append(indexStreams, stream)
+		}
+	}
+	stripeFooter.Streams = append(append(indexStreams, streams...), stripeFooter.Streams[len(indexStreams):]...)
+	byt, err := gproto.Marshal(stripeFooter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	stripe.IndexLength = &indexLength
+	stripe.FooterLength = ptrUint64(uint64(len(byt)))
+	out = append(out, b[metadataOffset:footerOffset]...)
+	if byt, err = gproto.Marshal(footer); err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	postScript.FooterLength = ptrUint64(uint64(len(byt)))
+	postScript.WriterVersion = &writerVersion
+	if byt, err = gproto.Marshal(postScript); err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	return append(out, byte(len(byt)))
+}
+
+func TestReaderBloomFilters(t *testing.T) {
+
+	schema, err := ParseSchema("struct<string1:string,int1:bigint,double1:double>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.footer.RowIndexStride = ptrUint32(100)
+	var rows [][]interface{}
+	for i := 0; i < 300; i++ {
+		row := []interface{}{fmt.Sprintf("value %d", i), int64(i * 7), float64(i) / 4}
+		if i%3 == 0 {
+			row[0] = fmt.Sprintf("café %d", i)
+		}
+		rows = append(rows, row)
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// latin1 returns the ISO-8859-1 encoding of a string, the default charset of
+	// some older writers.
+	latin1 := func(s string) []byte {
+		var b []byte
+		for _, r := range s {
+			b = append(b, byte(r))
+		}
+		return b
+	}
+
+	// filters returns the bloom filter streams of each column for each row group
+	// using a stream of the provided kind, with strings encoded by encode.
+	filters := func(kind proto.Stream_Kind, encode func(string) []byte) ([]*proto.Stream, [][]byte) {
+		var streams []*proto.Stream
+		var indexes [][]byte
+		for column := 1; column <= 3; column++ {
+			var groups []*BloomFilter
+			for start := 0; start < len(rows); start += 100 {
+				var hashes []uint64
+				for _, row := range rows[start : start+100] {
+					switch v := row[column-1].(type) {
+					case string:
+						hashes = append(hashes, murmur3Hash64(encode(v)))
+					case int64:
+						hashes = append(hashes, longHash(v))
+					case float64:
+						hashes = append(hashes, doubleHash(v))
+					}
+				}
+				groups = append(groups, testBloomFilter(hashes))
+			}
+			streams = append(streams, &proto.Stream{Column: ptrUint32(uint32(column)), Kind: kind.Enum()})
+			indexes = append(indexes, marshalBloomFilters(t, kind, groups))
+		}
+		return streams, indexes
+	}
+
+	utf8Streams, utf8Indexes := filters(proto.Stream_BLOOM_FILTER_UTF8, func(s string) []byte { return []byte(s) })
+	// A BLOOM_FILTER stream written alongside a BLOOM_FILTER_UTF8 stream, which
+	// would report every value as missing were it used instead.
+	var emptyStreams []*proto.Stream
+	var emptyIndexes [][]byte
+	for column := 1; column <= 3; column++ {
+		empty := &BloomFilter{bitset: make([]uint64, 16), numHashFunctions: 3}
+		emptyStreams = append(emptyStreams, &proto.Stream{Column: ptrUint32(uint32(column)), Kind: proto.Stream_BLOOM_FILTER.Enum()})
+		emptyIndexes = append(emptyIndexes, marshalBloomFilters(t, proto.Stream_BLOOM_FILTER, []*BloomFilter{empty, empty, empty}))
+	}
+	legacyStreams, legacyIndexes := filters(proto.Stream_BLOOM_FILTER, latin1)
+	fixedStreams, fixedIndexes := filters(proto.Stream_BLOOM_FILTER, func(s string) []byte { return []byte(s) })
+
+	testCases := []struct {
+		name          string
+		writerVersion uint32
+		streams       []*proto.Stream
+		indexes       [][]byte
+		// nonASCII is whether strings that are not ASCII can be tested.
+		nonASCII bool
+	}{
+		{
+			name:          "utf8",
+			writerVersion: DefaultWriterVersion,
+			streams:       append(utf8Streams, emptyStreams...),
+			indexes:       append(utf8Indexes, emptyIndexes...),
+			nonASCII:      true,
+		},
+		{
+			name:          "before HIVE-12055",
+			writerVersion: 1,
+			streams:       legacyStreams,
+			indexes:       legacyIndexes,
+		},
+		{
+			name:          "after HIVE-12055",
+			writerVersion: writerVersionHive12055,
+			streams:       fixedStreams,
+			indexes:       fixedIndexes,
+			nonASCII:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := NewReader(bytes.NewReader(withBloomFilters(t, buf.Bytes(), tc.writerVersion, tc.streams, tc.indexes)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actual) != len(rows) {
+			t.Fatalf("Test failed, %s: expected %d rows got %d", tc.name, len(rows), len(actual))
+		}
+		for i, column := range []string{"string1", "int1", "double1"} {
+			groups, err := r.BloomFilters(0, column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(groups) != 3 {
+				t.Fatalf("Test failed, %s: expected 3 bloom filters for %s got %d", tc.name, column, len(groups))
+			}
+			for j, row := range rows {
+				if v := row[i]; !groups[j/100].MightContain(v) {
+					t.Errorf("Test failed, %s: expected row group %d to contain %v", tc.name, j/100, v)
+				}
+				// Strings that are not ASCII cannot be tested when hashed in the
+				// default charset of the writer, so are possibly contained by the
+				// other row groups too.
+				if str, ok := row[i].(string); ok && !isASCII(str) && !tc.nonASCII {
+					if !groups[(j/100+1)%3].MightContain(str) {
+						t.Errorf("Test failed, %s: expected %q to be reported as possibly contained", tc.name, str)
+					}
+				}
+			}
+			// Each bloom filter of 1024 bits with 3 hash functions reports
+			// around 2% of the missing values as possibly contained.
+			var misses int
+			for j := 1000; j < 1300; j++ {
+				missing := []interface{}{fmt.Sprintf("value %d", j), int64(j * 7), float64(j) / 4}
+				if !groups[0].MightContain(missing[i]) {
+					misses++
+				}
+			}
+			if misses < 270 {
+				t.Errorf("Test failed, %s: expected most missing %s values to be missing got %d of 300", tc.name, column, misses)
+			}
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups, err := r.BloomFilters(0, "string1"); err != nil || groups != nil {
+		t.Errorf("Test failed, expected no bloom filters got %v, %v", groups, err)
+	}
+	if _, err := r.BloomFilters(1, "string1"); err == nil {
+		t.Errorf("Test failed, expected error for an invalid stripe")
+	}
+	if _, err := r.BloomFilters(0, "missing"); err == nil {
+		t.Errorf("Test failed, expected error for an unknown column")
+	}
+
+}
+
+func TestReaderBloomFiltersPreRelease(t *testing.T) {
+
+	// The bloom filters of over1k_bloom.orc were written by a pre-release version
+	// of Hive, using a different layout to the released BloomFilter message.
+	b, err := ioutil.ReadFile("examples/over1k_bloom.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"_col3", "_col5", "_col7"} {
+		r, err := NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select(column)
+		for stripe := 0; c.Stripes(); stripe++ {
+			groups, err := r.BloomFilters(stripe, column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(groups) != 1 {
+				t.Fatalf("Test failed, expected 1 bloom filter got %d", len(groups))
+			}
+			for c.Next() {
+				if v := c.Row()[0]; !groups[0].MightContain(v) {
+					t.Errorf("Test failed, expected %s to possibly contain %v", column, v)
+				}
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
@@ -1,6 +1,9 @@
 package orc
 
-import "io"
+import (
+	"io"
+	"math/bits"
+)
 
 type BooleanReader struct {
 	*RunLengthByteReader
@@ -33,6 +36,50 @@ func (b *BooleanReader) Next() bool {
 	return true
 }
 
+// Skip skips the next n values. Whole bytes of values are skipped by the byte
+// reader, so that only the values in the bytes at either end of those skipped are
+// read individually. Skip returns io.EOF if fewer than n values remain.
+func (b *BooleanReader) Skip(n uint64) error {
+	_, err := b.skipBits(n)
+	return err
+}
+
+// skipBits skips the next n values, returning the number of them that are true.
+func (b *BooleanReader) skipBits(n uint64) (uint64, error) {
+	// Skip the values remaining in the current byte, which are its high bits.
+	k := uint64(b.bitsInData)
+	if k > n {
+		k = n
+	}
+	ones := uint64(bits.OnesCount8(b.data >> (8 - k)))
+	b.data <<= k
+	b.bitsInData -= int(k)
+	n -= k
+	if n == 0 {
+		return ones, nil
+	}
+
+	whole, err := b.RunLengthByteReader.skipBytes(n / 8)
+	ones += whole
+	if err != nil {
+		return ones, err
+	}
+	n %= 8
+	if n == 0 {
+		return ones, nil
+	}
+
+	// Skip the first values of the next byte, leaving the rest to be read.
+	if !b.RunLengthByteReader.Next() {
+		return ones, b.RunLengthByteReader.Err()
+	}
+	byt := b.RunLengthByteReader.Byte()
+	ones += uint64(bits.OnesCount8(byt >> (8 - n)))
+	b.data = byt << n
+	b.bitsInData = 8 - int(n)
+	return ones, nil
+}
+
 func (b *BooleanReader) Bool() bool {
 	return b.val
 }
@@ -3,6 +3,7 @@ package orc
 import (
 	"bytes"
 	"io"
+	"math/rand"
 	"reflect"
 	"testing"
 )
@@ -47,6 +48,114 @@ func TestBooleanReader(t *testing.T) {
 
 }
 
+// encodeBools returns the values encoded as a boolean stream.
+func encodeBools(t testing.TB, values []bool) []byte {
+	var buf bytes.Buffer
+	w := NewBooleanWriter(&buf)
+	for _, v := range values {
+		if err := w.WriteBool(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBooleanReaderSkip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// Runs of the same value encode as repeated runs of 0x00 or 0xff, between
+	// which are literal runs of random values.
+	var values []bool
+	for len(values) < 20000 {
+		n := rnd.Intn(2000)
+		switch rnd.Intn(3) {
+		case 0, 1:
+			v := rnd.Intn(2) == 0
+			for i := 0; i < n; i++ {
+				values = append(values, v)
+			}
+		case 2:
+			for i := 0; i < n; i++ {
+				values = append(values, rnd.Intn(2) == 0)
+			}
+		}
+	}
+	input := encodeBools(t, values)
+
+	for _, maxSkip := range []int{1, 7, 9, 64, 1000, 10000} {
+		r := NewBooleanReader(bytes.NewReader(input))
+		for i := 0; i < len(values); i++ {
+			if n := rnd.Intn(maxSkip + 1); n > 0 {
+				if i+n > len(values) {
+					n = len(values) - i
+				}
+				var expected uint64
+				for _, v := range values[i : i+n] {
+					if v {
+						expected++
+					}
+				}
+				ones, err := r.skipBits(uint64(n))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ones != expected {
+					t.Errorf("Test failed, skipping %d values from %d expected %d true got %d", n, i, expected, ones)
+				}
+				i += n
+				if i == len(values) {
+					break
+				}
+			}
+			if !r.Next() {
+				t.Fatalf("Test failed, expected value %d: %v", i, r.Err())
+			}
+			if r.Bool() != values[i] {
+				t.Fatalf("Test failed, max skip %d expected value %d to be %v", maxSkip, i, values[i])
+			}
+		}
+	}
+
+	// Skipping beyond the end of the stream, which is padded to whole bytes,
+	// returns io.EOF.
+	r := NewBooleanReader(bytes.NewReader(encodeBools(t, []bool{true, false, true})))
+	if err := r.Skip(8); err != nil {
+		t.Errorf("Test failed, expected no error got %v", err)
+	}
+	if err := r.Skip(1); err != io.EOF {
+		t.Errorf("Test failed, expected io.EOF got %v", err)
+	}
+}
+
+func BenchmarkBooleanReaderSkip(b *testing.B) {
+	values := make([]bool, 1<<22)
+	for i := range values {
+		values[i] = i%(1<<16) < 1<<15 || i%1000 == 0
+	}
+	input := encodeBools(b, values)
+
+	b.Run("skip", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := NewBooleanReader(bytes.NewReader(input))
+			if err := r.Skip(uint64(len(values))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("next", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r := NewBooleanReader(bytes.NewReader(input))
+			for j := 0; j < len(values); j++ {
+				if !r.Next() {
+					b.Fatal(r.Err())
+				}
+			}
+		}
+	})
+}
+
 func BenchmarkBooleanReader(b *testing.B) {
 	input := bytes.Repeat([]byte{0xff, 0x80}, b.N)
 	bs := NewBooleanReader(bytes.NewReader(input))
@@ -4,6 +4,8 @@ import (
 	"io"
 )
 
+// BooleanWriter writes boolean values as a bit field, most significant bit
+// first, which is then byte run length encoded to the underlying writer.
 type BooleanWriter struct {
 	*RunLengthByteWriter
 	bitsInData int
@@ -17,34 +19,46 @@ func NewBooleanWriter(w io.ByteWriter) *BooleanWriter {
 }
 
 func (b *BooleanWriter) WriteBool(t bool) error {
-	// If bitsInData is equal to 8 then write the byte
-	// to the underlying ByteStreamWriter.
-	if b.bitsInData >= 8 {
-		err := b.Flush()
-		if err != nil {
-			return err
-		}
-	}
 	if t {
 		// If true, toggle the bit at relevant position.
 		b.data |= (1 << uint(7-b.bitsInData))
 	}
 	b.bitsInData++
+	// Once all 8 bits of the current byte are used write the byte
+	// to the underlying RunLengthByteWriter.
+	if b.bitsInData == 8 {
+		return b.writeByte()
+	}
+	return nil
+}
+
+// writeByte writes the current, possibly partially filled, byte to the
+// RunLengthByteWriter. Any unused trailing bits are zero.
+func (b *BooleanWriter) writeByte() error {
+	err := b.RunLengthByteWriter.WriteByte(b.data)
+	if err != nil {
+		return err
+	}
+	b.bitsInData = 0
+	b.data = 0
 	return nil
 }
 
+// Flush writes any buffered runs of complete bytes to the underlying writer.
+// A partially filled byte is retained so that subsequent values continue
+// to fill it.
 func (b *BooleanWriter) Flush() error {
+	return b.RunLengthByteWriter.Flush()
+}
+
+// Close writes any partially filled byte, padding the unused bits with zeros,
+// and flushes the underlying RunLengthByteWriter. Readers ignore the padding
+// bits as the number of values is determined by the number of rows.
+func (b *BooleanWriter) Close() error {
 	if b.bitsInData > 0 {
-		err := b.RunLengthByteWriter.WriteByte(b.data)
-		if err != nil {
+		if err := b.writeByte(); err != nil {
 			return err
 		}
-		b.bitsInData = 0
-		b.data = 0
 	}
 	return b.RunLengthByteWriter.Flush()
 }
-
-func (b *BooleanWriter) Close() error {
-	return b.Flush()
-}
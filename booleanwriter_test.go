@@ -21,6 +21,49 @@ func TestBooleanWriter(t *testing.T) {
 				}
 			},
 		},
+		{
+			// Trailing bits of the final byte are padded with zeros.
+			input: []bool{true, true, true},
+			expect: func(output []byte) {
+				expected := []byte{0xff, 0xe0}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// Alternating bits.
+			input: []bool{
+				true, false, true, false, true, false, true, false,
+				true, false, true, false, true, false, true, false,
+			},
+			expect: func(output []byte) {
+				expected := []byte{0xfe, 0xaa, 0xaa}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// A run of complete bytes is run length encoded.
+			input: repeatBool(true, 80),
+			expect: func(output []byte) {
+				expected := []byte{0x07, 0xff}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// A run ending exactly on a byte boundary followed by a partial byte.
+			input: append(repeatBool(false, 24), true),
+			expect: func(output []byte) {
+				expected := []byte{0x00, 0x00, 0xff, 0x80}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -70,3 +113,41 @@ func TestWriteReadBools(t *testing.T) {
 		index++
 	}
 }
+
+func TestBooleanWriterFlush(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewBooleanWriter(&buf)
+	input := []bool{true, false, true, true, false, true, false, false, true, true, true}
+	for i := range input {
+		// Flushing between values must not pad partially filled bytes.
+		err := w.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = w.WriteBool(input[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewBooleanReader(&buf)
+	for i := range input {
+		if !r.Next() {
+			t.Fatalf("Test failed, expected value at index %v", i)
+		}
+		if b := r.Bool(); input[i] != b {
+			t.Errorf("Test failed, %v does not equal %v at index %v", b, input[i], i)
+		}
+	}
+}
+
+func repeatBool(b bool, n int) []bool {
+	bools := make([]bool, n)
+	for i := range bools {
+		bools[i] = b
+	}
+	return bools
+}
@@ -55,6 +55,13 @@ func (b *BufferedWriter) Positions() []uint64 {
 	}
 }
 
+// memoryUsage returns the number of bytes held in memory by the BufferedWriter,
+// being the capacity of the underlying bytes.Buffer along with the bytes yet to
+// be flushed to it. The fixed size chunk buffer itself is not included.
+func (b *BufferedWriter) memoryUsage() int64 {
+	return int64(b.Buffer.Cap() + b.Writer.Buffered())
+}
+
 // Close flushes any buffered bytes to the underlying writer.
 func (b *BufferedWriter) Close() error {
 	return b.Writer.Flush()
@@ -64,3 +71,12 @@ func (b *BufferedWriter) Close() error {
 func (b *BufferedWriter) Reset() {
 	b.Buffer.Reset()
 }
+
+// discard discards every byte written, including those yet to be flushed to the
+// underlying bytes.Buffer, so that the BufferedWriter is empty.
+func (b *BufferedWriter) discard() {
+	b.Writer.Reset(b.codec.Encoder(b.Buffer))
+	b.Buffer.Reset()
+	b.checkpoint = 0
+	b.written = 0
+}
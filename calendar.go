@@ -0,0 +1,89 @@
+package orc
+
+import (
+	"fmt"
+	"time"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// Calendar is the calendar in which the dates and timestamps of a file are
+// recorded, which differ for values before the adoption of the Gregorian calendar
+// on 15 October 1582.
+type Calendar int
+
+const (
+	// CalendarJulianGregorian is the hybrid calendar of Java, being the Julian
+	// calendar before 15 October 1582 and the Gregorian calendar from then on.
+	// Files that do not record their calendar use it unless they were written by
+	// this package.
+	CalendarJulianGregorian = Calendar(proto.CalendarKind_JULIAN_GREGORIAN)
+	// CalendarProlepticGregorian is the Gregorian calendar extended to dates
+	// before its adoption, as used by the time package and by this package when
+	// writing files.
+	CalendarProlepticGregorian = Calendar(proto.CalendarKind_PROLEPTIC_GREGORIAN)
+)
+
+func (c Calendar) String() string {
+	switch c {
+	case CalendarJulianGregorian:
+		return "Julian/Gregorian"
+	case CalendarProlepticGregorian:
+		return "proleptic Gregorian"
+	}
+	return fmt.Sprintf("Calendar(%d)", int(c))
+}
+
+// gregorianSwitchoverDays is the number of days since the Unix epoch of 15 October
+// 1582, the first day of the Gregorian calendar.
+const gregorianSwitchoverDays = -141427
+
+// julianDate returns the date in the Julian calendar of the day at the provided
+// number of days since the Unix epoch.
+func julianDate(days int64) (year int, month time.Month, day int) {
+	// Convert the Julian day number to a date in the Julian calendar, as by
+	// the algorithm of Richards, using floored divisions for days before the
+	// start of the Julian period.
+	f := days + 2440588 + 1401
+	e := 4*f + 3
+	g := (e%1461 + 1461) % 1461 / 4
+	h := 5*g + 2
+	day = int(h%153/5) + 1
+	month = time.Month((h/153+2)%12 + 1)
+	year = int(floorDiv(e, 1461)) - 4716 + (14-int(month))/12
+	return year, month, day
+}
+
+// floorDiv returns x divided by y rounded down.
+func floorDiv(x, y int64) int64 {
+	q := x / y
+	if (x%y != 0) && ((x < 0) != (y < 0)) {
+		q--
+	}
+	return q
+}
+
+// julianGregorianDays returns the number of days since the Unix epoch in the
+// proleptic Gregorian calendar of the date recorded by a writer using the hybrid
+// Julian and Gregorian calendar as the provided number of days, such that the
+// date has the same year, month and day in both calendars.
+func julianGregorianDays(days int64) int64 {
+	if days >= gregorianSwitchoverDays {
+		return days
+	}
+	year, month, day := julianDate(days)
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// julianGregorianTime returns the time in the proleptic Gregorian calendar of a
+// timestamp recorded by a writer using the hybrid Julian and Gregorian calendar,
+// having the same date and time of day in the location of t in both calendars.
+func julianGregorianTime(t time.Time) time.Time {
+	year, month, day := t.Date()
+	days := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400
+	if days >= gregorianSwitchoverDays {
+		return t
+	}
+	year, month, day = julianDate(days)
+	return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
@@ -0,0 +1,127 @@
+package orc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+func TestJulianGregorianDays(t *testing.T) {
+
+	testCases := []struct {
+		// julian is the date in the Julian calendar of the days recorded.
+		julian time.Time
+		// days are the days since the Unix epoch recorded by a writer using the
+		// hybrid calendar, given by the same day in the proleptic Gregorian
+		// calendar.
+		days time.Time
+	}{
+		{julian: time.Date(1582, 10, 4, 0, 0, 0, 0, time.UTC), days: time.Date(1582, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1500, 1, 1, 0, 0, 0, 0, time.UTC), days: time.Date(1500, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1200, 12, 31, 0, 0, 0, 0, time.UTC), days: time.Date(1201, 1, 7, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), days: time.Date(0, 12, 30, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(-100, 3, 1, 0, 0, 0, 0, time.UTC), days: time.Date(-100, 2, 27, 0, 0, 0, 0, time.UTC)},
+		// The Julian calendar has a leap day in 1000 which the proleptic
+		// Gregorian calendar does not, so it is normalized to 1 March.
+		{julian: time.Date(1000, 2, 28, 0, 0, 0, 0, time.UTC), days: time.Date(1000, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1000, 3, 1, 0, 0, 0, 0, time.UTC), days: time.Date(1000, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1000, 3, 1, 0, 0, 0, 0, time.UTC), days: time.Date(1000, 3, 7, 0, 0, 0, 0, time.UTC)},
+		// Dates from the adoption of the Gregorian calendar are unchanged.
+		{julian: time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC), days: time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{julian: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), days: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		days := julianGregorianDays(tc.days.Unix() / 86400)
+		if actual := time.Unix(days*86400, 0).UTC(); !actual.Equal(tc.julian) {
+			t.Errorf("Test failed, expected %s for %s got %s", tc.julian.Format("2006-01-02"), tc.days.Format("2006-01-02"), actual.Format("2006-01-02"))
+		}
+	}
+
+}
+
+func TestReaderCalendar(t *testing.T) {
+
+	schema, err := ParseSchema("struct<date1:date,timestamp1:timestamp>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The values recorded, which are 1500-01-01 and 2000-02-03 in the hybrid
+	// calendar.
+	rows := [][]interface{}{
+		{Date{time.Date(1500, 1, 10, 0, 0, 0, 0, time.UTC)}, time.Date(1500, 1, 10, 12, 34, 56, 789000000, time.UTC)},
+		{Date{time.Date(2000, 2, 3, 0, 0, 0, 0, time.UTC)}, time.Date(2000, 2, 3, 4, 5, 6, 0, time.UTC)},
+	}
+	hybrid := [][]interface{}{
+		{Date{time.Date(1500, 1, 1, 0, 0, 0, 0, time.UTC)}, time.Date(1500, 1, 1, 12, 34, 56, 789000000, time.UTC)},
+		rows[1],
+	}
+
+	testCases := []struct {
+		calendar *proto.CalendarKind
+		writer   uint32
+		opts     []ReaderConfigFunc
+		expected Calendar
+	}{
+		{calendar: proto.CalendarKind_PROLEPTIC_GREGORIAN.Enum(), expected: CalendarProlepticGregorian},
+		{calendar: proto.CalendarKind_JULIAN_GREGORIAN.Enum(), expected: CalendarJulianGregorian},
+		{calendar: proto.CalendarKind_JULIAN_GREGORIAN.Enum(), opts: []ReaderConfigFunc{WithCalendar(CalendarProlepticGregorian)}, expected: CalendarProlepticGregorian},
+		{calendar: proto.CalendarKind_PROLEPTIC_GREGORIAN.Enum(), opts: []ReaderConfigFunc{WithCalendar(CalendarJulianGregorian)}, expected: CalendarJulianGregorian},
+		// Files without a calendar are in the hybrid calendar unless written by
+		// this package.
+		{writer: WriterGo, expected: CalendarProlepticGregorian},
+		{writer: 0, expected: CalendarJulianGregorian},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(time.UTC))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.footer.Calendar = tc.calendar
+		w.footer.Writer = ptrUint32(tc.writer)
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := r.Calendar(); c != tc.expected {
+			t.Errorf("Test failed, expected calendar %s got %s", tc.expected, c)
+		}
+		expected := rows
+		if tc.expected == CalendarJulianGregorian {
+			expected = hybrid
+		}
+		actual, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actual) != len(expected) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(expected), len(actual))
+		}
+		for i := range actual {
+			if date := actual[i][0].(Date); !date.Equal(expected[i][0].(Date).Time) {
+				t.Errorf("Test failed, calendar %s expected date %s got %s", tc.expected, expected[i][0], date)
+			}
+			if ts := actual[i][1].(time.Time); !ts.Equal(expected[i][1].(time.Time)) {
+				t.Errorf("Test failed, calendar %s expected timestamp %s got %s", tc.expected, expected[i][1], ts)
+			}
+		}
+	}
+
+	if _, err := NewReader(bytes.NewReader(nil), WithCalendar(0)); err == nil || err.Error() != "invalid calendar Calendar(0)" {
+		t.Errorf("Test failed, expected an invalid calendar error got %v", err)
+	}
+
+}
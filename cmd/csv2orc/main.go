@@ -0,0 +1,139 @@
+// Command csv2orc converts a CSV file to an ORC file.
+//
+// Usage:
+//
+//	csv2orc [flags] input.csv output.orc
+//
+// The input is read from stdin if its path is "-". The -schema flag sets the type
+// of the rows, such as "struct<id:bigint,name:string,day:date>", which must be a
+// struct with a primitive field, other than binary, for each column of the CSV
+// records. Without it every column is a string named by the header record.
+//
+// Fields parse as in orc.WriteORCFromCSV: empty fields are null values except
+// in string columns, dates parse in the layout of the -date-layout flag and
+// timestamps in RFC 3339. With -skip-malformed, records that cannot be parsed
+// are reported with their line to stderr and left out of the output.
+//
+// The output is not compressed, as the writer writes only uncompressed files.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "csv2orc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run converts the CSV file named by the first of args, or read from stdin, to
+// the ORC file named by the second, configured by the flags of args.
+func run(args []string, stdin io.Reader, stderr io.Writer) error {
+	fs := flag.NewFlagSet("csv2orc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schema := fs.String("schema", "", "type of the rows, by default a string column for each field of the header")
+	header := fs.Bool("header", true, "whether the first record is a header, which is skipped")
+	delimiter := fs.String("delimiter", ",", "character separating the fields of the CSV records")
+	dateLayout := fs.String("date-layout", "2006-01-02", "layout of the time package in which dates are parsed")
+	emptyNull := fs.Bool("empty-null", false, "write empty fields of string columns as null values")
+	skipMalformed := fs.Bool("skip-malformed", false, "skip records that cannot be parsed, reporting them to stderr, in place of failing")
+	stripeSize := fs.Int64("stripe-size", orc.DefaultStripeTargetSize, "target size in bytes of the stripes")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: csv2orc [flags] input.csv output.orc\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected the paths of the CSV and ORC files")
+	}
+	comma, size := utf8.DecodeRuneInString(*delimiter)
+	if comma == utf8.RuneError || size != len(*delimiter) {
+		return fmt.Errorf("invalid delimiter %q", *delimiter)
+	}
+	if !*header && *schema == "" {
+		return errors.New("a schema is required for CSV files without a header")
+	}
+
+	in := stdin
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	csvr := csv.NewReader(bufio.NewReader(in))
+	csvr.Comma = comma
+	csvr.ReuseRecord = true
+	// Records with the wrong number of fields are reported with the line of the
+	// record by WriteORCFromCSVWithOptions.
+	csvr.FieldsPerRecord = -1
+
+	var td *orc.TypeDescription
+	if *schema != "" {
+		var err error
+		if td, err = orc.ParseSchema(*schema); err != nil {
+			return err
+		}
+	}
+	if *header {
+		record, err := csvr.Read()
+		if err == io.EOF {
+			return errors.New("expected a header record")
+		}
+		if err != nil {
+			return err
+		}
+		if td == nil {
+			if td, err = orc.CSVHeaderSchema(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	f, err := os.Create(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	skipped := 0
+	err = orc.WriteORCFromCSVWithOptions(w, csvr, td, orc.CSVOptions{
+		DateLayout:         *dateLayout,
+		EmptyStringsAsNull: *emptyNull,
+		SkipMalformed:      *skipMalformed,
+		OnMalformed: func(err error) {
+			skipped++
+			fmt.Fprintf(stderr, "skipped %v\n", err)
+		},
+	}, orc.WithStripeTargetSize(*stripeSize))
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Leave no partial file in place of the output.
+		os.Remove(fs.Arg(1))
+		return err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(stderr, "skipped %d malformed records\n", skipped)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+const testSchema = "struct<id:int,customer:string,price:decimal(10,2),ordered:date,shipped:timestamp,paid:boolean,weight:double>"
+
+// readRows returns the schema of the ORC file at path and its rows, each value
+// formatted with its type.
+func readRows(t *testing.T, path string) (string, [][]string) {
+	t.Helper()
+	r, err := orc.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	c := r.Select(r.Schema().Columns()...)
+	var rows [][]string
+	for c.Stripes() {
+		for c.Next() {
+			var row []string
+			for _, v := range c.Row() {
+				switch v := v.(type) {
+				case orc.Date:
+					row = append(row, "date "+v.Format("2006-01-02"))
+				case time.Time:
+					row = append(row, "timestamp "+v.UTC().Format(time.RFC3339Nano))
+				case nil:
+					row = append(row, "null")
+				default:
+					row = append(row, fmt.Sprintf("%T %v", v, v))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return r.Schema().String(), rows
+}
+
+func TestRun(t *testing.T) {
+
+	out := filepath.Join(t.TempDir(), "orders.orc")
+	var stderr bytes.Buffer
+	args := []string{"-schema", testSchema, "-date-layout", "01/02/2006", "-skip-malformed", "testdata/orders.csv", out}
+	if err := run(args, nil, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	expectedErrors := `skipped line 5, column 1: id: strconv.ParseInt: parsing "x": invalid syntax
+skipped line 6, column 12: ordered: parsing time "2020-03-05": month out of range
+skipped line 7: wrong number of fields, expected: 7, got: 3
+skipped 3 malformed records
+`
+	if actual := stderr.String(); actual != expectedErrors {
+		t.Errorf("Test failed, expected errors:\n%s got:\n%s", expectedErrors, actual)
+	}
+
+	schema, rows := readRows(t, out)
+	if schema != testSchema {
+		t.Errorf("Test failed, expected schema %s got %s", testSchema, schema)
+	}
+	expected := [][]string{
+		{"int64 1", "string Ann", "orc.Decimal 12.50", "date 2020-03-01", "timestamp 2020-03-02T10:30:00Z", "bool true", "orc.Double 1.5"},
+		{"int64 2", "string ", "orc.Decimal 0.99", "date 2020-03-02", "null", "bool false", "null"},
+		{"int64 3", "string Smith, Bob", "orc.Decimal -4.25", "null", "timestamp 2020-03-04T06:00:00.5Z", "null", "orc.Double 2.25"},
+		{"int64 7", `string Fay "F"`, "orc.Decimal 100.01", "date 2019-12-31", "timestamp 2019-12-31T23:59:59Z", "bool true", "orc.Double 0"},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected rows %q got %q", expected, rows)
+	}
+
+}
+
+func TestRunHeaderSchema(t *testing.T) {
+
+	out := filepath.Join(t.TempDir(), "out.orc")
+	stdin := strings.NewReader("a;b\n1;\n;x\n")
+	var stderr bytes.Buffer
+	if err := run([]string{"-delimiter", ";", "-empty-null", "-", out}, stdin, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	schema, rows := readRows(t, out)
+	if expected := "struct<a:string,b:string>"; schema != expected {
+		t.Errorf("Test failed, expected schema %s got %s", expected, schema)
+	}
+	if expected := [][]string{{"string 1", "null"}, {"null", "string x"}}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected rows %q got %q", expected, rows)
+	}
+
+}
+
+func TestRunErrors(t *testing.T) {
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.orc")
+
+	testCases := []struct {
+		args  []string
+		stdin string
+		err   string
+	}{
+		{
+			args: []string{"-schema", testSchema, "-date-layout", "01/02/2006", "testdata/orders.csv", out},
+			err:  `line 5, column 1: id: strconv.ParseInt: parsing "x": invalid syntax`,
+		},
+		{
+			args:  []string{"-", out},
+			stdin: "a,a\n",
+			err:   "duplicate column a of the CSV header",
+		},
+		{
+			args: []string{"-", out},
+			err:  "expected a header record",
+		},
+		{
+			args: []string{"-header=false", "-", out},
+			err:  "a schema is required for CSV files without a header",
+		},
+		{
+			args: []string{"-delimiter", ",,", "-", out},
+			err:  `invalid delimiter ",,"`,
+		},
+		{
+			args: []string{"-stripe-size", "0", "testdata/orders.csv", out},
+			err:  "invalid stripe target size 0",
+		},
+		{
+			args: []string{"testdata/orders.csv"},
+			err:  "expected the paths of the CSV and ORC files",
+		},
+	}
+
+	for _, tc := range testCases {
+		var stderr bytes.Buffer
+		err := run(tc.args, strings.NewReader(tc.stdin), &stderr)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("Test failed, expected error %q got %v", tc.err, err)
+		}
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Errorf("Test failed, expected no output for %q got %v", tc.args, err)
+		}
+	}
+
+}
@@ -0,0 +1,104 @@
+// Command json2orc converts a file of JSON objects, such as newline-delimited
+// JSON, to an ORC file.
+//
+// Usage:
+//
+//	json2orc [flags] input.json output.orc
+//
+// The input is read from stdin if its path is "-". The -schema flag sets the type
+// of the rows, such as "struct<id:bigint,tags:array<string>>", whose fields are
+// named by the keys of the objects. Without it the schema is inferred from the
+// first objects, as many as the -infer flag sets, and printed to stderr.
+//
+// Values parse as in orc.WriteORCFromJSON, which is the inverse of the output of
+// orc-cat other than for binary values, maps and unions.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "json2orc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run converts the JSON file named by the first of args, or read from stdin, to
+// the ORC file named by the second, configured by the flags of args.
+func run(args []string, stdin io.Reader, stderr io.Writer) error {
+	fs := flag.NewFlagSet("json2orc", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	schema := fs.String("schema", "", "type of the rows, by default inferred from the first objects")
+	infer := fs.Int("infer", 1000, "number of objects from which the schema is inferred without -schema, or 0 for every object")
+	stripeSize := fs.Int64("stripe-size", orc.DefaultStripeTargetSize, "target size in bytes of the stripes")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: json2orc [flags] input.json output.orc\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("expected the paths of the JSON and ORC files")
+	}
+	if *infer < 0 {
+		return fmt.Errorf("invalid number of objects to infer from %d", *infer)
+	}
+
+	var in io.Reader = bufio.NewReader(stdin)
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = bufio.NewReader(f)
+	}
+
+	var td *orc.TypeDescription
+	if *schema != "" {
+		var err error
+		if td, err = orc.ParseSchema(*schema); err != nil {
+			return err
+		}
+	} else {
+		// The objects read to infer the schema are read again to be written.
+		var sample bytes.Buffer
+		var err error
+		if td, err = orc.InferJSONSchema(io.TeeReader(in, &sample), *infer); err != nil {
+			return err
+		}
+		in = io.MultiReader(&sample, in)
+		fmt.Fprintf(stderr, "schema: %s\n", td)
+	}
+
+	f, err := os.Create(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = orc.WriteORCFromJSON(w, in, td, orc.WithStripeTargetSize(*stripeSize))
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Leave no partial file in place of the output.
+		os.Remove(fs.Arg(1))
+		return err
+	}
+	return nil
+}
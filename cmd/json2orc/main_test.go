@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+const testSchema = "struct<id:bigint,user:struct<name:string,age:int>,tags:array<string>,counts:map<string,int>,at:timestamp>"
+
+// readRows returns the schema of the ORC file at path and its rows formatted with
+// fmt.
+func readRows(t *testing.T, path string) (string, []string) {
+	t.Helper()
+	r, err := orc.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	c := r.Select(r.Schema().Columns()...)
+	var rows []string
+	for c.Stripes() {
+		for c.Next() {
+			row := c.Row()
+			for i, v := range row {
+				if v, ok := v.(time.Time); ok {
+					row[i] = v.UTC().Format(time.RFC3339)
+				}
+			}
+			rows = append(rows, fmt.Sprint(row))
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return r.Schema().String(), rows
+}
+
+func TestRun(t *testing.T) {
+
+	out := filepath.Join(t.TempDir(), "events.orc")
+	var stderr bytes.Buffer
+	if err := run([]string{"-schema", testSchema, "testdata/events.jsonl", out}, nil, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	schema, rows := readRows(t, out)
+	if schema != testSchema {
+		t.Errorf("Test failed, expected schema %s got %s", testSchema, schema)
+	}
+	expected := []string{
+		"[1 map[age:31 name:ann] [a b] [{x 1}] 2020-03-02T10:30:00Z]",
+		"[2 <nil> [] [] <nil>]",
+		"[9223372036854775807 map[age:<nil> name:bob] <nil> [{y 2} {z <nil>}] <nil>]",
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected rows %q got %q", expected, rows)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Test failed, expected no output to stderr got %q", stderr.String())
+	}
+
+}
+
+func TestRunInfer(t *testing.T) {
+
+	b, err := ioutil.ReadFile("testdata/events.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "events.orc")
+	var stderr bytes.Buffer
+	// The schema is inferred from the first two objects only.
+	if err := run([]string{"-infer", "2", "-", out}, bytes.NewReader(b), &stderr); err != nil {
+		t.Fatal(err)
+	}
+	expectedSchema := "struct<id:bigint,user:struct<name:string,age:bigint>,tags:array<string>,counts:struct<x:bigint>,at:string,extra:boolean>"
+	if actual := stderr.String(); actual != "schema: "+expectedSchema+"\n" {
+		t.Errorf("Test failed, expected the inferred schema to be printed got %q", actual)
+	}
+	schema, rows := readRows(t, out)
+	if schema != expectedSchema {
+		t.Errorf("Test failed, expected schema %s got %s", expectedSchema, schema)
+	}
+	expected := []string{
+		"[1 map[age:31 name:ann] [a b] map[x:1] 2020-03-02T10:30:00Z <nil>]",
+		"[2 <nil> [] map[x:<nil>] <nil> true]",
+		"[9223372036854775807 map[age:<nil> name:bob] <nil> map[x:<nil>] <nil> <nil>]",
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected rows %q got %q", expected, rows)
+	}
+
+}
+
+func TestRunErrors(t *testing.T) {
+
+	out := filepath.Join(t.TempDir(), "out.orc")
+
+	testCases := []struct {
+		args  []string
+		stdin string
+		err   string
+	}{
+		{
+			args: []string{"-schema", "struct<id:int>", "testdata/events.jsonl", out},
+			err:  `record 3: id: strconv.ParseInt: parsing "9223372036854775807": value out of range`,
+		},
+		{
+			args: []string{"-schema", "struct<tags:string>", "testdata/events.jsonl", out},
+			err:  "record 1: tags: expected a value of type string, got an array",
+		},
+		{
+			args:  []string{"-", out},
+			stdin: `{"a": 1} {"a": [1]}`,
+			err:   "record 2: a: cannot infer a type of both bigint and array values",
+		},
+		{
+			args: []string{"-infer", "-1", "-", out},
+			err:  "invalid number of objects to infer from -1",
+		},
+		{
+			args: []string{"-stripe-size", "0", "testdata/events.jsonl", out},
+			err:  "invalid stripe target size 0",
+		},
+		{
+			args: []string{"testdata/events.jsonl"},
+			err:  "expected the paths of the JSON and ORC files",
+		},
+	}
+
+	for _, tc := range testCases {
+		var stderr bytes.Buffer
+		err := run(tc.args, strings.NewReader(tc.stdin), &stderr)
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("Test failed, expected error %q got %v", tc.err, err)
+		}
+		if _, err := os.Stat(out); !os.IsNotExist(err) {
+			t.Errorf("Test failed, expected no output for %q got %v", tc.args, err)
+		}
+	}
+
+}
@@ -0,0 +1,418 @@
+// Command orc-cat prints the rows of an ORC file as newline-delimited JSON, one
+// object per row with the selected columns as its fields in order.
+//
+// Usage:
+//
+//	orc-cat [flags] file.orc
+//
+// Null values print as null, timestamps in RFC 3339, dates as "2006-01-02",
+// binary values in base64 and decimals as strings holding their exact value. Maps
+// print as lists of objects with "_key" and "_value" fields and unions as objects
+// with "tag" and "value" fields, following the output of the Java orc-tools data
+// command. NaN and infinite floating point values print as the strings "NaN",
+// "Infinity" and "-Infinity".
+//
+// The -where flag prints only the rows in which a column compares with a value,
+// as in "id=3", "price>=10.5" or `name!="a b"`. Stripes that the statistics of the
+// column show cannot match are skipped without being read.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "orc-cat: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run prints the rows of the file named by args, configured by the flags of args,
+// to stdout.
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("orc-cat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	columns := fs.String("columns", "", "comma-separated columns to print, by default every top-level column; nested fields are named as a.b")
+	limit := fs.Int64("limit", -1, "maximum number of rows to print, or -1 for every row")
+	offset := fs.Int64("offset", 0, "number of rows, after any -where predicate, to skip before printing")
+	stripes := fs.String("stripe", "", "comma-separated indexes of the stripes to read, by default every stripe")
+	where := fs.String("where", "", "predicate that printed rows match, comparing a column with a value by =, !=, <, <=, > or >=")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: orc-cat [flags] file.orc\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected the path of a single ORC file")
+	}
+	if *offset < 0 {
+		return fmt.Errorf("invalid offset %d", *offset)
+	}
+
+	var pred *predicate
+	if *where != "" {
+		var err error
+		if pred, err = parsePredicate(*where); err != nil {
+			return err
+		}
+	}
+	var stripeSet map[int]bool
+	if *stripes != "" {
+		stripeSet = make(map[int]bool)
+		for _, s := range strings.Split(*stripes, ",") {
+			i, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil || i < 0 {
+				return fmt.Errorf("invalid stripe %q", s)
+			}
+			stripeSet[i] = true
+		}
+	}
+
+	var opts []orc.ReaderConfigFunc
+	if stripeSet != nil || pred != nil {
+		opts = append(opts, orc.WithStripeFilter(func(stripe int, stats []orc.ColumnStatistics) bool {
+			if stripeSet != nil && !stripeSet[stripe] {
+				return false
+			}
+			return pred == nil || pred.mayMatch(stats)
+		}))
+	}
+	r, err := orc.Open(fs.Arg(0), opts...)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	types := r.Schema().Types()
+	names := r.Schema().Columns()
+	if *columns != "" {
+		names = strings.Split(*columns, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+	}
+	for _, name := range names {
+		if _, err := columnID(types, name); err != nil {
+			return err
+		}
+	}
+	selected := names
+	predIndex := -1
+	if pred != nil {
+		id, err := columnID(types, pred.column)
+		if err != nil {
+			return err
+		}
+		if err := pred.bind(types[id], id); err != nil {
+			return err
+		}
+		for i, name := range names {
+			if name == pred.column {
+				predIndex = i
+			}
+		}
+		if predIndex < 0 {
+			selected = append(append([]string(nil), names...), pred.column)
+			predIndex = len(names)
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	var printed, skipped int64
+	c := r.Select(selected...)
+	// The column of the predicate may follow the printed columns.
+	_, cols := c.Columns()
+	cols = cols[:len(names)]
+	for c.Stripes() {
+		for *limit < 0 || printed < *limit {
+			// Without a predicate the rows before the offset are skipped
+			// without decoding them.
+			if pred == nil && skipped < *offset {
+				if n := c.Skip(int(*offset - skipped)); n > 0 {
+					skipped += int64(n)
+					continue
+				}
+			}
+			if !c.Next() {
+				break
+			}
+			row := c.Row()
+			if pred != nil && !pred.match(row[predIndex]) {
+				continue
+			}
+			if skipped < *offset {
+				skipped++
+				continue
+			}
+			b, err := orc.ColumnsToJSON(names, cols, row[:len(names)])
+			if err != nil {
+				return err
+			}
+			if _, err := out.Write(append(b, '\n')); err != nil {
+				return err
+			}
+			printed++
+		}
+		if *limit >= 0 && printed >= *limit {
+			break
+		}
+	}
+	if err := c.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// columnID returns the ID of the column with the provided name, the names of
+// nested fields being separated by dots, within the types of a file.
+func columnID(types []*proto.Type, name string) (int, error) {
+	var id uint32
+	for _, field := range strings.Split(name, ".") {
+		t := types[id]
+		var found bool
+		if t.GetKind() == proto.Type_STRUCT {
+			for i, fieldName := range t.GetFieldNames() {
+				if fieldName == field {
+					id, found = t.GetSubtypes()[i], true
+					break
+				}
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("no column named %s", name)
+		}
+	}
+	return int(id), nil
+}
+
+// predicateRegexp matches a predicate comparing a column with a value.
+var predicateRegexp = regexp.MustCompile(`^\s*([^=!<>\s]+)\s*(=|!=|<=|>=|<|>)\s*(.*?)\s*$`)
+
+// predicate compares the values of a column with a value.
+type predicate struct {
+	column string
+	op     string
+	// literal is the value compared with, which is parsed as value per the type
+	// of the column once bound.
+	literal string
+	kind    proto.Type_Kind
+	id      int
+	value   interface{}
+}
+
+// parsePredicate parses a predicate such as "id>=3" or `name="a b"`.
+func parsePredicate(s string) (*predicate, error) {
+	m := predicateRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid predicate %q, expected a column, an operator and a value such as id>=3", s)
+	}
+	literal := m[3]
+	if strings.HasPrefix(literal, `"`) {
+		unquoted, err := strconv.Unquote(literal)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted value %s", literal)
+		}
+		literal = unquoted
+	}
+	return &predicate{column: m[1], op: m[2], literal: literal}, nil
+}
+
+// bind parses the value of the predicate per the type of the column with the
+// provided ID.
+func (p *predicate) bind(t *proto.Type, id int) error {
+	p.kind, p.id = t.GetKind(), id
+	var err error
+	switch p.kind {
+	case proto.Type_BYTE, proto.Type_SHORT, proto.Type_INT, proto.Type_LONG:
+		p.value, err = strconv.ParseInt(p.literal, 10, 64)
+	case proto.Type_FLOAT, proto.Type_DOUBLE:
+		p.value, err = strconv.ParseFloat(p.literal, 64)
+	case proto.Type_STRING, proto.Type_VARCHAR, proto.Type_CHAR:
+		p.value = p.literal
+	case proto.Type_BOOLEAN:
+		if p.op != "=" && p.op != "!=" {
+			return fmt.Errorf("boolean column %s can only be compared by = or !=", p.column)
+		}
+		p.value, err = strconv.ParseBool(p.literal)
+	case proto.Type_DATE:
+		p.value, err = time.Parse("2006-01-02", p.literal)
+	case proto.Type_TIMESTAMP, proto.Type_TIMESTAMP_INSTANT:
+		p.value, err = time.Parse(time.RFC3339Nano, p.literal)
+	default:
+		return fmt.Errorf("cannot compare column %s of type %s", p.column, strings.ToLower(p.kind.String()))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value for column %s: %v", p.column, err)
+	}
+	return nil
+}
+
+// compare returns the sign of v compared with the value of the predicate, and
+// false if they cannot be compared, as when v is null or NaN.
+func (p *predicate) compare(v interface{}) (int, bool) {
+	switch x := v.(type) {
+	case int8:
+		return compareInt64(int64(x), p.value.(int64)), true
+	case int64:
+		return compareInt64(x, p.value.(int64)), true
+	case orc.Float:
+		return compareFloat64(float64(x), p.value.(float64))
+	case orc.Double:
+		return compareFloat64(float64(x), p.value.(float64))
+	case float64:
+		return compareFloat64(x, p.value.(float64))
+	case string:
+		return strings.Compare(x, p.value.(string)), true
+	case bool:
+		if x == p.value.(bool) {
+			return 0, true
+		}
+		return 1, true
+	case orc.Date:
+		return compareTime(x.Time, p.value.(time.Time)), true
+	case time.Time:
+		return compareTime(x, p.value.(time.Time)), true
+	}
+	return 0, false
+}
+
+// match returns whether the value v of a row matches the predicate. Null values
+// match no predicate.
+func (p *predicate) match(v interface{}) bool {
+	c, ok := p.compare(v)
+	if !ok {
+		return false
+	}
+	switch p.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// mayMatch returns whether a stripe with the provided statistics may contain a
+// row matching the predicate, being false only when the minimum and maximum of
+// the column exclude every matching value or the column has only nulls.
+func (p *predicate) mayMatch(stats []orc.ColumnStatistics) bool {
+	if p.id >= len(stats) {
+		return true
+	}
+	pb := stats[p.id].Statistics()
+	if pb.NumberOfValues != nil && pb.GetNumberOfValues() == 0 {
+		return false
+	}
+	var min, max interface{}
+	switch p.kind {
+	case proto.Type_BYTE, proto.Type_SHORT, proto.Type_INT, proto.Type_LONG:
+		is := pb.GetIntStatistics()
+		if is == nil || is.Minimum == nil || is.Maximum == nil {
+			return true
+		}
+		min, max = is.GetMinimum(), is.GetMaximum()
+	case proto.Type_FLOAT, proto.Type_DOUBLE:
+		lo, hi, ok := orc.DoubleRange(stats[p.id])
+		if !ok {
+			return true
+		}
+		min, max = lo, hi
+	case proto.Type_STRING, proto.Type_VARCHAR, proto.Type_CHAR:
+		ss := pb.GetStringStatistics()
+		if ss == nil {
+			return true
+		}
+		// Truncated statistics bound the values by their lower and upper bounds
+		// in place of their minimum and maximum.
+		switch {
+		case ss.Minimum != nil:
+			min = ss.GetMinimum()
+		case ss.LowerBound != nil:
+			min = ss.GetLowerBound()
+		default:
+			return true
+		}
+		switch {
+		case ss.Maximum != nil:
+			max = ss.GetMaximum()
+		case ss.UpperBound != nil:
+			max = ss.GetUpperBound()
+		default:
+			return true
+		}
+	default:
+		return true
+	}
+	lo, lok := p.compare(min)
+	hi, hok := p.compare(max)
+	if !lok || !hok {
+		return true
+	}
+	switch p.op {
+	case "=":
+		return lo <= 0 && hi >= 0
+	case "!=":
+		return lo != 0 || hi != 0
+	case "<":
+		return lo < 0
+	case "<=":
+		return lo <= 0
+	case ">":
+		return hi > 0
+	}
+	return hi >= 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) (int, bool) {
+	switch {
+	case a < b:
+		return -1, true
+	case a > b:
+		return 1, true
+	case a == b:
+		return 0, true
+	}
+	return 0, false
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+// writeTestFile writes an ORC file of two stripes to a temporary directory and
+// returns its path.
+func writeTestFile(t *testing.T) string {
+	t.Helper()
+	schema, err := orc.ParseSchema("struct<id:int,name:string,price:double,day:date,at:timestamp,amount:decimal(10,2),tags:array<string>,attrs:map<string,int>,point:struct<x:int,y:int>,choice:uniontype<int,string>,flag:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile(t.TempDir(), "*.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w, err := orc.NewWriter(f, orc.SetSchema(schema), orc.WithWriterTimezone(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes := [][][]interface{}{
+		{
+			{int64(1), "a<b", 1.5, orc.Date{Time: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}, time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC), "12.30", []interface{}{"x", "y"}, []orc.MapEntry{{Key: "k", Value: int64(1)}}, []interface{}{int64(1), int64(2)}, orc.UnionValue{Tag: 0, Value: int64(7)}, true},
+			{int64(2), nil, math.NaN(), nil, nil, nil, nil, nil, nil, nil, nil},
+		},
+		{
+			{int64(3), "c", math.Inf(1), nil, nil, "-0.05", []interface{}{}, []orc.MapEntry{}, []interface{}{nil, int64(4)}, orc.UnionValue{Tag: 1, Value: "s"}, false},
+			{int64(4), "d", 4.0, nil, nil, nil, nil, nil, nil, nil, true},
+		},
+	}
+	for _, rows := range stripes {
+		for _, row := range rows {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestRun(t *testing.T) {
+
+	path := writeTestFile(t)
+	row1 := `{"id":1,"name":"a<b","price":1.5,"day":"2020-01-02","at":"2020-01-02T03:04:05.6Z","amount":"12.30","tags":["x","y"],"attrs":[{"_key":"k","_value":1}],"point":{"x":1,"y":2},"choice":{"tag":0,"value":7},"flag":true}`
+	row2 := `{"id":2,"name":null,"price":"NaN","day":null,"at":null,"amount":null,"tags":null,"attrs":null,"point":null,"choice":null,"flag":null}`
+	row3 := `{"id":3,"name":"c","price":"Infinity","day":null,"at":null,"amount":"-0.05","tags":[],"attrs":[],"point":{"x":null,"y":4},"choice":{"tag":1,"value":"s"},"flag":false}`
+	row4 := `{"id":4,"name":"d","price":4,"day":null,"at":null,"amount":null,"tags":null,"attrs":null,"point":null,"choice":null,"flag":true}`
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: nil, expected: []string{row1, row2, row3, row4}},
+		{args: []string{"-columns", "name,id"}, expected: []string{`{"name":"a<b","id":1}`, `{"name":null,"id":2}`, `{"name":"c","id":3}`, `{"name":"d","id":4}`}},
+		{args: []string{"-columns", "point"}, expected: []string{`{"point":{"x":1,"y":2}}`, `{"point":null}`, `{"point":{"x":null,"y":4}}`, `{"point":null}`}},
+		{args: []string{"-limit", "2"}, expected: []string{row1, row2}},
+		{args: []string{"-limit", "0"}, expected: nil},
+		{args: []string{"-offset", "1", "-limit", "2"}, expected: []string{row2, row3}},
+		{args: []string{"-offset", "3"}, expected: []string{row4}},
+		{args: []string{"-offset", "5"}, expected: nil},
+		{args: []string{"-stripe", "1"}, expected: []string{row3, row4}},
+		{args: []string{"-stripe", "0,1", "-limit", "1"}, expected: []string{row1}},
+		{args: []string{"-columns", "id", "-where", "id>=2"}, expected: []string{`{"id":2}`, `{"id":3}`, `{"id":4}`}},
+		{args: []string{"-columns", "id", "-where", "id>=2", "-offset", "1"}, expected: []string{`{"id":3}`, `{"id":4}`}},
+		{args: []string{"-columns", "id", "-where", "name = \"a<b\""}, expected: []string{`{"id":1}`}},
+		{args: []string{"-columns", "id", "-where", "name!=c"}, expected: []string{`{"id":1}`, `{"id":4}`}},
+		{args: []string{"-columns", "id", "-where", "price<4"}, expected: []string{`{"id":1}`}},
+		{args: []string{"-columns", "id", "-where", "flag=true"}, expected: []string{`{"id":1}`, `{"id":4}`}},
+		{args: []string{"-columns", "id", "-where", "day=2020-01-02"}, expected: []string{`{"id":1}`}},
+		{args: []string{"-columns", "id", "-where", "at>2020-01-01T00:00:00Z"}, expected: []string{`{"id":1}`}},
+		{args: []string{"-columns", "id", "-where", "id>10"}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		var stdout, stderr bytes.Buffer
+		if err := run(append(tc.args, path), &stdout, &stderr); err != nil {
+			t.Errorf("Test failed, %v returned an error: %v", tc.args, err)
+			continue
+		}
+		var expected string
+		if tc.expected != nil {
+			expected = strings.Join(tc.expected, "\n") + "\n"
+		}
+		if actual := stdout.String(); actual != expected {
+			t.Errorf("Test failed, %v expected:\n%s\ngot:\n%s", tc.args, expected, actual)
+		}
+	}
+
+}
+
+func TestRunErrors(t *testing.T) {
+
+	path := writeTestFile(t)
+
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{args: nil, expected: "expected the path of a single ORC file"},
+		{args: []string{path, path}, expected: "expected the path of a single ORC file"},
+		{args: []string{"-offset", "-1", path}, expected: "invalid offset -1"},
+		{args: []string{"-stripe", "x", path}, expected: `invalid stripe "x"`},
+		{args: []string{"-columns", "missing", path}, expected: "no column named missing"},
+		{args: []string{"-where", "id", path}, expected: `invalid predicate "id", expected a column, an operator and a value such as id>=3`},
+		{args: []string{"-where", "missing=1", path}, expected: "no column named missing"},
+		{args: []string{"-where", "id=x", path}, expected: `invalid value for column id: strconv.ParseInt: parsing "x": invalid syntax`},
+		{args: []string{"-where", "flag<true", path}, expected: "boolean column flag can only be compared by = or !="},
+		{args: []string{"-where", "tags=x", path}, expected: "cannot compare column tags of type list"},
+		{args: []string{"-where", `name="x`, path}, expected: `invalid quoted value "x`},
+		{args: []string{filepath.Join(t.TempDir(), "missing.orc")}, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		var stdout, stderr bytes.Buffer
+		err := run(tc.args, &stdout, &stderr)
+		if err == nil {
+			t.Errorf("Test failed, %v expected an error", tc.args)
+			continue
+		}
+		if tc.expected == "" {
+			if !os.IsNotExist(err) {
+				t.Errorf("Test failed, %v expected a not exist error got %v", tc.args, err)
+			}
+			continue
+		}
+		if err.Error() != tc.expected {
+			t.Errorf("Test failed, %v expected error %q got %q", tc.args, tc.expected, err)
+		}
+	}
+
+}
@@ -0,0 +1,547 @@
+// Command orc-meta prints the metadata of an ORC file: its postscript, schema,
+// stripes, statistics and user metadata keys.
+//
+// Usage:
+//
+//	orc-meta [flags] file.orc
+//
+// The -json flag prints the metadata as a single JSON object in place of the
+// human-readable report, and -verbose adds the encodings of the columns of each
+// stripe. Parts of a damaged file that cannot be read, such as the footers of
+// stripes beyond the end of a truncated file, are reported as errors after
+// printing every part that could be read, as long as the end of the file holding
+// the postscript and footer can be.
+//
+// The -check flag additionally checks the consistency of the file, that its
+// stripes do not overlap, that their row counts and streams agree with the footer
+// and that every row of every stripe can be decoded, and reports any problems
+// found.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "orc-meta: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run prints the metadata of the file named by args, configured by the flags of
+// args, to stdout.
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("orc-meta", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	asJSON := fs.Bool("json", false, "print the metadata as JSON")
+	verbose := fs.Bool("verbose", false, "print the encodings of the columns of each stripe")
+	check := fs.Bool("check", false, "check the consistency of the file and decode every row")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: orc-meta [flags] file.orc\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected the path of a single ORC file")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	r, err := orc.NewReader(sizedFile{f, fi.Size()})
+	if err != nil {
+		return err
+	}
+	m := readMeta(r, fs.Arg(0), fi.Size(), *verbose)
+	if *check {
+		m.Problems = checkFile(r, m)
+		m.Checked = true
+	}
+
+	if *asJSON {
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(stdout, "%s\n", b); err != nil {
+			return err
+		}
+	} else if err := writeReport(stdout, m); err != nil {
+		return err
+	}
+	if len(m.Errors) > 0 {
+		return fmt.Errorf("could not read %d parts of the file", len(m.Errors))
+	}
+	if len(m.Problems) > 0 {
+		return fmt.Errorf("check found %d problems", len(m.Problems))
+	}
+	return nil
+}
+
+// sizedFile is an orc.SizedReaderAt reading from a file of the provided size.
+type sizedFile struct {
+	*os.File
+	size int64
+}
+
+func (f sizedFile) Size() int64 {
+	return f.size
+}
+
+// fileMeta is the metadata of a file.
+type fileMeta struct {
+	File       string         `json:"file"`
+	Size       int64          `json:"size"`
+	PostScript postScriptMeta `json:"postscript"`
+	Rows       uint64         `json:"rows"`
+	// RowIndexStride is the number of rows of each row group, or 0 if the file
+	// has no row indexes.
+	RowIndexStride uint32            `json:"rowIndexStride"`
+	Writer         uint32            `json:"writer"`
+	Schema         *typeMeta         `json:"schema"`
+	Stripes        []stripeMeta      `json:"stripes"`
+	Statistics     []*statisticsMeta `json:"statistics"`
+	UserMetadata   []string          `json:"userMetadata"`
+	Errors         []string          `json:"errors,omitempty"`
+	Checked        bool              `json:"-"`
+	Problems       []string          `json:"problems,omitempty"`
+	stripes        []*proto.StripeFooter
+}
+
+// postScriptMeta holds the fields of the postscript of a file.
+type postScriptMeta struct {
+	Compression          string `json:"compression"`
+	CompressionBlockSize uint64 `json:"compressionBlockSize,omitempty"`
+	Version              string `json:"version"`
+	WriterVersion        uint32 `json:"writerVersion"`
+	FooterLength         uint64 `json:"footerLength"`
+	MetadataLength       uint64 `json:"metadataLength"`
+	Length               int    `json:"length"`
+}
+
+// typeMeta is a column of the schema of a file.
+type typeMeta struct {
+	ID       uint32      `json:"id"`
+	Name     string      `json:"name,omitempty"`
+	Type     string      `json:"type"`
+	Children []*typeMeta `json:"children,omitempty"`
+}
+
+// stripeMeta is the layout, encodings and statistics of a stripe.
+type stripeMeta struct {
+	Index          int               `json:"index"`
+	Offset         uint64            `json:"offset"`
+	IndexLength    uint64            `json:"indexLength"`
+	DataLength     uint64            `json:"dataLength"`
+	FooterLength   uint64            `json:"footerLength"`
+	Rows           uint64            `json:"rows"`
+	Encodings      []encodingMeta    `json:"encodings,omitempty"`
+	WriterTimezone string            `json:"writerTimezone,omitempty"`
+	Statistics     []*statisticsMeta `json:"statistics,omitempty"`
+}
+
+// encodingMeta is the encoding of a column of a stripe.
+type encodingMeta struct {
+	Column         int    `json:"column"`
+	Kind           string `json:"kind"`
+	DictionarySize uint32 `json:"dictionarySize,omitempty"`
+}
+
+// statisticsMeta is the statistics of a column, each optional field being nil
+// when unset.
+type statisticsMeta struct {
+	Column        int         `json:"column"`
+	Count         uint64      `json:"count"`
+	HasNull       bool        `json:"hasNull"`
+	Min           interface{} `json:"min,omitempty"`
+	LowerBound    interface{} `json:"lowerBound,omitempty"`
+	Max           interface{} `json:"max,omitempty"`
+	UpperBound    interface{} `json:"upperBound,omitempty"`
+	Sum           interface{} `json:"sum,omitempty"`
+	TrueCount     interface{} `json:"trueCount,omitempty"`
+	MinChildren   interface{} `json:"minChildren,omitempty"`
+	MaxChildren   interface{} `json:"maxChildren,omitempty"`
+	TotalChildren interface{} `json:"totalChildren,omitempty"`
+}
+
+// readMeta reads the metadata of the file of r, which is size bytes long,
+// recording the parts that cannot be read as errors. The encodings of the
+// stripes are included if verbose.
+func readMeta(r *orc.Reader, path string, size int64, verbose bool) *fileMeta {
+	ps, footer := r.PostScript(), r.Footer()
+	version := make([]string, len(ps.GetVersion()))
+	for i, v := range ps.GetVersion() {
+		version[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	m := &fileMeta{
+		File: path,
+		Size: size,
+		PostScript: postScriptMeta{
+			Compression:    ps.GetCompression().String(),
+			Version:        strings.Join(version, "."),
+			WriterVersion:  ps.GetWriterVersion(),
+			FooterLength:   ps.GetFooterLength(),
+			MetadataLength: ps.GetMetadataLength(),
+			Length:         len(r.RawPostScript()),
+		},
+		Rows:           footer.GetNumberOfRows(),
+		RowIndexStride: footer.GetRowIndexStride(),
+		Writer:         footer.GetWriter(),
+		Statistics:     statisticsOf(footer.GetStatistics()),
+		UserMetadata:   []string{},
+	}
+	if ps.GetCompression() != proto.CompressionKind_NONE {
+		m.PostScript.CompressionBlockSize = ps.GetCompressionBlockSize()
+	}
+	var err error
+	if m.Schema, err = schemaOf(footer.GetTypes(), 0, ""); err != nil {
+		m.Errors = append(m.Errors, err.Error())
+	}
+	for _, item := range footer.GetMetadata() {
+		m.UserMetadata = append(m.UserMetadata, item.GetName())
+	}
+
+	stripeStats := r.Metadata().GetStripeStats()
+	for i, stripe := range footer.GetStripes() {
+		s := stripeMeta{
+			Index:        i,
+			Offset:       stripe.GetOffset(),
+			IndexLength:  stripe.GetIndexLength(),
+			DataLength:   stripe.GetDataLength(),
+			FooterLength: stripe.GetFooterLength(),
+			Rows:         stripe.GetNumberOfRows(),
+		}
+		if i < len(stripeStats) {
+			s.Statistics = statisticsOf(stripeStats[i].GetColStats())
+		}
+		stripeFooter, err := r.StripeFooter(i)
+		if err != nil {
+			m.Errors = append(m.Errors, fmt.Sprintf("stripe %d footer: %v", i, err))
+		} else if verbose {
+			for col, encoding := range stripeFooter.GetColumns() {
+				s.Encodings = append(s.Encodings, encodingMeta{
+					Column:         col,
+					Kind:           encoding.GetKind().String(),
+					DictionarySize: encoding.GetDictionarySize(),
+				})
+			}
+			s.WriterTimezone = stripeFooter.GetWriterTimezone()
+		}
+		m.Stripes = append(m.Stripes, s)
+		m.stripes = append(m.stripes, stripeFooter)
+	}
+	return m
+}
+
+// schemaOf returns the column with the provided ID and field name of the types
+// of a file.
+func schemaOf(types []*proto.Type, id uint32, name string) (*typeMeta, error) {
+	if int(id) >= len(types) {
+		return nil, fmt.Errorf("column %d of the schema out of range", id)
+	}
+	t := types[id]
+	m := &typeMeta{ID: id, Name: name, Type: strings.ToLower(t.GetKind().String())}
+	switch t.GetKind() {
+	case proto.Type_DECIMAL:
+		m.Type = fmt.Sprintf("decimal(%d,%d)", t.GetPrecision(), t.GetScale())
+	case proto.Type_CHAR, proto.Type_VARCHAR:
+		m.Type = fmt.Sprintf("%s(%d)", m.Type, t.GetMaximumLength())
+	case proto.Type_TIMESTAMP_INSTANT:
+		m.Type = "timestamp with local time zone"
+	}
+	for i, subtype := range t.GetSubtypes() {
+		// Subtypes follow their parent in the types of a valid file, so a
+		// subtype that does not would recurse forever.
+		if subtype <= id {
+			return m, fmt.Errorf("column %d of the schema has subtype %d out of order", id, subtype)
+		}
+		var childName string
+		if i < len(t.GetFieldNames()) {
+			childName = t.GetFieldNames()[i]
+		}
+		child, err := schemaOf(types, subtype, childName)
+		if child != nil {
+			m.Children = append(m.Children, child)
+		}
+		if err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// statisticsOf returns the statistics of the columns of a file or stripe.
+func statisticsOf(stats []*proto.ColumnStatistics) []*statisticsMeta {
+	metas := make([]*statisticsMeta, len(stats))
+	for col, s := range stats {
+		m := &statisticsMeta{Column: col, Count: s.GetNumberOfValues(), HasNull: s.GetHasNull()}
+		switch {
+		case s.IntStatistics != nil:
+			is := s.GetIntStatistics()
+			m.Min, m.Max, m.Sum = optional(is.Minimum), optional(is.Maximum), optional(is.Sum)
+		case s.DoubleStatistics != nil:
+			ds := s.GetDoubleStatistics()
+			m.Min, m.Max, m.Sum = optionalFloat(ds.Minimum), optionalFloat(ds.Maximum), optionalFloat(ds.Sum)
+		case s.StringStatistics != nil:
+			ss := s.GetStringStatistics()
+			m.Min, m.LowerBound = optional(ss.Minimum), optional(ss.LowerBound)
+			m.Max, m.UpperBound = optional(ss.Maximum), optional(ss.UpperBound)
+			m.Sum = optional(ss.Sum)
+		case s.BucketStatistics != nil:
+			if counts := s.GetBucketStatistics().GetCount(); len(counts) > 0 {
+				m.TrueCount = counts[0]
+			}
+		case s.DecimalStatistics != nil:
+			ds := s.GetDecimalStatistics()
+			m.Min, m.Max, m.Sum = optional(ds.Minimum), optional(ds.Maximum), optional(ds.Sum)
+		case s.DateStatistics != nil:
+			ds := s.GetDateStatistics()
+			if ds.Minimum != nil {
+				m.Min = time.Unix(int64(ds.GetMinimum())*86400, 0).UTC().Format("2006-01-02")
+			}
+			if ds.Maximum != nil {
+				m.Max = time.Unix(int64(ds.GetMaximum())*86400, 0).UTC().Format("2006-01-02")
+			}
+		case s.TimestampStatistics != nil:
+			ts := s.GetTimestampStatistics()
+			if ts.Minimum != nil {
+				m.Min = time.UnixMilli(ts.GetMinimum()).UTC().Format(time.RFC3339Nano)
+			}
+			if ts.Maximum != nil {
+				m.Max = time.UnixMilli(ts.GetMaximum()).UTC().Format(time.RFC3339Nano)
+			}
+		case s.BinaryStatistics != nil:
+			m.Sum = optional(s.GetBinaryStatistics().Sum)
+		case s.CollectionStatistics != nil:
+			cs := s.GetCollectionStatistics()
+			m.MinChildren, m.MaxChildren, m.TotalChildren = optional(cs.MinChildren), optional(cs.MaxChildren), optional(cs.TotalChildren)
+		}
+		metas[col] = m
+	}
+	return metas
+}
+
+// optional returns the value pointed to by an optional protobuf field, or nil if
+// it is unset.
+func optional(ptr interface{}) interface{} {
+	v := reflect.ValueOf(ptr)
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
+
+// optionalFloat returns the value pointed to by an optional protobuf field, or
+// nil if it is unset, NaN and infinite values being returned as strings as JSON
+// cannot represent them.
+func optionalFloat(ptr *float64) interface{} {
+	switch {
+	case ptr == nil:
+		return nil
+	case math.IsNaN(*ptr):
+		return "NaN"
+	case math.IsInf(*ptr, 1):
+		return "Infinity"
+	case math.IsInf(*ptr, -1):
+		return "-Infinity"
+	}
+	return *ptr
+}
+
+// checkFile checks the consistency of the file of r with the metadata m read
+// from it, returning the problems found.
+func checkFile(r *orc.Reader, m *fileMeta) []string {
+	var problems []string
+	problemf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// The stripes follow the header and each other, ending before the metadata.
+	// Writers may pad the gaps between stripes, such as to align them with the
+	// blocks of a file system, but stripes never overlap.
+	tail := uint64(m.Size) - uint64(m.PostScript.Length) - 1 - m.PostScript.FooterLength - m.PostScript.MetadataLength
+	offset := uint64(len("ORC"))
+	var rows uint64
+	for _, s := range m.Stripes {
+		if s.Offset < offset {
+			problemf("stripe %d starts at offset %d, before the end of the preceding data at offset %d", s.Index, s.Offset, offset)
+		}
+		offset = s.Offset + s.IndexLength + s.DataLength + s.FooterLength
+		if offset > tail {
+			problemf("stripe %d ends at offset %d after the metadata at offset %d", s.Index, offset, tail)
+		}
+		rows += s.Rows
+		if sf := m.stripes[s.Index]; sf != nil {
+			var length uint64
+			for _, stream := range sf.GetStreams() {
+				length += stream.GetLength()
+			}
+			if length != s.IndexLength+s.DataLength {
+				problemf("streams of stripe %d have %d bytes, expected %d", s.Index, length, s.IndexLength+s.DataLength)
+			}
+			if len(sf.GetColumns()) != len(r.Footer().GetTypes()) {
+				problemf("stripe %d has encodings of %d columns, expected %d", s.Index, len(sf.GetColumns()), len(r.Footer().GetTypes()))
+			}
+		}
+	}
+	if rows != m.Rows {
+		problemf("stripes have %d rows, expected %d", rows, m.Rows)
+	}
+	if n := len(r.Metadata().GetStripeStats()); m.PostScript.MetadataLength > 0 && n != len(m.Stripes) {
+		problemf("metadata has statistics of %d stripes, expected %d", n, len(m.Stripes))
+	}
+	if len(m.Statistics) > 0 && m.Statistics[0].Count != 0 && m.Statistics[0].Count != m.Rows {
+		problemf("file statistics count %d rows, expected %d", m.Statistics[0].Count, m.Rows)
+	}
+	if len(m.Errors) > 0 {
+		// Stripes whose footers cannot be read cannot be decoded either.
+		return problems
+	}
+
+	// Decode every row of every stripe.
+	c := r.Select("*")
+	var stripe int
+	for c.Stripes() {
+		var n uint64
+		for c.Next() {
+			n++
+		}
+		if c.Err() != nil {
+			break
+		}
+		if stripe < len(m.Stripes) && n != m.Stripes[stripe].Rows {
+			problemf("stripe %d decoded %d rows, expected %d", stripe, n, m.Stripes[stripe].Rows)
+		}
+		stripe++
+	}
+	if err := c.Err(); err != nil {
+		problemf("stripe %d could not be decoded: %v", stripe, err)
+	}
+	return problems
+}
+
+// writeReport writes the metadata m as a human-readable report.
+func writeReport(w io.Writer, m *fileMeta) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "File: %s (%d bytes)\n", m.File, m.Size)
+	fmt.Fprintf(&b, "File version: %s with writer version %d\n", m.PostScript.Version, m.PostScript.WriterVersion)
+	fmt.Fprintf(&b, "Compression: %s\n", m.PostScript.Compression)
+	if m.PostScript.CompressionBlockSize != 0 {
+		fmt.Fprintf(&b, "Compression block size: %d\n", m.PostScript.CompressionBlockSize)
+	}
+	fmt.Fprintf(&b, "Postscript: %d bytes, footer: %d bytes, metadata: %d bytes\n", m.PostScript.Length, m.PostScript.FooterLength, m.PostScript.MetadataLength)
+	fmt.Fprintf(&b, "Rows: %d\n", m.Rows)
+	fmt.Fprintf(&b, "Row index stride: %d\n", m.RowIndexStride)
+	fmt.Fprintf(&b, "Writer: %d\n", m.Writer)
+
+	fmt.Fprintf(&b, "\nSchema:\n")
+	var writeType func(t *typeMeta, depth int)
+	writeType = func(t *typeMeta, depth int) {
+		b.WriteString(strings.Repeat("  ", depth+1))
+		if t.Name != "" {
+			fmt.Fprintf(&b, "%s: ", t.Name)
+		}
+		fmt.Fprintf(&b, "%s (column %d)\n", t.Type, t.ID)
+		for _, child := range t.Children {
+			writeType(child, depth+1)
+		}
+	}
+	if m.Schema != nil {
+		writeType(m.Schema, 0)
+	}
+
+	fmt.Fprintf(&b, "\nStripes:\n")
+	for _, s := range m.Stripes {
+		fmt.Fprintf(&b, "  Stripe %d: offset: %d index: %d data: %d footer: %d rows: %d\n", s.Index, s.Offset, s.IndexLength, s.DataLength, s.FooterLength, s.Rows)
+		for _, e := range s.Encodings {
+			fmt.Fprintf(&b, "    Encoding column %d: %s", e.Column, e.Kind)
+			if e.DictionarySize != 0 {
+				fmt.Fprintf(&b, "[%d]", e.DictionarySize)
+			}
+			b.WriteString("\n")
+		}
+		if s.WriterTimezone != "" {
+			fmt.Fprintf(&b, "    Writer timezone: %s\n", s.WriterTimezone)
+		}
+		for _, stats := range s.Statistics {
+			fmt.Fprintf(&b, "    Column %d: %s\n", stats.Column, stats)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nFile statistics:\n")
+	for _, stats := range m.Statistics {
+		fmt.Fprintf(&b, "  Column %d: %s\n", stats.Column, stats)
+	}
+
+	if len(m.UserMetadata) > 0 {
+		fmt.Fprintf(&b, "\nUser metadata:\n")
+		for _, key := range m.UserMetadata {
+			fmt.Fprintf(&b, "  %s\n", key)
+		}
+	}
+	if len(m.Errors) > 0 {
+		fmt.Fprintf(&b, "\nErrors:\n")
+		for _, e := range m.Errors {
+			fmt.Fprintf(&b, "  %s\n", e)
+		}
+	}
+	if m.Checked {
+		if len(m.Problems) == 0 {
+			fmt.Fprintf(&b, "\nCheck: OK\n")
+		} else {
+			fmt.Fprintf(&b, "\nCheck problems:\n")
+			for _, p := range m.Problems {
+				fmt.Fprintf(&b, "  %s\n", p)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the statistics on a single line, omitting those unset.
+func (s *statisticsMeta) String() string {
+	out := fmt.Sprintf("count: %d hasNull: %t", s.Count, s.HasNull)
+	for _, field := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"min", s.Min},
+		{"lower", s.LowerBound},
+		{"max", s.Max},
+		{"upper", s.UpperBound},
+		{"sum", s.Sum},
+		{"true", s.TrueCount},
+		{"minChildren", s.MinChildren},
+		{"maxChildren", s.MaxChildren},
+		{"totalChildren", s.TotalChildren},
+	} {
+		if field.value != nil {
+			out += fmt.Sprintf(" %s: %v", field.name, field.value)
+		}
+	}
+	return out
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.simon-critchley.co.uk/orc"
+)
+
+// writeTestFile writes an ORC file of two stripes with user metadata to a
+// temporary directory and returns its path and contents.
+func writeTestFile(t *testing.T) (string, []byte) {
+	t.Helper()
+	schema, err := orc.ParseSchema("struct<id:int,name:string,price:decimal(10,2),tags:array<string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := orc.NewWriter(&buf, orc.SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes := [][][]interface{}{
+		{{int64(1), "a", "1.50", []interface{}{"x"}}, {int64(2), nil, nil, nil}},
+		{{int64(3), "c", "-2.25", []interface{}{"y", "z"}}},
+	}
+	for _, rows := range stripes {
+		for _, row := range rows {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddUserMetadata("owner", []byte("tests")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "test.orc")
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, buf.Bytes()
+}
+
+func TestRun(t *testing.T) {
+
+	path, _ := writeTestFile(t)
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-check", path}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	report := stdout.String()
+	for _, line := range []string{
+		"Compression: NONE\n",
+		"Rows: 3\n",
+		"  struct (column 0)\n    id: int (column 1)\n    name: string (column 2)\n    price: decimal(10,2) (column 3)\n    tags: list (column 4)\n      string (column 5)\n",
+		"  Stripe 0: offset: 3 ",
+		" rows: 2\n",
+		"  Stripe 1: offset: ",
+		" rows: 1\n",
+		"    Column 1: count: 2 hasNull: false min: 1 max: 2 sum: 3\n",
+		"  Column 1: count: 3 hasNull: false min: 1 max: 3 sum: 6\n",
+		"  Column 2: count: 2 hasNull: true min: a max: c sum: 2\n",
+		"\nUser metadata:\n  owner\n",
+		"\nCheck: OK\n",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Test failed, expected the report to contain %q got:\n%s", line, report)
+		}
+	}
+	if strings.Contains(report, "Encoding column") {
+		t.Errorf("Test failed, expected no encodings without -verbose got:\n%s", report)
+	}
+
+	stdout.Reset()
+	if err := run([]string{"-verbose", path}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if report := stdout.String(); !strings.Contains(report, "    Encoding column 1: DIRECT_V2\n") || strings.Contains(report, "Check") {
+		t.Errorf("Test failed, expected encodings without a check got:\n%s", report)
+	}
+
+}
+
+func TestRunJSON(t *testing.T) {
+
+	path, b := writeTestFile(t)
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"-json", "-verbose", path}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Size       int64
+		PostScript struct {
+			Compression string
+			Version     string
+		}
+		Rows   uint64
+		Schema *typeMeta
+		// Stripes are decoded as maps to check the names of their fields.
+		Stripes      []map[string]interface{}
+		Statistics   []map[string]interface{}
+		UserMetadata []string
+		Errors       []string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Size != int64(len(b)) || m.PostScript.Compression != "NONE" || m.PostScript.Version != "0.12" || m.Rows != 3 {
+		t.Errorf("Test failed, unexpected file metadata %+v", m)
+	}
+	if m.Schema == nil || len(m.Schema.Children) != 4 || m.Schema.Children[2].Type != "decimal(10,2)" || m.Schema.Children[3].Children[0].ID != 5 {
+		t.Errorf("Test failed, unexpected schema %+v", m.Schema)
+	}
+	if len(m.Stripes) != 2 || m.Stripes[0]["rows"] != 2.0 || m.Stripes[1]["rows"] != 1.0 || m.Stripes[0]["offset"] != 3.0 {
+		t.Errorf("Test failed, unexpected stripes %v", m.Stripes)
+	}
+	if encodings, ok := m.Stripes[0]["encodings"].([]interface{}); !ok || len(encodings) != 6 {
+		t.Errorf("Test failed, expected the encodings of 6 columns got %v", m.Stripes[0]["encodings"])
+	}
+	expected := map[string]interface{}{"column": 3.0, "count": 2.0, "hasNull": true, "min": "-2.25", "max": "1.5", "sum": "-0.75"}
+	if len(m.Statistics) != 6 || !reflect.DeepEqual(m.Statistics[3], expected) {
+		t.Errorf("Test failed, expected decimal statistics %v got %v", expected, m.Statistics)
+	}
+	if !reflect.DeepEqual(m.UserMetadata, []string{"owner"}) || m.Errors != nil {
+		t.Errorf("Test failed, unexpected user metadata %v or errors %v", m.UserMetadata, m.Errors)
+	}
+
+}
+
+func TestRunDamaged(t *testing.T) {
+
+	path, b := writeTestFile(t)
+	r, err := orc.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Overwrite the footer of the second stripe.
+	stripe := r.Footer().GetStripes()[1]
+	start := stripe.GetOffset() + stripe.GetIndexLength() + stripe.GetDataLength()
+	for i := start; i < start+stripe.GetFooterLength(); i++ {
+		b[i] = 0xff
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = run([]string{"-verbose", "-check", path}, &stdout, &stderr)
+	if err == nil || err.Error() != "could not read 1 parts of the file" {
+		t.Errorf("Test failed, expected an error reading the file got %v", err)
+	}
+	// The parts of the file that could be read are still reported.
+	report := stdout.String()
+	for _, line := range []string{
+		"    Encoding column 1: DIRECT_V2\n",
+		"  Stripe 1: offset: ",
+		"  Column 1: count: 3 hasNull: false min: 1 max: 3 sum: 6\n",
+		"\nErrors:\n  stripe 1 footer: ",
+		"\nCheck: OK\n",
+	} {
+		if !strings.Contains(report, line) {
+			t.Errorf("Test failed, expected the report to contain %q got:\n%s", line, report)
+		}
+	}
+
+}
+
+func TestCheckFile(t *testing.T) {
+
+	path, b := writeTestFile(t)
+
+	testCases := []struct {
+		// modify modifies the metadata read from the file, returning the
+		// problems expected to be found.
+		modify func(m *fileMeta) []string
+	}{
+		{
+			modify: func(m *fileMeta) []string { return nil },
+		},
+		{
+			modify: func(m *fileMeta) []string {
+				m.Rows++
+				return []string{"stripes have 3 rows, expected 4", "file statistics count 3 rows, expected 4"}
+			},
+		},
+		{
+			modify: func(m *fileMeta) []string {
+				end := m.Stripes[1].Offset
+				m.Stripes[1].Offset--
+				return []string{fmt.Sprintf("stripe 1 starts at offset %d, before the end of the preceding data at offset %d", end-1, end)}
+			},
+		},
+		{
+			modify: func(m *fileMeta) []string {
+				m.Stripes[0].Rows = 3
+				return []string{"stripes have 4 rows, expected 3", "stripe 0 decoded 2 rows, expected 3"}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := orc.NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := readMeta(r, path, int64(len(b)), false)
+		expected := tc.modify(m)
+		if problems := checkFile(r, m); !reflect.DeepEqual(problems, expected) {
+			t.Errorf("Test failed, expected problems %q got %q", expected, problems)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{filepath.Join(t.TempDir(), "missing.orc")}, &stdout, &stderr); !os.IsNotExist(err) {
+		t.Errorf("Test failed, expected a not exist error got %v", err)
+	}
+	if err := run(nil, &stdout, &stderr); err == nil || err.Error() != "expected the path of a single ORC file" {
+		t.Errorf("Test failed, expected a usage error got %v", err)
+	}
+
+}
@@ -0,0 +1,203 @@
+package orc
+
+import (
+	"fmt"
+	"math"
+)
+
+// ColumnBatch holds a batch of rows by column for writing with Writer.WriteBatch,
+// avoiding boxing each value in an interface. Columns holds one ColumnVector for
+// each top level column of the schema, in order, each of the same length.
+type ColumnBatch struct {
+	Columns []*ColumnVector
+}
+
+// ColumnVector holds the values of a single column of a ColumnBatch. At most one
+// of the slices of values is set: Int64s for tinyint, smallint, int and bigint
+// columns, Float64s for float and double columns, Strings for string, varchar
+// and char columns, Bools for boolean columns and Values for a column of any type,
+// holding the values accepted by Writer.Write. Nulls is either nil or of the same
+// length as the values and is true where the value is null, in which case the
+// value itself is ignored.
+type ColumnVector struct {
+	Int64s   []int64
+	Float64s []float64
+	Strings  []string
+	Bools    []bool
+	Values   []interface{}
+	Nulls    []bool
+}
+
+// Len returns the number of values of the ColumnVector.
+func (v *ColumnVector) Len() int {
+	switch {
+	case v.Int64s != nil:
+		return len(v.Int64s)
+	case v.Float64s != nil:
+		return len(v.Float64s)
+	case v.Strings != nil:
+		return len(v.Strings)
+	case v.Bools != nil:
+		return len(v.Bools)
+	}
+	return len(v.Values)
+}
+
+// check returns an error if more than one slice of values is set or if the values
+// cannot be written to a column of the provided category.
+func (v *ColumnVector) check(category Category) error {
+	var set int
+	for _, isSet := range []bool{v.Int64s != nil, v.Float64s != nil, v.Strings != nil, v.Bools != nil, v.Values != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return fmt.Errorf("column vector has more than one slice of values set")
+	}
+	if v.Nulls != nil && len(v.Nulls) != v.Len() {
+		return fmt.Errorf("column vector has %d nulls for %d values", len(v.Nulls), v.Len())
+	}
+	var categories []Category
+	switch {
+	case v.Int64s != nil:
+		categories = []Category{CategoryByte, CategoryShort, CategoryInt, CategoryLong}
+	case v.Float64s != nil:
+		categories = []Category{CategoryFloat, CategoryDouble}
+	case v.Strings != nil:
+		categories = []Category{CategoryString, CategoryVarchar, CategoryChar}
+	case v.Bools != nil:
+		categories = []Category{CategoryBoolean}
+	default:
+		return nil
+	}
+	for _, c := range categories {
+		if c == category {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot write column vector to %s column", category)
+}
+
+// isNull returns whether the value at index i is null.
+func (v *ColumnVector) isNull(i int) bool {
+	return v.Nulls != nil && v.Nulls[i]
+}
+
+// nulls returns the nulls of the values from start to end, or nil if none are set.
+func (v *ColumnVector) nulls(start, end int) []bool {
+	if v.Nulls == nil {
+		return nil
+	}
+	return v.Nulls[start:end]
+}
+
+// writeVector writes the values of v from start to end to the TreeWriter t of a
+// column of the provided category. The values are written without boxing them
+// when t supports the type of the values, otherwise they are written one at a
+// time by Write.
+func writeVector(t TreeWriter, category Category, v *ColumnVector, start, end int) error {
+	switch {
+	case v.Int64s != nil:
+		if w, ok := t.(interface {
+			writeInt64s(values []int64, nulls []bool) error
+		}); ok {
+			return w.writeInt64s(v.Int64s[start:end], v.nulls(start, end))
+		}
+	case v.Float64s != nil:
+		if w, ok := t.(interface {
+			writeFloat64s(values []float64, nulls []bool) error
+		}); ok {
+			return w.writeFloat64s(v.Float64s[start:end], v.nulls(start, end))
+		}
+	case v.Strings != nil:
+		if w, ok := t.(interface {
+			writeStrings(values []string, nulls []bool) error
+		}); ok {
+			return w.writeStrings(v.Strings[start:end], v.nulls(start, end))
+		}
+	case v.Bools != nil:
+		if w, ok := t.(interface {
+			writeBools(values []bool, nulls []bool) error
+		}); ok {
+			return w.writeBools(v.Bools[start:end], v.nulls(start, end))
+		}
+	}
+	for i := start; i < end; i++ {
+		value, err := v.value(category, i)
+		if err != nil {
+			return err
+		}
+		if err := t.Write(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// value returns the value at index i as accepted by the Write method of the
+// TreeWriter of a column of the provided category.
+func (v *ColumnVector) value(category Category, i int) (interface{}, error) {
+	if v.isNull(i) {
+		return nil, nil
+	}
+	switch {
+	case v.Int64s != nil:
+		if category == CategoryByte {
+			if v.Int64s[i] < math.MinInt8 || v.Int64s[i] > math.MaxInt8 {
+				return nil, fmt.Errorf("value %d out of range for tinyint column", v.Int64s[i])
+			}
+			return int8(v.Int64s[i]), nil
+		}
+		return v.Int64s[i], nil
+	case v.Float64s != nil:
+		if category == CategoryFloat {
+			return narrowFloat(v.Float64s[i]), nil
+		}
+		return v.Float64s[i], nil
+	case v.Strings != nil:
+		return v.Strings[i], nil
+	case v.Bools != nil:
+		return v.Bools[i], nil
+	}
+	return v.Values[i], nil
+}
+
+// row returns the values of the row at index i of the batch.
+func (b *ColumnBatch) row(schema *TypeDescription, i int) ([]interface{}, error) {
+	values := make([]interface{}, len(b.Columns))
+	for j, column := range b.Columns {
+		value, err := column.value(schema.children[j].getCategory(), i)
+		if err != nil {
+			return nil, err
+		}
+		values[j] = value
+	}
+	return values, nil
+}
+
+// check returns the number of rows of the batch, or an error if it does not match
+// the struct schema.
+func (b *ColumnBatch) check(schema *TypeDescription) (int, error) {
+	if schema.getCategory() != CategoryStruct {
+		return 0, fmt.Errorf("cannot write a column batch to a schema of type %s", schema.getCategory())
+	}
+	if len(b.Columns) != len(schema.children) {
+		return 0, fmt.Errorf("wrong number of columns, expected: %v, got: %v", len(schema.children), len(b.Columns))
+	}
+	var rows int
+	for i, column := range b.Columns {
+		if column == nil {
+			return 0, fmt.Errorf("column vector %s is nil", schema.fieldNames[i])
+		}
+		if err := column.check(schema.children[i].getCategory()); err != nil {
+			return 0, fmt.Errorf("column %s: %v", schema.fieldNames[i], err)
+		}
+		if i == 0 {
+			rows = column.Len()
+		} else if column.Len() != rows {
+			return 0, fmt.Errorf("column %s has %d values, expected %d", schema.fieldNames[i], column.Len(), rows)
+		}
+	}
+	return rows, nil
+}
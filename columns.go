@@ -0,0 +1,177 @@
+package orc
+
+import (
+	"fmt"
+	"io"
+)
+
+// ReadInt64Column reads every value of the integer column from the file,
+// returning the values along with a parallel slice that is true where the value
+// is null. It returns an error if the column is not a tinyint, smallint, int or
+// bigint column. The values of stripes in which a smallint, int or bigint column
+// has no nulls and is encoded with RLE v2 are decoded directly into the returned
+// slice.
+func ReadInt64Column(r *Reader, column string) ([]int64, []bool, error) {
+	rows := int64(r.footer.GetNumberOfRows())
+	if r.limited && r.limit < rows {
+		rows = r.limit
+	}
+	values := make([]int64, 0, rows)
+	readStripe := func(c *Cursor) (int, bool, error) {
+		return readDenseInt64s(c, &values)
+	}
+	nulls, err := readColumn(r, column, "int64", []Category{CategoryByte, CategoryShort, CategoryInt, CategoryLong}, readStripe, func(value interface{}) {
+		switch v := value.(type) {
+		case int64:
+			values = append(values, v)
+		case int8:
+			values = append(values, int64(v))
+		default:
+			values = append(values, 0)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, nulls, nil
+}
+
+// ReadFloat64Column reads every value of the float or double column from the
+// file, returning the values along with a parallel slice that is true where the
+// value is null. It returns an error if the column is not a float or double column.
+func ReadFloat64Column(r *Reader, column string) ([]float64, []bool, error) {
+	var values []float64
+	nulls, err := readColumn(r, column, "float64", []Category{CategoryFloat, CategoryDouble}, nil, func(value interface{}) {
+		switch v := value.(type) {
+		case Double:
+			values = append(values, float64(v))
+		case Float:
+			values = append(values, widenFloat(float32(v)))
+		default:
+			values = append(values, 0)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, nulls, nil
+}
+
+// ReadStringColumn reads every value of the string column from the file,
+// returning the values along with a parallel slice that is true where the value
+// is null. It returns an error if the column is not a string, varchar or char
+// column.
+func ReadStringColumn(r *Reader, column string) ([]string, []bool, error) {
+	var values []string
+	nulls, err := readColumn(r, column, "string", []Category{CategoryString, CategoryVarchar, CategoryChar}, nil, func(value interface{}) {
+		// The values are []byte when read using WithStringsAsBytes.
+		var v string
+		switch s := value.(type) {
+		case string:
+			v = s
+		case []byte:
+			v = string(s)
+		}
+		values = append(values, v)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, nulls, nil
+}
+
+// ReadBoolColumn reads every value of the boolean column from the file, returning
+// the values along with a parallel slice that is true where the value is null. It
+// returns an error if the column is not a boolean column.
+func ReadBoolColumn(r *Reader, column string) ([]bool, []bool, error) {
+	var values []bool
+	nulls, err := readColumn(r, column, "bool", []Category{CategoryBoolean}, nil, func(value interface{}) {
+		v, _ := value.(bool)
+		values = append(values, v)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, nulls, nil
+}
+
+// readDenseInt64s appends the values of the current stripe of the Cursor c to
+// values if the Cursor selects an integer column which has no nulls in the stripe,
+// nor has the stripe null rows, and is encoded with RLE v2. It returns the number
+// of values appended and false if the stripe must be read value by value instead.
+func readDenseInt64s(c *Cursor, values *[]int64) (int, bool, error) {
+	if len(c.readers) != 1 || c.root.BooleanReader != nil || len(c.acidReaders) > 0 || c.stripeRow != 0 {
+		return 0, false, nil
+	}
+	reader, ok := c.readers[0].(*IntegerTreeReader)
+	if !ok || reader.BaseTreeReader.BooleanReader != nil {
+		return 0, false, nil
+	}
+	rle, ok := reader.IntegerReader.(*RunLengthIntegerReaderV2)
+	if !ok {
+		return 0, false, nil
+	}
+	rows := int64(c.stripeRows)
+	if c.Reader.limited && rows > c.Reader.limit-c.rows {
+		rows = c.Reader.limit - c.rows
+	}
+	if rows <= 0 {
+		return 0, true, nil
+	}
+	start := len(*values)
+	*values = append(*values, make([]int64, rows)...)
+	n, err := rle.readInt64s((*values)[start:])
+	*values = (*values)[:start+n]
+	c.stripeRow += uint64(n)
+	c.rows += int64(n)
+	if err == io.EOF {
+		err = newDecodeError("stripe has %d rows, read %d values", rows, n)
+	}
+	return n, true, err
+}
+
+// readColumn checks that column is one of the provided categories before reading
+// the whole file from its first stripe, calling fn with each value of the column
+// and returning whether each value was null. If readStripe is not nil it is called
+// at the start of each stripe and, if it returns true, has read the number of
+// values of the stripe it returns, none of which are null, in place of fn.
+func readColumn(r *Reader, column string, goType string, categories []Category, readStripe func(c *Cursor) (int, bool, error), fn func(value interface{})) ([]bool, error) {
+	td, err := r.schema.GetField(column)
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	for _, category := range categories {
+		if td.getCategory() == category {
+			ok = true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("column %s of type %s cannot be read as %s", column, td, goType)
+	}
+	// Read from the first stripe regardless of any prior reads, leaving the
+	// stripe position of r unchanged for its other Cursors.
+	var nulls []bool
+	c := r.rewound().Select(column)
+	for c.Stripes() {
+		if readStripe != nil {
+			n, ok, err := readStripe(c)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				nulls = append(nulls, make([]bool, n)...)
+				continue
+			}
+		}
+		for c.Next() {
+			value := c.Row()[0]
+			nulls = append(nulls, value == nil)
+			fn(value)
+		}
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return nulls, nil
+}
@@ -0,0 +1,312 @@
+package orc
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestReadColumns(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,byte1:tinyint,string1:string,float1:float,double1:double,boolean1:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{1, int8(-1), "a", float32(1.5), 2.25, true},
+		{nil, nil, nil, nil, nil, nil},
+		{-3, int8(3), "", float32(-0.5), -1e10, false},
+		{4, nil, "d", nil, 1.0 / 3, nil},
+	}
+	for _, row := range rows {
+		err = w.Write(row...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ints, nulls, err := ReadInt64Column(r, "int1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ints, []int64{1, 0, -3, 4}) || !reflect.DeepEqual(nulls, []bool{false, true, false, false}) {
+		t.Errorf("Test failed, unexpected int column %v with nulls %v", ints, nulls)
+	}
+
+	bytesValues, nulls, err := ReadInt64Column(r, "byte1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bytesValues, []int64{-1, 0, 3, 0}) || !reflect.DeepEqual(nulls, []bool{false, true, false, true}) {
+		t.Errorf("Test failed, unexpected tinyint column %v with nulls %v", bytesValues, nulls)
+	}
+
+	strs, nulls, err := ReadStringColumn(r, "string1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(strs, []string{"a", "", "", "d"}) || !reflect.DeepEqual(nulls, []bool{false, true, false, false}) {
+		t.Errorf("Test failed, unexpected string column %v with nulls %v", strs, nulls)
+	}
+
+	// The values of a reader returning strings as []byte are still read.
+	br, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())}, WithStringsAsBytes(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strs, nulls, err = ReadStringColumn(br, "string1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(strs, []string{"a", "", "", "d"}) || !reflect.DeepEqual(nulls, []bool{false, true, false, false}) {
+		t.Errorf("Test failed, unexpected string column %v with nulls %v read as bytes", strs, nulls)
+	}
+
+	floats, nulls, err := ReadFloat64Column(r, "float1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(floats, []float64{1.5, 0, -0.5, 0}) || !reflect.DeepEqual(nulls, []bool{false, true, false, true}) {
+		t.Errorf("Test failed, unexpected float column %v with nulls %v", floats, nulls)
+	}
+
+	doubles, nulls, err := ReadFloat64Column(r, "double1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doubles, []float64{2.25, 0, -1e10, 1.0 / 3}) || !reflect.DeepEqual(nulls, []bool{false, true, false, false}) {
+		t.Errorf("Test failed, unexpected double column %v with nulls %v", doubles, nulls)
+	}
+
+	bools, nulls, err := ReadBoolColumn(r, "boolean1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bools, []bool{true, false, false, false}) || !reflect.DeepEqual(nulls, []bool{false, true, false, true}) {
+		t.Errorf("Test failed, unexpected boolean column %v with nulls %v", bools, nulls)
+	}
+
+	// Reading a column as an incompatible Go type returns an error.
+	for _, fn := range []func() error{
+		func() error { _, _, err := ReadInt64Column(r, "string1"); return err },
+		func() error { _, _, err := ReadStringColumn(r, "double1"); return err },
+		func() error { _, _, err := ReadFloat64Column(r, "int1"); return err },
+		func() error { _, _, err := ReadBoolColumn(r, "byte1"); return err },
+		func() error { _, _, err := ReadInt64Column(r, "missing"); return err },
+	} {
+		if err := fn(); err == nil {
+			t.Errorf("Test failed, expected an error reading a column as the wrong type")
+		}
+	}
+}
+
+// writeInt64Stripes returns a file of a bigint column with a stripe for each of
+// the provided stripes of values, which are null where nil.
+func writeInt64Stripes(t testing.TB, stripes [][]interface{}) []byte {
+	schema, err := ParseSchema("struct<long1:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stripe := range stripes {
+		for _, v := range stripe {
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadInt64ColumnDense(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	// A stripe of random values encoded as direct runs, a stripe with nulls and a
+	// stripe of runs encoded in each way.
+	var random, nullable, mixed []interface{}
+	for i := 0; i < 3000; i++ {
+		random = append(random, rnd.Int63()-rnd.Int63())
+		if i%7 == 0 {
+			nullable = append(nullable, nil)
+		} else {
+			nullable = append(nullable, int64(i))
+		}
+	}
+	for len(mixed) < 5000 {
+		n := rnd.Intn(600)
+		start := rnd.Int63n(1 << 40)
+		for i := 0; i < n; i++ {
+			switch len(mixed) / 1000 {
+			case 0:
+				mixed = append(mixed, start)
+			case 1:
+				mixed = append(mixed, start+int64(i)*3)
+			case 2:
+				mixed = append(mixed, rnd.Int63n(100))
+			case 3:
+				if i%100 == 0 {
+					mixed = append(mixed, rnd.Int63())
+				} else {
+					mixed = append(mixed, rnd.Int63n(1000))
+				}
+			default:
+				mixed = append(mixed, -rnd.Int63n(1<<20))
+			}
+		}
+	}
+	stripes := [][]interface{}{random, nullable, mixed}
+	b := writeInt64Stripes(t, stripes)
+
+	var expected []int64
+	var expectedNulls []bool
+	for _, stripe := range stripes {
+		for _, v := range stripe {
+			i, _ := v.(int64)
+			expected = append(expected, i)
+			expectedNulls = append(expectedNulls, v == nil)
+		}
+	}
+
+	for _, limit := range []int64{-1, 0, 1000, 3500, 10000} {
+		r, err := NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Limit(limit)
+		values, nulls, err := ReadInt64Column(r, "long1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := len(expected)
+		if limit >= 0 && limit < int64(n) {
+			n = int(limit)
+		}
+		if len(values) != n || (n > 0 && !reflect.DeepEqual(values, expected[:n])) {
+			t.Errorf("Test failed, limit %d expected %d values got %d differing values", limit, n, len(values))
+		}
+		if len(nulls) != n || (n > 0 && !reflect.DeepEqual(nulls, expectedNulls[:n])) {
+			t.Errorf("Test failed, limit %d expected %d nulls got %d differing nulls", limit, n, len(nulls))
+		}
+	}
+
+	// Only the stripes without nulls are read directly.
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := r.Select("long1")
+	var direct []bool
+	for c.Stripes() {
+		var values []int64
+		_, ok, err := readDenseInt64s(c, &values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		direct = append(direct, ok)
+	}
+	if !reflect.DeepEqual(direct, []bool{true, false, true}) {
+		t.Errorf("Test failed, expected the first and last stripes to be read directly got %v", direct)
+	}
+
+}
+
+func TestReadColumnInterleaved(t *testing.T) {
+
+	stripes := [][]interface{}{{int64(1), int64(2)}, {int64(3), int64(4)}, {int64(5)}}
+	r, err := NewReader(bytes.NewReader(writeInt64Stripes(t, stripes)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading a whole column between the stripes of a Cursor neither restarts
+	// nor exhausts the Cursor.
+	var values []interface{}
+	c := r.Select("long1")
+	for i := 0; c.Stripes(); i++ {
+		for c.Next() {
+			values = append(values, c.Row()[0])
+		}
+		column, _, err := ReadInt64Column(r, "long1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(column, []int64{1, 2, 3, 4, 5}) {
+			t.Errorf("Test failed, after stripe %d expected every value of the column got %v", i, column)
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []interface{}{int64(1), int64(2), int64(3), int64(4), int64(5)}) {
+		t.Errorf("Test failed, expected the Cursor to read every row once got %v", values)
+	}
+
+}
+
+func BenchmarkReadInt64Column(b *testing.B) {
+	const rows = 10000000
+	values := make([]interface{}, rows)
+	rnd := rand.New(rand.NewSource(1))
+	for i := range values {
+		values[i] = rnd.Int63n(1 << 40)
+	}
+	file := writeInt64Stripes(b, [][]interface{}{values})
+
+	// The direct path of ReadInt64Column against reading each value of the
+	// column with a Cursor.
+	b.Run("direct", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r, err := NewReader(bytes.NewReader(file))
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := ReadInt64Column(r, "long1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cursor", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			r, err := NewReader(bytes.NewReader(file))
+			if err != nil {
+				b.Fatal(err)
+			}
+			ints := make([]int64, 0, rows)
+			c := r.Select("long1")
+			for c.Stripes() {
+				for c.Next() {
+					ints = append(ints, c.Row()[0].(int64))
+				}
+			}
+			if err := c.Err(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
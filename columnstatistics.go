@@ -1,6 +1,12 @@
 package orc
 
 import (
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
 	"code.simon-critchley.co.uk/orc/proto"
 )
 
@@ -8,12 +14,20 @@ type statisticsMap map[int]ColumnStatistics
 
 func NewColumnStatistics(category Category) ColumnStatistics {
 	switch category {
-	case CategoryInt, CategoryShort, CategoryLong:
+	case CategoryByte, CategoryInt, CategoryShort, CategoryLong:
 		return NewIntegerStatistics()
-	case CategoryString:
+	case CategoryFloat, CategoryDouble:
+		return NewDoubleStatistics()
+	case CategoryDecimal:
+		return NewDecimalStatistics()
+	case CategoryString, CategoryVarchar, CategoryChar:
 		return NewStringStatistics()
+	case CategoryDate:
+		return NewDateStatistics()
 	case CategoryBoolean:
 		return NewBucketStatistics()
+	case CategoryList, CategoryMap:
+		return NewCollectionStatistics()
 	default:
 		return NewBaseStatistics()
 	}
@@ -78,18 +92,39 @@ func NewBaseStatistics() BaseStatistics {
 	}
 }
 
+// Add records a null if value is nil, which unlike other values is not counted
+// in the number of values.
 func (b BaseStatistics) Add(value interface{}) {
-	if hasNull := value == nil; hasNull {
+	if value == nil {
+		hasNull := true
 		b.HasNull = &hasNull
+		if b.ColumnStatistics.NumberOfValues == nil {
+			b.ColumnStatistics.NumberOfValues = ptrUint64(0)
+		}
+		return
 	}
-	n := b.ColumnStatistics.GetNumberOfValues() + 1
-	b.ColumnStatistics.NumberOfValues = &n
+	b.addValue()
 }
 
+// addValue increments the number of values, in place once it is set so that
+// adding a value does not allocate.
+func (b BaseStatistics) addValue() {
+	if b.ColumnStatistics.NumberOfValues != nil {
+		*b.ColumnStatistics.NumberOfValues++
+		return
+	}
+	b.ColumnStatistics.NumberOfValues = ptrUint64(1)
+}
+
+// Merge adds the number of values of other and records whether either contains
+// a null value.
 func (b BaseStatistics) Merge(other ColumnStatistics) {
-	if bs, ok := other.(BaseStatistics); ok {
-		numValues := b.GetNumberOfValues() + bs.GetNumberOfValues()
-		b.NumberOfValues = &numValues
+	os := other.Statistics()
+	numValues := b.GetNumberOfValues() + os.GetNumberOfValues()
+	b.NumberOfValues = &numValues
+	if os.GetHasNull() {
+		hasNull := true
+		b.HasNull = &hasNull
 	}
 }
 
@@ -97,43 +132,80 @@ func (b BaseStatistics) Statistics() *proto.ColumnStatistics {
 	return b.ColumnStatistics
 }
 
+// IntegerStatistics records the minimum, maximum and sum of an integer column.
+// As with the Java implementation the sum is omitted once it overflows an int64.
 type IntegerStatistics struct {
 	BaseStatistics
-	minSet bool
+	minSet     bool
+	sumInvalid bool
 }
 
 func (i *IntegerStatistics) Merge(other ColumnStatistics) {
 	if is, ok := other.(*IntegerStatistics); ok {
-		if is.IntStatistics.GetMaximum() > i.IntStatistics.GetMaximum() {
-			i.IntStatistics.Maximum = is.IntStatistics.Maximum
+		if is.minSet {
+			i.update(is.IntStatistics.GetMinimum())
+			i.update(is.IntStatistics.GetMaximum())
 		}
-		if is.IntStatistics.GetMinimum() < i.IntStatistics.GetMinimum() {
-			i.IntStatistics.Minimum = is.IntStatistics.Minimum
+		if is.sumInvalid {
+			i.invalidateSum()
+		} else {
+			i.addSum(is.IntStatistics.GetSum())
 		}
-		sum := i.IntStatistics.GetSum() + is.IntStatistics.GetSum()
-		i.IntStatistics.Sum = &sum
 		i.BaseStatistics.Merge(is.BaseStatistics)
 	}
 }
 
 func (i *IntegerStatistics) Add(value interface{}) {
 	if val, ok := value.(int64); ok {
-		if val > i.IntStatistics.GetMaximum() {
-			i.IntStatistics.Maximum = &val
-		}
-		if !i.minSet {
-			i.IntStatistics.Minimum = &val
-			i.minSet = true
-		}
-		if val < i.IntStatistics.GetMinimum() {
-			i.IntStatistics.Minimum = &val
-		}
-		sum := i.IntStatistics.GetSum() + val
-		i.IntStatistics.Sum = &sum
+		i.addInt(val)
+		return
 	}
 	i.BaseStatistics.Add(value)
 }
 
+// addInt adds the non-null value val without boxing it in an interface.
+func (i *IntegerStatistics) addInt(val int64) {
+	i.update(val)
+	i.addSum(val)
+	i.BaseStatistics.addValue()
+}
+
+// update adjusts the minimum and maximum to include val.
+func (i *IntegerStatistics) update(val int64) {
+	if !i.minSet || val < i.IntStatistics.GetMinimum() {
+		min := val
+		i.IntStatistics.Minimum = &min
+	}
+	if !i.minSet || val > i.IntStatistics.GetMaximum() {
+		max := val
+		i.IntStatistics.Maximum = &max
+	}
+	i.minSet = true
+}
+
+// addSum adds val to the sum, removing the sum if the addition overflows.
+func (i *IntegerStatistics) addSum(val int64) {
+	if i.sumInvalid {
+		return
+	}
+	sum := i.IntStatistics.GetSum() + val
+	if (val > 0 && sum < i.IntStatistics.GetSum()) || (val < 0 && sum > i.IntStatistics.GetSum()) {
+		i.invalidateSum()
+		return
+	}
+	if i.IntStatistics.Sum != nil {
+		*i.IntStatistics.Sum = sum
+		return
+	}
+	i.IntStatistics.Sum = ptrInt64(sum)
+}
+
+// invalidateSum removes the sum after it has overflowed.
+func (i *IntegerStatistics) invalidateSum() {
+	i.sumInvalid = true
+	i.IntStatistics.Sum = nil
+}
+
 func (i *IntegerStatistics) Statistics() *proto.ColumnStatistics {
 	return i.ColumnStatistics
 }
@@ -150,9 +222,300 @@ func NewIntegerStatistics() *IntegerStatistics {
 	}
 }
 
+// DateStatistics records the minimum and maximum of a date column as the number
+// of days since the epoch.
+type DateStatistics struct {
+	BaseStatistics
+	minSet bool
+}
+
+func NewDateStatistics() *DateStatistics {
+	base := NewBaseStatistics()
+	base.DateStatistics = &proto.DateStatistics{}
+	return &DateStatistics{
+		BaseStatistics: base,
+	}
+}
+
+func (d *DateStatistics) Merge(other ColumnStatistics) {
+	if ds, ok := other.(*DateStatistics); ok {
+		if ds.minSet {
+			d.update(ds.DateStatistics.GetMinimum())
+			d.update(ds.DateStatistics.GetMaximum())
+		}
+		d.BaseStatistics.Merge(ds.BaseStatistics)
+	}
+}
+
+// Add adds a value, which is the number of days since the epoch of a date.
+func (d *DateStatistics) Add(value interface{}) {
+	if val, ok := value.(int64); ok {
+		d.update(int32(val))
+		d.BaseStatistics.addValue()
+		return
+	}
+	d.BaseStatistics.Add(value)
+}
+
+// update adjusts the minimum and maximum to include val.
+func (d *DateStatistics) update(val int32) {
+	if !d.minSet || val < d.DateStatistics.GetMinimum() {
+		d.DateStatistics.Minimum = ptrInt32(val)
+	}
+	if !d.minSet || val > d.DateStatistics.GetMaximum() {
+		d.DateStatistics.Maximum = ptrInt32(val)
+	}
+	d.minSet = true
+}
+
+func (d *DateStatistics) Reset() {
+	*d = *NewDateStatistics()
+}
+
+func (d *DateStatistics) Statistics() *proto.ColumnStatistics {
+	return d.ColumnStatistics
+}
+
+// DoubleStatistics records the minimum, maximum and sum of a float or double
+// column. Following the Java implementation a NaN value invalidates the
+// minimum and maximum, which are then omitted from the statistics, whereas
+// infinite values are included in them. The sum is always written, being zero
+// when there are no values.
+type DoubleStatistics struct {
+	BaseStatistics
+	minSet bool
+	hasNaN bool
+}
+
+func NewDoubleStatistics() *DoubleStatistics {
+	base := NewBaseStatistics()
+	base.DoubleStatistics = &proto.DoubleStatistics{
+		Sum: ptrFloat64(0),
+	}
+	return &DoubleStatistics{
+		BaseStatistics: base,
+	}
+}
+
+func (d *DoubleStatistics) Merge(other ColumnStatistics) {
+	if ds, ok := other.(*DoubleStatistics); ok {
+		if ds.hasNaN {
+			d.invalidate()
+		}
+		if ds.minSet && !d.hasNaN {
+			d.update(ds.DoubleStatistics.GetMinimum())
+			d.update(ds.DoubleStatistics.GetMaximum())
+		}
+		*d.DoubleStatistics.Sum += ds.DoubleStatistics.GetSum()
+		d.BaseStatistics.Merge(ds.BaseStatistics)
+	}
+}
+
+func (d *DoubleStatistics) Add(value interface{}) {
+	var val float64
+	var ok bool
+	switch t := value.(type) {
+	case float64:
+		val, ok = t, true
+	case float32:
+		val, ok = float64(t), true
+	case Double:
+		val, ok = float64(t), true
+	case Float:
+		val, ok = float64(t), true
+	}
+	if ok {
+		d.addFloat(val)
+		return
+	}
+	d.BaseStatistics.Add(value)
+}
+
+// addFloat adds the non-null value val without boxing it in an interface.
+func (d *DoubleStatistics) addFloat(val float64) {
+	if math.IsNaN(val) {
+		d.invalidate()
+	} else if !d.hasNaN {
+		d.update(val)
+	}
+	*d.DoubleStatistics.Sum += val
+	d.BaseStatistics.addValue()
+}
+
+// update adjusts the minimum and maximum to include val.
+func (d *DoubleStatistics) update(val float64) {
+	if !d.minSet || val < d.DoubleStatistics.GetMinimum() {
+		min := val
+		d.DoubleStatistics.Minimum = &min
+	}
+	if !d.minSet || val > d.DoubleStatistics.GetMaximum() {
+		max := val
+		d.DoubleStatistics.Maximum = &max
+	}
+	d.minSet = true
+}
+
+// invalidate removes the minimum and maximum after a NaN value is seen.
+func (d *DoubleStatistics) invalidate() {
+	d.hasNaN = true
+	d.DoubleStatistics.Minimum = nil
+	d.DoubleStatistics.Maximum = nil
+}
+
+// DoubleRange returns the minimum and maximum values of a float or double column
+// from its statistics, or false if the range of its values is unknown. The range is
+// unknown when the statistics omit the minimum or maximum, as they do once the
+// column holds a NaN, or record either as NaN. As NaN compares false
+// to every value, a predicate on the range of the values of a column, such as that
+// of a stripe filter, must treat a column of unknown range as possibly matching.
+func DoubleRange(s ColumnStatistics) (min, max float64, ok bool) {
+	ds := s.Statistics().GetDoubleStatistics()
+	if ds == nil || ds.Minimum == nil || ds.Maximum == nil {
+		return 0, 0, false
+	}
+	min, max = ds.GetMinimum(), ds.GetMaximum()
+	if math.IsNaN(min) || math.IsNaN(max) {
+		return 0, 0, false
+	}
+	return min, max, true
+}
+
+func (d *DoubleStatistics) Reset() {
+	*d = *NewDoubleStatistics()
+}
+
+func (d *DoubleStatistics) Statistics() *proto.ColumnStatistics {
+	return d.ColumnStatistics
+}
+
+// DoubleMinMax returns the minimum and maximum of the double statistics ds and
+// whether they are set, which they are not when the column has no values or any
+// of its values is NaN.
+func DoubleMinMax(ds *proto.DoubleStatistics) (min, max float64, hasMinMax bool) {
+	if ds == nil || ds.Minimum == nil || ds.Maximum == nil {
+		return 0, 0, false
+	}
+	return ds.GetMinimum(), ds.GetMaximum(), true
+}
+
+// DoubleSum returns the sum of the double statistics ds and whether it is set.
+// The sum is NaN when any value is NaN or the values include both infinities.
+func DoubleSum(ds *proto.DoubleStatistics) (sum float64, hasSum bool) {
+	if ds == nil || ds.Sum == nil {
+		return 0, false
+	}
+	return ds.GetSum(), true
+}
+
+// DecimalStatistics records the minimum, maximum and sum of a decimal column,
+// which are stored as strings. As with the Java implementation the sum is
+// omitted once it overflows the maximum decimal precision.
+type DecimalStatistics struct {
+	BaseStatistics
+	min        *Decimal
+	max        *Decimal
+	sum        *big.Rat
+	sumInvalid bool
+}
+
+func NewDecimalStatistics() *DecimalStatistics {
+	base := NewBaseStatistics()
+	base.DecimalStatistics = &proto.DecimalStatistics{}
+	return &DecimalStatistics{
+		BaseStatistics: base,
+		sum:            new(big.Rat),
+	}
+}
+
+func (d *DecimalStatistics) Merge(other ColumnStatistics) {
+	if ds, ok := other.(*DecimalStatistics); ok {
+		if ds.min != nil {
+			d.update(*ds.min)
+			d.update(*ds.max)
+		}
+		if ds.sumInvalid {
+			d.sumInvalid = true
+		} else {
+			d.addSum(ds.sum)
+		}
+		d.BaseStatistics.Merge(ds.BaseStatistics)
+	}
+}
+
+func (d *DecimalStatistics) Add(value interface{}) {
+	if val, ok := value.(Decimal); ok {
+		d.update(val)
+		d.addSum(val.Rat())
+	}
+	d.BaseStatistics.Add(value)
+}
+
+// update adjusts the minimum and maximum to include val.
+func (d *DecimalStatistics) update(val Decimal) {
+	r := val.Rat()
+	if d.min == nil || r.Cmp(d.min.Rat()) < 0 {
+		d.min = &val
+	}
+	if d.max == nil || r.Cmp(d.max.Rat()) > 0 {
+		d.max = &val
+	}
+}
+
+func (d *DecimalStatistics) addSum(r *big.Rat) {
+	if d.sumInvalid {
+		return
+	}
+	d.sum.Add(d.sum, r)
+	// The precision is the number of integer digits plus the
+	// number of fractional digits excluding trailing zeros.
+	parts := strings.SplitN(strings.TrimPrefix(formatDecimalStatistic(d.sum), "-"), ".", 2)
+	precision := len(strings.TrimLeft(parts[0], "0"))
+	if len(parts) == 2 {
+		precision += len(parts[1])
+	}
+	if precision > maxPrecision {
+		d.sumInvalid = true
+	}
+}
+
+func (d *DecimalStatistics) Reset() {
+	*d = *NewDecimalStatistics()
+}
+
+func (d *DecimalStatistics) Statistics() *proto.ColumnStatistics {
+	if d.min != nil {
+		d.DecimalStatistics.Minimum = ptrStr(formatDecimalStatistic(d.min.Rat()))
+		d.DecimalStatistics.Maximum = ptrStr(formatDecimalStatistic(d.max.Rat()))
+	}
+	if d.sumInvalid {
+		d.DecimalStatistics.Sum = nil
+	} else {
+		d.DecimalStatistics.Sum = ptrStr(formatDecimalStatistic(d.sum))
+	}
+	return d.ColumnStatistics
+}
+
+// formatDecimalStatistic formats r without trailing fractional zeros, which is
+// the string form used by the Java implementation, e.g. 1.50 is written as 1.5.
+func formatDecimalStatistic(r *big.Rat) string {
+	s := r.FloatString(maxScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// maxStringStatisticLength is the maximum length in bytes of the minimum and maximum
+// values written by StringStatistics. Longer values are truncated and written as
+// the lower and upper bounds of the values instead, as done by the Java
+// implementation.
+const maxStringStatisticLength = 1024
+
 type StringStatistics struct {
 	BaseStatistics
 	minSet bool
+	// min and max hold the exact minimum and maximum values, which are truncated
+	// when written by Statistics.
+	min string
+	max string
 }
 
 func NewStringStatistics() *StringStatistics {
@@ -165,11 +528,9 @@ func NewStringStatistics() *StringStatistics {
 
 func (s *StringStatistics) Merge(other ColumnStatistics) {
 	if ss, ok := other.(*StringStatistics); ok {
-		if ss.StringStatistics.GetMaximum() > s.StringStatistics.GetMaximum() {
-			s.StringStatistics.Maximum = ss.StringStatistics.Maximum
-		}
-		if ss.StringStatistics.GetMinimum() < s.StringStatistics.GetMinimum() {
-			s.StringStatistics.Minimum = ss.StringStatistics.Minimum
+		if ss.minSet {
+			s.update(ss.min)
+			s.update(ss.max)
 		}
 		sum := s.StringStatistics.GetSum() + ss.StringStatistics.GetSum()
 		s.StringStatistics.Sum = &sum
@@ -179,45 +540,235 @@ func (s *StringStatistics) Merge(other ColumnStatistics) {
 
 func (s *StringStatistics) Add(value interface{}) {
 	if val, ok := value.(string); ok {
-		if val > s.StringStatistics.GetMaximum() {
-			s.StringStatistics.Maximum = &val
-		}
-		if !s.minSet {
-			s.StringStatistics.Minimum = &val
-			s.minSet = true
-		}
-		if val < s.StringStatistics.GetMinimum() {
-			s.StringStatistics.Minimum = &val
-		}
-		sum := s.StringStatistics.GetSum() + int64(len(val))
-		s.StringStatistics.Sum = &sum
+		s.addString(val)
+		return
 	}
 	s.BaseStatistics.Add(value)
 }
 
+// addString adds the non-null value val without boxing it in an interface.
+func (s *StringStatistics) addString(val string) {
+	s.update(val)
+	if s.StringStatistics.Sum != nil {
+		*s.StringStatistics.Sum += int64(len(val))
+	} else {
+		s.StringStatistics.Sum = ptrInt64(int64(len(val)))
+	}
+	s.BaseStatistics.addValue()
+}
+
+// update adjusts the minimum and maximum to include val.
+func (s *StringStatistics) update(val string) {
+	if !s.minSet || val < s.min {
+		s.min = val
+	}
+	if !s.minSet || val > s.max {
+		s.max = val
+	}
+	s.minSet = true
+}
+
 func (s *StringStatistics) Reset() {
 	*s = *NewStringStatistics()
 }
 
+// Statistics returns the statistics with the minimum and maximum values, or with
+// the lower and upper bounds in place of either of them when longer than
+// maxStringStatisticLength bytes.
 func (s *StringStatistics) Statistics() *proto.ColumnStatistics {
+	if s.minSet {
+		ss := s.StringStatistics
+		ss.Minimum, ss.LowerBound = nil, nil
+		if len(s.min) > maxStringStatisticLength {
+			ss.LowerBound = ptrStr(truncateLowerBound(s.min))
+		} else {
+			ss.Minimum = ptrStr(s.min)
+		}
+		ss.Maximum, ss.UpperBound = nil, nil
+		if upper, ok := truncateUpperBound(s.max); ok && len(s.max) > maxStringStatisticLength {
+			ss.UpperBound = ptrStr(upper)
+		} else {
+			ss.Maximum = ptrStr(s.max)
+		}
+	}
 	return s.ColumnStatistics
 }
 
+// truncateLowerBound returns the first maxStringStatisticLength bytes of val, which
+// is less than val. The cut is moved back to the start of a character when val is
+// valid UTF-8.
+func truncateLowerBound(val string) string {
+	return val[:truncationPoint(val)]
+}
+
+// truncateUpperBound returns the shortest string greater than val, formed by
+// truncating val to maxStringStatisticLength bytes and incrementing its last
+// character, dropping any characters following it that cannot be incremented. The
+// characters of valid UTF-8 are its code points, which are incremented to the next
+// code point that is not a surrogate, as by the Java implementation, and otherwise
+// its bytes. It returns false if no character of the truncated value can be
+// incremented, as no such string exists.
+func truncateUpperBound(val string) (string, bool) {
+	truncated := val[:truncationPoint(val)]
+	if utf8.ValidString(val) {
+		for len(truncated) > 0 {
+			r, size := utf8.DecodeLastRuneInString(truncated)
+			truncated = truncated[:len(truncated)-size]
+			if r < utf8.MaxRune {
+				// Skip the surrogates from U+D800 to U+DFFF.
+				if r++; r == 0xd800 {
+					r = 0xe000
+				}
+				return truncated + string(r), true
+			}
+		}
+		return "", false
+	}
+	b := []byte(truncated)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
+
+// truncationPoint returns the length to which val is truncated.
+func truncationPoint(val string) int {
+	n := maxStringStatisticLength
+	if n >= len(val) {
+		return len(val)
+	}
+	if utf8.ValidString(val) {
+		for n > 0 && !utf8.RuneStart(val[n]) {
+			n--
+		}
+	}
+	return n
+}
+
+// StringBounds returns the lower and upper bounds of the values of the string
+// statistics ss, and whether each bound is exact, being the minimum or maximum
+// value, or was truncated by the writer, in which case the minimum or maximum
+// value is only known to be greater than or equal to lower, or less than upper.
+func StringBounds(ss *proto.StringStatistics) (lower string, lowerExact bool, upper string, upperExact bool) {
+	if ss.GetLowerBound() != "" {
+		lower = ss.GetLowerBound()
+	} else {
+		lower, lowerExact = ss.GetMinimum(), ss != nil && ss.Minimum != nil
+	}
+	if ss.GetUpperBound() != "" {
+		upper = ss.GetUpperBound()
+	} else {
+		upper, upperExact = ss.GetMaximum(), ss != nil && ss.Maximum != nil
+	}
+	return lower, lowerExact, upper, upperExact
+}
+
+// CollectionStatistics records the minimum, maximum and total number of child
+// elements of a list or map column.
+type CollectionStatistics struct {
+	BaseStatistics
+	minSet bool
+}
+
+func NewCollectionStatistics() *CollectionStatistics {
+	base := NewBaseStatistics()
+	base.CollectionStatistics = &proto.CollectionStatistics{}
+	return &CollectionStatistics{
+		BaseStatistics: base,
+	}
+}
+
+func (c *CollectionStatistics) Merge(other ColumnStatistics) {
+	if cs, ok := other.(*CollectionStatistics); ok {
+		if cs.minSet {
+			c.update(cs.CollectionStatistics.GetMinChildren())
+			c.update(cs.CollectionStatistics.GetMaxChildren())
+		}
+		total := c.CollectionStatistics.GetTotalChildren() + cs.CollectionStatistics.GetTotalChildren()
+		c.CollectionStatistics.TotalChildren = &total
+		c.BaseStatistics.Merge(cs.BaseStatistics)
+	}
+}
+
+func (c *CollectionStatistics) Add(value interface{}) {
+	if value != nil {
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.Slice, reflect.Map:
+			n := uint64(v.Len())
+			c.update(n)
+			total := c.CollectionStatistics.GetTotalChildren() + n
+			c.CollectionStatistics.TotalChildren = &total
+		}
+	}
+	c.BaseStatistics.Add(value)
+}
+
+// update adjusts the minimum and maximum number of children to include n.
+func (c *CollectionStatistics) update(n uint64) {
+	if !c.minSet || n < c.CollectionStatistics.GetMinChildren() {
+		min := n
+		c.CollectionStatistics.MinChildren = &min
+	}
+	if !c.minSet || n > c.CollectionStatistics.GetMaxChildren() {
+		max := n
+		c.CollectionStatistics.MaxChildren = &max
+	}
+	c.minSet = true
+}
+
+func (c *CollectionStatistics) Reset() {
+	*c = *NewCollectionStatistics()
+}
+
+func (c *CollectionStatistics) Statistics() *proto.ColumnStatistics {
+	return c.ColumnStatistics
+}
+
+// BucketStatistics records the number of true values of a boolean column.
 type BucketStatistics struct {
 	BaseStatistics
 }
 
 func NewBucketStatistics() *BucketStatistics {
 	base := NewBaseStatistics()
-	base.BucketStatistics = &proto.BucketStatistics{}
+	base.BucketStatistics = &proto.BucketStatistics{
+		Count: []uint64{0},
+	}
 	return &BucketStatistics{
 		base,
 	}
 }
 
-// func (b *BucketStatistics) Add(value interface{}) {
-// 	if t, ok := value.(bool); ok {
-// 		b.BaseStatistics
-// 	}
-// 	b.BaseStatistics.Add(value)
-// }
+func (b *BucketStatistics) Merge(other ColumnStatistics) {
+	if bs, ok := other.(*BucketStatistics); ok {
+		b.BucketStatistics.Count[0] += bs.BucketStatistics.GetCount()[0]
+		b.BaseStatistics.Merge(bs.BaseStatistics)
+	}
+}
+
+func (b *BucketStatistics) Add(value interface{}) {
+	if t, ok := value.(bool); ok {
+		b.addBool(t)
+		return
+	}
+	b.BaseStatistics.Add(value)
+}
+
+// addBool adds the non-null value val without boxing it in an interface.
+func (b *BucketStatistics) addBool(val bool) {
+	if val {
+		b.BucketStatistics.Count[0]++
+	}
+	b.BaseStatistics.addValue()
+}
+
+func (b *BucketStatistics) Reset() {
+	*b = *NewBucketStatistics()
+}
+
+func (b *BucketStatistics) Statistics() *proto.ColumnStatistics {
+	return b.ColumnStatistics
+}
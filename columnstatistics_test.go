@@ -0,0 +1,437 @@
+package orc
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// countValues returns the number of values other than nulls, being those counted
+// by the statistics.
+func countValues(values []interface{}) uint64 {
+	var n uint64
+	for _, v := range values {
+		if v != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDoubleStatistics(t *testing.T) {
+
+	testCases := []struct {
+		input  []interface{}
+		hasMin bool
+		min    float64
+		max    float64
+		sum    float64
+	}{
+		{
+			input:  []interface{}{1.5, -2.5, nil, 4.0},
+			hasMin: true,
+			min:    -2.5,
+			max:    4.0,
+			sum:    3.0,
+		},
+		{
+			input:  []interface{}{float32(0.5), Float(1.5), Double(-1)},
+			hasMin: true,
+			min:    -1,
+			max:    1.5,
+			sum:    1,
+		},
+		{
+			input:  []interface{}{math.Inf(-1), 1.0, math.Inf(1)},
+			hasMin: true,
+			min:    math.Inf(-1),
+			max:    math.Inf(1),
+			sum:    math.NaN(),
+		},
+		{
+			input:  []interface{}{1.0, math.NaN(), 2.0},
+			hasMin: false,
+			sum:    math.NaN(),
+		},
+		{
+			input:  []interface{}{math.NaN(), 2.0},
+			hasMin: false,
+			sum:    math.NaN(),
+		},
+		{
+			input:  []interface{}{nil},
+			hasMin: false,
+			sum:    0,
+		},
+	}
+
+	for _, tc := range testCases {
+		s := NewDoubleStatistics()
+		for _, v := range tc.input {
+			s.Add(v)
+		}
+		ds := s.Statistics().GetDoubleStatistics()
+		if hasMin := ds.Minimum != nil && ds.Maximum != nil; hasMin != tc.hasMin {
+			t.Errorf("Test failed, expected min and max set to be %v for input %v", tc.hasMin, tc.input)
+		}
+		if tc.hasMin && (ds.GetMinimum() != tc.min || ds.GetMaximum() != tc.max) {
+			t.Errorf("Test failed, expected min %v max %v got min %v max %v", tc.min, tc.max, ds.GetMinimum(), ds.GetMaximum())
+		}
+		if sum := ds.GetSum(); sum != tc.sum && !(math.IsNaN(sum) && math.IsNaN(tc.sum)) {
+			t.Errorf("Test failed, expected sum %v got %v", tc.sum, sum)
+		}
+		if n, expected := s.Statistics().GetNumberOfValues(), countValues(tc.input); n != expected {
+			t.Errorf("Test failed, expected %v values got %v", expected, n)
+		}
+	}
+
+}
+
+func TestDoubleStatisticsMerge(t *testing.T) {
+	a := NewDoubleStatistics()
+	a.Add(1.0)
+	a.Add(5.0)
+	b := NewDoubleStatistics()
+	b.Add(-3.0)
+	a.Merge(b)
+	ds := a.Statistics().GetDoubleStatistics()
+	if ds.GetMinimum() != -3 || ds.GetMaximum() != 5 || ds.GetSum() != 3 {
+		t.Errorf("Test failed, got min %v max %v sum %v", ds.GetMinimum(), ds.GetMaximum(), ds.GetSum())
+	}
+	c := NewDoubleStatistics()
+	c.Add(math.NaN())
+	a.Merge(c)
+	if ds := a.Statistics().GetDoubleStatistics(); ds.Minimum != nil || ds.Maximum != nil {
+		t.Errorf("Test failed, expected NaN to invalidate min and max")
+	}
+}
+
+func TestDoubleRange(t *testing.T) {
+
+	// stats returns the statistics of the values.
+	stats := func(values ...float64) ColumnStatistics {
+		s := NewDoubleStatistics()
+		for _, v := range values {
+			s.Add(v)
+		}
+		return s
+	}
+	// recorded returns statistics recording the minimum and maximum.
+	recorded := func(min, max float64) ColumnStatistics {
+		return BaseStatistics{&proto.ColumnStatistics{
+			DoubleStatistics: &proto.DoubleStatistics{Minimum: &min, Maximum: &max, Sum: ptrFloat64(min + max)},
+		}}
+	}
+
+	testCases := []struct {
+		stats    ColumnStatistics
+		min, max float64
+		ok       bool
+	}{
+		{stats: stats(1, -2, 3), min: -2, max: 3, ok: true},
+		{stats: stats(math.Inf(-1), math.Inf(1)), min: math.Inf(-1), max: math.Inf(1), ok: true},
+		{stats: stats(-1, math.NaN(), -2)},
+		{stats: stats()},
+		{stats: recorded(math.NaN(), 1)},
+		{stats: recorded(-1, math.NaN())},
+		{stats: NewIntegerStatistics()},
+	}
+
+	for i, tc := range testCases {
+		min, max, ok := DoubleRange(tc.stats)
+		if min != tc.min || max != tc.max || ok != tc.ok {
+			t.Errorf("Test failed, case %d expected %v %v %v got %v %v %v", i, tc.min, tc.max, tc.ok, min, max, ok)
+		}
+	}
+
+	// A stripe containing NaN is never excluded by a predicate on the range of
+	// its values, here that a value is greater than zero.
+	schema, err := ParseSchema("struct<d:double>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stripe := range [][]float64{{-5, -1}, {-3, math.NaN(), -2}} {
+		for _, v := range stripe {
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), WithStripeFilter(func(stripe int, stats []ColumnStatistics) bool {
+		_, max, ok := DoubleRange(stats[1])
+		return !ok || max > 0
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != Double(-3) {
+		t.Errorf("Test failed, expected the rows of the stripe containing NaN got %v", rows)
+	}
+
+}
+
+func TestDecimalStatistics(t *testing.T) {
+	s := NewDecimalStatistics()
+	for _, v := range []string{"1.50", "-12345.678", "99.125"} {
+		d, err := toDecimal(v, 10, 3, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Add(d)
+	}
+	s.Add(nil)
+	ds := s.Statistics().GetDecimalStatistics()
+	if ds.GetMinimum() != "-12345.678" || ds.GetMaximum() != "99.125" || ds.GetSum() != "-12245.053" {
+		t.Errorf("Test failed, got min %v max %v sum %v", ds.GetMinimum(), ds.GetMaximum(), ds.GetSum())
+	}
+	if !s.Statistics().GetHasNull() {
+		t.Errorf("Test failed, expected hasNull to be true")
+	}
+
+	// The sum is omitted once it overflows the maximum precision.
+	o := NewDecimalStatistics()
+	for i := 0; i < 2; i++ {
+		d, err := toDecimal("9999999999999999999999999999.9999999999", 38, 10, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Add(d)
+	}
+	if o.Statistics().GetDecimalStatistics().Sum != nil {
+		t.Errorf("Test failed, expected overflowing sum to be omitted")
+	}
+	s.Merge(o)
+	ds = s.Statistics().GetDecimalStatistics()
+	if ds.GetMaximum() != "9999999999999999999999999999.9999999999" || ds.Sum != nil {
+		t.Errorf("Test failed, got max %v sum %v", ds.GetMaximum(), ds.GetSum())
+	}
+}
+
+func TestIntegerStatistics(t *testing.T) {
+
+	testCases := []struct {
+		input  []interface{}
+		merge  []interface{}
+		min    int64
+		max    int64
+		hasSum bool
+		sum    int64
+	}{
+		{
+			input:  []interface{}{int64(-5), int64(-2), nil, int64(-9)},
+			min:    -9,
+			max:    -2,
+			hasSum: true,
+			sum:    -16,
+		},
+		{
+			input:  []interface{}{nil},
+			merge:  []interface{}{int64(3), int64(7)},
+			min:    3,
+			max:    7,
+			hasSum: true,
+			sum:    10,
+		},
+		{
+			input: []interface{}{int64(math.MaxInt64), int64(1), int64(-10)},
+			min:   -10,
+			max:   math.MaxInt64,
+		},
+		{
+			input: []interface{}{int64(math.MinInt64)},
+			merge: []interface{}{int64(-1)},
+			min:   math.MinInt64,
+			max:   -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		s := NewIntegerStatistics()
+		for _, v := range tc.input {
+			s.Add(v)
+		}
+		if tc.merge != nil {
+			o := NewIntegerStatistics()
+			for _, v := range tc.merge {
+				o.Add(v)
+			}
+			s.Merge(o)
+		}
+		is := s.Statistics().GetIntStatistics()
+		if is.GetMinimum() != tc.min || is.GetMaximum() != tc.max {
+			t.Errorf("Test failed, expected min %v max %v got min %v max %v", tc.min, tc.max, is.GetMinimum(), is.GetMaximum())
+		}
+		if hasSum := is.Sum != nil; hasSum != tc.hasSum || is.GetSum() != tc.sum {
+			t.Errorf("Test failed, expected sum %v (set %v) got %v (set %v)", tc.sum, tc.hasSum, is.GetSum(), hasSum)
+		}
+		if n, expected := s.Statistics().GetNumberOfValues(), countValues(tc.input)+countValues(tc.merge); n != expected {
+			t.Errorf("Test failed, expected %v values got %v", expected, n)
+		}
+	}
+
+}
+
+func TestStringStatisticsTruncation(t *testing.T) {
+
+	long := strings.Repeat("a", 2000)
+	// The last byte before the cut point cannot be incremented, so the upper bound
+	// carries into the preceding byte.
+	carry := strings.Repeat("a", 1021) + "\xff\xff\xff" + "zzz"
+	ones := strings.Repeat("\xff", 2000)
+	// The two byte character straddling the cut point is dropped from both bounds.
+	multibyte := strings.Repeat("a", 1023) + strings.Repeat("\u00e9", 10)
+	// The last character before the cut point of valid UTF-8 is incremented as a
+	// code point, whose last byte would otherwise become a lead byte, skipping the
+	// surrogates and carrying past the last code point.
+	codePoint := strings.Repeat("a", 1022) + "\u00bf" + "zzz"
+	surrogate := strings.Repeat("a", 1021) + "\ud7ff" + "zzz"
+	maxRune := strings.Repeat("a", 1020) + "\U0010ffff" + "zzz"
+
+	testCases := []struct {
+		input      []interface{}
+		merge      []interface{}
+		lower      string
+		lowerExact bool
+		upper      string
+		upperExact bool
+	}{
+		{
+			input:      []interface{}{"b", nil, "a"},
+			lower:      "a",
+			lowerExact: true,
+			upper:      "b",
+			upperExact: true,
+		},
+		{
+			input: []interface{}{long},
+			lower: long[:1024],
+			upper: long[:1023] + "b",
+		},
+		{
+			input: []interface{}{carry},
+			lower: carry[:1024],
+			upper: carry[:1020] + "b",
+		},
+		{
+			input:      []interface{}{ones},
+			lower:      ones[:1024],
+			upper:      ones,
+			upperExact: true,
+		},
+		{
+			input: []interface{}{multibyte},
+			lower: multibyte[:1023],
+			upper: multibyte[:1022] + "b",
+		},
+		{
+			input: []interface{}{codePoint},
+			lower: codePoint[:1024],
+			upper: codePoint[:1022] + "\u00c0",
+		},
+		{
+			input: []interface{}{surrogate},
+			lower: surrogate[:1024],
+			upper: surrogate[:1021] + "\ue000",
+		},
+		{
+			input: []interface{}{maxRune},
+			lower: maxRune[:1024],
+			upper: maxRune[:1019] + "b",
+		},
+		{
+			input:      []interface{}{long},
+			merge:      []interface{}{"0", carry},
+			lower:      "0",
+			lowerExact: true,
+			upper:      carry[:1020] + "b",
+		},
+	}
+
+	for _, tc := range testCases {
+		s := NewStringStatistics()
+		for _, v := range tc.input {
+			s.Add(v)
+		}
+		if tc.merge != nil {
+			o := NewStringStatistics()
+			for _, v := range tc.merge {
+				o.Add(v)
+			}
+			s.Merge(o)
+		}
+		ss := s.Statistics().GetStringStatistics()
+		lower, lowerExact, upper, upperExact := StringBounds(ss)
+		if lower != tc.lower || lowerExact != tc.lowerExact {
+			t.Errorf("Test failed, expected lower bound %q (exact %v) got %q (exact %v)", tc.lower, tc.lowerExact, lower, lowerExact)
+		}
+		if upper != tc.upper || upperExact != tc.upperExact {
+			t.Errorf("Test failed, expected upper bound %q (exact %v) got %q (exact %v)", tc.upper, tc.upperExact, upper, upperExact)
+		}
+		if (ss.Minimum != nil) != tc.lowerExact || (ss.LowerBound != nil) == tc.lowerExact {
+			t.Errorf("Test failed, expected only one of minimum %v and lower bound %v to be set", ss.Minimum != nil, ss.LowerBound != nil)
+		}
+		if (ss.Maximum != nil) != tc.upperExact || (ss.UpperBound != nil) == tc.upperExact {
+			t.Errorf("Test failed, expected only one of maximum %v and upper bound %v to be set", ss.Maximum != nil, ss.UpperBound != nil)
+		}
+		if len(lower) > 1024 || upper != ones && len(upper) > 1024 {
+			t.Errorf("Test failed, expected bounds of at most 1024 bytes got %d and %d", len(lower), len(upper))
+		}
+		if s, ok := tc.input[0].(string); ok && utf8.ValidString(s) && (!utf8.ValidString(lower) || !utf8.ValidString(upper)) {
+			t.Errorf("Test failed, expected bounds of valid UTF-8 got %q and %q", lower, upper)
+		}
+	}
+
+	if lower, lowerExact, upper, upperExact := StringBounds(nil); lower != "" || lowerExact || upper != "" || upperExact {
+		t.Errorf("Test failed, expected no bounds for nil statistics")
+	}
+
+}
+
+func TestStatisticsMerge(t *testing.T) {
+	a := NewStringStatistics()
+	a.Add(nil)
+	b := NewStringStatistics()
+	b.Add("foo")
+	b.Add("bar")
+	a.Merge(b)
+	ss := a.Statistics().GetStringStatistics()
+	if ss.GetMinimum() != "bar" || ss.GetMaximum() != "foo" || ss.GetSum() != 6 {
+		t.Errorf("Test failed, got min %v max %v sum %v", ss.GetMinimum(), ss.GetMaximum(), ss.GetSum())
+	}
+	if !a.Statistics().GetHasNull() {
+		t.Errorf("Test failed, expected hasNull to be true")
+	}
+
+	c := NewBucketStatistics()
+	c.Add(true)
+	c.Add(false)
+	d := NewBucketStatistics()
+	d.Add(true)
+	d.Add(nil)
+	c.Merge(d)
+	if counts := c.Statistics().GetBucketStatistics().GetCount(); len(counts) != 1 || counts[0] != 2 {
+		t.Errorf("Test failed, expected a true count of 2 got %v", counts)
+	}
+	if !c.Statistics().GetHasNull() || c.Statistics().GetNumberOfValues() != 3 {
+		t.Errorf("Test failed, got hasNull %v and %v values", c.Statistics().GetHasNull(), c.Statistics().GetNumberOfValues())
+	}
+}
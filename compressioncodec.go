@@ -4,12 +4,54 @@ import (
 	"bytes"
 	"compress/flate"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"io/ioutil"
 
 	"github.com/golang/snappy"
 )
 
+// maxChunkLength is the maximum length of a compression chunk. The length of the
+// chunk, whether compressed or original, is stored in the 23 bits of the chunk
+// header that remain after the original flag.
+const maxChunkLength = 1<<23 - 1
+
+// WalkChunks reads the headers of the compression chunks of the compressed stream
+// r, calling fn with the offset in r of the header of each chunk, the length of
+// the chunk excluding its header and whether the chunk is stored uncompressed.
+// The chunks themselves are skipped without being decompressed. WalkChunks stops
+// at the end of r, returning nil, or at the first error returned by fn. A DecodeError
+// is returned if the final chunk is truncated.
+func WalkChunks(r io.Reader, fn func(compressedOffset int64, chunkLen int, isOriginal bool) error) error {
+	header := make([]byte, 4)
+	var offset int64
+	for {
+		_, err := io.ReadFull(r, header[:3])
+		if err == io.EOF {
+			return nil
+		}
+		if err == io.ErrUnexpectedEOF {
+			return newDecodeError("chunk header at offset %d is truncated", offset)
+		}
+		if err != nil {
+			return err
+		}
+		headerVal := binary.LittleEndian.Uint32(header)
+		chunkLen := int(headerVal / 2)
+		if err := fn(offset, chunkLen, headerVal%2 == 1); err != nil {
+			return err
+		}
+		n, err := io.CopyN(ioutil.Discard, r, int64(chunkLen))
+		if err == io.EOF {
+			return newDecodeError("chunk of length %d at offset %d is truncated after %d bytes", chunkLen, offset, n)
+		}
+		if err != nil {
+			return err
+		}
+		offset += 3 + int64(chunkLen)
+	}
+}
+
 // CompressionCodec is an interface that provides methods for creating
 // an Encoder or Decoder of the CompressionCodec implementation.
 type CompressionCodec interface {
@@ -51,40 +93,77 @@ type CompressionZlibDecoder struct {
 	decoded     io.Reader
 	isOriginal  bool
 	chunkLength int
-	remaining   int64
+	// remaining is the number of bytes of the current original chunk that are
+	// yet to be read.
+	remaining int64
+	// chunkOffset is the offset in the source of the header of the current
+	// chunk and nextOffset that of the following chunk.
+	chunkOffset int64
+	nextOffset  int64
+	// err is the error returned by every Read once a chunk is found to be
+	// truncated, as the source is no longer positioned at a chunk header.
+	err error
 }
 
 func (c *CompressionZlibDecoder) readHeader() (int, error) {
 	header := make([]byte, 4, 4)
-	_, err := c.source.Read(header[:3])
+	c.chunkOffset = c.nextOffset
+	_, err := io.ReadFull(c.source, header[:3])
+	if err == io.ErrUnexpectedEOF {
+		c.err = newDecodeError("zlib chunk header at offset %d is truncated", c.chunkOffset)
+		return 0, c.err
+	}
 	if err != nil {
 		return 0, err
 	}
 	headerVal := binary.LittleEndian.Uint32(header)
 	c.isOriginal = headerVal%2 == 1
 	c.chunkLength = int(headerVal / 2)
+	c.nextOffset = c.chunkOffset + 3 + int64(c.chunkLength)
 	if !c.isOriginal {
 		c.decoded = flate.NewReader(io.LimitReader(c.source, int64(c.chunkLength)))
 	} else {
+		// Original chunks are copied from the source without inflating them.
 		c.decoded = io.LimitReader(c.source, int64(c.chunkLength))
+		c.remaining = int64(c.chunkLength)
 	}
 	return 0, nil
 }
 
 func (c *CompressionZlibDecoder) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
 	if c.decoded == nil {
 		return c.readHeader()
 	}
 	n, err := c.decoded.Read(p)
+	if c.isOriginal {
+		c.remaining -= int64(n)
+	}
 	if err == io.EOF {
+		if c.isOriginal && c.remaining > 0 {
+			c.err = newDecodeError("zlib chunk of length %d at offset %d is truncated after %d bytes", c.chunkLength, c.chunkOffset, int64(c.chunkLength)-c.remaining)
+			return n, c.err
+		}
 		c.decoded = nil
 		return n, nil
 	}
+	if err == io.ErrUnexpectedEOF {
+		// The deflate data ended before the end of the compressed block,
+		// either as the chunk or the source itself is truncated.
+		c.err = newDecodeError("zlib chunk of length %d at offset %d is truncated", c.chunkLength, c.chunkOffset)
+		return n, c.err
+	}
 	return n, err
 }
 
 // CompressionSnappy implements the CompressionCodec for Snappy compression.
-type CompressionSnappy struct{}
+type CompressionSnappy struct {
+	// Allocator provides the buffers of the compressed and decompressed chunks
+	// of a stream, the default Allocator being used if nil.
+	Allocator Allocator
+}
 
 // Encoder implements the CompressionCodec interface. This is currently not implemented.
 func (c CompressionSnappy) Encoder(w io.Writer) io.Writer {
@@ -93,56 +172,109 @@ func (c CompressionSnappy) Encoder(w io.Writer) io.Writer {
 
 // Decoder implements the CompressionCodec interface.
 func (c CompressionSnappy) Decoder(r io.Reader) io.Reader {
-	return &CompressionSnappyDecoder{source: r}
+	return &CompressionSnappyDecoder{source: r, allocator: getAllocator(c.Allocator)}
 }
 
 // CompressionSnappyDecoder implements the decoder for CompressionSnappy.
 type CompressionSnappyDecoder struct {
-	source      io.Reader
-	decoded     io.Reader
+	source  io.Reader
+	decoded io.Reader
+	// allocator provides buf, which holds the decompressed bytes of the
+	// current chunk and is returned to it once they are read.
+	allocator   Allocator
+	buf         []byte
 	isOriginal  bool
 	chunkLength int
-	remaining   int64
+	// remaining is the number of bytes of the current original chunk that are
+	// yet to be read.
+	remaining int64
+	// chunkOffset is the offset in the source of the header of the current
+	// chunk and nextOffset that of the following chunk.
+	chunkOffset int64
+	nextOffset  int64
+	// err is the error returned by every Read once a chunk is found to be
+	// truncated, as the source is no longer positioned at a chunk header.
+	err error
 }
 
 func (c *CompressionSnappyDecoder) readHeader() (int, error) {
 	header := make([]byte, 4, 4)
-	_, err := c.source.Read(header[:3])
+	c.chunkOffset = c.nextOffset
+	_, err := io.ReadFull(c.source, header[:3])
+	if err == io.ErrUnexpectedEOF {
+		c.err = newDecodeError("snappy chunk header at offset %d is truncated", c.chunkOffset)
+		return 0, c.err
+	}
 	if err != nil {
 		return 0, err
 	}
 	headerVal := binary.LittleEndian.Uint32(header)
 	c.isOriginal = headerVal%2 == 1
 	c.chunkLength = int(headerVal / 2)
+	c.nextOffset = c.chunkOffset + 3 + int64(c.chunkLength)
 	if !c.isOriginal {
 		// ORC does not use snappy's framing as implemented in the
 		// github.com/golang/snappy Reader implementation. As a result
 		// we have to read and decompress the entire chunk.
 		// TODO: find reader implementation with optional framing.
-		r := io.LimitReader(c.source, int64(c.chunkLength))
-		src, err := ioutil.ReadAll(r)
+		src := c.allocator.Get(c.chunkLength)
+		defer c.allocator.Put(src)
+		n, err := io.ReadFull(c.source, src)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			c.err = newDecodeError("snappy chunk of length %d at offset %d is truncated after %d bytes", c.chunkLength, c.chunkOffset, n)
+			return 0, c.err
+		}
+		if err != nil {
+			return 0, err
+		}
+		decodedLength, err := snappy.DecodedLen(src)
 		if err != nil {
 			return 0, err
 		}
-		decodedBytes, err := snappy.Decode(nil, src)
+		if decodedLength > maxChunkLength {
+			return 0, fmt.Errorf("snappy chunk decoded length %d exceeds maximum chunk length %d", decodedLength, maxChunkLength)
+		}
+		c.buf = c.allocator.Get(decodedLength)
+		decodedBytes, err := snappy.Decode(c.buf, src)
 		if err != nil {
+			c.release()
 			return 0, err
 		}
 		c.decoded = bytes.NewReader(decodedBytes)
 	} else {
 		c.decoded = io.LimitReader(c.source, int64(c.chunkLength))
+		c.remaining = int64(c.chunkLength)
 	}
 	return 0, nil
 }
 
 func (c *CompressionSnappyDecoder) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
 	if c.decoded == nil {
 		return c.readHeader()
 	}
 	n, err := c.decoded.Read(p)
-	if err == io.EOF || err == snappy.ErrCorrupt {
+	if c.isOriginal {
+		c.remaining -= int64(n)
+	}
+	if err == io.EOF {
+		if c.isOriginal && c.remaining > 0 {
+			c.err = newDecodeError("snappy chunk of length %d at offset %d is truncated after %d bytes", c.chunkLength, c.chunkOffset, int64(c.chunkLength)-c.remaining)
+			return n, c.err
+		}
 		c.decoded = nil
+		c.release()
 		return n, nil
 	}
 	return n, err
 }
+
+// release returns the buffer of the decompressed chunk to the allocator.
+func (c *CompressionSnappyDecoder) release() {
+	if c.buf != nil {
+		c.allocator.Put(c.buf)
+		c.buf = nil
+	}
+}
@@ -0,0 +1,422 @@
+package orc
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/golang/snappy"
+)
+
+// encodeChunks encodes src as a sequence of ORC compression chunks of at most
+// chunkSize bytes using compress. As with the Java writer a chunk is stored as
+// original when compressing it does not reduce its size.
+func encodeChunks(src []byte, chunkSize int, compress func([]byte) []byte) []byte {
+	var out []byte
+	for len(src) > 0 {
+		n := minInt(chunkSize, len(src))
+		chunk := src[:n]
+		src = src[n:]
+		body := compress(chunk)
+		header := uint32(len(body)) << 1
+		if len(body) >= len(chunk) {
+			body = chunk
+			header = uint32(len(chunk))<<1 | 1
+		}
+		out = append(out, byte(header), byte(header>>8), byte(header>>16))
+		out = append(out, body...)
+	}
+	return out
+}
+
+// deflater is reused between chunks as creating a flate.Writer is expensive.
+var deflater, _ = flate.NewWriter(nil, flate.DefaultCompression)
+
+func deflate(src []byte) []byte {
+	var buf bytes.Buffer
+	deflater.Reset(&buf)
+	deflater.Write(src)
+	deflater.Close()
+	return buf.Bytes()
+}
+
+// fuzzCodecs are the codecs covered by the fuzz targets. There are no zstd or
+// lz4 codecs in this package to cover.
+var fuzzCodecs = []struct {
+	name   string
+	codec  CompressionCodec
+	encode func(src []byte, chunkSize int) []byte
+}{
+	{
+		name:  "none",
+		codec: CompressionNone{},
+		encode: func(src []byte, chunkSize int) []byte {
+			var buf bytes.Buffer
+			CompressionNone{}.Encoder(&buf).Write(src)
+			return buf.Bytes()
+		},
+	},
+	{
+		name:  "zlib",
+		codec: CompressionZlib{},
+		encode: func(src []byte, chunkSize int) []byte {
+			return encodeChunks(src, chunkSize, deflate)
+		},
+	},
+	{
+		name:  "snappy",
+		codec: CompressionSnappy{},
+		encode: func(src []byte, chunkSize int) []byte {
+			return encodeChunks(src, chunkSize, func(chunk []byte) []byte {
+				return snappy.Encode(nil, chunk)
+			})
+		},
+	},
+}
+
+func FuzzCompressionRoundTrip(f *testing.F) {
+	f.Add([]byte{}, uint16(1))
+	f.Add([]byte("a"), uint16(1))
+	f.Add(bytes.Repeat([]byte("orc"), 100), uint16(64))
+	f.Add(bytes.Repeat([]byte{0}, 300), uint16(100))
+	f.Add([]byte("incompressible?!"), uint16(7))
+	f.Fuzz(func(t *testing.T, src []byte, chunkSize uint16) {
+		// Limit the number of chunks as compressing each of them is expensive
+		// with fuzzing instrumentation.
+		size := int(chunkSize)
+		if min := len(src)/64 + 1; size < min {
+			size = min
+		}
+		for _, tc := range fuzzCodecs {
+			encoded := tc.encode(src, size)
+			// Read a byte at a time so that short reads, such as those of the
+			// chunk headers, are exercised.
+			decoded, err := ioutil.ReadAll(tc.codec.Decoder(iotest.OneByteReader(bytes.NewReader(encoded))))
+			if err != nil {
+				t.Fatalf("Test failed, %s decoder returned error: %v", tc.name, err)
+			}
+			if !bytes.Equal(decoded, src) {
+				t.Fatalf("Test failed, %s decoder returned %d bytes expected %d", tc.name, len(decoded), len(src))
+			}
+		}
+	})
+}
+
+func FuzzCompressionDecode(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x0b, 0x00, 0x00, 0x68, 0x65, 0x6c, 0x6c, 0x6f})
+	f.Add([]byte{0xff, 0xff, 0xff, 0x00})
+	f.Add([]byte{0x0a, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x00})
+	f.Add(encodeChunks(bytes.Repeat([]byte("orc"), 100), 64, deflate))
+	f.Fuzz(func(t *testing.T, src []byte) {
+		// Only a panic is a failure, arbitrary input may return an error.
+		for _, tc := range fuzzCodecs {
+			ioutil.ReadAll(tc.codec.Decoder(bytes.NewReader(src)))
+		}
+	})
+}
+
+func TestCompressionNone(t *testing.T) {
+
+	// Bytes that would be read as the header of an original chunk of 5 bytes by
+	// the other codecs are passed through as data.
+	src := []byte{0x0b, 0x00, 0x00, 'a', 'b', 'c', 'd', 'e'}
+	var buf bytes.Buffer
+	if _, err := (CompressionNone{}).Encoder(&buf).Write(src); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), src) {
+		t.Errorf("Test failed, expected encoded bytes %v got %v", src, buf.Bytes())
+	}
+	decoded, err := ioutil.ReadAll(CompressionNone{}.Decoder(bytes.NewReader(buf.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded, src) {
+		t.Errorf("Test failed, expected decoded bytes %v got %v", src, decoded)
+	}
+
+}
+
+func TestCompressionZlibTruncated(t *testing.T) {
+
+	src := bytes.Repeat([]byte("orc file "), 200)
+	chunk := deflate(src)
+	compressedHeader := func(length int) []byte {
+		header := uint32(length) << 1
+		return []byte{byte(header), byte(header >> 8), byte(header >> 16)}
+	}
+	valid := append(compressedHeader(len(chunk)), chunk...)
+
+	testCases := []struct {
+		name    string
+		encoded []byte
+	}{
+		{
+			// The source ends part way through the deflate data.
+			name:    "truncated file",
+			encoded: append(append([]byte{}, valid...), valid[:len(valid)/2]...),
+		},
+		{
+			// The header declares a chunk shorter than its deflate data.
+			name:    "truncated chunk",
+			encoded: append(append([]byte{}, valid...), append(compressedHeader(len(chunk)/2), chunk...)...),
+		},
+		{
+			name:    "truncated header",
+			encoded: append(append([]byte{}, valid...), valid[:2]...),
+		},
+		{
+			// The source ends part way through an original chunk.
+			name:    "truncated original",
+			encoded: append(append([]byte{}, valid...), 0x15, 0x00, 0x00, 'h', 'e', 'l', 'l', 'o'),
+		},
+	}
+
+	for _, tc := range testCases {
+		d := CompressionZlib{}.Decoder(bytes.NewReader(tc.encoded))
+		decoded, err := ioutil.ReadAll(d)
+		decodeErr, ok := err.(*DecodeError)
+		if !ok {
+			t.Errorf("Test failed, %s: expected DecodeError got %v", tc.name, err)
+			continue
+		}
+		if !strings.Contains(decodeErr.Error(), fmt.Sprintf("offset %d", len(valid))) {
+			t.Errorf("Test failed, %s: expected error for offset %d got %v", tc.name, len(valid), err)
+		}
+		if !bytes.Equal(decoded[:len(src)], src) {
+			t.Errorf("Test failed, %s: expected the first chunk to be decoded", tc.name)
+		}
+		for i := 0; i < 2; i++ {
+			if n, err := d.Read(make([]byte, 16)); n != 0 || err != decodeErr {
+				t.Errorf("Test failed, %s: expected subsequent Read to return %v got %d, %v", tc.name, decodeErr, n, err)
+			}
+		}
+	}
+
+}
+
+func TestCompressionSnappy(t *testing.T) {
+
+	chunkHeader := func(length int, original bool) []byte {
+		header := uint32(length) << 1
+		if original {
+			header |= 1
+		}
+		return []byte{byte(header), byte(header >> 8), byte(header >> 16)}
+	}
+	chunk := func(body []byte, original bool) []byte {
+		return append(chunkHeader(len(body), original), body...)
+	}
+	// A chunk of more than 64KiB has a length spanning all three header bytes.
+	large := bytes.Repeat([]byte("0123456789abcdef"), 4500)
+	// The literal "abcd" followed by a copy of it at offset 4 is as long as the
+	// 8 bytes it decodes to.
+	minimal := []byte{0x08, 0x0c, 'a', 'b', 'c', 'd', 0x01, 0x04}
+
+	testCases := []struct {
+		name     string
+		encoded  []byte
+		expected []byte
+	}{
+		{
+			name:     "original",
+			encoded:  []byte{0x0b, 0x00, 0x00, 'h', 'e', 'l', 'l', 'o'},
+			expected: []byte("hello"),
+		},
+		{
+			name:     "empty original",
+			encoded:  chunkHeader(0, true),
+			expected: []byte{},
+		},
+		{
+			name:     "minimally compressed",
+			encoded:  chunk(minimal, false),
+			expected: []byte("abcdabcd"),
+		},
+		{
+			name:     "large original",
+			encoded:  chunk(large, true),
+			expected: large,
+		},
+		{
+			name:     "large compressed",
+			encoded:  chunk(snappy.Encode(nil, large), false),
+			expected: large,
+		},
+		{
+			name:     "mixed",
+			encoded:  append(append(chunk([]byte("ab"), true), chunk(minimal, false)...), chunk([]byte("c"), true)...),
+			expected: []byte("ababcdabcdc"),
+		},
+	}
+
+	for _, tc := range testCases {
+		decoded, err := ioutil.ReadAll(CompressionSnappy{}.Decoder(iotest.OneByteReader(bytes.NewReader(tc.encoded))))
+		if err != nil {
+			t.Errorf("Test failed, %s: %v", tc.name, err)
+			continue
+		}
+		if !bytes.Equal(decoded, tc.expected) {
+			t.Errorf("Test failed, %s: expected %d bytes got %d", tc.name, len(tc.expected), len(decoded))
+		}
+	}
+
+	valid := chunk([]byte("hello"), true)
+	truncated := []struct {
+		name    string
+		encoded []byte
+	}{
+		{
+			name:    "truncated original",
+			encoded: append(append([]byte{}, valid...), valid[:5]...),
+		},
+		{
+			name:    "truncated compressed",
+			encoded: append(append([]byte{}, valid...), chunk(minimal, false)[:6]...),
+		},
+		{
+			name:    "truncated header",
+			encoded: append(append([]byte{}, valid...), valid[:2]...),
+		},
+	}
+
+	for _, tc := range truncated {
+		d := CompressionSnappy{}.Decoder(bytes.NewReader(tc.encoded))
+		decoded, err := ioutil.ReadAll(d)
+		decodeErr, ok := err.(*DecodeError)
+		if !ok {
+			t.Errorf("Test failed, %s: expected DecodeError got %v", tc.name, err)
+			continue
+		}
+		if !strings.Contains(decodeErr.Error(), fmt.Sprintf("offset %d", len(valid))) {
+			t.Errorf("Test failed, %s: expected error for offset %d got %v", tc.name, len(valid), err)
+		}
+		if !bytes.HasPrefix(decoded, []byte("hello")) {
+			t.Errorf("Test failed, %s: expected the first chunk to be decoded", tc.name)
+		}
+		if n, err := d.Read(make([]byte, 16)); n != 0 || err != decodeErr {
+			t.Errorf("Test failed, %s: expected subsequent Read to return %v got %d, %v", tc.name, decodeErr, n, err)
+		}
+	}
+
+}
+
+func TestCompressionOriginalChunks(t *testing.T) {
+
+	// Five original chunks of random bytes, as written for data that does not
+	// compress, totalling more than the default compression block size.
+	src := make([]byte, 5*60000)
+	rand.New(rand.NewSource(1)).Read(src)
+	encoded := encodeChunks(src, 60000, func(chunk []byte) []byte {
+		return chunk
+	})
+	if len(src) <= int(DefaultCompressionChunkSize) {
+		t.Fatalf("Test failed, expected more than %d bytes", DefaultCompressionChunkSize)
+	}
+
+	for _, codec := range []CompressionCodec{CompressionZlib{}, CompressionSnappy{}} {
+		var chunks int
+		err := WalkChunks(bytes.NewReader(encoded), func(compressedOffset int64, chunkLen int, isOriginal bool) error {
+			if !isOriginal {
+				t.Errorf("Test failed, expected chunk at offset %d to be original", compressedOffset)
+			}
+			chunks++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunks != 5 {
+			t.Errorf("Test failed, expected 5 chunks got %d", chunks)
+		}
+		for _, r := range []io.Reader{bytes.NewReader(encoded), iotest.OneByteReader(bytes.NewReader(encoded))} {
+			decoded, err := ioutil.ReadAll(codec.Decoder(r))
+			if err != nil {
+				t.Errorf("Test failed, %T: %v", codec, err)
+				continue
+			}
+			if !bytes.Equal(decoded, src) {
+				t.Errorf("Test failed, %T: expected %d bytes got %d", codec, len(src), len(decoded))
+			}
+		}
+	}
+
+}
+
+func TestWalkChunks(t *testing.T) {
+
+	// Three compressed chunks followed by one of random bytes stored as original.
+	random := make([]byte, 100)
+	rand.New(rand.NewSource(1)).Read(random)
+	src := append(bytes.Repeat([]byte("orc"), 300), random...)
+	encoded := append(encodeChunks(src[:900], 300, deflate), encodeChunks(random, 300, deflate)...)
+
+	type chunk struct {
+		offset     int64
+		length     int
+		isOriginal bool
+	}
+	var chunks []chunk
+	err := WalkChunks(bytes.NewReader(encoded), func(offset int64, length int, isOriginal bool) error {
+		chunks = append(chunks, chunk{offset, length, isOriginal})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("Test failed, expected 4 chunks got %d", len(chunks))
+	}
+	var offset int64
+	var decoded []byte
+	for i, c := range chunks {
+		if c.offset != offset {
+			t.Errorf("Test failed, expected chunk %d at offset %d got %d", i, offset, c.offset)
+		}
+		// Decoding the chunk alone from its offset yields the next bytes of src.
+		body, err := ioutil.ReadAll(CompressionZlib{}.Decoder(bytes.NewReader(encoded[c.offset : c.offset+3+int64(c.length)])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, body...)
+		offset += 3 + int64(c.length)
+	}
+	if offset != int64(len(encoded)) {
+		t.Errorf("Test failed, expected chunks to span %d bytes got %d", len(encoded), offset)
+	}
+	if !bytes.Equal(decoded, src) {
+		t.Errorf("Test failed, expected chunks to decode to the source")
+	}
+	for i, c := range chunks {
+		if c.isOriginal != (i == 3) {
+			t.Errorf("Test failed, expected only the last chunk to be original got %v", chunks)
+			break
+		}
+	}
+
+	// The walk stops at the first error returned by fn.
+	stop := fmt.Errorf("stop")
+	var calls int
+	err = WalkChunks(bytes.NewReader(encoded), func(int64, int, bool) error {
+		calls++
+		if calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || calls != 2 {
+		t.Errorf("Test failed, expected to stop after 2 chunks with %v got %d chunks and %v", stop, calls, err)
+	}
+
+	if err := WalkChunks(bytes.NewReader(encoded[:len(encoded)-1]), func(int64, int, bool) error { return nil }); err == nil {
+		t.Errorf("Test failed, expected error for a truncated chunk")
+	}
+
+}
@@ -0,0 +1,222 @@
+package orc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvTimestampLayouts are the layouts in which timestamps are parsed from CSV
+// fields. Timestamps without a UTC offset are in the timezone of the Writer.
+var csvTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+}
+
+// csvDateLayout is the layout in which dates are parsed from CSV fields by default.
+const csvDateLayout = "2006-01-02"
+
+// CSVOptions configures the parsing of CSV records by WriteORCFromCSVWithOptions.
+type CSVOptions struct {
+	// DateLayout is the layout of the time package in which dates are parsed.
+	// By default dates are parsed as "2006-01-02".
+	DateLayout string
+	// EmptyStringsAsNull parses empty fields of string, varchar and char columns
+	// as null values, as are empty fields of other columns. By default they are
+	// empty strings.
+	EmptyStringsAsNull bool
+	// SkipMalformed skips records that cannot be parsed, either as CSV or per
+	// the schema, in place of returning their error. Errors writing a parsed
+	// record are returned regardless.
+	SkipMalformed bool
+	// OnMalformed, if set, is called with the error of each record skipped, a
+	// *CSVError or a *csv.ParseError, both of which report the line of the
+	// record.
+	OnMalformed func(err error)
+}
+
+// CSVError is the error of a CSV record that cannot be parsed per the schema.
+type CSVError struct {
+	// Line is the line at which the record, or the field in error, starts.
+	Line int
+	// Column is the column, counted in runes from 1, at which the field in error
+	// starts, or 0 if the error is of the record as a whole.
+	Column int
+	// Field is the name of the column of the field in error, if any.
+	Field string
+	Err   error
+}
+
+func (e *CSVError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %v", e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// CSVHeaderSchema returns a schema of string columns named by the fields of a CSV
+// header record, for converting CSV files whose types are unknown.
+func CSVHeaderSchema(header []string) (*TypeDescription, error) {
+	fns := []TypeDescriptionTransformFunc{SetCategory(CategoryStruct)}
+	names := make(map[string]bool)
+	for i, name := range header {
+		if name == "" {
+			return nil, fmt.Errorf("empty name of column %d of the CSV header", i+1)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate column %s of the CSV header", name)
+		}
+		names[name] = true
+		fns = append(fns, AddField(name, SetCategory(CategoryString)))
+	}
+	return NewTypeDescription(fns...)
+}
+
+// WriteORCFromCSV reads every record of csvr and writes it as a row of an ORC
+// file to w, configured by opts. The schema must be a struct with a field for each
+// column of the CSV records, in order, of a primitive type other than binary.
+// Each field is parsed per the type of its column: integers, floating point
+// values and booleans in the forms accepted by the strconv package, decimals
+// such as "-12345.678", dates as "2006-01-02" and timestamps in RFC 3339 or as
+// "2006-01-02 15:04:05.999999999". An empty field is a null value except in
+// string, varchar and char columns. Any header record must be read from csvr
+// before calling WriteORCFromCSV. Errors parsing a field report its line and
+// column.
+func WriteORCFromCSV(w io.Writer, csvr *csv.Reader, schema *TypeDescription, opts ...WriterConfigFunc) error {
+	return WriteORCFromCSVWithOptions(w, csvr, schema, CSVOptions{}, opts...)
+}
+
+// WriteORCFromCSVWithOptions is equivalent to WriteORCFromCSV, parsing the records
+// as configured by csvOpts.
+func WriteORCFromCSVWithOptions(w io.Writer, csvr *csv.Reader, schema *TypeDescription, csvOpts CSVOptions, opts ...WriterConfigFunc) error {
+	if schema.getCategory() != CategoryStruct {
+		return fmt.Errorf("cannot write CSV records to a schema of type %s", schema.getCategory())
+	}
+	for i, child := range schema.children {
+		switch child.getCategory() {
+		case CategoryBinary, CategoryList, CategoryMap, CategoryStruct, CategoryUnion:
+			return fmt.Errorf("cannot parse column %s of type %s from CSV", schema.fieldNames[i], child.getCategory())
+		}
+	}
+	if csvOpts.DateLayout == "" {
+		csvOpts.DateLayout = csvDateLayout
+	}
+	writer, err := NewWriter(w, append([]WriterConfigFunc{SetSchema(schema)}, opts...)...)
+	if err != nil {
+		return err
+	}
+	// malformed returns the error of a malformed record, or nil if the record is
+	// skipped.
+	malformed := func(err error) error {
+		if !csvOpts.SkipMalformed {
+			return err
+		}
+		if csvOpts.OnMalformed != nil {
+			csvOpts.OnMalformed(err)
+		}
+		return nil
+	}
+	row := make([]interface{}, len(schema.children))
+records:
+	for {
+		record, err := csvr.Read()
+		if err == io.EOF {
+			break
+		}
+		if _, ok := err.(*csv.ParseError); ok {
+			if err := malformed(err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := csvr.FieldPos(0)
+		if len(record) != len(schema.children) {
+			err := fmt.Errorf("wrong number of fields, expected: %v, got: %v", len(schema.children), len(record))
+			if err := malformed(&CSVError{Line: line, Err: err}); err != nil {
+				return err
+			}
+			continue
+		}
+		for i, field := range record {
+			row[i], err = writer.parseCSVField(schema.children[i], field, csvOpts)
+			if err != nil {
+				fieldLine, column := csvr.FieldPos(i)
+				if err := malformed(&CSVError{Line: fieldLine, Column: column, Field: schema.fieldNames[i], Err: err}); err != nil {
+					return err
+				}
+				continue records
+			}
+		}
+		if err := writer.Write(row...); err != nil {
+			return &CSVError{Line: line, Err: err}
+		}
+	}
+	return writer.Close()
+}
+
+// parseCSVField returns the value of a CSV field for writing to a column of the
+// provided type.
+func (w *Writer) parseCSVField(td *TypeDescription, field string, opts CSVOptions) (interface{}, error) {
+	category := td.getCategory()
+	switch category {
+	case CategoryString, CategoryVarchar, CategoryChar:
+		if field == "" && opts.EmptyStringsAsNull {
+			return nil, nil
+		}
+		return field, nil
+	}
+	if field == "" {
+		return nil, nil
+	}
+	switch category {
+	case CategoryBoolean:
+		return strconv.ParseBool(field)
+	case CategoryByte:
+		i, err := strconv.ParseInt(field, 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		return int8(i), nil
+	case CategoryShort:
+		return strconv.ParseInt(field, 10, 16)
+	case CategoryInt:
+		return strconv.ParseInt(field, 10, 32)
+	case CategoryLong:
+		return strconv.ParseInt(field, 10, 64)
+	case CategoryFloat:
+		f, err := strconv.ParseFloat(field, 32)
+		if err != nil {
+			return nil, err
+		}
+		return float32(f), nil
+	case CategoryDouble:
+		return strconv.ParseFloat(field, 64)
+	case CategoryDecimal:
+		return toDecimal(field, td.precision, td.scale, w.decimalRounding)
+	case CategoryDate:
+		t, err := time.Parse(opts.DateLayout, field)
+		if err != nil {
+			return nil, err
+		}
+		return Date{t}, nil
+	case CategoryTimestamp, CategoryTimestampInstant:
+		var err error
+		for _, layout := range csvTimestampLayouts {
+			var t time.Time
+			if t, err = time.ParseInLocation(layout, field, w.timezone); err == nil {
+				return t, nil
+			}
+		}
+		return nil, err
+	}
+	return nil, fmt.Errorf("cannot parse %s value from CSV", category)
+}
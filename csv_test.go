@@ -0,0 +1,310 @@
+package orc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteORCFromCSV(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:int,tiny:tinyint,flag:boolean,price:decimal(10,2),ratio:double,score:float,name:string,day:date,created:timestamp>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := `id,tiny,flag,price,ratio,score,name,day,created
+1,-128,true,12.5,0.25,1.5,plain,2020-02-29,2020-02-29T12:30:45.123456789Z
+2,,false,-0.01,-1e10,,"quoted, with a comma",1969-12-31,2001-02-03 04:05:06
+3,127,,,,-2.25,"two
+lines",,
+4,0,true,99999999.99,NaN,3,,1900-01-01,1969-12-31T23:59:59.5+01:00
+`
+	expected := [][]interface{}{
+		{int64(1), int8(-128), true, "12.50", Double(0.25), Float(1.5), "plain", "2020-02-29", time.Date(2020, 2, 29, 12, 30, 45, 123456789, time.UTC)},
+		{int64(2), nil, false, "-0.01", Double(-1e10), nil, "quoted, with a comma", "1969-12-31", time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{int64(3), int8(127), nil, nil, nil, Float(-2.25), "two\nlines", nil, nil},
+		{int64(4), int8(0), true, "99999999.99", nil, Float(3), "", "1900-01-01", time.Date(1969, 12, 31, 22, 59, 59, 500000000, time.UTC)},
+	}
+
+	csvr := csv.NewReader(strings.NewReader(input))
+	if _, err := csvr.Read(); err != nil {
+		t.Fatal(err)
+	}
+	// Timestamps without a UTC offset are in the writer timezone.
+	var buf bytes.Buffer
+	if err := WriteORCFromCSV(&buf, csvr, schema, WithWriterTimezone(time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := r.Select(schema.fieldNames...)
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			if i >= len(expected) {
+				t.Fatalf("Test failed, expected %d rows", len(expected))
+			}
+			for j, value := range c.Row() {
+				switch v := value.(type) {
+				case Decimal:
+					value = v.String()
+				case Date:
+					value = v.Format("2006-01-02")
+				case time.Time:
+					value = v.UTC()
+				case Double:
+					// NaN is not equal to itself.
+					if v != v {
+						value = nil
+					}
+				}
+				if !reflect.DeepEqual(value, expected[i][j]) {
+					t.Errorf("Test failed, row %d column %s expected %v (%T) got %v (%T)", i, schema.fieldNames[j], expected[i][j], expected[i][j], value, value)
+				}
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+
+}
+
+func TestWriteORCFromCSVErrors(t *testing.T) {
+
+	testCases := []struct {
+		schema   string
+		input    string
+		expected string
+	}{
+		{
+			schema:   "struct<a:int,b:int>",
+			input:    "1,2\n3,x\n",
+			expected: "line 2, column 3: b: ",
+		},
+		{
+			schema:   "struct<a:string,b:tinyint>",
+			input:    "\"multi\nline\",1\nfoo,128\n",
+			expected: "line 3, column 5: b: ",
+		},
+		{
+			schema:   "struct<a:decimal(4,2)>",
+			input:    "1.5\n123.45\n",
+			expected: "line 2, column 1: a: decimal value 123.45 exceeds precision 4",
+		},
+		{
+			schema:   "struct<a:date>",
+			input:    "2020-13-01\n",
+			expected: "line 1, column 1: a: ",
+		},
+		{
+			schema:   "struct<a:timestamp>",
+			input:    "yesterday\n",
+			expected: "line 1, column 1: a: ",
+		},
+		{
+			schema:   "struct<a:varchar(3)>",
+			input:    "abc\nabcd\n",
+			expected: "line 2: ",
+		},
+		{
+			schema:   "struct<a:binary>",
+			expected: "cannot parse column a of type binary from CSV",
+		},
+		{
+			schema:   "int",
+			expected: "cannot write CSV records to a schema of type int",
+		},
+	}
+
+	for _, tc := range testCases {
+		schema, err := ParseSchema(tc.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		csvr := csv.NewReader(strings.NewReader(tc.input))
+		// Let the number of fields be checked against the schema.
+		csvr.FieldsPerRecord = -1
+		var buf bytes.Buffer
+		err = WriteORCFromCSV(&buf, csvr, schema)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+			t.Errorf("Test failed, expected error starting %q got %v", tc.expected, err)
+		}
+	}
+
+	schema, err := ParseSchema("struct<a:int,b:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvr := csv.NewReader(strings.NewReader("1,2\n3\n"))
+	csvr.FieldsPerRecord = -1
+	var buf bytes.Buffer
+	if err := WriteORCFromCSV(&buf, csvr, schema); err == nil || err.Error() != "line 2: wrong number of fields, expected: 2, got: 1" {
+		t.Errorf("Test failed, got %v", err)
+	}
+
+}
+
+func TestWriteORCFromCSVWithOptions(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:int,name:string,day:date>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := `1,a,02/29/2020
+2,,12/31/1969
+x,b,01/01/2000
+4,"c
+d",2000-01-01
+5,e
+6,f"g,01/01/2000
+7,,
+`
+
+	testCases := []struct {
+		opts     CSVOptions
+		expected [][]interface{}
+		errors   []string
+	}{
+		{
+			opts: CSVOptions{DateLayout: "01/02/2006", SkipMalformed: true},
+			expected: [][]interface{}{
+				{int64(1), "a", "2020-02-29"},
+				{int64(2), "", "1969-12-31"},
+				{int64(7), "", nil},
+			},
+			errors: []string{
+				`line 3, column 1: id: strconv.ParseInt: parsing "x": invalid syntax`,
+				`line 5, column 4: day: parsing time "2000-01-01": month out of range`,
+				"line 6: wrong number of fields, expected: 3, got: 2",
+				`parse error on line 7, column 4: bare " in non-quoted-field`,
+			},
+		},
+		{
+			opts: CSVOptions{DateLayout: "01/02/2006", SkipMalformed: true, EmptyStringsAsNull: true},
+			expected: [][]interface{}{
+				{int64(1), "a", "2020-02-29"},
+				{int64(2), nil, "1969-12-31"},
+				{int64(7), nil, nil},
+			},
+			errors: []string{
+				`line 3, column 1: id: strconv.ParseInt: parsing "x": invalid syntax`,
+				`line 5, column 4: day: parsing time "2000-01-01": month out of range`,
+				"line 6: wrong number of fields, expected: 3, got: 2",
+				`parse error on line 7, column 4: bare " in non-quoted-field`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		csvr := csv.NewReader(strings.NewReader(input))
+		csvr.FieldsPerRecord = -1
+		var errors []string
+		tc.opts.OnMalformed = func(err error) {
+			errors = append(errors, err.Error())
+		}
+		var buf bytes.Buffer
+		if err := WriteORCFromCSVWithOptions(&buf, csvr, schema, tc.opts); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(errors, tc.errors) {
+			t.Errorf("Test failed, expected errors %q got %q", tc.errors, errors)
+		}
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range actual {
+			if date, ok := row[2].(Date); ok {
+				row[2] = date.Format("2006-01-02")
+			}
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("Test failed, expected rows %v got %v", tc.expected, actual)
+		}
+	}
+
+	// Without SkipMalformed the first malformed record is returned as a CSVError.
+	csvr := csv.NewReader(strings.NewReader(input))
+	var buf bytes.Buffer
+	err = WriteORCFromCSVWithOptions(&buf, csvr, schema, CSVOptions{DateLayout: "01/02/2006"})
+	var csvErr *CSVError
+	if !errors.As(err, &csvErr) || csvErr.Line != 3 || csvErr.Column != 1 || csvErr.Field != "id" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Test failed, expected a CSVError of line 3 got %#v", err)
+	}
+
+}
+
+func TestCSVHeaderSchema(t *testing.T) {
+
+	testCases := []struct {
+		header   []string
+		expected string
+		err      string
+	}{
+		{header: []string{"id", "first name", "b"}, expected: "struct<id:string,`first name`:string,b:string>"},
+		{header: []string{"a", ""}, err: "empty name of column 2 of the CSV header"},
+		{header: []string{"a", "b", "a"}, err: "duplicate column a of the CSV header"},
+	}
+
+	for _, tc := range testCases {
+		schema, err := CSVHeaderSchema(tc.header)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Test failed, expected error %q got %v", tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := schema.String(); actual != tc.expected {
+			t.Errorf("Test failed, expected schema %s got %s", tc.expected, actual)
+		}
+	}
+
+	// Records are written to the schema of their header.
+	csvr := csv.NewReader(strings.NewReader("id,name\n1,a\n2,\n"))
+	header, err := csvr.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := CSVHeaderSchema(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteORCFromCSV(&buf, csvr, schema); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]interface{}{{"1", "a"}, {"2", ""}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+}
@@ -3,6 +3,8 @@ package orc
 import (
 	"fmt"
 	"io"
+
+	"code.simon-critchley.co.uk/orc/proto"
 )
 
 // Cursor is used for iterating through the stripes and
@@ -10,11 +12,29 @@ import (
 type Cursor struct {
 	*Reader
 	streams  streamMap
+	fields   []string
 	columns  []*TypeDescription
 	included []int
 	readers  []TreeReader
 	nextVal  []interface{}
 	err      error
+	// stripeRows is the number of rows in the current stripe and
+	// stripeRow is the number of those rows that have been read.
+	stripeRows uint64
+	stripeRow  uint64
+	// stripeStart is the row number within the file of the first row of the
+	// current stripe and rowNumber that of the row last read by Next.
+	stripeStart int64
+	rowNumber   int64
+	// rows is the number of rows read by the Cursor, counted against the limit
+	// of the Reader.
+	rows int64
+	// root reads the present stream of the root struct column, a null value
+	// of which denotes a null row, unless the root column itself is selected.
+	root BaseTreeReader
+	// acidReaders read the ACID metadata columns of unwrapped ACID tables.
+	acidReaders []TreeReader
+	acidEvent   AcidEvent
 }
 
 // Select determines the columns that will be read from the ORC file.
@@ -32,23 +52,65 @@ func (c *Cursor) Select(fields ...string) *Cursor {
 		included = append(included, column.getID())
 		included = append(included, column.getChildrenIDs()...)
 	}
+	if !c.selectsRoot(columns) {
+		included = append(included, c.Reader.schema.getID())
+	}
+	// The ACID metadata columns are always read from unwrapped ACID tables.
+	if c.Reader.acidSchema != nil {
+		for _, column := range c.Reader.acidSchema.children[:len(acidFieldNames)-1] {
+			included = append(included, column.getID())
+		}
+	}
+	c.fields = fields
 	c.columns = columns
 	c.included = included
 	return c
 }
 
+// Columns returns the names of the columns selected by Select, as passed to it,
+// along with their types.
+func (c *Cursor) Columns() ([]string, []*TypeDescription) {
+	return c.fields, c.columns
+}
+
+// selectsRoot returns whether the root column of the schema is one of columns,
+// in which case null rows are read as a null value of the root column.
+func (c *Cursor) selectsRoot(columns []*TypeDescription) bool {
+	for _, column := range columns {
+		if column == c.Reader.schema {
+			return true
+		}
+	}
+	return false
+}
+
 // prepareStreamReaders prepares TreeReaders for each of the columns
 // that will be read.
 func (c *Cursor) prepareStreamReaders() error {
+	ctx := c.Reader.newReadContext(c.streams)
 	var readers []TreeReader
 	for _, column := range c.columns {
-		reader, err := createTreeReader(column, c.streams, c.Reader)
+		reader, err := createTreeReader(column, ctx)
 		if err != nil {
 			return err
 		}
 		readers = append(readers, reader)
 	}
 	c.readers = readers
+	c.root = BaseTreeReader{}
+	if !c.selectsRoot(c.columns) {
+		c.root = NewBaseTreeReader(c.streams.get(streamName{c.Reader.schema.getID(), proto.Stream_PRESENT}))
+	}
+	c.acidReaders = nil
+	if c.Reader.acidSchema != nil {
+		for _, column := range c.Reader.acidSchema.children[:len(acidFieldNames)-1] {
+			reader, err := createTreeReader(column, ctx)
+			if err != nil {
+				return err
+			}
+			c.acidReaders = append(c.acidReaders, reader)
+		}
+	}
 	return nil
 }
 
@@ -62,6 +124,17 @@ func (c *Cursor) prepareNextStripe() error {
 	if err != nil {
 		return err
 	}
+	stripes, err := c.Reader.getStripes()
+	if err != nil {
+		return err
+	}
+	// Count the rows of the previous stripes, including any not read.
+	c.stripeStart = 0
+	for _, stripe := range stripes[:c.Reader.currentStripeOffset-1] {
+		c.stripeStart += int64(stripe.GetNumberOfRows())
+	}
+	c.stripeRows = stripes[c.Reader.currentStripeOffset-1].GetNumberOfRows()
+	c.stripeRow = 0
 	return c.prepareStreamReaders()
 }
 
@@ -69,49 +142,209 @@ func (c *Cursor) prepareNextStripe() error {
 func (c *Cursor) Next() bool {
 	// If readers have values available return true.
 	if c.next() {
+		c.rowNumber = c.stripeStart + int64(c.stripeRow) - 1
 		c.row()
-		return true
+		return c.err == nil
 	}
 	return false
 }
 
+// Skip advances the Cursor past up to n rows of the current stripe without reading
+// their values, the values of list and map columns being skipped by the lengths
+// of the lists and maps rather than decoded. It returns the number of rows skipped,
+// which is less than n once the end of the stripe or the limit of the Reader is
+// reached, or if an error occurs, which is returned by Err.
+func (c *Cursor) Skip(n int) int {
+	if err := c.Err(); err != nil && err != io.EOF {
+		return 0
+	}
+	if n <= 0 || len(c.readers) == 0 {
+		return 0
+	}
+	rows := int64(n)
+	if remaining := int64(c.stripeRows - c.stripeRow); rows > remaining {
+		rows = remaining
+	}
+	if c.Reader.limited && rows > c.Reader.limit-c.rows {
+		rows = c.Reader.limit - c.rows
+	}
+	if rows <= 0 {
+		return 0
+	}
+	// The columns of a null row have no values.
+	present, err := c.root.countPresent(int(rows))
+	if err != nil {
+		c.err = err
+		return 0
+	}
+	for _, reader := range c.readers {
+		if err := skipValues(reader, present); err != nil {
+			c.err = err
+			return 0
+		}
+	}
+	for _, reader := range c.acidReaders {
+		if err := skipValues(reader, int(rows)); err != nil {
+			c.err = err
+			return 0
+		}
+	}
+	c.nextVal = nil
+	c.stripeRow += uint64(rows)
+	c.rows += rows
+	return int(rows)
+}
+
 // next returns true if all readers return that another row is available.
 func (c *Cursor) next() bool {
 	// If there are no readers then return false.
 	if len(c.readers) == 0 {
 		return false
 	}
+	// Streams such as boolean bit fields may be padded beyond the
+	// final row, therefore, stop once all rows in the stripe are read.
+	if c.stripeRow >= c.stripeRows {
+		return false
+	}
+	if c.limitReached() {
+		return false
+	}
+	// The columns of a null row have no values.
+	if !c.root.Next() {
+		return false
+	}
 	// Check all readers have values available. Assumes all readers
 	// will always have the same number of values per stripe.
 	for _, reader := range c.readers {
+		if c.root.IsPresent() && !reader.Next() {
+			return false
+		}
+	}
+	for _, reader := range c.acidReaders {
 		if !reader.Next() {
 			return false
 		}
 	}
+	c.stripeRow++
+	c.rows++
 	return true
 }
 
+// limitReached returns whether the Cursor has read the maximum number of rows set
+// by Reader.Limit.
+func (c *Cursor) limitReached() bool {
+	return c.Reader.limited && c.rows >= c.Reader.limit
+}
+
 // row preallocates the next row of values and stores in nextVal.
 func (c *Cursor) row() {
 	c.nextVal = make([]interface{}, len(c.readers), len(c.readers))
+	// The ACID metadata is read even for null rows, such as those of deletes.
+	if len(c.acidReaders) > 0 {
+		values := make([]interface{}, len(c.acidReaders))
+		for i, reader := range c.acidReaders {
+			values[i] = reader.Value()
+		}
+		event, err := newAcidEvent(values)
+		if err != nil {
+			c.err = err
+		}
+		c.acidEvent = event
+	}
+	if !c.root.IsPresent() {
+		return
+	}
 	for i, reader := range c.readers {
 		c.nextVal[i] = reader.Value()
 	}
 }
 
-// Row returns the next row of values.
+// AcidEvent returns the ACID metadata of the current row when reading a Hive ACID
+// table using WithAcidUnwrap.
+func (c *Cursor) AcidEvent() AcidEvent {
+	return c.acidEvent
+}
+
+// RowNumber returns the number of the row last read by Next within the file,
+// counting from 0, or -1 if no row has been read. The rows of stripes excluded by
+// WithStripeFilter and rows passed over by Skip are counted.
+func (c *Cursor) RowNumber() int64 {
+	return c.rowNumber
+}
+
+// Row returns the next row of values. Every value of a null row is nil.
 func (c *Cursor) Row() []interface{} {
 	return c.nextVal
 }
 
-// Scan assigns the values returned by the readers to the destination slice.
+// ValueAt returns the value of the current row at the provided path of ordinals and
+// whether it is null. The first ordinal indexes the selected columns and each
+// subsequent one a child of the previous value: the field of a struct in schema
+// order, the element of a list, the entry of a map followed by 0 for its key or 1
+// for its value, or the variant of a union, which is null unless the union holds
+// that variant. A path that does not address a value of the current row, such as
+// an index beyond the length of a list, returns a null value.
+func (c *Cursor) ValueAt(path ...int) (interface{}, bool) {
+	if len(path) == 0 || path[0] < 0 || path[0] >= len(c.nextVal) || path[0] >= len(c.columns) {
+		return nil, true
+	}
+	value, td := c.nextVal[path[0]], c.columns[path[0]]
+	var entry *MapEntry
+	for _, i := range path[1:] {
+		if value == nil {
+			return nil, true
+		}
+		if entry != nil {
+			// Select the key or value of the map entry.
+			switch i {
+			case 0:
+				value, td = entry.Key, td.children[0]
+			case 1:
+				value, td = entry.Value, td.children[1]
+			default:
+				return nil, true
+			}
+			entry = nil
+			continue
+		}
+		if i < 0 {
+			return nil, true
+		}
+		switch t := value.(type) {
+		case Struct:
+			if td.getCategory() != CategoryStruct || i >= len(td.children) {
+				return nil, true
+			}
+			value, td = t[td.fieldNames[i]], td.children[i]
+		case []interface{}:
+			if i >= len(t) {
+				return nil, true
+			}
+			value, td = t[i], td.children[0]
+		case []MapEntry:
+			if i >= len(t) {
+				return nil, true
+			}
+			entry = &t[i]
+			value = *entry
+		case UnionValue:
+			if t.Tag != i || i >= len(td.children) {
+				return nil, true
+			}
+			value, td = t.Value, td.children[i]
+		default:
+			return nil, true
+		}
+	}
+	return value, value == nil
+}
+
+// Scan assigns the values of the current row to the destination slice.
 func (c *Cursor) Scan(dest ...interface{}) error {
 	if len(dest) != len(c.readers) {
 		return fmt.Errorf("expected destination slice of length %v got %v", len(c.readers), len(dest))
 	}
-	for i, reader := range c.readers {
-		dest[i] = reader.Value()
-	}
+	copy(dest, c.nextVal)
 	return nil
 }
 
@@ -125,17 +358,34 @@ func (c *Cursor) Err() error {
 		return c.err
 	}
 	// Otherwise, return the first error returned by the readers.
+	if err := c.root.Err(); err != nil {
+		return err
+	}
 	for _, reader := range c.readers {
 		if err := reader.Err(); err != nil {
 			return err
 		}
 	}
+	for _, reader := range c.acidReaders {
+		if err := reader.Err(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // Stripes prepares the next stripe for reading, returning true once its ready. It
 // returns false if an error occurs whilst preparing the stripe.
 func (c *Cursor) Stripes() bool {
+	// Stop once an error has occurred whilst reading the previous stripe. The
+	// readers of a stripe may report io.EOF once their streams are exhausted.
+	if err := c.Err(); err != nil && err != io.EOF {
+		return false
+	}
+	// Stop without reading the next stripe once the limit is reached.
+	if c.limitReached() {
+		return false
+	}
 	// Prepare the next stripe for reading.
 	err := c.prepareNextStripe()
 	if err != nil {
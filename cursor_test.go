@@ -1,6 +1,10 @@
 package orc
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
 	"testing"
 
 	"code.simon-critchley.co.uk/orc/proto"
@@ -49,3 +53,430 @@ func TestCursor(t *testing.T) {
 	}
 
 }
+
+func TestCursorNullRows(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every fourth row is null as a whole, which is written directly to the
+	// root StructTreeWriter as Write only accepts the values of a row.
+	var expected [][]interface{}
+	for i := 0; i < 100; i++ {
+		if i%4 == 1 {
+			w.stripeRows++
+			w.totalRows++
+			if err := w.treeWriter.Write(nil); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, nil)
+			continue
+		}
+		row := []interface{}{int64(i), fmt.Sprintf("row %d", i)}
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, row)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		fields []string
+		row    func(expected []interface{}) []interface{}
+	}{
+		{
+			fields: []string{"int1", "string1"},
+			row: func(expected []interface{}) []interface{} {
+				if expected == nil {
+					return []interface{}{nil, nil}
+				}
+				return expected
+			},
+		},
+		{
+			fields: []string{"string1"},
+			row: func(expected []interface{}) []interface{} {
+				if expected == nil {
+					return []interface{}{nil}
+				}
+				return expected[1:]
+			},
+		},
+		{
+			// Selecting the root column reads null rows as a null struct.
+			fields: []string{"*"},
+			row: func(expected []interface{}) []interface{} {
+				if expected == nil {
+					return []interface{}{nil}
+				}
+				return []interface{}{Struct{"int1": expected[0], "string1": expected[1]}}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		r.currentStripeOffset = 0
+		c := r.Select(tc.fields...)
+		var i int
+		for c.Stripes() {
+			for c.Next() {
+				if row := c.Row(); !reflect.DeepEqual(row, tc.row(expected[i])) {
+					t.Errorf("Test failed, selecting %v expected %v at row %d got %v", tc.fields, tc.row(expected[i]), i, row)
+				}
+				dest := make([]interface{}, len(tc.fields))
+				if err := c.Scan(dest...); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(dest, tc.row(expected[i])) {
+					t.Errorf("Test failed, selecting %v expected %v from Scan at row %d got %v", tc.fields, tc.row(expected[i]), i, dest)
+				}
+				i++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if i != len(expected) {
+			t.Errorf("Test failed, selecting %v expected %d rows got %d", tc.fields, len(expected), i)
+		}
+	}
+
+}
+
+func TestCursorValueAt(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:int,b:array<struct<c:string>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{1, []interface{}{[]interface{}{"x"}, []interface{}{nil}}},
+		{nil, nil},
+		{3, []interface{}{}},
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	type lookup struct {
+		path  []int
+		value interface{}
+		null  bool
+	}
+	testCases := []struct {
+		fields  []string
+		lookups [][]lookup
+	}{
+		{
+			fields: []string{"a", "b"},
+			lookups: [][]lookup{
+				{
+					{[]int{0}, int64(1), false},
+					{[]int{1, 0, 0}, "x", false},
+					{[]int{1, 1, 0}, nil, true},
+					{[]int{1, 1}, Struct{"c": nil}, false},
+					{[]int{1, 2, 0}, nil, true},
+					{[]int{1, 0, 1}, nil, true},
+					{[]int{0, 0}, nil, true},
+					{[]int{2}, nil, true},
+					{nil, nil, true},
+				},
+				{
+					{[]int{0}, nil, true},
+					{[]int{1, 0, 0}, nil, true},
+				},
+				{
+					{[]int{1}, []interface{}{}, false},
+					{[]int{1, 0}, nil, true},
+				},
+			},
+		},
+		{
+			fields: []string{"*"},
+			lookups: [][]lookup{
+				{
+					{[]int{0, 0}, int64(1), false},
+					{[]int{0, 1, 0, 0}, "x", false},
+					{[]int{0, 1, -1}, nil, true},
+				},
+				{
+					{[]int{0, 0}, nil, true},
+				},
+				{
+					{[]int{0, 0}, int64(3), false},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select(tc.fields...)
+		var row int
+		for c.Stripes() {
+			for c.Next() {
+				for _, l := range tc.lookups[row] {
+					value, null := c.ValueAt(l.path...)
+					if null != l.null || !reflect.DeepEqual(value, l.value) {
+						t.Errorf("Test failed, %v row %d path %v: expected %v, %t got %v, %t", tc.fields, row, l.path, l.value, l.null, value, null)
+					}
+				}
+				row++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if row != len(rows) {
+			t.Errorf("Test failed, expected %d rows got %d", len(rows), row)
+		}
+	}
+
+}
+
+func TestCursorSkip(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:int,lists:array<array<int>>,map:map<string,array<int>>,struct:struct<list:array<int>,s:string>,flag:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// list returns a list of up to n integers which is null and has null values
+	// at random.
+	rnd := rand.New(rand.NewSource(1))
+	list := func(n int) interface{} {
+		if rnd.Intn(10) == 0 {
+			return nil
+		}
+		l := make([]interface{}, rnd.Intn(n+1))
+		for i := range l {
+			if rnd.Intn(10) != 0 {
+				l[i] = int32(rnd.Intn(1000))
+			}
+		}
+		return l
+	}
+	for stripe := 0; stripe < 3; stripe++ {
+		for i := 0; i < 5000; i++ {
+			var lists interface{}
+			if rnd.Intn(10) != 0 {
+				l := make([]interface{}, rnd.Intn(5))
+				for j := range l {
+					l[j] = list(5)
+				}
+				lists = l
+			}
+			var m interface{}
+			if rnd.Intn(10) != 0 {
+				entries := make([]MapEntry, rnd.Intn(4))
+				for j := range entries {
+					entries[j] = MapEntry{Key: fmt.Sprintf("key %d", j), Value: list(3)}
+				}
+				m = entries
+			}
+			var s interface{}
+			if rnd.Intn(10) != 0 {
+				s = []interface{}{list(4), fmt.Sprintf("s %d", i)}
+			}
+			var flag interface{}
+			if rnd.Intn(10) != 0 {
+				flag = rnd.Intn(2) == 0
+			}
+			if err := w.Write(int32(stripe*5000+i), lists, m, s, flag); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows are skipped at random, every row read matching that of a full scan
+	// whichever columns are selected.
+	for _, columns := range [][]string{schema.Columns(), {"lists"}, {"map", "struct"}, {"struct", "id"}, {"flag"}} {
+		for _, limit := range []int64{-1, 12000} {
+			r, err := NewReader(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var indexes []int
+			for _, column := range columns {
+				for i, name := range schema.Columns() {
+					if name == column {
+						indexes = append(indexes, i)
+					}
+				}
+			}
+			c := r.Limit(limit).Select(columns...)
+			var row, read int
+			for c.Stripes() {
+				for {
+					if rnd.Intn(2) == 0 {
+						row += c.Skip(rnd.Intn(100))
+					}
+					if !c.Next() {
+						break
+					}
+					want := make([]interface{}, len(indexes))
+					for i, index := range indexes {
+						want[i] = expected[row][index]
+					}
+					if got := c.Row(); !reflect.DeepEqual(got, want) {
+						t.Fatalf("Test failed, columns %v row %d expected %v got %v", columns, row, want, got)
+					}
+					row++
+					read++
+				}
+			}
+			if err := c.Err(); err != nil {
+				t.Fatal(err)
+			}
+			total := int64(len(expected))
+			if limit >= 0 {
+				total = limit
+			}
+			if int64(row) != total || read == 0 {
+				t.Errorf("Test failed, columns %v expected %d rows got %d having read %d", columns, total, row, read)
+			}
+		}
+	}
+
+}
+
+func TestCursorRowNumber(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for stripe := 0; stripe < 3; stripe++ {
+		for i := 0; i < 3000; i++ {
+			if err := w.Write(int64(stripe*3000 + i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		filter func(stripe int, stats []ColumnStatistics) bool
+		skip   bool
+		first  int64
+		rows   int
+	}{
+		{
+			name:  "all rows",
+			first: 0,
+			rows:  9000,
+		},
+		{
+			name:  "skipped rows",
+			skip:  true,
+			first: 0,
+		},
+		{
+			name:   "filtered stripes",
+			filter: func(stripe int, stats []ColumnStatistics) bool { return stripe != 0 },
+			first:  3000,
+			rows:   6000,
+		},
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, tc := range testCases {
+		var fns []ReaderConfigFunc
+		if tc.filter != nil {
+			fns = append(fns, WithStripeFilter(tc.filter))
+		}
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), fns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select("id")
+		if n := c.RowNumber(); n != -1 {
+			t.Errorf("Test failed, %s expected row number -1 before the first row got %d", tc.name, n)
+		}
+		var rows int
+		previous := tc.first - 1
+		for c.Stripes() {
+			for {
+				if tc.skip && rnd.Intn(2) == 0 {
+					c.Skip(rnd.Intn(100))
+				}
+				if !c.Next() {
+					break
+				}
+				n := c.RowNumber()
+				if id := c.Row()[0].(int64); n != id {
+					t.Fatalf("Test failed, %s expected row number %d got %d", tc.name, id, n)
+				}
+				if n <= previous || (!tc.skip && n != previous+1) {
+					t.Fatalf("Test failed, %s expected row number %d to follow %d", tc.name, n, previous)
+				}
+				previous = n
+				rows++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !tc.skip && rows != tc.rows {
+			t.Errorf("Test failed, %s expected %d rows got %d", tc.name, tc.rows, rows)
+		}
+	}
+
+}
@@ -1,11 +1,11 @@
 package orc
 
 import (
-	"encoding/asn1"
 	"encoding/json"
+	"fmt"
 	"io"
-	"math"
 	"math/big"
+	"strings"
 )
 
 // Decimal is a decimal type.
@@ -14,9 +14,23 @@ type Decimal struct {
 	Exp int64
 }
 
+// Rat returns the exact value of the Decimal as a big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat)
+	if d.Abs == nil {
+		return r
+	}
+	r.SetInt(d.Abs)
+	if d.Exp > 0 {
+		return r.Quo(r, new(big.Rat).SetInt(pow10(d.Exp)))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(pow10(-d.Exp)))
+}
+
 // Float64 returns the float64 equivalent of the Decimal value.
 func (d Decimal) Float64() float64 {
-	return float64(d.Abs.Int64()) / (1 / math.Pow(10, -float64(d.Exp)))
+	f, _ := d.Rat().Float64()
+	return f
 }
 
 // Float32 returns the float32 equivalent of the Decimal value.
@@ -24,31 +38,157 @@ func (d Decimal) Float32() float32 {
 	return float32(d.Float64())
 }
 
-// MarshalJSON implements the json.Marshaller interface.
+// String returns the Decimal formatted with Exp digits after the decimal point.
+func (d Decimal) String() string {
+	if d.Abs == nil {
+		return "0"
+	}
+	s := new(big.Int).Abs(d.Abs).String()
+	if d.Exp <= 0 {
+		s += strings.Repeat("0", int(-d.Exp))
+	} else {
+		exp := int(d.Exp)
+		if len(s) <= exp {
+			s = strings.Repeat("0", exp-len(s)+1) + s
+		}
+		s = s[:len(s)-exp] + "." + s[len(s)-exp:]
+	}
+	if d.Abs.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the Decimal as a
+// string holding its exact value, as the precision of a decimal may exceed that
+// of a float64.
 func (d Decimal) MarshalJSON() ([]byte, error) {
-	return json.Marshal(d.Float64())
+	return json.Marshal(d.String())
+}
+
+// toDecimal converts a Decimal, *big.Rat or string value to a Decimal with the
+// provided precision and scale. Values with more fractional digits than scale
+// are rounded half away from zero if round is true, otherwise an error is
+// returned. An error is always returned if the value exceeds the precision.
+func toDecimal(value interface{}, precision, scale int, round bool) (Decimal, error) {
+	var r *big.Rat
+	switch t := value.(type) {
+	case Decimal:
+		r = t.Rat()
+	case *big.Rat:
+		r = t
+	case string:
+		var ok bool
+		r, ok = new(big.Rat).SetString(t)
+		if !ok {
+			return Decimal{}, fmt.Errorf("invalid decimal string: %q", t)
+		}
+	default:
+		return Decimal{}, fmt.Errorf("cannot write %T to decimal column type", value)
+	}
+	num := new(big.Int).Mul(r.Num(), pow10(int64(scale)))
+	abs, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		if !round {
+			return Decimal{}, fmt.Errorf("decimal value %s has more than %v fractional digits", r.RatString(), scale)
+		}
+		// Round half away from zero.
+		rem.Abs(rem).Lsh(rem, 1)
+		if rem.Cmp(r.Denom()) >= 0 {
+			abs.Add(abs, big.NewInt(int64(num.Sign())))
+		}
+	}
+	d := Decimal{Abs: abs, Exp: int64(scale)}
+	if digits := decimalDigits(abs); digits > precision {
+		return Decimal{}, fmt.Errorf("decimal value %s exceeds precision %v", d, precision)
+	}
+	return d, nil
+}
+
+// decimalDigits returns the number of decimal digits in the absolute value of i.
+func decimalDigits(i *big.Int) int {
+	if i.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(i).String())
 }
 
-// decodeBase128Varint decodes an unbounded Base128 varint
-// from r, returning a big.Int or an error.
+// pow10 returns 10 raised to the power of n.
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// decodeBase128Varint decodes an unbounded, zigzag encoded
+// Base128 varint from r, returning a big.Int or an error.
+// Values of up to 9 bytes, which include every value of a
+// decimal with a precision of 18 or less, are decoded in a
+// uint64, only those that are longer being accumulated in
+// a big.Int.
 func decodeBase128Varint(r io.ByteReader) (*big.Int, error) {
-	var b []byte
+	var zz uint64
+	var shift uint
+	for shift < 63 {
+		byt, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		zz |= uint64(byt&0x7f) << shift
+		shift += 7
+		if byt&0x80 == 0 {
+			return big.NewInt(zigzagDecode(zz)), nil
+		}
+	}
+	bi := new(big.Int).SetUint64(zz)
+	group := &big.Int{}
 	for {
 		byt, err := r.ReadByte()
 		if err != nil {
 			return nil, err
 		}
-		b = append(b, byt)
+		group.SetUint64(uint64(byt & 0x7f))
+		bi.Or(bi, group.Lsh(group, shift))
+		shift += 7
 		// Check whether the Base128 varint continues
 		// into the next byte. If not, then break.
 		if byt&0x80 == 0 {
 			break
 		}
 	}
-	bi := &big.Int{}
-	_, err := asn1.Unmarshal(b, &bi)
-	if err != nil {
-		return nil, err
+	// Reverse the zigzag encoding, negative values
+	// are stored as (-n << 1) - 1.
+	negative := bi.Bit(0) == 1
+	bi.Rsh(bi, 1)
+	if negative {
+		bi.Not(bi)
 	}
 	return bi, nil
 }
+
+// encodeBase128Varint writes i to w as an unbounded,
+// zigzag encoded Base128 varint, using a big.Int only
+// for values that do not fit in an int64.
+func encodeBase128Varint(w io.ByteWriter, i *big.Int) error {
+	if i.IsInt64() {
+		return writeVslong(w, i.Int64())
+	}
+	zz := new(big.Int)
+	if i.Sign() < 0 {
+		zz.Not(i)
+		zz.Lsh(zz, 1)
+		zz.SetBit(zz, 0, 1)
+	} else {
+		zz.Lsh(i, 1)
+	}
+	group := &big.Int{}
+	mask := big.NewInt(0x7f)
+	for {
+		byt := byte(group.And(zz, mask).Uint64())
+		zz.Rsh(zz, 7)
+		if zz.Sign() == 0 {
+			return w.WriteByte(byt)
+		}
+		if err := w.WriteByte(byt | 0x80); err != nil {
+			return err
+		}
+	}
+}
@@ -2,8 +2,13 @@ package orc
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
+	"io"
+	"math"
 	"math/big"
+	"os"
 	"testing"
 )
 
@@ -23,9 +28,214 @@ func TestDecimal(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	expected := []byte(`-836.1232`)
+	expected := []byte(`"-836.1232"`)
 	if !bytes.Equal(byt, expected) {
 		t.Errorf("Test failed, expected %s got %s", expected, byt)
 	}
 
 }
+
+func TestBase128Varint(t *testing.T) {
+
+	max, _ := new(big.Int).SetString("99999999999999999999999999999999999999", 10)
+
+	testCases := []struct {
+		value   *big.Int
+		encoded []byte
+	}{
+		{value: big.NewInt(0), encoded: []byte{0x00}},
+		{value: big.NewInt(-1), encoded: []byte{0x01}},
+		{value: big.NewInt(1), encoded: []byte{0x02}},
+		{value: big.NewInt(63), encoded: []byte{0x7e}},
+		{value: big.NewInt(-64), encoded: []byte{0x7f}},
+		{value: big.NewInt(64), encoded: []byte{0x80, 0x01}},
+		{value: big.NewInt(-8361232), encoded: []byte{0x9f, 0xd4, 0xfc, 0x07}},
+		{value: big.NewInt(math.MaxInt64), encoded: []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{value: big.NewInt(math.MinInt64), encoded: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{value: new(big.Int).Lsh(big.NewInt(1), 63), encoded: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x02}},
+		{value: new(big.Int).Sub(big.NewInt(math.MinInt64), big.NewInt(1)), encoded: []byte{0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x02}},
+		// The largest values held in 9 bytes, which are decoded without a big.Int.
+		{value: big.NewInt(1<<62 - 1), encoded: []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{value: big.NewInt(-1 << 62), encoded: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{value: big.NewInt(1 << 62), encoded: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+		{value: max},
+		{value: new(big.Int).Neg(max)},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		err := encodeBase128Varint(&buf, tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.encoded != nil && !bytes.Equal(buf.Bytes(), tc.encoded) {
+			t.Errorf("Test failed, expected %v to encode as %v got %v", tc.value, tc.encoded, buf.Bytes())
+		}
+		value, err := decodeBase128Varint(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.Cmp(tc.value) != 0 {
+			t.Errorf("Test failed, expected %v got %v", tc.value, value)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Test failed, expected %v to be decoded from every byte, %d remain", tc.value, buf.Len())
+		}
+	}
+
+	if _, err := decodeBase128Varint(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80})); err != io.EOF {
+		t.Errorf("Test failed, expected EOF for a truncated varint got %v", err)
+	}
+
+}
+
+func TestReadWideDecimals(t *testing.T) {
+
+	// The decimal(38,18) column written by the Java implementation includes
+	// unscaled values beyond the range of an int64.
+	r, err := Open("./examples/TestOrcFile.testUnionAndTimestamp.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	gz, err := os.Open("./examples/expected/TestOrcFile.testUnionAndTimestamp.jsn.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	jsn, err := gzip.NewReader(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(jsn)
+	dec.UseNumber()
+
+	c := r.Select("decimal")
+	var rows, wide int
+	for c.Stripes() {
+		for c.Next() {
+			var expected struct {
+				Decimal *json.Number `json:"decimal"`
+			}
+			if err := dec.Decode(&expected); err != nil {
+				t.Fatal(err)
+			}
+			value := c.Row()[0]
+			if expected.Decimal == nil {
+				if value != nil {
+					t.Errorf("Test failed, row %d expected null got %v", rows, value)
+				}
+				rows++
+				continue
+			}
+			d, ok := value.(Decimal)
+			if !ok {
+				t.Fatalf("Test failed, row %d expected a Decimal got %T", rows, value)
+			}
+			// The values are written with the least scale that represents
+			// them exactly, rather than the scale of the column.
+			want, ok := new(big.Rat).SetString(expected.Decimal.String())
+			if !ok {
+				t.Fatalf("Test failed, row %d has invalid expected value %s", rows, expected.Decimal)
+			}
+			if d.Rat().Cmp(want) != 0 {
+				t.Errorf("Test failed, row %d expected %s got %s", rows, expected.Decimal, d)
+			}
+			if !d.Abs.IsInt64() {
+				wide++
+			}
+			rows++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if wide == 0 {
+		t.Errorf("Test failed, expected unscaled values beyond the range of an int64")
+	}
+
+}
+
+func TestDecimalString(t *testing.T) {
+
+	testCases := []struct {
+		decimal  Decimal
+		expected string
+	}{
+		{Decimal{big.NewInt(-12345678), 3}, "-12345.678"},
+		{Decimal{big.NewInt(5), 3}, "0.005"},
+		{Decimal{big.NewInt(-5), 1}, "-0.5"},
+		{Decimal{big.NewInt(150), 2}, "1.50"},
+		{Decimal{big.NewInt(12), 0}, "12"},
+		{Decimal{big.NewInt(0), 2}, "0.00"},
+	}
+
+	for _, tc := range testCases {
+		if s := tc.decimal.String(); s != tc.expected {
+			t.Errorf("Test failed, expected %s got %s", tc.expected, s)
+		}
+	}
+
+}
+
+func TestToDecimal(t *testing.T) {
+
+	testCases := []struct {
+		value     interface{}
+		precision int
+		scale     int
+		round     bool
+		expected  string
+		err       bool
+	}{
+		{value: "-12345.678", precision: 10, scale: 3, expected: "-12345.678"},
+		{value: "1.5", precision: 10, scale: 3, expected: "1.500"},
+		{value: big.NewRat(-1, 4), precision: 5, scale: 2, expected: "-0.25"},
+		{value: Decimal{big.NewInt(12345), 1}, precision: 10, scale: 2, expected: "1234.50"},
+		{value: "1.005", precision: 10, scale: 2, err: true},
+		{value: "1.005", precision: 10, scale: 2, round: true, expected: "1.01"},
+		{value: "-1.005", precision: 10, scale: 2, round: true, expected: "-1.01"},
+		{value: "1.004", precision: 10, scale: 2, round: true, expected: "1.00"},
+		{value: "99.99", precision: 4, scale: 2, expected: "99.99"},
+		{value: "100.00", precision: 4, scale: 2, err: true},
+		{value: "9.999", precision: 3, scale: 2, round: true, err: true},
+		{value: "abc", precision: 10, scale: 2, err: true},
+		{value: 1.5, precision: 10, scale: 2, err: true},
+	}
+
+	for _, tc := range testCases {
+		d, err := toDecimal(tc.value, tc.precision, tc.scale, tc.round)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Test failed, expected error for %v got %v", tc.value, d)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test failed, unexpected error for %v: %v", tc.value, err)
+			continue
+		}
+		if s := d.String(); s != tc.expected {
+			t.Errorf("Test failed, expected %s got %s", tc.expected, s)
+		}
+	}
+
+}
+
+func BenchmarkBase128Varint(b *testing.B) {
+	wide, _ := new(big.Int).SetString("-12345678901234567890123456789", 10)
+	for _, value := range []*big.Int{big.NewInt(-8361232), wide} {
+		var buf bytes.Buffer
+		if err := encodeBase128Varint(&buf, value); err != nil {
+			b.Fatal(err)
+		}
+		encoded := buf.Bytes()
+		b.Run(fmt.Sprintf("bytes=%d", len(encoded)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := decodeBase128Varint(bytes.NewReader(encoded)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
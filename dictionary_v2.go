@@ -8,8 +8,14 @@ import (
 type DictionaryV2 struct {
 	values    []string
 	valuesMap map[string]int
+	bytes     int64
 }
 
+// dictionaryEntryOverhead is the approximate number of bytes used by each entry
+// of the dictionary in addition to the bytes of the value itself, covering the
+// string header, the index and the overhead of the hash map.
+const dictionaryEntryOverhead = 48
+
 // NewDictionaryV2 returns a new DictionaryV2 intialised with the provided initialCapacity.
 func NewDictionaryV2() *DictionaryV2 {
 	return &DictionaryV2{
@@ -18,13 +24,19 @@ func NewDictionaryV2() *DictionaryV2 {
 }
 
 func (d *DictionaryV2) add(value string) {
-	d.valuesMap[value] = 0
+	if _, ok := d.valuesMap[value]; !ok {
+		d.bytes += int64(len(value)) + dictionaryEntryOverhead
+		d.valuesMap[value] = len(d.values)
+		d.values = append(d.values, value)
+	}
 }
 
-func (d *DictionaryV2) prepare() {
-	d.values = make([]string, 0, len(d.valuesMap))
-	for value := range d.valuesMap {
-		d.values = append(d.values, value)
+// prepare orders the values of the dictionary ready for them to be written. The
+// values are sorted by their bytes when sorted is true, as by the Java writer,
+// otherwise they remain in the order in which they were first added.
+func (d *DictionaryV2) prepare(sorted bool) {
+	if !sorted {
+		return
 	}
 	sort.Strings(d.values)
 	for i := range d.values {
@@ -52,8 +64,15 @@ func (d *DictionaryV2) forEach(fn func(value string) error) error {
 func (d *DictionaryV2) reset() {
 	d.valuesMap = make(map[string]int)
 	d.values = nil
+	d.bytes = 0
 }
 
 func (d *DictionaryV2) size() int {
 	return len(d.valuesMap)
 }
+
+// memoryUsage returns the approximate number of bytes held in memory by the
+// dictionary.
+func (d *DictionaryV2) memoryUsage() int64 {
+	return d.bytes
+}
@@ -0,0 +1,205 @@
+package orc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// DumpOptions configures the output of Dump.
+type DumpOptions struct {
+	// ShowData additionally decodes and prints every row of the file
+	// as JSON. By default no column data is decoded.
+	ShowData bool
+}
+
+// Dump writes a human-readable report of the ORC file contained in ra to w. The
+// report includes the postscript, the schema, the stripe layout along with the
+// kind and length of every stream, the column encodings and the stripe and file
+// level statistics.
+func Dump(w io.Writer, ra io.ReaderAt, size int64, opts DumpOptions) error {
+	r, err := NewReader(sizedReaderAt{ra, size})
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	d := &dumper{w: w, r: r}
+	d.dumpPostScript()
+	d.dumpStripeStatistics()
+	d.dumpFileStatistics()
+	if err := d.dumpStripes(); err != nil {
+		return err
+	}
+	d.dumpUserMetadata()
+	if opts.ShowData {
+		if err := d.dumpData(); err != nil {
+			return err
+		}
+	}
+	return d.err
+}
+
+// dumper holds the state used whilst writing a Dump report, retaining the first
+// error returned by the underlying writer.
+type dumper struct {
+	w   io.Writer
+	r   *Reader
+	err error
+}
+
+func (d *dumper) printf(format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, format, args...)
+}
+
+func (d *dumper) dumpPostScript() {
+	ps := d.r.postScript
+	version := make([]string, len(ps.GetVersion()))
+	for i, v := range ps.GetVersion() {
+		version[i] = fmt.Sprint(v)
+	}
+	d.printf("File Version: %s with writer version %d\n", strings.Join(version, "."), ps.GetWriterVersion())
+	d.printf("Rows: %d\n", d.r.footer.GetNumberOfRows())
+	d.printf("Compression: %s\n", ps.GetCompression())
+	if ps.GetCompression() != proto.CompressionKind_NONE {
+		d.printf("Compression size: %d\n", ps.GetCompressionBlockSize())
+	}
+	d.printf("Type: %s\n", d.r.schema)
+	d.printf("Row index stride: %d\n", d.r.footer.GetRowIndexStride())
+}
+
+func (d *dumper) dumpStripeStatistics() {
+	d.printf("\nStripe Statistics:\n")
+	for i, stripeStats := range d.r.metadata.GetStripeStats() {
+		d.printf("  Stripe %d:\n", i+1)
+		for col, stats := range stripeStats.GetColStats() {
+			d.printf("    Column %d: %s\n", col, formatStatistics(stats))
+		}
+	}
+}
+
+func (d *dumper) dumpFileStatistics() {
+	d.printf("\nFile Statistics:\n")
+	for col, stats := range d.r.footer.GetStatistics() {
+		d.printf("  Column %d: %s\n", col, formatStatistics(stats))
+	}
+}
+
+func (d *dumper) dumpStripes() error {
+	d.printf("\nStripes:\n")
+	for _, stripe := range d.r.footer.GetStripes() {
+		d.printf(
+			"  Stripe: offset: %d data: %d rows: %d tail: %d index: %d\n",
+			stripe.GetOffset(),
+			stripe.GetDataLength(),
+			stripe.GetNumberOfRows(),
+			stripe.GetFooterLength(),
+			stripe.GetIndexLength(),
+		)
+		stripeFooter, err := d.r.getStripeFooter(stripe)
+		if err != nil {
+			return err
+		}
+		offset := stripe.GetOffset()
+		for _, stream := range stripeFooter.GetStreams() {
+			d.printf(
+				"    Stream: column %d section %s start: %d length %d\n",
+				stream.GetColumn(),
+				stream.GetKind(),
+				offset,
+				stream.GetLength(),
+			)
+			offset += stream.GetLength()
+		}
+		for col, encoding := range stripeFooter.GetColumns() {
+			d.printf("    Encoding column %d: %s", col, encoding.GetKind())
+			switch encoding.GetKind() {
+			case proto.ColumnEncoding_DICTIONARY, proto.ColumnEncoding_DICTIONARY_V2:
+				d.printf("[%d]", encoding.GetDictionarySize())
+			}
+			d.printf("\n")
+		}
+		if tz := stripeFooter.GetWriterTimezone(); tz != "" {
+			d.printf("    Writer timezone: %s\n", tz)
+		}
+	}
+	return nil
+}
+
+func (d *dumper) dumpUserMetadata() {
+	metadata := d.r.footer.GetMetadata()
+	if len(metadata) == 0 {
+		return
+	}
+	d.printf("\nUser Metadata:\n")
+	for _, item := range metadata {
+		d.printf("  %s: %d bytes\n", item.GetName(), len(item.GetValue()))
+	}
+}
+
+func (d *dumper) dumpData() error {
+	d.printf("\nData:\n")
+	c := d.r.Select("*")
+	for c.Stripes() {
+		for c.Next() {
+			byt, err := json.Marshal(c.Row()[0])
+			if err != nil {
+				return err
+			}
+			d.printf("%s\n", byt)
+		}
+	}
+	return c.Err()
+}
+
+// formatStatistics returns a single line, human-readable representation of the
+// provided column statistics. Optional values are only included when set.
+func formatStatistics(stats *proto.ColumnStatistics) string {
+	s := fmt.Sprintf("count: %d hasNull: %t", stats.GetNumberOfValues(), stats.GetHasNull())
+	switch {
+	case stats.IntStatistics != nil:
+		is := stats.GetIntStatistics()
+		s += formatOptional(" min: %d", is.Minimum) + formatOptional(" max: %d", is.Maximum) + formatOptional(" sum: %d", is.Sum)
+	case stats.DoubleStatistics != nil:
+		ds := stats.GetDoubleStatistics()
+		s += formatOptional(" min: %v", ds.Minimum) + formatOptional(" max: %v", ds.Maximum) + formatOptional(" sum: %v", ds.Sum)
+	case stats.StringStatistics != nil:
+		ss := stats.GetStringStatistics()
+		s += formatOptional(" min: %s", ss.Minimum) + formatOptional(" lower: %s", ss.LowerBound) + formatOptional(" max: %s", ss.Maximum) + formatOptional(" upper: %s", ss.UpperBound) + formatOptional(" sum: %d", ss.Sum)
+	case stats.BucketStatistics != nil:
+		if counts := stats.GetBucketStatistics().GetCount(); len(counts) > 0 {
+			s += fmt.Sprintf(" true: %d", counts[0])
+		}
+	case stats.DecimalStatistics != nil:
+		ds := stats.GetDecimalStatistics()
+		s += formatOptional(" min: %s", ds.Minimum) + formatOptional(" max: %s", ds.Maximum) + formatOptional(" sum: %s", ds.Sum)
+	case stats.DateStatistics != nil:
+		ds := stats.GetDateStatistics()
+		s += formatOptional(" min: %d", ds.Minimum) + formatOptional(" max: %d", ds.Maximum)
+	case stats.TimestampStatistics != nil:
+		ts := stats.GetTimestampStatistics()
+		s += formatOptional(" min: %d", ts.Minimum) + formatOptional(" max: %d", ts.Maximum)
+	case stats.BinaryStatistics != nil:
+		s += formatOptional(" sum: %d", stats.GetBinaryStatistics().Sum)
+	case stats.CollectionStatistics != nil:
+		cs := stats.GetCollectionStatistics()
+		s += formatOptional(" minChildren: %d", cs.MinChildren) + formatOptional(" maxChildren: %d", cs.MaxChildren) + formatOptional(" totalChildren: %d", cs.TotalChildren)
+	}
+	return s
+}
+
+// formatOptional formats the value pointed to by ptr, which must be a pointer
+// to a protobuf optional field, returning an empty string if it is unset.
+func formatOptional(format string, ptr interface{}) string {
+	v := reflect.ValueOf(ptr)
+	if v.IsNil() {
+		return ""
+	}
+	return fmt.Sprintf(format, v.Elem().Interface())
+}
@@ -0,0 +1,78 @@
+package orc
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+
+	testCases := []struct {
+		example  string
+		expected string
+		opts     DumpOptions
+	}{
+		{
+			example:  "./examples/TestOrcFile.test1.orc",
+			expected: "./examples/expected/TestOrcFile.test1.dump",
+		},
+		{
+			example:  "./examples/TestOrcFile.testSnappy.orc",
+			expected: "./examples/expected/TestOrcFile.testSnappy.dump",
+		},
+	}
+
+	for _, tc := range testCases {
+		f, err := os.Open(tc.example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		err = Dump(&buf, f, fi.Size(), tc.opts)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ioutil.ReadFile(tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expected, buf.Bytes()) {
+			t.Errorf("Test failed, dump of %s does not match %s, got:\n%s", tc.example, tc.expected, buf.String())
+		}
+	}
+
+}
+
+func TestDumpShowData(t *testing.T) {
+
+	f, err := os.Open("./examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = Dump(&buf, f, fi.Size(), DumpOptions{ShowData: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("\nData:\n")) {
+		t.Errorf("Test failed, expected data section in dump")
+	}
+	if n := bytes.Count(buf.Bytes(), []byte(`{"boolean1":`)); n != 2 {
+		t.Errorf("Test failed, expected 2 rows got %v", n)
+	}
+
+}
@@ -0,0 +1,264 @@
+package orc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+const (
+	// maxEncryptedColumn is the largest column ID of an encrypted stream, the IV
+	// of each stream holding the column ID in 3 bytes.
+	maxEncryptedColumn = 1<<24 - 1
+	// maxEncryptStripeID is the largest encryption stripe ID, the IV of each
+	// stream holding the stripe ID in 3 bytes.
+	maxEncryptStripeID = 1<<24 - 1
+)
+
+// WithDecryptionKeyProvider sets the function through which the local keys of the
+// encrypted columns of a file are obtained from the caller's key management
+// service, so that those columns are read in place of the masked values stored
+// for readers without the keys. It is called once for each encrypted local key
+// recorded in the stripes of the file, being the key metadata of its column
+// encryption variant, and returns the decrypted local key, of 16 bytes for the
+// AES_CTR_128 algorithm or 32 bytes for AES_CTR_256. The master key that
+// encrypted each local key is described by the encryption of the footer.
+//
+// The streams of the encrypted columns are decrypted by AES-CTR before they are
+// decompressed, each stream's IV being derived from its column, kind and stripe as
+// specified by ORC. The statistics of the encrypted columns, which are themselves
+// encrypted, are not decrypted.
+func WithDecryptionKeyProvider(fn func(keyMetadata []byte) ([]byte, error)) ReaderConfigFunc {
+	return func(r *Reader) error {
+		if fn == nil {
+			return fmt.Errorf("invalid decryption key provider, must not be nil")
+		}
+		r.decryptionKeyProvider = fn
+		r.localKeys = make(map[string]cipher.Block)
+		return nil
+	}
+}
+
+// stripeStream is a stream of a stripe and its offset within the file. Streams of
+// encrypted columns hold the cipher of their local key and the encryption stripe
+// ID of their stripe, from which the IV of the stream is derived.
+type stripeStream struct {
+	*proto.Stream
+	offset   int64
+	block    cipher.Block
+	stripeID uint64
+}
+
+// reader returns a reader of the stream from ra, decrypting it if it is encrypted.
+func (s stripeStream) reader(ra io.ReaderAt) io.Reader {
+	sr := io.NewSectionReader(ra, s.offset, int64(s.GetLength()))
+	if s.block == nil {
+		return sr
+	}
+	iv := streamIV(s.GetColumn(), s.GetKind(), s.stripeID)
+	return cipher.StreamReader{S: cipher.NewCTR(s.block, iv), R: sr}
+}
+
+// streamIV returns the initial counter block of the AES-CTR encryption of a stream,
+// holding the column ID in 3 bytes, the stream kind in 2 bytes and the encryption
+// stripe ID in 3 bytes, all big endian, followed by a block counter starting from
+// zero in the last 8 bytes.
+func streamIV(column uint32, kind proto.Stream_Kind, stripeID uint64) []byte {
+	iv := make([]byte, aes.BlockSize)
+	iv[0], iv[1], iv[2] = byte(column>>16), byte(column>>8), byte(column)
+	iv[3], iv[4] = byte(kind>>8), byte(kind)
+	iv[5], iv[6], iv[7] = byte(stripeID>>16), byte(stripeID>>8), byte(stripeID)
+	return iv
+}
+
+// isIndexStream returns whether streams of kind are stored in the index area of a
+// stripe, rather than its data area.
+func isIndexStream(kind proto.Stream_Kind) bool {
+	switch kind {
+	case proto.Stream_ROW_INDEX, proto.Stream_BLOOM_FILTER, proto.Stream_BLOOM_FILTER_UTF8, proto.Stream_ENCRYPTED_INDEX:
+		return true
+	}
+	return false
+}
+
+// stripeStreams returns the streams of the stripe at index stripe, which has the
+// provided stripe footer, with the encoding of each column. Without a decryption
+// key provider, these are the streams and encodings of the stripe footer, being
+// the masked streams of any encrypted columns, other than the ENCRYPTED_INDEX and
+// ENCRYPTED_DATA streams holding their encrypted streams. Otherwise the streams
+// and encodings of each encryption variant replace those of its columns, the
+// encrypted streams of a variant being stored in place of its ENCRYPTED_INDEX and
+// ENCRYPTED_DATA streams, or after the other streams of the stripe if it has none.
+func (r *Reader) stripeStreams(stripe int, footer *proto.StripeFooter) ([]stripeStream, []*proto.ColumnEncoding, error) {
+	stripes, err := r.getStripes()
+	if err != nil {
+		return nil, nil, err
+	}
+	offset := int64(stripes[stripe].GetOffset())
+	encodings := footer.GetColumns()
+	variants := r.footer.GetEncryption().GetVariants()
+	if r.decryptionKeyProvider == nil || len(variants) == 0 {
+		var streams []stripeStream
+		for _, stream := range footer.GetStreams() {
+			if kind := stream.GetKind(); kind != proto.Stream_ENCRYPTED_INDEX && kind != proto.Stream_ENCRYPTED_DATA {
+				streams = append(streams, stripeStream{Stream: stream, offset: offset})
+			}
+			offset += int64(stream.GetLength())
+		}
+		return streams, encodings, nil
+	}
+	if len(footer.GetEncryption()) != len(variants) {
+		return nil, nil, fmt.Errorf("stripe footer has %d encryption variants, expected %d", len(footer.GetEncryption()), len(variants))
+	}
+
+	// The encryption stripe ID is recorded by the first stripe of each file
+	// merged into this one, the others following their previous stripe, and the
+	// local keys of each stripe are those of the last stripe to record them.
+	var stripeID uint64
+	var localKeys [][]byte
+	for _, info := range stripes[:stripe+1] {
+		if info.EncryptStripeId != nil {
+			stripeID = info.GetEncryptStripeId()
+		} else {
+			stripeID++
+		}
+		if keys := info.GetEncryptedLocalKeys(); len(keys) > 0 {
+			localKeys = keys
+		}
+	}
+	if stripeID > maxEncryptStripeID {
+		return nil, nil, fmt.Errorf("encryption stripe ID %d exceeds the maximum of %d", stripeID, maxEncryptStripeID)
+	}
+
+	types, err := r.getTypes()
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := r.footer.GetEncryption().GetKey()
+	encodings = append([]*proto.ColumnEncoding(nil), encodings...)
+	encrypted := make(map[uint32]bool)
+	blocks := make([]cipher.Block, len(variants))
+	for i, variant := range variants {
+		root := int(variant.GetRoot())
+		if root >= len(types) || root > maxEncryptedColumn {
+			return nil, nil, fmt.Errorf("encryption variant %d has root column %d not in the schema of %d columns", i, root, len(types))
+		}
+		if variant.GetKey() >= uint32(len(keys)) {
+			return nil, nil, fmt.Errorf("encryption variant %d has key %d, the file has %d keys", i, variant.GetKey(), len(keys))
+		}
+		if i >= len(localKeys) {
+			return nil, nil, fmt.Errorf("stripe %d has no local key for encryption variant %d", stripe, i)
+		}
+		blocks[i], err = r.localKey(localKeys[i], keys[variant.GetKey()].GetAlgorithm())
+		if err != nil {
+			return nil, nil, fmt.Errorf("encryption variant %d: %w", i, err)
+		}
+		variantEncodings := footer.GetEncryption()[i].GetEncoding()
+		for column := root; column <= maximumColumn(types, root); column++ {
+			if column-root >= len(variantEncodings) || column >= len(encodings) {
+				return nil, nil, fmt.Errorf("encryption variant %d has no encoding for column %d", i, column)
+			}
+			encodings[column] = variantEncodings[column-root]
+			encrypted[uint32(column)] = true
+		}
+	}
+
+	// placed records the areas of each variant whose streams have been placed,
+	// being its index area or its data area.
+	type area struct {
+		variant int
+		index   bool
+	}
+	placed := make(map[area]bool)
+	var streams []stripeStream
+	place := func(a area, offset int64) int64 {
+		placed[a] = true
+		for _, stream := range footer.GetEncryption()[a.variant].GetStreams() {
+			if isIndexStream(stream.GetKind()) == a.index {
+				streams = append(streams, stripeStream{Stream: stream, offset: offset, block: blocks[a.variant], stripeID: stripeID})
+				offset += int64(stream.GetLength())
+			}
+		}
+		return offset
+	}
+	for _, stream := range footer.GetStreams() {
+		length := int64(stream.GetLength())
+		switch kind := stream.GetKind(); {
+		case kind == proto.Stream_ENCRYPTED_INDEX || kind == proto.Stream_ENCRYPTED_DATA:
+			name := streamName{int(stream.GetColumn()), kind}
+			a := area{variant: -1, index: kind == proto.Stream_ENCRYPTED_INDEX}
+			for i := range variants {
+				if variants[i].GetRoot() == stream.GetColumn() && !placed[area{i, a.index}] {
+					a.variant = i
+					break
+				}
+			}
+			if a.variant < 0 {
+				return nil, nil, fmt.Errorf("stream %s is not of an encryption variant", name)
+			}
+			if end := place(a, offset); end-offset != length {
+				return nil, nil, fmt.Errorf("stream %s has length %d, its encrypted streams have length %d", name, length, end-offset)
+			}
+		case !encrypted[stream.GetColumn()]:
+			streams = append(streams, stripeStream{Stream: stream, offset: offset})
+		}
+		offset += length
+	}
+	for i := range variants {
+		for _, a := range []area{{i, true}, {i, false}} {
+			if !placed[a] {
+				offset = place(a, offset)
+			}
+		}
+	}
+	return streams, encodings, nil
+}
+
+// localKey returns the cipher of the local key of an encryption variant using the
+// provided algorithm, decrypting encryptedKey by the decryption key provider. Each
+// key is decrypted once, however many stripes it is used by.
+func (r *Reader) localKey(encryptedKey []byte, algorithm proto.EncryptionAlgorithm) (cipher.Block, error) {
+	if block, ok := r.localKeys[string(encryptedKey)]; ok {
+		return block, nil
+	}
+	var size int
+	switch algorithm {
+	case proto.EncryptionAlgorithm_AES_CTR_128:
+		size = 16
+	case proto.EncryptionAlgorithm_AES_CTR_256:
+		size = 32
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm %s", algorithm)
+	}
+	key, err := r.decryptionKeyProvider(encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting local key: %w", err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("local key of %d bytes, expected %d bytes for %s", len(key), size, algorithm)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	r.localKeys[string(encryptedKey)] = block
+	return block, nil
+}
+
+// maximumColumn returns the largest column ID of the subtree of types rooted at
+// column.
+func maximumColumn(types []*proto.Type, column int) int {
+	max := column
+	for _, subtype := range types[column].GetSubtypes() {
+		if int(subtype) <= column || int(subtype) >= len(types) {
+			continue
+		}
+		if m := maximumColumn(types, int(subtype)); m > max {
+			max = m
+		}
+	}
+	return max
+}
@@ -0,0 +1,348 @@
+package orc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.simon-critchley.co.uk/orc/proto"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+func TestStreamDecryption(t *testing.T) {
+
+	// The ciphertexts were produced by openssl enc -aes-128-ctr and -aes-256-ctr
+	// with the IVs of the ORC specification for each stream.
+	testCases := []struct {
+		key        string
+		column     uint32
+		kind       proto.Stream_Kind
+		stripeID   uint64
+		iv         string
+		ciphertext string
+	}{
+		{
+			key:        "000102030405060708090a0b0c0d0e0f",
+			column:     1,
+			kind:       proto.Stream_DATA,
+			stripeID:   1,
+			iv:         "00000100010000010000000000000000",
+			ciphertext: "46517c57400dd25a04f80807be2f60114f850bde703662a4b2595c7b94d75f7e2cea27572a56b04883d35e",
+		},
+		{
+			key:        "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			column:     3,
+			kind:       proto.Stream_SECONDARY,
+			stripeID:   7,
+			iv:         "00000300050000070000000000000000",
+			ciphertext: "0a867eb99531b0e04e0f85c620047d87df86f48c49f865b37251dca8ec6f937d1613d1e510d1106b613964",
+		},
+	}
+
+	for _, tc := range testCases {
+		if iv := hex.EncodeToString(streamIV(tc.column, tc.kind, tc.stripeID)); iv != tc.iv {
+			t.Errorf("Test failed, expected IV %s got %s", tc.iv, iv)
+		}
+		key, _ := hex.DecodeString(tc.key)
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, _ := hex.DecodeString(tc.ciphertext)
+		s := stripeStream{
+			Stream:   &proto.Stream{Column: ptrUint32(tc.column), Kind: tc.kind.Enum(), Length: ptrUint64(uint64(len(ciphertext)))},
+			offset:   3,
+			block:    block,
+			stripeID: tc.stripeID,
+		}
+		b, err := ioutil.ReadAll(s.reader(bytes.NewReader(append([]byte("ORC"), ciphertext...))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "The quick brown fox jumps over the lazy dog"; string(b) != expected {
+			t.Errorf("Test failed, expected %q got %q", expected, b)
+		}
+	}
+
+}
+
+// encryptVariant returns the uncompressed ORC file b with the columns rooted at
+// root encrypted as a single variant by AES_CTR_128, laid out as by the Java
+// implementation. The index and data streams of the variant are stored after the
+// unencrypted streams of the index and data areas of each stripe, in place of
+// ENCRYPTED_INDEX and ENCRYPTED_DATA streams, and the unencrypted streams of the
+// variant are replaced by those of its columns masked by nullify, being a PRESENT
+// stream of the root column that is all false and empty streams of its children.
+// The file is recorded as ZLIB compressed, the streams and tail being stored as
+// original chunks, so that the streams must be decrypted before they are
+// decompressed.
+//
+// The local key of each stripe is recorded as the encrypted key of that stripe in
+// encryptedKeys, or that of the last stripe to record one if it is nil, and is
+// decrypted by keys.
+func encryptVariant(t *testing.T, b []byte, root int, encryptedKeys [][]byte, keys map[string][]byte) []byte {
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.postScript.GetCompression() != proto.CompressionKind_NONE {
+		t.Fatal("encryptVariant requires an uncompressed file")
+	}
+	postScript := gproto.Clone(r.postScript).(*proto.PostScript)
+	postScript.Compression = proto.CompressionKind_ZLIB.Enum()
+	postScript.CompressionBlockSize = ptrUint64(1024)
+	footer := gproto.Clone(r.footer).(*proto.Footer)
+	types := footer.GetTypes()
+	last := maximumColumn(types, root)
+
+	out := append([]byte{}, b[:footer.GetHeaderLength()]...)
+	var localKey []byte
+	for i, stripe := range footer.GetStripes() {
+		stripeFooter, err := r.getStripeFooter(r.footer.GetStripes()[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encryptedKeys[i] != nil {
+			localKey = keys[string(encryptedKeys[i])]
+			stripe.EncryptedLocalKeys = [][]byte{encryptedKeys[i]}
+		}
+		if i == 0 {
+			stripe.EncryptStripeId = ptrUint64(1)
+		}
+		block, err := aes.NewCipher(localKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		variant := &proto.StripeEncryptionVariant{}
+		encodings := stripeFooter.GetColumns()
+		for column := root; column <= last; column++ {
+			variant.Encoding = append(variant.Encoding, encodings[column])
+			encodings[column] = &proto.ColumnEncoding{Kind: proto.ColumnEncoding_DIRECT_V2.Enum()}
+		}
+		// nullify masks every value of the variant as null.
+		var present bytes.Buffer
+		bw := NewBooleanWriter(&present)
+		for row := uint64(0); row < stripe.GetNumberOfRows(); row++ {
+			if err := bw.WriteBool(false); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := bw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		offset := int64(r.footer.GetStripes()[i].GetOffset())
+		data := make([][]byte, len(stripeFooter.GetStreams()))
+		for j, stream := range stripeFooter.GetStreams() {
+			data[j] = originalChunks(postScript, b[offset:offset+int64(stream.GetLength())])
+			offset += int64(stream.GetLength())
+		}
+		stripe.Offset = ptrUint64(uint64(len(out)))
+		var streams []*proto.Stream
+		for _, index := range []bool{true, false} {
+			start := len(out)
+			var encryptedData []byte
+			for j, stream := range stripeFooter.GetStreams() {
+				if isIndexStream(stream.GetKind()) != index {
+					continue
+				}
+				stream.Length = ptrUint64(uint64(len(data[j])))
+				if column := int(stream.GetColumn()); column < root || column > last {
+					streams = append(streams, stream)
+					out = append(out, data[j]...)
+					continue
+				}
+				encrypted := make([]byte, len(data[j]))
+				cipher.NewCTR(block, streamIV(stream.GetColumn(), stream.GetKind(), uint64(i+1))).XORKeyStream(encrypted, data[j])
+				variant.Streams = append(variant.Streams, stream)
+				encryptedData = append(encryptedData, encrypted...)
+			}
+			kind := proto.Stream_ENCRYPTED_INDEX
+			if !index {
+				masked := originalChunks(postScript, present.Bytes())
+				streams = append(streams, &proto.Stream{Column: ptrUint32(uint32(root)), Kind: proto.Stream_PRESENT.Enum(), Length: ptrUint64(uint64(len(masked)))})
+				out = append(out, masked...)
+				// The children of the root column hold no values.
+				for column := root + 1; column <= last; column++ {
+					for _, s := range dataStreams(types[column].GetKind(), proto.ColumnEncoding_DIRECT_V2) {
+						streams = append(streams, &proto.Stream{Column: ptrUint32(uint32(column)), Kind: s.kind.Enum(), Length: ptrUint64(0)})
+					}
+				}
+				kind = proto.Stream_ENCRYPTED_DATA
+			}
+			streams = append(streams, &proto.Stream{Column: ptrUint32(uint32(root)), Kind: kind.Enum(), Length: ptrUint64(uint64(len(encryptedData)))})
+			out = append(out, encryptedData...)
+			if index {
+				stripe.IndexLength = ptrUint64(uint64(len(out) - start))
+			} else {
+				stripe.DataLength = ptrUint64(uint64(len(out) - start))
+			}
+		}
+		stripeFooter.Streams = streams
+		stripeFooter.Encryption = []*proto.StripeEncryptionVariant{variant}
+		byt, err := gproto.Marshal(stripeFooter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byt = originalChunks(postScript, byt)
+		stripe.FooterLength = ptrUint64(uint64(len(byt)))
+		out = append(out, byt...)
+	}
+	footer.ContentLength = ptrUint64(uint64(len(out)))
+	footer.Encryption = &proto.Encryption{
+		Mask:        []*proto.DataMask{{Name: ptrStr("nullify"), Columns: []uint32{uint32(root)}}},
+		Key:         []*proto.EncryptionKey{{KeyName: ptrStr("pii"), KeyVersion: ptrUint32(1), Algorithm: proto.EncryptionAlgorithm_AES_CTR_128.Enum()}},
+		Variants:    []*proto.EncryptionVariant{{Root: ptrUint32(uint32(root)), Key: ptrUint32(0), EncryptedKey: []byte("file key")}},
+		KeyProvider: proto.KeyProviderKind_UNKNOWN.Enum(),
+	}
+
+	var tail []byte
+	for _, pb := range []gproto.Message{r.metadata, footer} {
+		byt, err := gproto.Marshal(pb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byt = originalChunks(postScript, byt)
+		if pb == r.metadata {
+			postScript.MetadataLength = ptrUint64(uint64(len(byt)))
+		} else {
+			postScript.FooterLength = ptrUint64(uint64(len(byt)))
+		}
+		tail = append(tail, byt...)
+	}
+	ps, err := gproto.Marshal(postScript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(append(append(out, tail...), ps...), byte(len(ps)))
+}
+
+func TestReaderDecryption(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint,pii:struct<name:string,age:int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected [][]interface{}
+	for stripe := 0; stripe < 3; stripe++ {
+		for i := 0; i < 100; i++ {
+			id := int64(stripe*100 + i)
+			name := fmt.Sprintf("name %d", id%7)
+			if err := w.Write(id, []interface{}{name, id % 90}); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, []interface{}{id, Struct{"name": name, "age": id % 90}})
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string][]byte{
+		"local key 1": []byte("0123456789abcdef"),
+		"local key 2": []byte("fedcba9876543210"),
+	}
+	// The second stripe reuses the local key of the first.
+	file := encryptVariant(t, buf.Bytes(), 2, [][]byte{[]byte("local key 1"), nil, []byte("local key 2")}, keys)
+
+	// readRows returns the rows of the file read using the provided options.
+	readRows := func(fns ...ReaderConfigFunc) ([][]interface{}, error) {
+		r, err := NewReader(bytes.NewReader(file), fns...)
+		if err != nil {
+			return nil, err
+		}
+		var rows [][]interface{}
+		c := r.Select("id", "pii")
+		for c.Stripes() {
+			for c.Next() {
+				rows = append(rows, append([]interface{}{}, c.Row()...))
+			}
+		}
+		return rows, c.Err()
+	}
+
+	var requested []string
+	rows, err := readRows(WithDecryptionKeyProvider(func(keyMetadata []byte) ([]byte, error) {
+		requested = append(requested, string(keyMetadata))
+		return keys[string(keyMetadata)], nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected the decrypted rows %v got %v", expected[:3], rows)
+	}
+	if !reflect.DeepEqual(requested, []string{"local key 1", "local key 2"}) {
+		t.Errorf("Test failed, expected each local key to be requested once got %q", requested)
+	}
+
+	// Without a key provider the masked values are read.
+	rows, err = readRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Test failed, expected %d masked rows got %d", len(expected), len(rows))
+	}
+	for i, row := range rows {
+		if row[0] != expected[i][0] || row[1] != nil {
+			t.Errorf("Test failed, expected the masked row [%v <nil>] got %v", expected[i][0], row)
+			break
+		}
+	}
+
+	// The encrypted streams are counted towards the size of the root column.
+	r, err := NewReader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := r.ColumnSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes[2].Index == 0 || sizes[2].Data == 0 {
+		t.Errorf("Test failed, expected the encrypted index and data of the variant got %+v", sizes[2])
+	}
+
+	errKMS := errors.New("access denied")
+	testCases := []struct {
+		provider func(keyMetadata []byte) ([]byte, error)
+		expected string
+	}{
+		{
+			provider: func([]byte) ([]byte, error) { return nil, errKMS },
+			expected: "access denied",
+		},
+		{
+			provider: func([]byte) ([]byte, error) { return []byte("short"), nil },
+			expected: "local key of 5 bytes, expected 16 bytes for AES_CTR_128",
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := readRows(WithDecryptionKeyProvider(tc.provider))
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("Test failed, expected an error containing %q got %v", tc.expected, err)
+		}
+	}
+	if _, err := readRows(WithDecryptionKeyProvider(testCases[0].provider)); !errors.Is(err, errKMS) {
+		t.Errorf("Test failed, expected the error of the key provider got %v", err)
+	}
+
+}
@@ -1,11 +1,42 @@
 package orc
 
 import (
-	"encoding/json"
+	"bytes"
+	"math"
 )
 
 type Float float32
 
+// MarshalJSON implements the json.Marshaler interface, encoding NaN and infinite
+// values as the strings "NaN", "Infinity" and "-Infinity".
 func (f Float) MarshalJSON() ([]byte, error) {
-	return json.Marshal(float32(f))
+	var buf bytes.Buffer
+	appendJSONFloat(&buf, float64(f), 32)
+	return buf.Bytes(), nil
+}
+
+// widenFloat returns f as a float64. Unlike a conversion, which quiets signaling
+// NaNs on some architectures, the sign and payload of a NaN are kept.
+func widenFloat(f float32) float64 {
+	if f == f {
+		return float64(f)
+	}
+	bits := math.Float32bits(f)
+	return math.Float64frombits(uint64(bits>>31)<<63 | 0x7ff<<52 | uint64(bits&0x7fffff)<<29)
+}
+
+// narrowFloat returns f as a float32, keeping the sign and the high bits of the
+// payload of a NaN such that it is the inverse of widenFloat.
+func narrowFloat(f float64) float32 {
+	if f == f {
+		return float32(f)
+	}
+	bits := math.Float64bits(f)
+	payload := uint32(bits>>29) & 0x7fffff
+	// A NaN with only low bits of payload must remain a NaN rather than an
+	// infinity.
+	if payload == 0 {
+		payload = 1
+	}
+	return math.Float32frombits(uint32(bits>>63)<<31 | 0xff<<23 | payload)
 }
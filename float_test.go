@@ -0,0 +1,102 @@
+package orc
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestFloatSpecialValues(t *testing.T) {
+
+	testCases := []struct {
+		double uint64
+		float  uint32
+		// widened is the float as a float64.
+		widened uint64
+	}{
+		// Zeros and infinities.
+		{double: 0, float: 0, widened: 0},
+		{double: 0x8000000000000000, float: 0x80000000, widened: 0x8000000000000000},
+		{double: 0x7ff0000000000000, float: 0x7f800000, widened: 0x7ff0000000000000},
+		{double: 0xfff0000000000000, float: 0xff800000, widened: 0xfff0000000000000},
+		// Quiet NaNs, with and without a payload.
+		{double: 0x7ff8000000000000, float: 0x7fc00000, widened: 0x7ff8000000000000},
+		{double: 0xfff8000000000001, float: 0xffc00001, widened: 0xfff8000020000000},
+		// Signaling NaNs.
+		{double: 0x7ff0000000000001, float: 0x7f800001, widened: 0x7ff0000020000000},
+		{double: 0x7ff4000000000000, float: 0x7fa00000, widened: 0x7ff4000000000000},
+		// The smallest subnormal and the largest finite values.
+		{double: 1, float: 1, widened: 0x36a0000000000000},
+		{double: 0x7fefffffffffffff, float: 0x7f7fffff, widened: 0x47efffffe0000000},
+	}
+
+	schema, err := ParseSchema("struct<double1:double,float1:float>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rowsBuf, batchBuf bytes.Buffer
+	rows, err := NewWriter(&rowsBuf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := NewWriter(&batchBuf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doubles, floats []float64
+	for _, tc := range testCases {
+		if err := rows.Write(math.Float64frombits(tc.double), math.Float32frombits(tc.float)); err != nil {
+			t.Fatal(err)
+		}
+		doubles = append(doubles, math.Float64frombits(tc.double))
+		floats = append(floats, math.Float64frombits(tc.widened))
+	}
+	// The values of a float column of a batch are narrowed to float32.
+	if err := batch.WriteBatch(&ColumnBatch{Columns: []*ColumnVector{{Float64s: doubles}, {Float64s: floats}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range [][]byte{rowsBuf.Bytes(), batchBuf.Bytes()} {
+		r, err := NewReader(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actual) != len(testCases) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(testCases), len(actual))
+		}
+		for i, tc := range testCases {
+			if bits := math.Float64bits(float64(actual[i][0].(Double))); bits != tc.double {
+				t.Errorf("Test failed, expected double %#016x got %#016x", tc.double, bits)
+			}
+			if bits := math.Float32bits(float32(actual[i][1].(Float))); bits != tc.float {
+				t.Errorf("Test failed, expected float %#08x got %#08x", tc.float, bits)
+			}
+		}
+
+		values, _, err := ReadFloat64Column(r, "float1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, tc := range testCases {
+			if bits := math.Float64bits(values[i]); bits != tc.widened {
+				t.Errorf("Test failed, expected float %#08x to widen to %#016x got %#016x", tc.float, tc.widened, bits)
+			}
+		}
+	}
+
+	// A NaN with only low bits of payload remains a NaN when narrowed.
+	if bits := math.Float32bits(narrowFloat(math.Float64frombits(0xfff0000000000001))); bits != 0xff800001 {
+		t.Errorf("Test failed, expected NaN 0xff800001 got %#08x", bits)
+	}
+
+}
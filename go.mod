@@ -0,0 +1,10 @@
+module code.simon-critchley.co.uk/orc
+
+go 1.18
+
+require (
+	github.com/golang/protobuf v1.5.2
+	github.com/golang/snappy v1.0.0
+)
+
+require google.golang.org/protobuf v1.26.0 // indirect
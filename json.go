@@ -0,0 +1,470 @@
+package orc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// JSONError is the error of a JSON record that cannot be parsed per the schema.
+type JSONError struct {
+	// Record is the number of the record, counted from 1.
+	Record int
+	// Path is the path of the value in error within the record, such as
+	// "a.b[2]", or empty if the error is of the record as a whole.
+	Path string
+	Err  error
+}
+
+func (e *JSONError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+	}
+	return fmt.Sprintf("record %d: %s: %v", e.Record, e.Path, e.Err)
+}
+
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// WriteORCFromJSON reads every JSON object of r, such as newline-delimited JSON,
+// and writes it as a row of an ORC file to w, configured by opts. The schema must
+// be a struct whose fields are named by the keys of the objects, other keys
+// being ignored. Null values and missing keys are null values. Numbers are parsed
+// per the type of their column without loss of precision, as are the strings
+// "NaN", "Infinity" and "-Infinity" in floating point columns. Objects are the
+// values of struct columns and, keyed by their field names, of map columns
+// with string keys, and arrays the values of list columns. Strings are parsed as
+// decimals, dates and timestamps as by WriteORCFromCSV, whereas string columns
+// also accept numbers and booleans as their text. Binary and union columns are
+// not supported. Errors parsing a value report the
+// number of its record and its path.
+func WriteORCFromJSON(w io.Writer, r io.Reader, schema *TypeDescription, opts ...WriterConfigFunc) error {
+	if schema.getCategory() != CategoryStruct {
+		return fmt.Errorf("cannot write JSON records to a schema of type %s", schema.getCategory())
+	}
+	if err := checkJSONType(schema, ""); err != nil {
+		return err
+	}
+	writer, err := NewWriter(w, append([]WriterConfigFunc{SetSchema(schema)}, opts...)...)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for record := 1; dec.More(); record++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return &JSONError{Record: record, Err: err}
+		}
+		if tok != json.Delim('{') {
+			return &JSONError{Record: record, Err: fmt.Errorf("expected an object, got %s", describeJSONToken(tok))}
+		}
+		row, err := writer.parseJSONValue(dec, schema, "", tok)
+		if err != nil {
+			var jsonErr *JSONError
+			if errors.As(err, &jsonErr) {
+				jsonErr.Record = record
+				return jsonErr
+			}
+			return &JSONError{Record: record, Err: err}
+		}
+		if err := writer.Write(row.([]interface{})...); err != nil {
+			return &JSONError{Record: record, Err: err}
+		}
+	}
+	return writer.Close()
+}
+
+// checkJSONType returns an error if values of td, at path, cannot be parsed from
+// JSON.
+func checkJSONType(td *TypeDescription, path string) error {
+	switch td.getCategory() {
+	case CategoryBinary, CategoryUnion:
+		return fmt.Errorf("cannot parse column %s of type %s from JSON", path, td.getCategory())
+	case CategoryMap:
+		switch key := td.children[0].getCategory(); key {
+		case CategoryString, CategoryVarchar, CategoryChar:
+		default:
+			return fmt.Errorf("cannot parse column %s of type map with %s keys from JSON", path, key)
+		}
+	}
+	for i, child := range td.children {
+		childPath := path
+		if td.getCategory() == CategoryStruct {
+			childPath = jsonFieldPath(path, td.fieldNames[i])
+		}
+		if err := checkJSONType(child, childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonFieldPath returns the path of field of the value at path.
+func jsonFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// describeJSONToken returns a description of tok for errors.
+func describeJSONToken(tok json.Token) string {
+	switch tok := tok.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "the number " + tok.String()
+	case string:
+		return "a string"
+	case json.Delim:
+		switch tok {
+		case '{':
+			return "an object"
+		case '[':
+			return "an array"
+		}
+	}
+	return fmt.Sprintf("%v", tok)
+}
+
+// parseJSONValue returns the value, starting with tok, at path of the next JSON
+// value of dec for writing to a column of the provided type.
+func (w *Writer) parseJSONValue(dec *json.Decoder, td *TypeDescription, path string, tok json.Token) (interface{}, error) {
+	if tok == nil {
+		return nil, nil
+	}
+	// expected returns the error of a value that is not of the kind expected.
+	expected := func(kind string) error {
+		return &JSONError{Path: path, Err: fmt.Errorf("expected %s, got %s", kind, describeJSONToken(tok))}
+	}
+	switch td.getCategory() {
+	case CategoryStruct:
+		if tok != json.Delim('{') {
+			return nil, expected("an object")
+		}
+		values := make([]interface{}, len(td.children))
+		err := parseJSONObject(dec, path, func(key string, tok json.Token) (bool, error) {
+			for i, name := range td.fieldNames {
+				if name == key {
+					var err error
+					values[i], err = w.parseJSONValue(dec, td.children[i], jsonFieldPath(path, key), tok)
+					return true, err
+				}
+			}
+			return false, nil
+		})
+		return values, err
+	case CategoryMap:
+		if tok != json.Delim('{') {
+			return nil, expected("an object")
+		}
+		entries := []MapEntry{}
+		err := parseJSONObject(dec, path, func(key string, tok json.Token) (bool, error) {
+			value, err := w.parseJSONValue(dec, td.children[1], fmt.Sprintf("%s[%q]", path, key), tok)
+			entries = append(entries, MapEntry{Key: key, Value: value})
+			return true, err
+		})
+		return entries, err
+	case CategoryList:
+		if tok != json.Delim('[') {
+			return nil, expected("an array")
+		}
+		values := []interface{}{}
+		for i := 0; dec.More(); i++ {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, &JSONError{Path: path, Err: err}
+			}
+			value, err := w.parseJSONValue(dec, td.children[0], fmt.Sprintf("%s[%d]", path, i), tok)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, &JSONError{Path: path, Err: err}
+		}
+		return values, nil
+	}
+
+	var value interface{}
+	var err error
+	switch tok := tok.(type) {
+	case json.Number:
+		value, err = w.parseJSONNumber(td, tok)
+	case string:
+		value, err = w.parseJSONString(td, tok)
+	case bool:
+		switch td.getCategory() {
+		case CategoryBoolean:
+			value = tok
+		case CategoryString, CategoryVarchar, CategoryChar:
+			value = strconv.FormatBool(tok)
+		default:
+			err = fmt.Errorf("cannot parse %s value from a boolean", td.getCategory())
+		}
+	default:
+		return nil, expected("a value of type " + td.getCategory().String())
+	}
+	if err != nil {
+		return nil, &JSONError{Path: path, Err: err}
+	}
+	return value, nil
+}
+
+// parseJSONObject reads the members of a JSON object of dec, whose opening
+// token has been read, calling fn with the key and first token of the value of
+// each. The fn returns whether it read the value, which is skipped otherwise.
+func parseJSONObject(dec *json.Decoder, path string, fn func(key string, tok json.Token) (bool, error)) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return &JSONError{Path: path, Err: err}
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return &JSONError{Path: path, Err: fmt.Errorf("expected a key, got %s", describeJSONToken(tok))}
+		}
+		if tok, err = dec.Token(); err != nil {
+			return &JSONError{Path: jsonFieldPath(path, key), Err: err}
+		}
+		read, err := fn(key, tok)
+		if err != nil {
+			return err
+		}
+		if !read {
+			if err := skipJSONValue(dec, tok); err != nil {
+				return &JSONError{Path: jsonFieldPath(path, key), Err: err}
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return &JSONError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// skipJSONValue reads the rest of the JSON value of dec starting with tok.
+func skipJSONValue(dec *json.Decoder, tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// parseJSONNumber returns the value of a JSON number for writing to a column of
+// the provided type.
+func (w *Writer) parseJSONNumber(td *TypeDescription, n json.Number) (interface{}, error) {
+	switch category := td.getCategory(); category {
+	case CategoryByte:
+		i, err := strconv.ParseInt(n.String(), 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		return int8(i), nil
+	case CategoryShort:
+		return strconv.ParseInt(n.String(), 10, 16)
+	case CategoryInt:
+		return strconv.ParseInt(n.String(), 10, 32)
+	case CategoryLong:
+		return strconv.ParseInt(n.String(), 10, 64)
+	case CategoryFloat:
+		f, err := strconv.ParseFloat(n.String(), 32)
+		if err != nil {
+			return nil, err
+		}
+		return float32(f), nil
+	case CategoryDouble:
+		return strconv.ParseFloat(n.String(), 64)
+	case CategoryDecimal:
+		return toDecimal(n.String(), td.precision, td.scale, w.decimalRounding)
+	case CategoryString, CategoryVarchar, CategoryChar:
+		return n.String(), nil
+	default:
+		return nil, fmt.Errorf("cannot parse %s value from a number", category)
+	}
+}
+
+// parseJSONString returns the value of a JSON string for writing to a column of
+// the provided type.
+func (w *Writer) parseJSONString(td *TypeDescription, s string) (interface{}, error) {
+	switch category := td.getCategory(); category {
+	case CategoryString, CategoryVarchar, CategoryChar:
+		return s, nil
+	case CategoryFloat, CategoryDouble:
+		var f float64
+		switch s {
+		case "NaN":
+			f = math.NaN()
+		case "Infinity":
+			f = math.Inf(1)
+		case "-Infinity":
+			f = math.Inf(-1)
+		default:
+			return nil, fmt.Errorf("cannot parse %s value from a string", category)
+		}
+		if category == CategoryFloat {
+			return float32(f), nil
+		}
+		return f, nil
+	case CategoryDecimal, CategoryDate, CategoryTimestamp, CategoryTimestampInstant:
+		return w.parseCSVField(td, s, CSVOptions{DateLayout: csvDateLayout})
+	default:
+		return nil, fmt.Errorf("cannot parse %s value from a string", category)
+	}
+}
+
+// jsonType is the type of the JSON values at a path of the records read by
+// InferJSONSchema.
+type jsonType struct {
+	// category is the zero Category if only null values have been read.
+	category Category
+	// fields are the names of the fields of an object in the order in which
+	// they were first read, and children their types, including the type of the
+	// elements of an array.
+	fields   []string
+	children []*jsonType
+}
+
+// InferJSONSchema returns a schema of the first n JSON objects of r, or of every
+// object if n is 0, for writing them by WriteORCFromJSON. Each object is a struct
+// with a field for every key read, in the order first read. Integers are
+// inferred as bigint, other numbers as double and strings, as well as values
+// read only as nulls, as string, whereas booleans, objects and arrays are of
+// their own types. Values of different types at a path are of the type that
+// holds both, a double for integers and other numbers and otherwise a string
+// for scalar values, or an error if either is an object or an array.
+func InferJSONSchema(r io.Reader, n int) (*TypeDescription, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	root := &jsonType{category: CategoryStruct}
+	for record := 1; (n == 0 || record <= n) && dec.More(); record++ {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, &JSONError{Record: record, Err: err}
+		}
+		if tok != json.Delim('{') {
+			return nil, &JSONError{Record: record, Err: fmt.Errorf("expected an object, got %s", describeJSONToken(tok))}
+		}
+		if err := root.infer(dec, "", tok); err != nil {
+			var jsonErr *JSONError
+			if errors.As(err, &jsonErr) {
+				jsonErr.Record = record
+				return nil, jsonErr
+			}
+			return nil, &JSONError{Record: record, Err: err}
+		}
+	}
+	return NewTypeDescription(root.transforms()...)
+}
+
+// infer merges the type of the JSON value at path of dec, starting with tok, into
+// t.
+func (t *jsonType) infer(dec *json.Decoder, path string, tok json.Token) error {
+	var category Category
+	switch tok := tok.(type) {
+	case nil:
+		return nil
+	case bool:
+		category = CategoryBoolean
+	case json.Number:
+		category = CategoryDouble
+		if _, err := tok.Int64(); err == nil {
+			category = CategoryLong
+		}
+	case string:
+		category = CategoryString
+	case json.Delim:
+		category = CategoryList
+		if tok == json.Delim('{') {
+			category = CategoryStruct
+		}
+	}
+	switch {
+	case t.category == Category{} || t.category == category:
+		t.category = category
+	case t.category.isPrimitive && category.isPrimitive:
+		if t.category == CategoryLong && category == CategoryDouble ||
+			t.category == CategoryDouble && category == CategoryLong {
+			t.category = CategoryDouble
+		} else {
+			t.category = CategoryString
+		}
+	default:
+		return &JSONError{Path: path, Err: fmt.Errorf("cannot infer a type of both %s and %s values", t.category, category)}
+	}
+
+	switch category {
+	case CategoryStruct:
+		return parseJSONObject(dec, path, func(key string, tok json.Token) (bool, error) {
+			var child *jsonType
+			for i, name := range t.fields {
+				if name == key {
+					child = t.children[i]
+				}
+			}
+			if child == nil {
+				child = &jsonType{}
+				t.fields = append(t.fields, key)
+				t.children = append(t.children, child)
+			}
+			return true, child.infer(dec, jsonFieldPath(path, key), tok)
+		})
+	case CategoryList:
+		if len(t.children) == 0 {
+			t.children = append(t.children, &jsonType{})
+		}
+		for i := 0; dec.More(); i++ {
+			tok, err := dec.Token()
+			if err != nil {
+				return &JSONError{Path: path, Err: err}
+			}
+			if err := t.children[0].infer(dec, fmt.Sprintf("%s[%d]", path, i), tok); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return &JSONError{Path: path, Err: err}
+		}
+	}
+	return nil
+}
+
+// transforms returns the functions creating a TypeDescription of t.
+func (t *jsonType) transforms() []TypeDescriptionTransformFunc {
+	switch t.category {
+	case Category{}:
+		return []TypeDescriptionTransformFunc{SetCategory(CategoryString)}
+	case CategoryStruct:
+		fns := []TypeDescriptionTransformFunc{SetCategory(CategoryStruct)}
+		for i, name := range t.fields {
+			fns = append(fns, AddField(name, t.children[i].transforms()...))
+		}
+		return fns
+	case CategoryList:
+		if len(t.children) == 0 {
+			return []TypeDescriptionTransformFunc{SetCategory(CategoryList), AddChild(SetCategory(CategoryString))}
+		}
+		return []TypeDescriptionTransformFunc{SetCategory(CategoryList), AddChild(t.children[0].transforms()...)}
+	}
+	return []TypeDescriptionTransformFunc{SetCategory(t.category)}
+}
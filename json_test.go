@@ -0,0 +1,255 @@
+package orc
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteORCFromJSON(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint,tiny:tinyint,flag:boolean,price:decimal(20,2),ratio:double,name:string,day:date,created:timestamp,point:struct<x:int,y:int>,tags:array<string>,attrs:map<string,float>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := `{"id": 9007199254740993, "tiny": -128, "flag": true, "price": 123456789012345678.25, "ratio": 0.25, "name": "plain", "day": "2020-02-29", "created": "2020-02-29T12:30:45.123456789Z", "point": {"x": 1, "y": -2}, "tags": ["a", null, "b"], "attrs": {"z": 1.5, "a": null}}
+{"id": 2, "name": 42, "ratio": "-Infinity", "point": {"y": 3, "extra": [1, {"two": 2}]}, "tags": [], "attrs": {}, "ignored": {"a": [1, 2]}}
+{"id": 3, "tiny": null, "flag": false, "price": "-0.01", "name": true, "point": null, "tags": null, "attrs": null}
+
+{}
+`
+	expected := [][]interface{}{
+		{int64(9007199254740993), int8(-128), true, "123456789012345678.25", Double(0.25), "plain", "2020-02-29", time.Date(2020, 2, 29, 12, 30, 45, 123456789, time.UTC), Struct{"x": int64(1), "y": int64(-2)}, []interface{}{"a", nil, "b"}, []MapEntry{{Key: "z", Value: Float(1.5)}, {Key: "a", Value: nil}}},
+		{int64(2), nil, nil, nil, Double(math.Inf(-1)), "42", nil, nil, Struct{"x": nil, "y": int64(3)}, []interface{}{}, []MapEntry{}},
+		{int64(3), nil, false, "-0.01", nil, "true", nil, nil, nil, nil, nil},
+		{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteORCFromJSON(&buf, strings.NewReader(input), schema); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("Test failed, expected %d rows got %d", len(expected), len(actual))
+	}
+	for i, row := range actual {
+		for j, value := range row {
+			switch v := value.(type) {
+			case Decimal:
+				value = v.String()
+			case Date:
+				value = v.Format("2006-01-02")
+			case time.Time:
+				value = v.UTC()
+			}
+			if !reflect.DeepEqual(value, expected[i][j]) {
+				t.Errorf("Test failed, row %d column %s expected %v (%T) got %v (%T)", i, schema.fieldNames[j], expected[i][j], expected[i][j], value, value)
+			}
+		}
+	}
+
+}
+
+func TestWriteORCFromJSONErrors(t *testing.T) {
+
+	testCases := []struct {
+		schema   string
+		input    string
+		expected string
+	}{
+		{
+			schema:   "struct<a:int>",
+			input:    `{"a": 1} {"a": 1.5}`,
+			expected: `record 2: a: strconv.ParseInt: parsing "1.5": invalid syntax`,
+		},
+		{
+			schema:   "struct<a:tinyint>",
+			input:    `{"a": 128}`,
+			expected: `record 1: a: strconv.ParseInt: parsing "128": value out of range`,
+		},
+		{
+			schema:   "struct<a:struct<b:array<int>>>",
+			input:    `{"a": {"b": [1, 2, "3"]}}`,
+			expected: "record 1: a.b[2]: cannot parse int value from a string",
+		},
+		{
+			schema:   "struct<a:map<string,struct<b:boolean>>>",
+			input:    `{"a": {"x": {"b": true}, "y": {"b": 1}}}`,
+			expected: `record 1: a["y"].b: cannot parse boolean value from a number`,
+		},
+		{
+			schema:   "struct<a:array<int>>",
+			input:    `{"a": {"b": 1}}`,
+			expected: "record 1: a: expected an array, got an object",
+		},
+		{
+			schema:   "struct<a:string>",
+			input:    `{"a": ["x"]}`,
+			expected: "record 1: a: expected a value of type string, got an array",
+		},
+		{
+			schema:   "struct<a:decimal(4,2)>",
+			input:    `{"a": 1.5} {"a": 123.45}`,
+			expected: "record 2: a: decimal value 123.45 exceeds precision 4",
+		},
+		{
+			schema:   "struct<a:date>",
+			input:    `{"a": 20200101}`,
+			expected: "record 1: a: cannot parse date value from a number",
+		},
+		{
+			schema:   "struct<a:array<binary>>",
+			expected: "cannot parse column a of type binary from JSON",
+		},
+		{
+			schema:   "struct<a:varchar(3)>",
+			input:    `{"a": "abc"} {"a": "abcd"}`,
+			expected: "record 2: ",
+		},
+		{
+			schema:   "struct<a:int>",
+			input:    `{"a": 1} [1]`,
+			expected: "record 2: expected an object, got an array",
+		},
+		{
+			schema:   "struct<a:int>",
+			input:    `{"a": 1} {"a": 2`,
+			expected: "record 2: unexpected end of JSON input",
+		},
+		{
+			schema:   "struct<a:int>",
+			input:    `{"a": 1, "b": [}`,
+			expected: "record 1: b: invalid character '}' looking for beginning of value",
+		},
+		{
+			schema:   "struct<a:map<int,string>>",
+			expected: "cannot parse column a of type map with int keys from JSON",
+		},
+		{
+			schema:   "struct<a:struct<b:uniontype<int,string>>>",
+			expected: "cannot parse column a.b of type uniontype from JSON",
+		},
+		{
+			schema:   "int",
+			expected: "cannot write JSON records to a schema of type int",
+		},
+	}
+
+	for _, tc := range testCases {
+		schema, err := ParseSchema(tc.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		err = WriteORCFromJSON(&buf, strings.NewReader(tc.input), schema)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.expected) {
+			t.Errorf("Test failed, expected error with prefix %q got %v", tc.expected, err)
+		}
+	}
+
+	schema, err := ParseSchema("struct<a:struct<b:int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = WriteORCFromJSON(&bytes.Buffer{}, strings.NewReader(`{"a": {"b": 1}}
+{"a": {"b": 2}}
+{"a": {"b": "x"}}`), schema)
+	var jsonErr *JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Record != 3 || jsonErr.Path != "a.b" {
+		t.Errorf("Test failed, expected a JSONError of record 3 at a.b got %#v", err)
+	}
+
+}
+
+func TestInferJSONSchema(t *testing.T) {
+
+	testCases := []struct {
+		input    string
+		n        int
+		expected string
+		err      string
+	}{
+		{
+			input:    `{"id": 1, "name": "a", "flag": true, "point": {"x": 1.5}, "tags": ["a"], "empty": [], "none": null}`,
+			expected: "struct<id:bigint,name:string,flag:boolean,point:struct<x:double>,tags:array<string>,empty:array<string>,none:string>",
+		},
+		{
+			input:    `{"a": 1, "b": 1, "c": true} {"a": 1.5, "b": "x", "d": {"e": null}} {"d": {"e": 2, "f": [1, 2.5]}}`,
+			expected: "struct<a:double,b:string,c:boolean,d:struct<e:bigint,f:array<double>>>",
+		},
+		{
+			input:    `{"a": 1} {"b": 2} {"c": "not read"`,
+			n:        2,
+			expected: "struct<a:bigint,b:bigint>",
+		},
+		{
+			input: `{"a": 1} {"a": {"b": 1}}`,
+			err:   "record 2: a: cannot infer a type of both bigint and struct values",
+		},
+		{
+			input: `{"a": [1]} {"a": [[1]]}`,
+			err:   "record 2: a[0]: cannot infer a type of both bigint and array values",
+		},
+		{
+			input: `{"a": 1} 2`,
+			err:   "record 2: expected an object, got the number 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		schema, err := InferJSONSchema(strings.NewReader(tc.input), tc.n)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Test failed, expected error %q got %v", tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := schema.String(); actual != tc.expected {
+			t.Errorf("Test failed, expected schema %s got %s", tc.expected, actual)
+		}
+	}
+
+	// Records are written to their inferred schema.
+	input := `{"id": 1, "tags": ["a"]} {"id": 2.5, "extra": {"b": true}}`
+	schema, err := InferJSONSchema(strings.NewReader(input), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteORCFromJSON(&buf, strings.NewReader(input), schema); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{
+		{Double(1), []interface{}{"a"}, nil},
+		{Double(2.5), nil, Struct{"b": true}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+}
@@ -1,7 +1,10 @@
 package orc
 
 import (
+	"io"
 	"os"
+
+	"code.simon-critchley.co.uk/orc/proto"
 )
 
 // Version is the version of the ORC file.
@@ -9,15 +12,52 @@ type Version struct {
 	name  string
 	major uint32
 	minor uint32
+	// rle is the version of the run length encoding of integers written.
+	rle int
 }
 
 var (
 	// Version0_11 is an ORC file version compatible with Hive 0.11.
-	Version0_11 = Version{"0.11", 0, 11}
+	Version0_11 = Version{"0.11", 0, 11, 1}
 	// Version0_12 is an ORC file version compatible with Hive 0.12.
-	Version0_12 = Version{"0.12", 0, 12}
+	Version0_12 = Version{"0.12", 0, 12, 2}
 )
 
+// versions are the ORC file versions that can be written.
+var versions = []Version{Version0_11, Version0_12}
+
+// directEncoding returns the direct column encoding kind for the run length
+// encoding version of the version. Version 0.11 predates run length encoding
+// version 2.
+func (v Version) directEncoding() proto.ColumnEncoding_Kind {
+	if v.rle == 1 {
+		return proto.ColumnEncoding_DIRECT
+	}
+	return proto.ColumnEncoding_DIRECT_V2
+}
+
+// dictionaryEncoding returns the dictionary column encoding kind for the run
+// length encoding version of the version.
+func (v Version) dictionaryEncoding() proto.ColumnEncoding_Kind {
+	if v.rle == 1 {
+		return proto.ColumnEncoding_DICTIONARY
+	}
+	return proto.ColumnEncoding_DICTIONARY_V2
+}
+
+// supports returns whether columns of the category can be written to files of
+// the version. The date, varchar, char and timestamp with local time zone types
+// postdate version 0.11.
+func (v Version) supports(category Category) bool {
+	if v.name == Version0_11.name {
+		switch category {
+		case CategoryDate, CategoryVarchar, CategoryChar, CategoryTimestampInstant:
+			return false
+		}
+	}
+	return true
+}
+
 type fileReader struct {
 	*os.File
 }
@@ -31,11 +71,23 @@ func (f fileReader) Size() int64 {
 	return stats.Size()
 }
 
+// sizedReaderAt wraps an io.ReaderAt of a known size so that it
+// implements the SizedReaderAt interface.
+type sizedReaderAt struct {
+	io.ReaderAt
+	size int64
+}
+
+// Size returns the size of the underlying source in bytes.
+func (s sizedReaderAt) Size() int64 {
+	return s.size
+}
+
 // Open opens the file at the provided filepath.
-func Open(filepath string) (*Reader, error) {
+func Open(filepath string, fns ...ReaderConfigFunc) (*Reader, error) {
 	f, err := os.Open(filepath)
 	if err != nil {
 		return nil, err
 	}
-	return NewReader(fileReader{f})
+	return NewReader(fileReader{f}, fns...)
 }
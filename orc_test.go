@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -49,14 +50,14 @@ func TestReadExamples(t *testing.T) {
 			expected: "TestOrcFile.testSnappy.jsn.gz",
 			example:  "TestOrcFile.testSnappy.orc",
 		},
-		// {
-		// 	expected: "TestOrcFile.testDate2038.jsn.gz",
-		// 	example:  "TestOrcFile.testDate2038.orc",
-		// },
-		// {
-		// 	expected: "TestOrcFile.testDate1900.jsn.gz",
-		// 	example:  "TestOrcFile.testDate1900.orc",
-		// },
+		{
+			expected: "TestOrcFile.testDate2038.jsn.gz",
+			example:  "TestOrcFile.testDate2038.orc",
+		},
+		{
+			expected: "TestOrcFile.testDate1900.jsn.gz",
+			example:  "TestOrcFile.testDate1900.orc",
+		},
 		{
 			expected: "TestOrcFile.columnProjection.jsn.gz",
 			example:  "TestOrcFile.columnProjection.orc",
@@ -92,8 +93,16 @@ func TestReadExamples(t *testing.T) {
 						switch ty := val.(type) {
 						case Date:
 							rowData[col] = ty.UTC().Format("2006-01-02")
+						case Decimal:
+							rowData[col] = ty.Float64()
 						case time.Time:
-							rowData[col] = ty.UTC().Format("2006-01-02 15:04:05.0")
+							// Timestamps are formatted in the timezone of
+							// the writer as java.sql.Timestamp values.
+							ts := ty.Format("2006-01-02 15:04:05.999999999")
+							if !strings.Contains(ts, ".") {
+								ts += ".0"
+							}
+							rowData[col] = ts
 						case []byte:
 							values := make([]uint, len(ty))
 							for j := range ty {
@@ -0,0 +1,518 @@
+// Package orcarrow converts between ORC files and Apache Arrow records. It is a
+// separate package so that only programs which use Arrow depend on it.
+//
+// ORC types map to Arrow types as follows: boolean, tinyint, smallint, int,
+// bigint, float and double to the equivalent boolean, integer and floating point
+// types, string, varchar and char to utf8, binary to binary, date to date32,
+// timestamps to timestamp[ns, tz=UTC], decimal(p,s) to decimal128(p, s), and
+// arrays, maps and structs to lists, maps and structs of the mapped types. Union
+// columns are not supported.
+package orcarrow
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/decimal128"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+)
+
+// Schema returns the Arrow schema of the fields of a struct type, such as the
+// schema of an ORC file.
+func Schema(td *orc.TypeDescription) (*arrow.Schema, error) {
+	if td.Category() != orc.CategoryStruct {
+		return nil, fmt.Errorf("cannot convert a schema of type %s to an Arrow schema", td.Category())
+	}
+	return schema(td.Columns(), td.Children())
+}
+
+// schema returns the Arrow schema of the named columns of the provided types.
+func schema(names []string, types []*orc.TypeDescription) (*arrow.Schema, error) {
+	fields, err := fields(names, types)
+	if err != nil {
+		return nil, err
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// fields returns the nullable Arrow fields of the named columns of the provided
+// types.
+func fields(names []string, types []*orc.TypeDescription) ([]arrow.Field, error) {
+	fields := make([]arrow.Field, len(types))
+	for i, td := range types {
+		dt, err := DataType(td)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", names[i], err)
+		}
+		fields[i] = arrow.Field{Name: names[i], Type: dt, Nullable: true}
+	}
+	return fields, nil
+}
+
+// DataType returns the Arrow data type of the values of an ORC type.
+func DataType(td *orc.TypeDescription) (arrow.DataType, error) {
+	switch td.Category() {
+	case orc.CategoryBoolean:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case orc.CategoryByte:
+		return arrow.PrimitiveTypes.Int8, nil
+	case orc.CategoryShort:
+		return arrow.PrimitiveTypes.Int16, nil
+	case orc.CategoryInt:
+		return arrow.PrimitiveTypes.Int32, nil
+	case orc.CategoryLong:
+		return arrow.PrimitiveTypes.Int64, nil
+	case orc.CategoryFloat:
+		return arrow.PrimitiveTypes.Float32, nil
+	case orc.CategoryDouble:
+		return arrow.PrimitiveTypes.Float64, nil
+	case orc.CategoryString, orc.CategoryVarchar, orc.CategoryChar:
+		return arrow.BinaryTypes.String, nil
+	case orc.CategoryBinary:
+		return arrow.BinaryTypes.Binary, nil
+	case orc.CategoryDate:
+		return arrow.FixedWidthTypes.Date32, nil
+	case orc.CategoryTimestamp, orc.CategoryTimestampInstant:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, nil
+	case orc.CategoryDecimal:
+		return &arrow.Decimal128Type{Precision: int32(td.Precision()), Scale: int32(td.Scale())}, nil
+	case orc.CategoryList:
+		elem, err := DataType(td.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		return arrow.ListOf(elem), nil
+	case orc.CategoryMap:
+		key, err := DataType(td.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		value, err := DataType(td.Children()[1])
+		if err != nil {
+			return nil, err
+		}
+		return arrow.MapOf(key, value), nil
+	case orc.CategoryStruct:
+		fields, err := fields(td.Columns(), td.Children())
+		if err != nil {
+			return nil, err
+		}
+		return arrow.StructOf(fields...), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", td.Category())
+}
+
+// TypeDescription returns the ORC struct type of the fields of an Arrow schema,
+// the inverse of Schema other than for varchar and char columns, which are
+// returned as strings, and timestamps of any unit or time zone, which are
+// returned as timestamp columns.
+func TypeDescription(s *arrow.Schema) (*orc.TypeDescription, error) {
+	fns := []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryStruct)}
+	for _, field := range s.Fields() {
+		fieldFns, err := transforms(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %v", field.Name, err)
+		}
+		fns = append(fns, orc.AddField(field.Name, fieldFns...))
+	}
+	return orc.NewTypeDescription(fns...)
+}
+
+// transforms returns the functions which build the ORC type of an Arrow data type.
+func transforms(dt arrow.DataType) ([]orc.TypeDescriptionTransformFunc, error) {
+	switch dt := dt.(type) {
+	case *arrow.BooleanType:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryBoolean)}, nil
+	case *arrow.Int8Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryByte)}, nil
+	case *arrow.Int16Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryShort)}, nil
+	case *arrow.Int32Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryInt)}, nil
+	case *arrow.Int64Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryLong)}, nil
+	case *arrow.Float32Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryFloat)}, nil
+	case *arrow.Float64Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryDouble)}, nil
+	case *arrow.StringType:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryString)}, nil
+	case *arrow.BinaryType:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryBinary)}, nil
+	case *arrow.Date32Type:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryDate)}, nil
+	case *arrow.TimestampType:
+		return []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryTimestamp)}, nil
+	case *arrow.Decimal128Type:
+		return []orc.TypeDescriptionTransformFunc{
+			orc.SetCategory(orc.CategoryDecimal),
+			orc.SetDecimal(int(dt.Precision), int(dt.Scale)),
+		}, nil
+	case *arrow.MapType:
+		key, err := transforms(dt.KeyType())
+		if err != nil {
+			return nil, err
+		}
+		value, err := transforms(dt.ItemType())
+		if err != nil {
+			return nil, err
+		}
+		return []orc.TypeDescriptionTransformFunc{
+			orc.SetCategory(orc.CategoryMap),
+			orc.AddChild(key...),
+			orc.AddChild(value...),
+		}, nil
+	case *arrow.ListType:
+		elem, err := transforms(dt.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return []orc.TypeDescriptionTransformFunc{
+			orc.SetCategory(orc.CategoryList),
+			orc.AddChild(elem...),
+		}, nil
+	case *arrow.StructType:
+		fns := []orc.TypeDescriptionTransformFunc{orc.SetCategory(orc.CategoryStruct)}
+		for _, field := range dt.Fields() {
+			fieldFns, err := transforms(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			fns = append(fns, orc.AddField(field.Name, fieldFns...))
+		}
+		return fns, nil
+	}
+	return nil, fmt.Errorf("unsupported Arrow type %s", dt)
+}
+
+// ReadArrow reads the rows of the next stripe of the cursor into an Arrow record
+// of the columns selected by Cursor.Select, with the schema returned by Schema. It
+// returns io.EOF once there are no more stripes. The returned record must be
+// released by the caller.
+func ReadArrow(c *orc.Cursor, mem memory.Allocator) (arrow.Record, error) {
+	names, columns := c.Columns()
+	s, err := schema(names, columns)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Stripes() {
+		if err := c.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	b := array.NewRecordBuilder(mem, s)
+	defer b.Release()
+	for c.Next() {
+		for i, value := range c.Row() {
+			if err := appendValue(b.Field(i), columns[i], value); err != nil {
+				return nil, fmt.Errorf("column %s: %v", names[i], err)
+			}
+		}
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return b.NewRecord(), nil
+}
+
+// appendValue appends a value read from a column of type td to the builder of
+// its Arrow data type.
+func appendValue(b array.Builder, td *orc.TypeDescription, value interface{}) error {
+	if value == nil {
+		b.AppendNull()
+		return nil
+	}
+	switch b := b.(type) {
+	case *array.BooleanBuilder:
+		if v, ok := value.(bool); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int8Builder:
+		if v, ok := value.(int8); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Int16Builder:
+		if v, ok := value.(int64); ok {
+			b.Append(int16(v))
+			return nil
+		}
+	case *array.Int32Builder:
+		if v, ok := value.(int64); ok {
+			b.Append(int32(v))
+			return nil
+		}
+	case *array.Int64Builder:
+		if v, ok := value.(int64); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Float32Builder:
+		if v, ok := value.(orc.Float); ok {
+			b.Append(float32(v))
+			return nil
+		}
+	case *array.Float64Builder:
+		if v, ok := value.(orc.Double); ok {
+			b.Append(float64(v))
+			return nil
+		}
+	case *array.StringBuilder:
+		switch v := value.(type) {
+		case string:
+			b.Append(v)
+			return nil
+		case []byte:
+			b.BinaryBuilder.Append(v)
+			return nil
+		}
+	case *array.BinaryBuilder:
+		if v, ok := value.([]byte); ok {
+			b.Append(v)
+			return nil
+		}
+	case *array.Date32Builder:
+		if v, ok := value.(orc.Date); ok {
+			b.Append(arrow.Date32FromTime(v.Time))
+			return nil
+		}
+	case *array.TimestampBuilder:
+		if v, ok := value.(time.Time); ok {
+			// UnixNano is undefined outside of the years 1678 to 2262.
+			if v.Before(minTimestamp) || v.After(maxTimestamp) {
+				return fmt.Errorf("timestamp %v out of range of nanoseconds", v)
+			}
+			b.Append(arrow.Timestamp(v.UnixNano()))
+			return nil
+		}
+	case *array.Decimal128Builder:
+		if v, ok := value.(orc.Decimal); ok {
+			num, err := decimal(v, td.Scale())
+			if err != nil {
+				return err
+			}
+			b.Append(num)
+			return nil
+		}
+	case *array.ListBuilder:
+		if v, ok := value.([]interface{}); ok {
+			b.Append(true)
+			for _, elem := range v {
+				if err := appendValue(b.ValueBuilder(), td.Children()[0], elem); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *array.MapBuilder:
+		if v, ok := value.([]orc.MapEntry); ok {
+			b.Append(true)
+			for _, entry := range v {
+				if err := appendValue(b.KeyBuilder(), td.Children()[0], entry.Key); err != nil {
+					return err
+				}
+				if err := appendValue(b.ItemBuilder(), td.Children()[1], entry.Value); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case *array.StructBuilder:
+		if v, ok := value.(orc.Struct); ok {
+			b.Append(true)
+			for i, name := range td.Columns() {
+				if err := appendValue(b.FieldBuilder(i), td.Children()[i], v[name]); err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected value of type %T for Arrow type %s", value, b.Type())
+}
+
+var (
+	minTimestamp = time.Unix(0, math.MinInt64)
+	maxTimestamp = time.Unix(0, math.MaxInt64)
+)
+
+// decimal returns the unscaled value of d at the provided scale, returning an
+// error if it cannot be represented exactly or exceeds 128 bits.
+func decimal(d orc.Decimal, scale int) (decimal128.Num, error) {
+	abs := d.Abs
+	if abs == nil {
+		abs = new(big.Int)
+	}
+	if exp := int64(scale) - d.Exp; exp > 0 {
+		abs = new(big.Int).Mul(abs, new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	} else if exp < 0 {
+		var rem big.Int
+		abs, _ = new(big.Int).QuoRem(abs, new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil), &rem)
+		if rem.Sign() != 0 {
+			return decimal128.Num{}, fmt.Errorf("decimal value %s exceeds scale %d", d, scale)
+		}
+	}
+	if abs.BitLen() > 127 {
+		return decimal128.Num{}, fmt.Errorf("decimal value %s exceeds 128 bits", d)
+	}
+	return decimal128.FromBigInt(abs), nil
+}
+
+// WriteArrow writes the rows of an Arrow record to w, whose schema must match that
+// of the record as returned by TypeDescription. The integer, floating point,
+// string and boolean columns of the record are written as a column batch without
+// boxing their values, other columns are converted to the values accepted by
+// Writer.Write. Binary columns cannot be written, as the writer does not support
+// them.
+func WriteArrow(w *orc.Writer, rec arrow.Record) error {
+	batch := &orc.ColumnBatch{Columns: make([]*orc.ColumnVector, rec.NumCols())}
+	for i, column := range rec.Columns() {
+		v, err := vector(column)
+		if err != nil {
+			return fmt.Errorf("column %s: %v", rec.ColumnName(i), err)
+		}
+		batch.Columns[i] = v
+	}
+	return w.WriteBatch(batch)
+}
+
+// vector returns the ColumnVector of the values of an Arrow array.
+func vector(arr arrow.Array) (*orc.ColumnVector, error) {
+	n := arr.Len()
+	v := &orc.ColumnVector{}
+	if arr.NullN() > 0 {
+		v.Nulls = make([]bool, n)
+		for i := range v.Nulls {
+			v.Nulls[i] = arr.IsNull(i)
+		}
+	}
+	switch a := arr.(type) {
+	case *array.Int8:
+		v.Int64s = make([]int64, n)
+		for i := range v.Int64s {
+			v.Int64s[i] = int64(a.Value(i))
+		}
+	case *array.Int16:
+		v.Int64s = make([]int64, n)
+		for i := range v.Int64s {
+			v.Int64s[i] = int64(a.Value(i))
+		}
+	case *array.Int32:
+		v.Int64s = make([]int64, n)
+		for i := range v.Int64s {
+			v.Int64s[i] = int64(a.Value(i))
+		}
+	case *array.Int64:
+		v.Int64s = a.Int64Values()
+	case *array.Float32:
+		v.Float64s = make([]float64, n)
+		for i := range v.Float64s {
+			v.Float64s[i] = float64(a.Value(i))
+		}
+	case *array.Float64:
+		v.Float64s = a.Float64Values()
+	case *array.String:
+		v.Strings = make([]string, n)
+		for i := range v.Strings {
+			v.Strings[i] = a.Value(i)
+		}
+	case *array.Boolean:
+		v.Bools = make([]bool, n)
+		for i := range v.Bools {
+			v.Bools[i] = a.Value(i)
+		}
+	default:
+		v.Values = make([]interface{}, n)
+		for i := range v.Values {
+			value, err := value(arr, i)
+			if err != nil {
+				return nil, err
+			}
+			v.Values[i] = value
+		}
+	}
+	return v, nil
+}
+
+// value returns the value at index i of an Arrow array as accepted by
+// Writer.Write.
+func value(arr arrow.Array, i int) (interface{}, error) {
+	if arr.IsNull(i) {
+		return nil, nil
+	}
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return a.Value(i), nil
+	case *array.Int8:
+		return a.Value(i), nil
+	case *array.Int16:
+		return int64(a.Value(i)), nil
+	case *array.Int32:
+		return int64(a.Value(i)), nil
+	case *array.Int64:
+		return a.Value(i), nil
+	case *array.Float32:
+		return a.Value(i), nil
+	case *array.Float64:
+		return a.Value(i), nil
+	case *array.String:
+		return a.Value(i), nil
+	case *array.Binary:
+		return a.Value(i), nil
+	case *array.Date32:
+		return orc.Date{Time: a.Value(i).ToTime()}, nil
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return a.Value(i).ToTime(unit), nil
+	case *array.Decimal128:
+		scale := a.DataType().(*arrow.Decimal128Type).Scale
+		return orc.Decimal{Abs: a.Value(i).BigInt(), Exp: int64(scale)}, nil
+	case *array.Map:
+		start, end := a.ValueOffsets(i)
+		keys, items := a.Keys(), a.Items()
+		entries := make([]orc.MapEntry, 0, end-start)
+		for j := int(start); j < int(end); j++ {
+			key, err := value(keys, j)
+			if err != nil {
+				return nil, err
+			}
+			item, err := value(items, j)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, orc.MapEntry{Key: key, Value: item})
+		}
+		return entries, nil
+	case *array.List:
+		start, end := a.ValueOffsets(i)
+		values := a.ListValues()
+		elems := make([]interface{}, 0, end-start)
+		for j := int(start); j < int(end); j++ {
+			elem, err := value(values, j)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return elems, nil
+	case *array.Struct:
+		fields := make([]interface{}, a.NumField())
+		for j := range fields {
+			field, err := value(a.Field(j), i)
+			if err != nil {
+				return nil, err
+			}
+			fields[j] = field
+		}
+		return fields, nil
+	}
+	return nil, fmt.Errorf("unsupported Arrow type %s", arr.DataType())
+}
@@ -0,0 +1,238 @@
+package orcarrow
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc"
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+)
+
+// readAll returns the rows of the selected columns of an ORC file.
+func readAll(t *testing.T, r *orc.Reader, columns []string) [][]interface{} {
+	t.Helper()
+	c := r.Select(columns...)
+	var rows [][]interface{}
+	for c.Stripes() {
+		for c.Next() {
+			rows = append(rows, c.Row())
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// roundTrip reads the selected columns of an ORC file into Arrow records and
+// writes them to a new ORC file, returning the records and the new file.
+func roundTrip(t *testing.T, mem memory.Allocator, r *orc.Reader, columns []string) ([]arrow.Record, *orc.Reader) {
+	t.Helper()
+	c := r.Select(columns...)
+	var records []arrow.Record
+	for {
+		rec, err := ReadArrow(c, mem)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) == 0 {
+		t.Fatal("Test failed, expected at least one record")
+	}
+
+	schema, err := TypeDescription(records[0].Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := orc.NewWriter(&buf, orc.SetSchema(schema), orc.WithWriterTimezone(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if err := WriteArrow(w, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := orc.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records, written
+}
+
+func TestRoundTripNested(t *testing.T) {
+
+	r, err := orc.Open("../examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// Binary columns cannot be written, so bytes1 is not selected.
+	var columns []string
+	for _, column := range r.Schema().Columns() {
+		if column != "bytes1" {
+			columns = append(columns, column)
+		}
+	}
+
+	expected := readAll(t, r, columns)
+
+	r, err = orc.Open("../examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	records, written := roundTrip(t, mem, r, columns)
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+
+	expectedSchema := "schema:\n  fields: 11\n" +
+		"    - boolean1: type=bool, nullable\n" +
+		"    - byte1: type=int8, nullable\n" +
+		"    - short1: type=int16, nullable\n" +
+		"    - int1: type=int32, nullable\n" +
+		"    - long1: type=int64, nullable\n" +
+		"    - float1: type=float32, nullable\n" +
+		"    - double1: type=float64, nullable\n" +
+		"    - string1: type=utf8, nullable\n" +
+		"    - middle: type=struct<list: list<item: struct<int1: int32, string1: utf8>, nullable>>, nullable\n" +
+		"    - list: type=list<item: struct<int1: int32, string1: utf8>, nullable>, nullable\n" +
+		"    - map: type=map<utf8, struct<int1: int32, string1: utf8>>, nullable"
+	if actual := records[0].Schema().String(); actual != expectedSchema {
+		t.Errorf("Test failed, expected schema:\n%s\ngot:\n%s", expectedSchema, actual)
+	}
+	if actual, expected := written.Schema().String(), "struct<boolean1:boolean,byte1:tinyint,short1:smallint,int1:int,long1:bigint,float1:float,double1:double,string1:string,middle:struct<list:array<struct<int1:int,string1:string>>>,list:array<struct<int1:int,string1:string>>,map:map<string,struct<int1:int,string1:string>>>"; actual != expected {
+		t.Errorf("Test failed, expected schema %s got %s", expected, actual)
+	}
+
+	var rows int64
+	for _, rec := range records {
+		rows += rec.NumRows()
+	}
+	if rows != int64(len(expected)) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), rows)
+	}
+	actual := readAll(t, written, columns)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+}
+
+func TestRoundTripNulls(t *testing.T) {
+
+	schema, err := orc.ParseSchema("struct<i:int,s:string,d:decimal(10,3),day:date,ts:timestamp,b:boolean,x:double,st:struct<a:bigint,l:array<string>>,m:map<string,smallint>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2021, 3, 4, 5, 6, 7, 890123456, time.UTC)
+	day := orc.Date{Time: time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)}
+	rows := [][]interface{}{
+		{int64(1), "a", orc.Decimal{Abs: big.NewInt(-12345), Exp: 3}, day, ts, true, 1.5, []interface{}{int64(2), []interface{}{"x", nil}}, []orc.MapEntry{{Key: "k", Value: int64(3)}, {Key: "n", Value: nil}}},
+		{nil, nil, nil, nil, nil, nil, nil, nil, nil},
+		{int64(-3), "", orc.Decimal{Abs: big.NewInt(7), Exp: 3}, day, ts, false, -2.0, []interface{}{nil, nil}, []orc.MapEntry{}},
+		{int64(4), "d", nil, nil, ts, nil, nil, []interface{}{nil, []interface{}{}}, nil},
+	}
+	var buf bytes.Buffer
+	w, err := orc.NewWriter(&buf, orc.SetSchema(schema), orc.WithWriterTimezone(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := orc.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := schema.Columns()
+	expected := readAll(t, r, columns)
+
+	r, err = orc.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+	records, written := roundTrip(t, mem, r, columns)
+	defer func() {
+		for _, rec := range records {
+			rec.Release()
+		}
+	}()
+	if len(records) != 1 {
+		t.Fatalf("Test failed, expected 1 record got %d", len(records))
+	}
+	rec := records[0]
+
+	// The null bitmaps match the nulls of each column.
+	expectedNulls := []int{1, 1, 2, 2, 1, 2, 2, 1, 2}
+	for i, column := range rec.Columns() {
+		if column.NullN() != expectedNulls[i] {
+			t.Errorf("Test failed, column %s expected %d nulls got %d", columns[i], expectedNulls[i], column.NullN())
+		}
+	}
+	st := rec.Column(7).(*array.Struct)
+	if a := st.Field(0); a.NullN() != 3 {
+		t.Errorf("Test failed, expected 3 nulls in st.a got %d", a.NullN())
+	}
+	if l := st.Field(1); l.NullN() != 2 || l.(*array.List).ListValues().NullN() != 1 {
+		t.Errorf("Test failed, expected 2 nulls in st.l and 1 in its elements got %d and %d", l.NullN(), l.(*array.List).ListValues().NullN())
+	}
+	if d := rec.Column(2).(*array.Decimal128); d.Value(0).BigInt().Int64() != -12345 {
+		t.Errorf("Test failed, expected decimal -12345 got %v", d.Value(0).BigInt())
+	}
+	if d := rec.Column(3).(*array.Date32); d.Value(0) != -1 {
+		t.Errorf("Test failed, expected date32 -1 got %d", d.Value(0))
+	}
+	if v := rec.Column(4).(*array.Timestamp).Value(0); int64(v) != ts.UnixNano() {
+		t.Errorf("Test failed, expected timestamp %d got %d", ts.UnixNano(), v)
+	}
+
+	actual := readAll(t, written, columns)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+}
+
+func TestDataTypeUnsupported(t *testing.T) {
+
+	schema, err := orc.ParseSchema("struct<a:int,b:struct<c:uniontype<int,string>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Schema(schema); err == nil || err.Error() != "column b: column c: unsupported type uniontype" {
+		t.Errorf("Test failed, expected an unsupported type error got %v", err)
+	}
+	s := arrow.NewSchema([]arrow.Field{{Name: "a", Type: arrow.FixedWidthTypes.Time32s}}, nil)
+	if _, err := TypeDescription(s); err == nil || err.Error() != "column a: unsupported Arrow type time32[s]" {
+		t.Errorf("Test failed, expected an unsupported Arrow type error got %v", err)
+	}
+
+}
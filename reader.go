@@ -1,7 +1,9 @@
 package orc
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/cipher"
 	"errors"
 	"fmt"
 	"io"
@@ -16,10 +18,17 @@ var (
 	errNoPostScript = errors.New("postscript is nil")
 	errNoFooter     = errors.New("footer is nil")
 	errNoTypes      = errors.New("no types")
+	// ErrTruncatedFooter is returned by NewReader when the lengths of the
+	// postscript, footer and metadata recorded at the end of the file exceed
+	// the size of the file.
+	ErrTruncatedFooter = errors.New("file tail is larger than the file")
 )
 
 const (
 	maxPostScriptSize = 256
+	// DefaultReadBufferSize is the default size of the buffer used when reading
+	// the streams of a stripe from the underlying source.
+	DefaultReadBufferSize = 256 * 1024
 )
 
 type SizedReaderAt interface {
@@ -35,13 +44,272 @@ type Reader struct {
 	currentStripeOffset int
 	stripesLength       int
 	columns             map[int]*proto.ColumnEncoding
+	writerTimezone      string
+	rawPostScript       []byte
+	rawFooter           []byte
 	schema              *TypeDescription
+	rawUnknownColumns   bool
+	columnHooks         map[int]*columnHook
+	acidUnwrap          bool
+	acidSchema          *TypeDescription
+	readBufferSize      int
+	// limit is the maximum number of rows read by a Cursor, if limited.
+	limit   int64
+	limited bool
+	// codec overrides the codec of the compression kind of the postscript.
+	codec          CompressionCodec
+	allocator      Allocator
+	stringsAsBytes bool
+	// calendar overrides the calendar recorded in the footer, if set.
+	calendar Calendar
+	// assumeUTCTimestamps overrides the writer timezone of timestamp columns.
+	assumeUTCTimestamps bool
+	// stripeFilter decides which stripes are read by their statistics, if set.
+	stripeFilter   func(stripe int, stats []ColumnStatistics) bool
+	utf8Validation UTF8Validation
+	// decryptionKeyProvider decrypts the local keys of encrypted columns, if set,
+	// the ciphers of which are cached by their encrypted keys in localKeys.
+	decryptionKeyProvider func(keyMetadata []byte) ([]byte, error)
+	localKeys             map[string]cipher.Block
 }
 
-func NewReader(r SizedReaderAt) (*Reader, error) {
+// ReaderConfigFunc is a function that configures a Reader.
+type ReaderConfigFunc func(r *Reader) error
+
+// WithRawUnknownColumns sets whether columns of an unknown type are read as a
+// RawColumn, containing the decompressed bytes of their streams, rather than
+// returning an error for the whole file.
+func WithRawUnknownColumns(enabled bool) ReaderConfigFunc {
+	return func(r *Reader) error {
+		r.rawUnknownColumns = enabled
+		return nil
+	}
+}
+
+// WithAcidUnwrap sets whether the rows of Hive ACID tables are unwrapped. When
+// enabled and the file has the layout of an ACID table, the schema of the Reader
+// is that of the inner row struct whilst the ACID metadata of each row is
+// available from Cursor.AcidEvent.
+func WithAcidUnwrap(enabled bool) ReaderConfigFunc {
+	return func(r *Reader) error {
+		r.acidUnwrap = enabled
+		return nil
+	}
+}
+
+// WithStringsAsBytes sets whether the values of string, varchar and char columns
+// are read as []byte values rather than strings. The bytes are not copied from the
+// decompressed streams of the stripe, so they must not be modified, but they remain
+// valid after reading further rows.
+func WithStringsAsBytes(enabled bool) ReaderConfigFunc {
+	return func(r *Reader) error {
+		r.stringsAsBytes = enabled
+		return nil
+	}
+}
+
+// UTF8Validation determines how the values of string, varchar and char columns that
+// are not valid UTF-8 are read.
+type UTF8Validation int
+
+const (
+	// UTF8ValidationOff reads values as they are stored, whether or not they are
+	// valid UTF-8. It is the default.
+	UTF8ValidationOff UTF8Validation = iota
+	// UTF8ValidationError fails the read at the first value that is not valid
+	// UTF-8, reporting its column and row.
+	UTF8ValidationError
+	// UTF8ValidationReplace replaces each run of bytes that are not valid UTF-8
+	// with the replacement character U+FFFD.
+	UTF8ValidationReplace
+)
+
+// WithValidateUTF8 sets how the values of string, varchar and char columns that are
+// not valid UTF-8 are read. Values are validated as they are read as strings, so
+// reading them as []byte values with WithStringsAsBytes skips validation.
+func WithValidateUTF8(mode UTF8Validation) ReaderConfigFunc {
+	return func(r *Reader) error {
+		switch mode {
+		case UTF8ValidationOff, UTF8ValidationError, UTF8ValidationReplace:
+		default:
+			return fmt.Errorf("invalid UTF-8 validation mode %d", mode)
+		}
+		r.utf8Validation = mode
+		return nil
+	}
+}
+
+// WithReadBufferSize sets the size in bytes of the buffer through which the
+// streams of a stripe are read from the underlying source, each read of the
+// source filling the buffer. Larger buffers reduce the number of reads, which
+// benefits sources with a high latency per read. The buffer is never larger
+// than the stream being read.
+func WithReadBufferSize(n int) ReaderConfigFunc {
+	return func(r *Reader) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid read buffer size %d, must be greater than zero", n)
+		}
+		r.readBufferSize = n
+		return nil
+	}
+}
+
+// WithCompressionCodec sets the CompressionCodec used to decompress the file in
+// place of the codec of the compression kind recorded in its postscript, including
+// the footer and metadata read by NewReader. It allows files whose postscript
+// records the wrong compression kind to be salvaged.
+func WithCompressionCodec(codec CompressionCodec) ReaderConfigFunc {
+	return func(r *Reader) error {
+		if codec == nil {
+			return fmt.Errorf("invalid compression codec, must not be nil")
+		}
+		r.codec = codec
+		return nil
+	}
+}
+
+// WithAllocator sets the Allocator providing the scratch buffers used whilst
+// reading the file, such as the chunks of snappy compressed streams, in place of
+// the default Allocator which pools them in a sync.Pool. It applies to the codec
+// of the compression kind of the postscript rather than one set by
+// WithCompressionCodec.
+func WithAllocator(a Allocator) ReaderConfigFunc {
+	return func(r *Reader) error {
+		if a == nil {
+			return fmt.Errorf("invalid allocator, must not be nil")
+		}
+		r.allocator = a
+		return nil
+	}
+}
+
+// WithStripeFilter sets a function that decides from the statistics of each stripe
+// in the file metadata whether the stripe is read, the statistics being indexed by
+// column ID. Cursors skip each stripe for which it returns false without reading
+// its stripe footer or streams, such that a predicate on the minimum and maximum
+// values of a column need only read the stripes that may match it. Stripes of files
+// without stripe statistics are always read.
+func WithStripeFilter(fn func(stripe int, stats []ColumnStatistics) bool) ReaderConfigFunc {
+	return func(r *Reader) error {
+		if fn == nil {
+			return fmt.Errorf("invalid stripe filter, must not be nil")
+		}
+		r.stripeFilter = fn
+		return nil
+	}
+}
+
+// includeStripe returns whether the stripe at index stripe is read, per the stripe
+// filter.
+func (r *Reader) includeStripe(stripe int) bool {
+	stripeStats := r.metadata.GetStripeStats()
+	if r.stripeFilter == nil || stripe >= len(stripeStats) {
+		return true
+	}
+	colStats := stripeStats[stripe].GetColStats()
+	stats := make([]ColumnStatistics, len(colStats))
+	for i, s := range colStats {
+		stats[i] = BaseStatistics{s}
+	}
+	return r.stripeFilter(stripe, stats)
+}
+
+// WithCalendar sets the calendar in which the dates and timestamps of the file
+// were recorded in place of the calendar recorded in its footer, for files whose
+// writers did not record the calendar they used. Values recorded in the hybrid
+// Julian and Gregorian calendar are read as the same date and time of day in the
+// proleptic Gregorian calendar of the time package.
+func WithCalendar(calendar Calendar) ReaderConfigFunc {
+	return func(r *Reader) error {
+		switch calendar {
+		case CalendarJulianGregorian, CalendarProlepticGregorian:
+			r.calendar = calendar
+			return nil
+		}
+		return fmt.Errorf("invalid calendar %s", calendar)
+	}
+}
+
+// WithAssumeUTCTimestamps sets whether the values of timestamp columns are read as
+// written in UTC whatever the writer timezone recorded in the stripe footers, for
+// files such as those written by Spark with spark.sql.orc.useUTCTimestamp set,
+// which store timestamps relative to UTC but may record the local timezone of the
+// writer. Without it, the offset of the recorded timezone from UTC is applied a
+// second time. Timestamps are then returned in UTC. Files recording UTC as their
+// writer timezone are read correctly without the option.
+func WithAssumeUTCTimestamps(enabled bool) ReaderConfigFunc {
+	return func(r *Reader) error {
+		r.assumeUTCTimestamps = enabled
+		return nil
+	}
+}
+
+// Calendar returns the calendar in which the dates and timestamps of the file were
+// recorded, as set by WithCalendar or otherwise as recorded in the footer. Files
+// without a calendar are in the hybrid Julian and Gregorian calendar unless they
+// were written by this package.
+func (r *Reader) Calendar() Calendar {
+	if r.calendar != 0 {
+		return r.calendar
+	}
+	switch r.footer.GetCalendar() {
+	case proto.CalendarKind_PROLEPTIC_GREGORIAN:
+		return CalendarProlepticGregorian
+	case proto.CalendarKind_JULIAN_GREGORIAN:
+		return CalendarJulianGregorian
+	}
+	if r.footer.Writer != nil && r.footer.GetWriter() == WriterGo {
+		return CalendarProlepticGregorian
+	}
+	return CalendarJulianGregorian
+}
+
+// WithCodec sets the CompressionCodec used to decompress the stripes read after
+// it is called in place of the codec of the compression kind recorded in the
+// postscript, as for WithCompressionCodec, for experimenting with the codecs of
+// an open file. The footer and metadata already read by NewReader are unchanged.
+// A nil codec removes the override.
+func (r *Reader) WithCodec(codec CompressionCodec) *Reader {
+	r.codec = codec
+	return r
+}
+
+// Limit sets the maximum number of rows read by the Cursors of the Reader, after
+// which Cursor.Next and Cursor.Stripes return false, such as for previewing the
+// first rows of a file. The streams of limited Cursors are decompressed as their
+// rows are read rather than whole stripes at a time, so that neither the row
+// groups after the last row read nor the stripes after it are decompressed. A
+// negative n removes the limit.
+func (r *Reader) Limit(n int64) *Reader {
+	r.limit = n
+	r.limited = n >= 0
+	return r
+}
+
+// rewound returns a copy of the Reader positioned at the first stripe, sharing the
+// source, footer and configuration of r but not its stripe position or the column
+// encodings of its current stripe, neither of which are changed by reading from
+// the copy.
+func (r *Reader) rewound() *Reader {
+	rr := *r
+	rr.currentStripeOffset = 0
+	rr.columns = make(map[int]*proto.ColumnEncoding)
+	return &rr
+}
+
+func NewReader(r SizedReaderAt, fns ...ReaderConfigFunc) (*Reader, error) {
 	reader := &Reader{
-		r:       r,
-		columns: make(map[int]*proto.ColumnEncoding),
+		r:              r,
+		columns:        make(map[int]*proto.ColumnEncoding),
+		columnHooks:    make(map[int]*columnHook),
+		readBufferSize: DefaultReadBufferSize,
+	}
+	// Apply any ReaderConfigFuncs to the new reader.
+	for _, fn := range fns {
+		err := fn(reader)
+		if err != nil {
+			return nil, err
+		}
 	}
 	err := reader.extractMetaInfoFromFooter()
 	if err != nil {
@@ -50,7 +318,65 @@ func NewReader(r SizedReaderAt) (*Reader, error) {
 	return reader, nil
 }
 
+// ReadFooter reads the footer and postscript of the ORC file contained in ra,
+// which is size bytes long, without reading any of its stripes. It allows tools
+// to catalog the schema and statistics of a file from its tail alone.
+func ReadFooter(ra io.ReaderAt, size int64) (*proto.Footer, *proto.PostScript, error) {
+	r, err := NewReader(sizedReaderAt{ra, size})
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.footer, r.postScript, nil
+}
+
+// RawPostScript returns the protobuf encoded postscript of the file, for tools
+// that parse it with a different version of the ORC protobuf definitions. The
+// returned bytes must not be modified.
+func (r *Reader) RawPostScript() []byte {
+	return r.rawPostScript
+}
+
+// RawFooter returns the decompressed, protobuf encoded footer of the file, for
+// tools that parse it with a different version of the ORC protobuf definitions
+// or that need fields which the Reader does not expose. The returned bytes must
+// not be modified.
+func (r *Reader) RawFooter() []byte {
+	return r.rawFooter
+}
+
+// PostScript returns the postscript of the file, which must not be modified.
+func (r *Reader) PostScript() *proto.PostScript {
+	return r.postScript
+}
+
+// Footer returns the footer of the file, which must not be modified.
+func (r *Reader) Footer() *proto.Footer {
+	return r.footer
+}
+
+// Metadata returns the metadata of the file holding the statistics of each stripe,
+// which must not be modified. It is empty for files written without metadata.
+func (r *Reader) Metadata() *proto.Metadata {
+	return r.metadata
+}
+
+// StripeFooter reads and returns the footer of the stripe with the provided index,
+// which holds the streams and column encodings of the stripe.
+func (r *Reader) StripeFooter(stripe int) (*proto.StripeFooter, error) {
+	stripes, err := r.getStripes()
+	if err != nil {
+		return nil, err
+	}
+	if stripe < 0 || stripe >= len(stripes) {
+		return nil, fmt.Errorf("stripe %d out of range, the file has %d stripes", stripe, len(stripes))
+	}
+	return r.getStripeFooter(stripes[stripe])
+}
+
 func (r *Reader) getCodec() (CompressionCodec, error) {
+	if r.codec != nil {
+		return r.codec, nil
+	}
 	if r.postScript == nil {
 		return nil, errNoPostScript
 	}
@@ -61,7 +387,7 @@ func (r *Reader) getCodec() (CompressionCodec, error) {
 	case proto.CompressionKind_ZLIB:
 		return CompressionZlib{}, nil
 	case proto.CompressionKind_SNAPPY:
-		return CompressionSnappy{}, nil
+		return CompressionSnappy{Allocator: r.allocator}, nil
 	default:
 		return nil, fmt.Errorf("unsupported compression kind %s", compressionKind)
 	}
@@ -71,9 +397,127 @@ func (r *Reader) Schema() *TypeDescription {
 	return r.schema
 }
 
+// ColumnHook is a function that is called with every value of a column after it
+// has been decoded, along with the index of the value within the column. The value
+// it returns replaces the decoded value, whereas returning an error stops reading.
+type ColumnHook func(rowIndex int, value interface{}) (interface{}, error)
+
+// SetColumnHook sets the ColumnHook fn called with the values of column, which may
+// be the name of a nested field such as "a.b", replacing any existing hook. It
+// returns an error if the column does not exist.
+func (r *Reader) SetColumnHook(column string, fn ColumnHook) error {
+	td, err := r.schema.GetField(column)
+	if err != nil {
+		return err
+	}
+	r.columnHooks[td.getID()] = &columnHook{
+		column: column,
+		fn:     fn,
+	}
+	return nil
+}
+
+// UserMetadata returns the user metadata stored in the footer of the file mapped
+// from each key to its binary value.
+func (r *Reader) UserMetadata() map[string][]byte {
+	metadata := make(map[string][]byte)
+	for _, item := range r.footer.GetMetadata() {
+		metadata[item.GetName()] = item.GetValue()
+	}
+	return metadata
+}
+
+// IsColumnAllNull returns whether column, which may be the name of a nested field
+// such as "a.b", has no values other than nulls across the file, as recorded by
+// the file statistics, so that predicates on the column need not be evaluated. A
+// column counts as all null if the statistics record no values and either record
+// a null or the file has rows. It returns an error if the column does not exist
+// or the file has no statistics of it.
+func (r *Reader) IsColumnAllNull(column string) (bool, error) {
+	td, err := r.schema.GetField(column)
+	if err != nil {
+		return false, err
+	}
+	statistics := r.footer.GetStatistics()
+	id := td.getID()
+	if id >= len(statistics) || statistics[id].NumberOfValues == nil {
+		return false, fmt.Errorf("no statistics of column %s", column)
+	}
+	if statistics[id].GetNumberOfValues() != 0 {
+		return false, nil
+	}
+	return statistics[id].GetHasNull() || r.footer.GetNumberOfRows() > 0, nil
+}
+
+// ColumnSize is the number of bytes, as stored in the file and so after any
+// compression, of the streams of a column across every stripe by their kind.
+type ColumnSize struct {
+	// Data is the size of the DATA and SECONDARY streams, and of the encrypted
+	// data of encryption variants rooted at the column.
+	Data int64
+	// Present is the size of the PRESENT streams.
+	Present int64
+	// Length is the size of the LENGTH streams.
+	Length int64
+	// Dictionary is the size of the DICTIONARY_DATA and DICTIONARY_COUNT streams.
+	Dictionary int64
+	// Index is the size of the ROW_INDEX and bloom filter streams, and of the
+	// encrypted indexes of encryption variants rooted at the column.
+	Index int64
+	// Other is the size of streams of any other kind, such as those of kinds
+	// added to the specification after this package was written.
+	Other int64
+}
+
+// Total returns the number of bytes of every stream of the column.
+func (s ColumnSize) Total() int64 {
+	return s.Data + s.Present + s.Length + s.Dictionary + s.Index + s.Other
+}
+
+// ColumnSizes returns the ColumnSize of each column that has streams, by column
+// ID, as recorded in the stripe footers without decoding the streams. The sizes
+// of a nested column exclude those of its children.
+func (r *Reader) ColumnSizes() (map[int]ColumnSize, error) {
+	stripes, err := r.getStripes()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[int]ColumnSize)
+	for _, stripe := range stripes {
+		stripeFooter, err := r.getStripeFooter(stripe)
+		if err != nil {
+			return nil, err
+		}
+		for _, stream := range stripeFooter.GetStreams() {
+			column := int(stream.GetColumn())
+			size := sizes[column]
+			length := int64(stream.GetLength())
+			switch stream.GetKind() {
+			case proto.Stream_DATA, proto.Stream_SECONDARY, proto.Stream_ENCRYPTED_DATA:
+				size.Data += length
+			case proto.Stream_PRESENT:
+				size.Present += length
+			case proto.Stream_LENGTH:
+				size.Length += length
+			case proto.Stream_DICTIONARY_DATA, proto.Stream_DICTIONARY_COUNT:
+				size.Dictionary += length
+			case proto.Stream_ROW_INDEX, proto.Stream_BLOOM_FILTER, proto.Stream_BLOOM_FILTER_UTF8, proto.Stream_ENCRYPTED_INDEX:
+				size.Index += length
+			default:
+				size.Other += length
+			}
+			sizes[column] = size
+		}
+	}
+	return sizes, nil
+}
+
 func (r *Reader) extractMetaInfoFromFooter() error {
 
 	size := int(r.r.Size())
+	if size == 0 {
+		return fmt.Errorf("%w: the file is empty", ErrTruncatedFooter)
+	}
 	psPlusByte := maxPostScriptSize + 1
 	if psPlusByte > size {
 		psPlusByte = size
@@ -88,12 +532,24 @@ func (r *Reader) extractMetaInfoFromFooter() error {
 	}
 	psLen := int(postScriptBytes[len(postScriptBytes)-1])
 	psOffset := len(postScriptBytes) - 1 - psLen
+	if psOffset < 0 {
+		return fmt.Errorf("%w: postscript length %d exceeds the file size %d", ErrTruncatedFooter, psLen, size)
+	}
+	r.rawPostScript = postScriptBytes[psOffset : psOffset+psLen]
 	r.postScript = &proto.PostScript{}
-	err = gproto.Unmarshal(postScriptBytes[psOffset:psOffset+psLen], r.postScript)
+	err = gproto.Unmarshal(r.rawPostScript, r.postScript)
 	if err != nil {
 		return err
 	}
 
+	// Check that the footer and metadata lie within the file before allocating
+	// their byte slices, comparing each length first so that the sum does not
+	// overflow.
+	tail := uint64(size - psLen - 1)
+	if fl, ml := r.postScript.GetFooterLength(), r.postScript.GetMetadataLength(); fl > tail || ml > tail || fl+ml > tail {
+		return fmt.Errorf("%w: footer length %d and metadata length %d exceed the %d bytes before the postscript", ErrTruncatedFooter, fl, ml, tail)
+	}
+
 	// Get the offset and length of the footer and preallocate a byte slice.
 	footerLength := int(r.postScript.GetFooterLength())
 	footerBytes := make([]byte, footerLength, footerLength)
@@ -124,30 +580,22 @@ func (r *Reader) extractMetaInfoFromFooter() error {
 		return err
 	}
 
-	// Decode the metadata into a new byte slice.
-	metadataDecoder := codec.Decoder(bytes.NewReader(metadataBytes))
-	decodedMetadataBytes, err := ioutil.ReadAll(metadataDecoder)
+	// Decode and unmarshal the footer and store against the reader. A valid
+	// footer always contains at least the root type.
+	r.footer = &proto.Footer{}
+	r.rawFooter, err = unmarshalTail(codec, footerBytes, r.footer, func() bool {
+		return len(r.footer.GetTypes()) > 0
+	})
 	if err != nil {
 		return err
 	}
 
-	// Unmarshal the metadata and store against the reader.
+	// Decode and unmarshal the metadata and store against the reader. When present
+	// the metadata usually contains the statistics of every stripe.
 	r.metadata = &proto.Metadata{}
-	err = gproto.Unmarshal(decodedMetadataBytes, r.metadata)
-	if err != nil {
-		return err
-	}
-
-	// Decode the footer into a new byte slice.
-	footerDecoder := codec.Decoder(bytes.NewReader(footerBytes))
-	decodedFooterBytes, err := ioutil.ReadAll(footerDecoder)
-	if err != nil {
-		return err
-	}
-
-	// Unmarshal the footer and store against the reader.
-	r.footer = &proto.Footer{}
-	err = gproto.Unmarshal(decodedFooterBytes, r.footer)
+	_, err = unmarshalTail(codec, metadataBytes, r.metadata, func() bool {
+		return metadataLength == 0 || len(r.metadata.GetStripeStats()) == len(r.footer.GetStripes())
+	})
 	if err != nil {
 		return err
 	}
@@ -163,10 +611,53 @@ func (r *Reader) extractMetaInfoFromFooter() error {
 		return err
 	}
 
+	// Surface just the row struct of ACID tables.
+	if r.acidUnwrap && isAcidSchema(r.schema) {
+		r.acidSchema = r.schema
+		r.schema = r.schema.children[len(acidFieldNames)-1]
+	}
+
 	return nil
 
 }
 
+// unmarshalTail decodes b using codec and unmarshals the result into pb,
+// returning the decoded bytes. Some old writers left the file tail uncompressed
+// whilst declaring a compression kind in the postscript, therefore, if this fails
+// or the result is not plausible then b is unmarshalled as uncompressed instead.
+// Plausibility only chooses between the two: the uncompressed result is used if
+// it is plausible or the declared codec failed, otherwise the result of the
+// declared codec is kept.
+func unmarshalTail(codec CompressionCodec, b []byte, pb gproto.Message, plausible func() bool) ([]byte, error) {
+	decoded, err := unmarshalDecoded(codec.Decoder(bytes.NewReader(b)), pb)
+	if err == nil && plausible() {
+		return decoded, nil
+	}
+	if _, ok := codec.(CompressionNone); ok {
+		return decoded, err
+	}
+	pb.Reset()
+	if rawErr := gproto.Unmarshal(b, pb); rawErr == nil && (err != nil || plausible()) {
+		return b, nil
+	}
+	pb.Reset()
+	if err != nil {
+		return nil, err
+	}
+	return decoded, gproto.Unmarshal(decoded, pb)
+}
+
+func unmarshalDecoded(r io.Reader, pb gproto.Message) ([]byte, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := gproto.Unmarshal(b, pb); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 func (r *Reader) getStreams(included ...int) (streamMap, error) {
 	stripes, err := r.getStripes()
 	if err != nil {
@@ -174,57 +665,58 @@ func (r *Reader) getStreams(included ...int) (streamMap, error) {
 	}
 
 	r.stripesLength = len(stripes)
+	// Skip the stripes excluded by their statistics without reading their
+	// stripe footers.
+	for r.currentStripeOffset < r.stripesLength && !r.includeStripe(r.currentStripeOffset) {
+		r.currentStripeOffset++
+	}
 	if r.currentStripeOffset >= r.stripesLength {
 		return nil, io.EOF
 	}
 
-	stripe := stripes[r.currentStripeOffset]
+	stripeIndex := r.currentStripeOffset
+	stripe := stripes[stripeIndex]
 	// Increment the currentStripeOffset so that the next call returns the next stripe.
 	r.currentStripeOffset++
 
-	// Unmarshal the stripe footer
-	stripeOffset := int64(stripe.GetOffset())
-	stripeFooterOffset := stripeOffset + int64(stripe.GetIndexLength()+stripe.GetDataLength())
-	stripeFooterLength := int64(stripe.GetFooterLength())
-	stripeFooterReader := io.NewSectionReader(r.r, stripeFooterOffset, stripeFooterLength)
-	stripeFooterBytes := make([]byte, stripeFooterLength, stripeFooterLength)
-
-	_, err = io.ReadFull(stripeFooterReader, stripeFooterBytes)
-	if err != nil {
-		return nil, err
-	}
-	codec, err := r.getCodec()
+	stripeFooter, err := r.getStripeFooter(stripe)
 	if err != nil {
 		return nil, err
 	}
-
-	// Decode the footer into a new byte slice.
-	stripeFooterDecoder := codec.Decoder(bytes.NewReader(stripeFooterBytes))
-	decodedStripeFooterBytes, err := ioutil.ReadAll(stripeFooterDecoder)
+	streamsProto, columns, err := r.stripeStreams(stripeIndex, stripeFooter)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("stripe %d: %w", stripeIndex, err)
 	}
 
-	// Unmarshal the footer and store against the reader.
-	stripeFooter := &proto.StripeFooter{}
-	err = gproto.Unmarshal(decodedStripeFooterBytes, stripeFooter)
-	if err != nil {
-		return nil, err
+	// Store the columns and their encoding types so that we can access them
+	// later, replacing those of the previous stripe as each stripe may encode
+	// a column differently.
+	for i := range r.columns {
+		delete(r.columns, i)
 	}
-
-	// Store the columns and their encoding types so that we can access them later.
-	columns := stripeFooter.GetColumns()
 	for i, column := range columns {
 		r.columns[i] = column
 	}
+	r.writerTimezone = stripeFooter.GetWriterTimezone()
 
-	streamOffset := stripeOffset
-	streamsProto := stripeFooter.GetStreams()
 	streams := make(streamMap)
 
 	if len(streamsProto) == 0 {
 		return streams, io.EOF
 	}
+	types, err := r.getTypes()
+	if err != nil {
+		return nil, err
+	}
+	// The streams and encodings are validated as read, those of any encrypted
+	// columns replacing their masked streams and encodings.
+	validated := &proto.StripeFooter{Columns: columns}
+	for _, stream := range streamsProto {
+		validated.Streams = append(validated.Streams, stream.Stream)
+	}
+	if err := validateStreams(validated, types, included); err != nil {
+		return nil, fmt.Errorf("stripe %d: %v", stripeIndex, err)
+	}
 
 	// Iterate through the streams and allocate byte buffers for each.
 	for _, stream := range streamsProto {
@@ -239,35 +731,223 @@ func (r *Reader) getStreams(included ...int) (streamMap, error) {
 				include = true
 			}
 		}
+		// A PRESENT stream without any data cannot hold the presence of any
+		// value, so the values of the column are all present as when the
+		// stream is absent from the stripe.
+		if stream.GetKind() == proto.Stream_PRESENT && streamLength == 0 {
+			include = false
+		}
 		// Only allocate buffers for columns that we are planning to read.
 		if include {
-			// Create a new section reader for the length of the stream.
-			streamReader := io.NewSectionReader(r.r, streamOffset, streamLength)
+			// Create a new section reader for the length of the stream,
+			// decrypting the stream if it is encrypted.
+			streamReader := stream.reader(r.r)
 			// Retrieve the codec
 			codec, err := r.getCodec()
 			if err != nil {
 				return nil, err
 			}
-			dec := codec.Decoder(streamReader)
-			// Copy the stream into a buffer.
+			bufferSize := r.readBufferSize
+			if int64(bufferSize) > streamLength {
+				bufferSize = int(streamLength)
+			}
+			dec := codec.Decoder(bufio.NewReaderSize(streamReader, bufferSize))
+			name := streamName{
+				columnID: int(stream.GetColumn()),
+				kind:     stream.GetKind(),
+			}
+			// The streams of a limited Reader are decompressed as they are
+			// read, as only some of their rows may be needed, except for
+			// dictionaries which are needed whole. Uncompressed streams are
+			// bounded by their length, against which the lengths of their
+			// values are checked before they are allocated.
+			if r.limited && name.kind != proto.Stream_DICTIONARY_DATA {
+				if _, ok := codec.(CompressionNone); ok {
+					dec = &boundedReader{r: dec, n: streamLength}
+				}
+				streams.set(name, dec)
+				continue
+			}
+			// Copy the stream into a buffer, which is at least the size of
+			// the stream if it is within the file.
 			var streamBuf bytes.Buffer
+			if stream.offset+streamLength <= r.r.Size() {
+				streamBuf.Grow(int(streamLength))
+			}
 			_, err = io.Copy(&streamBuf, dec)
 			if err != nil {
 				return nil, err
 			}
 			// Store the byte buffer within the streamMap using a streamName.
-			name := streamName{
-				columnID: int(stream.GetColumn()),
-				kind:     stream.GetKind(),
-			}
 			streams.set(name, &streamBuf)
 		}
-		// Increment the streamOffset for the next stream.
-		streamOffset += streamLength
 	}
 	return streams, nil
 }
 
+// boundedReader reads at most n bytes from r, as an io.LimitedReader, reporting the
+// number of bytes that may remain by Len.
+type boundedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (b *boundedReader) Read(p []byte) (int, error) {
+	if b.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.n {
+		p = p[:b.n]
+	}
+	n, err := b.r.Read(p)
+	b.n -= int64(n)
+	return n, err
+}
+
+// Len returns the number of bytes that may remain in the stream, being at most the
+// maximum int.
+func (b *boundedReader) Len() int {
+	if max := int64(^uint(0) >> 1); b.n > max {
+		return int(max)
+	}
+	return int(b.n)
+}
+
+// getStripeFooter reads and decodes the footer of the provided stripe.
+func (r *Reader) getStripeFooter(stripe *proto.StripeInformation) (*proto.StripeFooter, error) {
+	stripeOffset := int64(stripe.GetOffset())
+	stripeFooterOffset := stripeOffset + int64(stripe.GetIndexLength()+stripe.GetDataLength())
+	stripeFooterLength := int64(stripe.GetFooterLength())
+	stripeFooterReader := io.NewSectionReader(r.r, stripeFooterOffset, stripeFooterLength)
+	allocator := getAllocator(r.allocator)
+	stripeFooterBytes := allocator.Get(int(stripeFooterLength))
+	defer allocator.Put(stripeFooterBytes)
+
+	_, err := io.ReadFull(stripeFooterReader, stripeFooterBytes)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := r.getCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the footer into a new byte slice.
+	stripeFooterDecoder := codec.Decoder(bytes.NewReader(stripeFooterBytes))
+	decodedStripeFooterBytes, err := ioutil.ReadAll(stripeFooterDecoder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the footer.
+	stripeFooter := &proto.StripeFooter{}
+	err = gproto.Unmarshal(decodedStripeFooterBytes, stripeFooter)
+	if err != nil {
+		return nil, err
+	}
+	return stripeFooter, nil
+}
+
+// dataStream is a kind of stream holding the values of a column and whether the
+// stream is required.
+type dataStream struct {
+	kind     proto.Stream_Kind
+	required bool
+}
+
+// dataStreams returns the streams holding the values of a column of the provided
+// type and encoding, other than its PRESENT stream, or nil if they are not known.
+func dataStreams(kind proto.Type_Kind, encoding proto.ColumnEncoding_Kind) []dataStream {
+	switch kind {
+	case proto.Type_STRUCT:
+		return []dataStream{}
+	case proto.Type_BOOLEAN, proto.Type_BYTE, proto.Type_SHORT, proto.Type_INT, proto.Type_LONG,
+		proto.Type_FLOAT, proto.Type_DOUBLE, proto.Type_DATE, proto.Type_UNION:
+		return []dataStream{{proto.Stream_DATA, true}}
+	case proto.Type_STRING, proto.Type_VARCHAR, proto.Type_CHAR:
+		switch encoding {
+		case proto.ColumnEncoding_DICTIONARY, proto.ColumnEncoding_DICTIONARY_V2:
+			return []dataStream{
+				{proto.Stream_DATA, true},
+				{proto.Stream_LENGTH, true},
+				{proto.Stream_DICTIONARY_DATA, true},
+				{proto.Stream_DICTIONARY_COUNT, false},
+			}
+		}
+		return []dataStream{{proto.Stream_DATA, true}, {proto.Stream_LENGTH, true}}
+	case proto.Type_BINARY:
+		return []dataStream{{proto.Stream_DATA, true}, {proto.Stream_LENGTH, true}}
+	case proto.Type_DECIMAL, proto.Type_TIMESTAMP, proto.Type_TIMESTAMP_INSTANT:
+		return []dataStream{{proto.Stream_DATA, true}, {proto.Stream_SECONDARY, true}}
+	case proto.Type_LIST, proto.Type_MAP:
+		return []dataStream{{proto.Stream_LENGTH, true}}
+	}
+	return nil
+}
+
+// validateStreams returns an error if the streams of the stripe footer do not
+// match the streams expected for the included columns, such as when a stream is
+// missing or repeated, which would otherwise misalign the values decoded from the
+// stripe. The required streams of a column with a PRESENT stream may be absent, as
+// its values may all be null.
+func validateStreams(stripeFooter *proto.StripeFooter, types []*proto.Type, included []int) error {
+	encodings := stripeFooter.GetColumns()
+	if len(encodings) != len(types) {
+		return fmt.Errorf("stripe footer has %d column encodings, expected %d", len(encodings), len(types))
+	}
+	found := make(map[streamName]bool)
+	for _, stream := range stripeFooter.GetStreams() {
+		name := streamName{columnID: int(stream.GetColumn()), kind: stream.GetKind()}
+		if name.columnID >= len(types) {
+			return fmt.Errorf("stream %s is of a column not in the schema of %d columns", name, len(types))
+		}
+		if found[name] {
+			return fmt.Errorf("stream %s is repeated", name)
+		}
+		found[name] = true
+	}
+	for _, columnID := range included {
+		if columnID < 0 || columnID >= len(types) {
+			continue
+		}
+		kind := types[columnID].GetKind()
+		encoding := encodings[columnID].GetKind()
+		streams := dataStreams(kind, encoding)
+		// The streams of columns of unknown types are not known.
+		if streams == nil {
+			continue
+		}
+		for _, stream := range stripeFooter.GetStreams() {
+			if int(stream.GetColumn()) != columnID {
+				continue
+			}
+			// Only the streams holding values are checked, the others being
+			// ignored by the tree readers.
+			switch stream.GetKind() {
+			case proto.Stream_DATA, proto.Stream_LENGTH, proto.Stream_DICTIONARY_DATA, proto.Stream_DICTIONARY_COUNT, proto.Stream_SECONDARY:
+			default:
+				continue
+			}
+			var expected bool
+			for _, s := range streams {
+				expected = expected || s.kind == stream.GetKind()
+			}
+			if !expected {
+				return fmt.Errorf("unexpected stream %s for column of kind %s with encoding %s", streamName{columnID, stream.GetKind()}, kind, encoding)
+			}
+		}
+		if found[streamName{columnID, proto.Stream_PRESENT}] {
+			continue
+		}
+		for _, s := range streams {
+			if s.required && !found[streamName{columnID, s.kind}] {
+				return fmt.Errorf("missing stream %s for column of kind %s with encoding %s", streamName{columnID, s.kind}, kind, encoding)
+			}
+		}
+	}
+	return nil
+}
+
 func (r *Reader) getColumn(columnID int) (*proto.ColumnEncoding, error) {
 	if columnID > len(r.columns) || r.columns[columnID] == nil {
 		return nil, fmt.Errorf("column: %v does not exist", columnID)
@@ -304,16 +984,17 @@ func (r *Reader) createSchema(types []*proto.Type, rootColumn int) (*TypeDescrip
 		if err != nil {
 			return nil, err
 		}
+		// Files written by Hive 0.11 do not record the precision and
+		// scale, in which case the defaults are used.
 		precision := int(root.GetPrecision())
 		if precision != 0 {
-			err = td.withPrecision(precision)
+			// Set the scale first as the precision must not be
+			// less than the scale.
+			err = td.withScale(int(root.GetScale()))
 			if err != nil {
 				return nil, err
 			}
-		}
-		scale := int(root.GetScale())
-		if scale != 0 {
-			err = td.withScale(scale)
+			err = td.withPrecision(precision)
 			if err != nil {
 				return nil, err
 			}
@@ -321,10 +1002,12 @@ func (r *Reader) createSchema(types []*proto.Type, rootColumn int) (*TypeDescrip
 		return td, nil
 	case proto.Type_STRING:
 		return NewTypeDescription(SetCategory(CategoryString))
-	case proto.Type_CHAR:
-		return NewTypeDescription(SetCategory(CategoryChar))
-	case proto.Type_VARCHAR:
-		td, err = NewTypeDescription(SetCategory(CategoryVarchar))
+	case proto.Type_CHAR, proto.Type_VARCHAR:
+		category := CategoryVarchar
+		if root.GetKind() == proto.Type_CHAR {
+			category = CategoryChar
+		}
+		td, err = NewTypeDescription(SetCategory(category))
 		if err != nil {
 			return nil, err
 		}
@@ -338,6 +1021,8 @@ func (r *Reader) createSchema(types []*proto.Type, rootColumn int) (*TypeDescrip
 		return td, nil
 	case proto.Type_TIMESTAMP:
 		return NewTypeDescription(SetCategory(CategoryTimestamp))
+	case proto.Type_TIMESTAMP_INSTANT:
+		return NewTypeDescription(SetCategory(CategoryTimestampInstant))
 	case proto.Type_DATE:
 		return NewTypeDescription(SetCategory(CategoryDate))
 	case proto.Type_LIST:
@@ -400,6 +1085,11 @@ func (r *Reader) createSchema(types []*proto.Type, rootColumn int) (*TypeDescrip
 		}
 		return td, nil
 	default:
+		// Unknown types are only supported as leaf columns, as the
+		// column ids of any children cannot otherwise be determined.
+		if r.rawUnknownColumns && len(root.GetSubtypes()) == 0 {
+			return NewTypeDescription(SetCategory(unknownCategory(root.GetKind())))
+		}
 		return nil, fmt.Errorf("unsupported kind: %s", root.GetKind())
 	}
 }
@@ -423,6 +1113,6 @@ func (r *Reader) Close() error {
 }
 
 func (r *Reader) Select(fields ...string) *Cursor {
-	cursor := &Cursor{Reader: r}
+	cursor := &Cursor{Reader: r, rowNumber: -1}
 	return cursor.Select(fields...)
 }
@@ -0,0 +1,1811 @@
+package orc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.simon-critchley.co.uk/orc/proto"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+func TestReaderRawUnknownColumns(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,unknown1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a type that is unknown to the reader.
+	w.footer.Types[2].Kind = proto.Type_Kind(100).Enum()
+
+	for i := 0; i < 10; i++ {
+		err = w.Write(i, i*2)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+	if err == nil {
+		t.Errorf("Test failed, expected error reading unknown column type")
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())}, WithRawUnknownColumns(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := r.Schema().String(); s != "struct<int1:int,unknown1:unknown>" {
+		t.Errorf("Test failed, unexpected schema %s", s)
+	}
+
+	c := r.Select("int1", "unknown1")
+	var rows int
+	for c.Stripes() {
+		for c.Next() {
+			row := c.Row()
+			if row[0] != int64(rows) {
+				t.Errorf("Test failed, expected %v got %v", rows, row[0])
+			}
+			raw, ok := row[1].(RawColumn)
+			if !ok {
+				t.Fatalf("Test failed, expected RawColumn got %T", row[1])
+			}
+			if raw.Encoding.GetKind() != proto.ColumnEncoding_DIRECT_V2 {
+				t.Errorf("Test failed, unexpected encoding %v", raw.Encoding)
+			}
+			// Decode the raw data stream to check it is complete.
+			ir := NewRunLengthIntegerReaderV2(bytes.NewReader(raw.Streams[proto.Stream_DATA]), true, false)
+			var values []int64
+			for ir.Next() {
+				values = append(values, ir.Int())
+			}
+			expected := []int64{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}
+			if !reflect.DeepEqual(expected, values) {
+				t.Errorf("Test failed, expected %v got %v", expected, values)
+			}
+			rows++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 10 {
+		t.Errorf("Test failed, expected 10 rows got %v", rows)
+	}
+
+}
+
+// setPostScriptCompression rewrites the postscript of the ORC file in b to
+// declare the provided compression kind without modifying the remainder of the
+// file.
+func setPostScriptCompression(t *testing.T, b []byte, kind proto.CompressionKind) []byte {
+	return modifyPostScript(t, b, func(ps *proto.PostScript) {
+		ps.Compression = kind.Enum()
+		ps.CompressionBlockSize = gproto.Uint64(256 * 1024)
+	})
+}
+
+// modifyPostScript rewrites the postscript of the ORC file in b as modified by
+// fn without modifying the remainder of the file.
+func modifyPostScript(t *testing.T, b []byte, fn func(ps *proto.PostScript)) []byte {
+	psLen := int(b[len(b)-1])
+	psOffset := len(b) - 1 - psLen
+	ps := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[psOffset:len(b)-1], ps); err != nil {
+		t.Fatal(err)
+	}
+	fn(ps)
+	psBytes, err := gproto.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := append([]byte{}, b[:psOffset]...)
+	result = append(result, psBytes...)
+	return append(result, byte(len(psBytes)))
+}
+
+func TestReaderUncompressedFooter(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		err = w.Write(i, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an old writer that declared ZLIB but left the tail uncompressed.
+	malformed := setPostScriptCompression(t, buf.Bytes(), proto.CompressionKind_ZLIB)
+	r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(malformed)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Schema().String() != schema.String() {
+		t.Errorf("Test failed, expected schema %s got %s", schema, r.Schema())
+	}
+	if rows := r.footer.GetNumberOfRows(); rows != 10 {
+		t.Errorf("Test failed, expected 10 rows got %v", rows)
+	}
+	if stats := r.metadata.GetStripeStats(); len(stats) != 1 {
+		t.Errorf("Test failed, expected 1 stripe statistics got %v", len(stats))
+	}
+
+	// A footer that is invalid both compressed and uncompressed still fails.
+	footerLength := int(r.postScript.GetFooterLength())
+	footerOffset := len(malformed) - 1 - int(malformed[len(malformed)-1]) - footerLength
+	if !bytes.Equal(r.RawFooter(), malformed[footerOffset:footerOffset+footerLength]) {
+		t.Errorf("Test failed, expected the raw footer to be the uncompressed footer of the file")
+	}
+	for i := footerOffset; i < footerOffset+footerLength; i++ {
+		malformed[i] = 0xff
+	}
+	_, err = NewReader(&bytesSizedReaderAt{bytes.NewBuffer(malformed)})
+	if err == nil {
+		t.Errorf("Test failed, expected error reading corrupt footer")
+	}
+}
+
+func TestReaderStripeStatisticsMismatch(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		err = w.Write(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record statistics for an additional stripe in the metadata, as written by
+	// some writers, and declare ZLIB so that the uncompressed retry is attempted.
+	b := buf.Bytes()
+	psLen := int(b[len(b)-1])
+	psOffset := len(b) - 1 - psLen
+	ps := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[psOffset:len(b)-1], ps); err != nil {
+		t.Fatal(err)
+	}
+	footerOffset := psOffset - int(ps.GetFooterLength())
+	metadataOffset := footerOffset - int(ps.GetMetadataLength())
+	metadata := &proto.Metadata{}
+	if err := gproto.Unmarshal(b[metadataOffset:footerOffset], metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata.StripeStats = append(metadata.StripeStats, &proto.StripeStatistics{})
+	metadataBytes, err := gproto.Marshal(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps.MetadataLength = gproto.Uint64(uint64(len(metadataBytes)))
+	psBytes, err := gproto.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched := append([]byte{}, b[:metadataOffset]...)
+	mismatched = append(mismatched, metadataBytes...)
+	mismatched = append(mismatched, b[footerOffset:psOffset]...)
+	mismatched = append(mismatched, psBytes...)
+	mismatched = append(mismatched, byte(len(psBytes)))
+
+	for _, kind := range []proto.CompressionKind{proto.CompressionKind_NONE, proto.CompressionKind_ZLIB} {
+		r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(setPostScriptCompression(t, mismatched, kind))})
+		if err != nil {
+			t.Errorf("Test failed, expected %s file to open got %v", kind, err)
+			continue
+		}
+		if stats := r.metadata.GetStripeStats(); len(stats) != 2 {
+			t.Errorf("Test failed, expected 2 stripe statistics got %v", len(stats))
+		}
+		if rows := r.footer.GetNumberOfRows(); rows != 10 {
+			t.Errorf("Test failed, expected 10 rows got %v", rows)
+		}
+	}
+
+}
+
+func TestReaderColumnHook(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string,struct1:struct<string2:string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []string{"alpha", "bravo", "charlie", "delta"}
+	for i, value := range input {
+		var s interface{} = value
+		if i == 2 {
+			s = nil
+		}
+		err = w.Write(i, s, []interface{}{value})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rowIndexes []int
+	upper := func(rowIndex int, value interface{}) (interface{}, error) {
+		rowIndexes = append(rowIndexes, rowIndex)
+		if s, ok := value.(string); ok {
+			return strings.ToUpper(s), nil
+		}
+		return value, nil
+	}
+	if err := r.SetColumnHook("string1", upper); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetColumnHook("struct1.string2", upper); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetColumnHook("missing", upper); err == nil {
+		t.Errorf("Test failed, expected error setting hook for missing column")
+	}
+
+	expected := [][]interface{}{
+		{int64(0), "ALPHA", Struct{"string2": "ALPHA"}},
+		{int64(1), "BRAVO", Struct{"string2": "BRAVO"}},
+		{int64(2), nil, Struct{"string2": "CHARLIE"}},
+		{int64(3), "DELTA", Struct{"string2": "DELTA"}},
+	}
+	c := r.Select("int1", "string1", "struct1")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			if row := c.Row(); !reflect.DeepEqual(row, expected[i]) {
+				t.Errorf("Test failed, expected %v at row %d got %v", expected[i], i, row)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+	if !reflect.DeepEqual(rowIndexes, []int{0, 0, 1, 1, 2, 2, 3, 3}) {
+		t.Errorf("Test failed, unexpected hook row indexes %v", rowIndexes)
+	}
+
+	// An error returned by a hook stops the scan.
+	r, err = NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.SetColumnHook("string1", func(rowIndex int, value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, errors.New("unexpected null")
+		}
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = r.Select("int1", "string1")
+	var rows int
+	for c.Stripes() {
+		for c.Next() {
+			rows++
+		}
+	}
+	if rows != 2 {
+		t.Errorf("Test failed, expected 2 rows before the hook error got %d", rows)
+	}
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), "string1") || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Test failed, unexpected error %v", err)
+	}
+}
+
+func TestReaderAcidUnwrap(t *testing.T) {
+
+	schema, err := ParseSchema("struct<operation:int,originalTransaction:bigint,bucket:int,rowId:bigint,currentTransaction:bigint,row:struct<id:int,name:string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []AcidEvent{
+		{Operation: 0, OriginalTransaction: 10, Bucket: 536870912, RowID: 0, CurrentTransaction: 10},
+		{Operation: 0, OriginalTransaction: 10, Bucket: 536870912, RowID: 1, CurrentTransaction: 10},
+		{Operation: 1, OriginalTransaction: 10, Bucket: 536870912, RowID: 2, CurrentTransaction: 12},
+	}
+	names := []string{"a", "b", "c"}
+	for i, e := range events {
+		err = w.Write(e.Operation, e.OriginalTransaction, e.Bucket, e.RowID, e.CurrentTransaction, []interface{}{i, names[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the option the wrapper struct is returned.
+	r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Schema().String() != schema.String() {
+		t.Errorf("Test failed, expected schema %s got %s", schema, r.Schema())
+	}
+
+	r, err = NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())}, WithAcidUnwrap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema().String(); s != "struct<id:int,name:string>" {
+		t.Errorf("Test failed, unexpected schema %s", s)
+	}
+
+	c := r.Select("id", "name")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			expected := []interface{}{int64(i), names[i]}
+			if row := c.Row(); !reflect.DeepEqual(row, expected) {
+				t.Errorf("Test failed, expected %v got %v", expected, row)
+			}
+			if event := c.AcidEvent(); event != events[i] {
+				t.Errorf("Test failed, expected ACID event %+v got %+v", events[i], event)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(events) {
+		t.Errorf("Test failed, expected %d rows got %d", len(events), i)
+	}
+
+	// Schemas without the full ACID layout are not unwrapped.
+	plain, err := ParseSchema("struct<operation:int,row:struct<id:int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isAcidSchema(plain) {
+		t.Errorf("Test failed, expected %s not to be an ACID schema", plain)
+	}
+}
+
+func TestReaderRawFooter(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &latencyReaderAt{b: b}
+	r, err := NewReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The raw bytes are those already read, the footer of which is decompressed.
+	reads := atomic.LoadInt64(&src.reads)
+	var footer proto.Footer
+	if err := gproto.Unmarshal(r.RawFooter(), &footer); err != nil {
+		t.Fatal(err)
+	}
+	if !gproto.Equal(&footer, r.footer) {
+		t.Errorf("Test failed, expected the raw footer to unmarshal into the footer")
+	}
+	var postScript proto.PostScript
+	if err := gproto.Unmarshal(r.RawPostScript(), &postScript); err != nil {
+		t.Fatal(err)
+	}
+	if !gproto.Equal(&postScript, r.postScript) || postScript.GetCompression() != proto.CompressionKind_ZLIB {
+		t.Errorf("Test failed, expected the raw postscript to unmarshal into the postscript")
+	}
+	if n := atomic.LoadInt64(&src.reads); n != reads {
+		t.Errorf("Test failed, expected no further reads got %d", n-reads)
+	}
+
+}
+
+func TestReaderStripeFooter(t *testing.T) {
+
+	r, err := Open("./examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.PostScript() != r.postScript || r.Footer() != r.footer || r.Metadata() != r.metadata {
+		t.Errorf("Test failed, expected the postscript, footer and metadata of the reader")
+	}
+	stripeFooter, err := r.StripeFooter(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(stripeFooter.GetColumns()); n != 24 {
+		t.Errorf("Test failed, expected the encodings of 24 columns got %d", n)
+	}
+	if tz := stripeFooter.GetWriterTimezone(); tz != "US/Pacific" {
+		t.Errorf("Test failed, expected writer timezone US/Pacific got %s", tz)
+	}
+	for _, stripe := range []int{-1, 1} {
+		if _, err := r.StripeFooter(stripe); err == nil || err.Error() != fmt.Sprintf("stripe %d out of range, the file has 1 stripes", stripe) {
+			t.Errorf("Test failed, expected stripe %d to be out of range got %v", stripe, err)
+		}
+	}
+
+}
+
+func TestReaderIsColumnAllNull(t *testing.T) {
+
+	r, err := Open("./examples/TestOrcFile.metaData.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	testCases := []struct {
+		column   string
+		expected bool
+		err      string
+	}{
+		{column: "int1", expected: false},
+		{column: "bytes1", expected: true},
+		{column: "string1", expected: true},
+		// The fields of a null struct have neither values nor nulls.
+		{column: "middle.list", expected: true},
+		{column: "missing", err: "no field with name: missing"},
+	}
+
+	for _, tc := range testCases {
+		allNull, err := r.IsColumnAllNull(tc.column)
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Test failed, expected error %q for column %s got %v", tc.err, tc.column, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allNull != tc.expected {
+			t.Errorf("Test failed, expected column %s all null to be %v got %v", tc.column, tc.expected, allNull)
+		}
+	}
+
+	empty, err := Open("./examples/TestOrcFile.emptyFile.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	if allNull, err := empty.IsColumnAllNull("int1"); err != nil || allNull {
+		t.Errorf("Test failed, expected a column of an empty file not to be all null got %v, %v", allNull, err)
+	}
+	empty.footer.Statistics = nil
+	if _, err := empty.IsColumnAllNull("int1"); err == nil || err.Error() != "no statistics of column int1" {
+		t.Errorf("Test failed, expected an error without statistics got %v", err)
+	}
+
+	// Nulls written by the Writer are not counted as values.
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := w.Write(i, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for column, expected := range map[string]bool{"int1": false, "string1": true} {
+		if allNull, err := written.IsColumnAllNull(column); err != nil || allNull != expected {
+			t.Errorf("Test failed, expected written column %s all null to be %v got %v, %v", column, expected, allNull, err)
+		}
+	}
+
+}
+
+func TestNewReaderTruncatedFooter(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[len(b)-1-int(b[len(b)-1]):len(b)-1], ps); err != nil {
+		t.Fatal(err)
+	}
+	footerLength, metadataLength := ps.GetFooterLength(), ps.GetMetadataLength()
+
+	testCases := []struct {
+		b        []byte
+		expected string
+	}{
+		{
+			b:        nil,
+			expected: "the file is empty",
+		},
+		{
+			// The last byte claims a postscript longer than the file.
+			b:        []byte{1, 2, 200},
+			expected: "postscript length 200 exceeds the file size 3",
+		},
+		{
+			b: modifyPostScript(t, b, func(ps *proto.PostScript) {
+				ps.FooterLength = gproto.Uint64(uint64(len(b)))
+			}),
+		},
+		{
+			b: modifyPostScript(t, b, func(ps *proto.PostScript) {
+				ps.MetadataLength = gproto.Uint64(math.MaxUint64)
+			}),
+		},
+		{
+			// Both lengths are within the file but their sum is not.
+			b: modifyPostScript(t, b, func(ps *proto.PostScript) {
+				ps.FooterLength = gproto.Uint64(footerLength + uint64(len(b))/2)
+				ps.MetadataLength = gproto.Uint64(metadataLength + uint64(len(b))/2)
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewReader(bytes.NewReader(tc.b))
+		if !errors.Is(err, ErrTruncatedFooter) || !strings.HasSuffix(err.Error(), tc.expected) {
+			t.Errorf("Test failed, expected a truncated footer error ending %q got %v", tc.expected, err)
+		}
+	}
+
+	// The error reports the lengths of the footer and metadata.
+	oversized := testCases[3].b
+	_, err = NewReader(bytes.NewReader(oversized))
+	tail := len(oversized) - 1 - int(oversized[len(oversized)-1])
+	expected := fmt.Sprintf("file tail is larger than the file: footer length %d and metadata length %d exceed the %d bytes before the postscript", footerLength, uint64(math.MaxUint64), tail)
+	if err == nil || err.Error() != expected {
+		t.Errorf("Test failed, expected error %q got %v", expected, err)
+	}
+
+}
+
+func TestReadFooter(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.test1.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := &latencyReaderAt{b: b}
+
+	footer, postScript, err := ReadFooter(src, src.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(footer.GetTypes()); n != 24 {
+		t.Errorf("Test failed, expected 24 columns got %d", n)
+	}
+	if n := len(footer.GetStatistics()); n != 24 {
+		t.Errorf("Test failed, expected statistics for 24 columns got %d", n)
+	}
+	if n := footer.GetNumberOfRows(); n != 2 {
+		t.Errorf("Test failed, expected 2 rows got %d", n)
+	}
+	if n := len(footer.GetStripes()); n != 1 {
+		t.Errorf("Test failed, expected 1 stripe got %d", n)
+	}
+	if kind := postScript.GetCompression(); kind != proto.CompressionKind_ZLIB {
+		t.Errorf("Test failed, expected ZLIB compression got %s", kind)
+	}
+
+	// Only the tail of the file is read.
+	tail := postScript.GetFooterLength() + postScript.GetMetadataLength() + maxPostScriptSize + 1
+	src.b = append(make([]byte, len(b)-int(tail)), b[len(b)-int(tail):]...)
+	if _, _, err := ReadFooter(src, src.Size()); err != nil {
+		t.Errorf("Test failed, expected the footer to be read from the tail alone: %v", err)
+	}
+
+	if _, _, err := ReadFooter(bytes.NewReader(b[:len(b)/2]), int64(len(b)/2)); err == nil {
+		t.Errorf("Test failed, expected error for a truncated file")
+	}
+
+}
+
+// latencyReaderAt is a SizedReaderAt over a byte slice that counts the number of
+// reads and delays each by latency, simulating a remote source.
+type latencyReaderAt struct {
+	b       []byte
+	latency time.Duration
+	reads   int64
+}
+
+func (l *latencyReaderAt) Size() int64 {
+	return int64(len(l.b))
+}
+
+func (l *latencyReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	atomic.AddInt64(&l.reads, 1)
+	time.Sleep(l.latency)
+	return bytes.NewReader(l.b).ReadAt(p, off)
+}
+
+func readAllRows(r *Reader) ([][]interface{}, error) {
+	var rows [][]interface{}
+	c := r.Select(r.Schema().Columns()...)
+	for c.Stripes() {
+		for c.Next() {
+			rows = append(rows, c.Row())
+		}
+	}
+	if err := c.Err(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func TestReaderReadBufferSize(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.testSnappy.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected [][]interface{}
+	var maxReads int64
+	for _, size := range []int{1, 4 * 1024, DefaultReadBufferSize} {
+		src := &latencyReaderAt{b: b}
+		r, err := NewReader(src, WithReadBufferSize(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) == 0 {
+			t.Fatalf("Test failed, expected rows for buffer size %d", size)
+		}
+		if expected == nil {
+			expected = rows
+			maxReads = src.reads
+		} else if !reflect.DeepEqual(rows, expected) {
+			t.Errorf("Test failed, rows differ for buffer size %d", size)
+		}
+		if src.reads > maxReads {
+			t.Errorf("Test failed, expected at most %d reads for buffer size %d got %d", maxReads, size, src.reads)
+		}
+		maxReads = src.reads
+	}
+
+	if _, err := NewReader(&latencyReaderAt{b: b}, WithReadBufferSize(0)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid read buffer size")
+	}
+
+}
+
+// countingAllocator is an Allocator recording the buffers it has handed out and
+// which of them have been returned.
+type countingAllocator struct {
+	gets        int
+	puts        int
+	outstanding map[*byte]bool
+	unknown     int
+}
+
+func (a *countingAllocator) Get(n int) []byte {
+	a.gets++
+	// Allocate an extra byte so that empty buffers are identified too.
+	b := make([]byte, n, n+1)
+	a.outstanding[&b[:1][0]] = true
+	return b
+}
+
+func (a *countingAllocator) Put(b []byte) {
+	a.puts++
+	if cap(b) == 0 || !a.outstanding[&b[:1][0]] {
+		a.unknown++
+		return
+	}
+	delete(a.outstanding, &b[:1][0])
+}
+
+func TestReaderWithAllocator(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.testSnappy.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &countingAllocator{outstanding: make(map[*byte]bool)}
+	r, err = NewReader(bytes.NewReader(b), WithAllocator(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes, err := r.getStripes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The footer and metadata are read before the Reader is returned.
+	footerGets := a.gets
+	if footerGets == 0 {
+		t.Errorf("Test failed, expected the footer to be decompressed through the allocator")
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, rows differ when read with an allocator")
+	}
+	// Each stripe footer and every chunk of the streams obtain their buffers
+	// from the allocator, returning each of them once read.
+	if a.gets-footerGets <= 2*len(stripes) {
+		t.Errorf("Test failed, expected more than %d calls to Get got %d", 2*len(stripes), a.gets-footerGets)
+	}
+	if a.puts != a.gets || len(a.outstanding) != 0 || a.unknown != 0 {
+		t.Errorf("Test failed, expected %d calls to Put of the buffers from Get got %d with %d outstanding and %d unknown", a.gets, a.puts, len(a.outstanding), a.unknown)
+	}
+
+	if _, err := NewReader(bytes.NewReader(b), WithAllocator(nil)); err == nil {
+		t.Errorf("Test failed, expected error for a nil allocator")
+	}
+
+}
+
+// rangeReaderAt is a SizedReaderAt over a byte slice that records the start and
+// end offsets of each read.
+type rangeReaderAt struct {
+	b      []byte
+	starts []int64
+	ends   []int64
+}
+
+func (r *rangeReaderAt) Size() int64 {
+	return int64(len(r.b))
+}
+
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := bytes.NewReader(r.b).ReadAt(p, off)
+	r.starts = append(r.starts, off)
+	r.ends = append(r.ends, off+int64(n))
+	return n, err
+}
+
+func TestReaderLimit(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three stripes of three row groups of random values, which are directly
+	// encoded and so require reading the entire stream to decode.
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	var input []int64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3*int(DefaultRowIndexStride); j++ {
+			v := rnd.Int63()
+			input = append(input, v)
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Find the offset of the second row group of the DATA stream of the first
+	// stripe from its row index.
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes, err := r.getStripes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stripes) != 3 {
+		t.Fatalf("Test failed, expected 3 stripes got %d", len(stripes))
+	}
+	stripeFooter, err := r.getStripeFooter(stripes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataOffset := int64(stripes[0].GetOffset())
+	for _, stream := range stripeFooter.GetStreams() {
+		if stream.GetKind() == proto.Stream_DATA {
+			break
+		}
+		dataOffset += int64(stream.GetLength())
+	}
+	c := r.Select("a")
+	if !c.Stripes() {
+		t.Fatal(c.Err())
+	}
+	byt, err := ioutil.ReadAll(c.streams.get(streamName{1, proto.Stream_ROW_INDEX}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index proto.RowIndex
+	if err := gproto.Unmarshal(byt, &index); err != nil {
+		t.Fatal(err)
+	}
+	secondRowGroup := dataOffset + int64(index.GetEntry()[1].GetPositions()[0])
+	stripeEnd := int64(stripes[0].GetOffset() + stripes[0].GetIndexLength() + stripes[0].GetDataLength() + stripes[0].GetFooterLength())
+
+	testCases := []struct {
+		limit    int64
+		expected []int64
+	}{
+		{limit: 5, expected: input[:5]},
+		{limit: 0, expected: nil},
+		{limit: 30001, expected: input[:30001]},
+		{limit: -1, expected: input},
+		{limit: 100000, expected: input},
+	}
+
+	for _, tc := range testCases {
+		src := &rangeReaderAt{b: buf.Bytes()}
+		r, err := NewReader(src, WithReadBufferSize(4*1024))
+		if err != nil {
+			t.Fatal(err)
+		}
+		src.ends = nil
+		c := r.Limit(tc.limit).Select("a")
+		var output []int64
+		for c.Stripes() {
+			for c.Next() {
+				output = append(output, c.Row()[0].(int64))
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("Test failed, limit %d expected %d rows got %d", tc.limit, len(tc.expected), len(output))
+		}
+		if tc.limit == 5 {
+			// Only the stripe footer follows the first row group.
+			for _, end := range src.ends {
+				if end > secondRowGroup && end != stripeEnd {
+					t.Errorf("Test failed, limit %d read to offset %d beyond the first row group ending at %d", tc.limit, end, secondRowGroup)
+				}
+			}
+		}
+	}
+
+}
+
+func TestReaderColumnSizes(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint,name:string,note:string,tags:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 15000; j++ {
+			// The names repeat and so are dictionary encoded, whereas the notes
+			// are unique and include nulls.
+			var note interface{}
+			if j%3 != 0 {
+				note = fmt.Sprintf("note %d", rnd.Int63())
+			}
+			if err := w.Write(int64(j), fmt.Sprintf("name %d", j%10), note, []int32{int32(j), int32(j)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := r.ColumnSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The streams of every column fill the index and data of each stripe.
+	stripes, err := r.getStripes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stripes) != 2 {
+		t.Fatalf("Test failed, expected 2 stripes got %d", len(stripes))
+	}
+	var expected, total int64
+	for _, stripe := range stripes {
+		expected += int64(stripe.GetIndexLength() + stripe.GetDataLength())
+	}
+	for _, size := range sizes {
+		total += size.Total()
+	}
+	if total != expected {
+		t.Errorf("Test failed, expected a total size of %d got %d", expected, total)
+	}
+
+	for column, check := range map[int]func(ColumnSize) bool{
+		0: func(s ColumnSize) bool { return s.Index > 0 && s.Data == 0 && s.Present == 0 },
+		1: func(s ColumnSize) bool {
+			return s.Index > 0 && s.Data > 0 && s.Present == 0 && s.Length == 0 && s.Dictionary == 0
+		},
+		2: func(s ColumnSize) bool { return s.Data > 0 && s.Length > 0 && s.Dictionary > 0 && s.Present == 0 },
+		3: func(s ColumnSize) bool { return s.Data > 0 && s.Length > 0 && s.Present > 0 && s.Dictionary == 0 },
+		4: func(s ColumnSize) bool { return s.Length > 0 && s.Data == 0 },
+		5: func(s ColumnSize) bool { return s.Data > 0 && s.Length == 0 },
+	} {
+		if !check(sizes[column]) {
+			t.Errorf("Test failed, unexpected size of column %d: %+v", column, sizes[column])
+		}
+	}
+	// The unique notes are larger than the dictionary encoded names.
+	if sizes[3].Total() <= sizes[2].Total() {
+		t.Errorf("Test failed, expected column 3 to be larger than column 2: %+v %+v", sizes[3], sizes[2])
+	}
+
+	// Streams of unknown kinds are counted as Other rather than rejected.
+	var b bytes.Buffer
+	w, err = NewWriter(&b, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(int64(1), "name", "note", []int32{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var unknown int64
+	modified := withStripeFooter(t, b.Bytes(), func(stripeFooter *proto.StripeFooter) {
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetColumn() == 1 && stream.GetKind() == proto.Stream_DATA {
+				stream.Kind = proto.Stream_Kind(100).Enum()
+				unknown = int64(stream.GetLength())
+			}
+		}
+	})
+	r, err = NewReader(bytes.NewReader(modified))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err = r.ColumnSizes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := sizes[1]; unknown == 0 || s.Other != unknown || s.Data != 0 {
+		t.Errorf("Test failed, expected %d bytes of other streams got %+v", unknown, s)
+	}
+
+}
+
+func TestReaderMissingPresentStream(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:int,s:struct<x:int>,l:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each stripe has nulls in different columns, the children of the struct and
+	// list having no nulls of their own in the second stripe despite the nulls of
+	// their parents.
+	nulls := []func(i int) (a, s, x, l, e bool){
+		func(i int) (a, s, x, l, e bool) { return i%2 == 0, false, false, false, false },
+		func(i int) (a, s, x, l, e bool) { return false, i%3 == 0, false, i%5 == 0, false },
+		func(i int) (a, s, x, l, e bool) { return i%7 == 0, false, i%3 == 0, false, i%4 == 0 },
+	}
+	var expected [][]interface{}
+	for _, isNull := range nulls {
+		for i := 0; i < 3000; i++ {
+			aNull, sNull, xNull, lNull, eNull := isNull(i)
+			var a, s, x, l, e interface{} = int32(i), nil, int32(-i), nil, int32(i * 2)
+			var expectedA, expectedS, expectedX, expectedL, expectedE interface{} = int64(i), nil, int64(-i), nil, int64(i * 2)
+			if aNull {
+				a, expectedA = nil, nil
+			}
+			if xNull {
+				x, expectedX = nil, nil
+			}
+			if eNull {
+				e, expectedE = nil, nil
+			}
+			if !sNull {
+				s, expectedS = []interface{}{x}, Struct{"x": expectedX}
+			}
+			if !lNull {
+				l, expectedL = []interface{}{int32(i), e}, []interface{}{int64(i), expectedE}
+			}
+			if err := w.Write(a, s, l); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, []interface{}{expectedA, expectedS, expectedL})
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes, err := r.getStripes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stripes) != len(nulls) {
+		t.Fatalf("Test failed, expected %d stripes got %d", len(nulls), len(stripes))
+	}
+	// The columns with a PRESENT stream in each stripe, by column ID.
+	present := [][]int{{1}, {2, 4}, {1, 3, 5}}
+	for i, stripe := range stripes {
+		stripeFooter, err := r.getStripeFooter(stripe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var columns []int
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetKind() == proto.Stream_PRESENT {
+				columns = append(columns, int(stream.GetColumn()))
+			}
+		}
+		if !reflect.DeepEqual(columns, present[i]) {
+			t.Errorf("Test failed, expected PRESENT streams for columns %v of stripe %d got %v", present[i], i, columns)
+		}
+	}
+
+	// A limited Reader reads the same rows from streams decompressed as they are
+	// read.
+	for _, limit := range []int64{-1, int64(len(expected))} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Limit(limit)
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(expected) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(expected), len(rows))
+		}
+		for i := range rows {
+			if !reflect.DeepEqual(rows[i], expected[i]) {
+				t.Errorf("Test failed, row %d expected %v got %v", i, expected[i], rows[i])
+				break
+			}
+		}
+	}
+
+	// An empty PRESENT stream in the stripe footer, as written by other writers
+	// for columns without nulls, is read as if the stream were absent.
+	buf.Reset()
+	if w, err = NewWriter(&buf, SetSchema(schema)); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range expected[:100] {
+		if err := w.Write(row[0], []interface{}{row[1].(Struct)["x"]}, row[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var streams []*proto.Stream
+	var indexes [][]byte
+	for _, column := range []uint32{2, 3, 5} {
+		streams = append(streams, &proto.Stream{Kind: proto.Stream_PRESENT.Enum(), Column: ptrUint32(column)})
+		indexes = append(indexes, nil)
+	}
+	b := withBloomFilters(t, buf.Bytes(), DefaultWriterVersion, streams, indexes)
+	if r, err = NewReader(bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, expected[:100]) {
+		t.Errorf("Test failed, expected %v got %v", expected[:100], rows)
+	}
+
+}
+
+// withStripeFooter returns the uncompressed ORC file b, which has a single stripe,
+// with its stripe footer modified by fn.
+func withStripeFooter(t *testing.T, b []byte, fn func(stripeFooter *proto.StripeFooter)) []byte {
+	psLen := int(b[len(b)-1])
+	postScript := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[len(b)-1-psLen:len(b)-1], postScript); err != nil {
+		t.Fatal(err)
+	}
+	footerOffset := len(b) - 1 - psLen - int(postScript.GetFooterLength())
+	footer := &proto.Footer{}
+	if err := gproto.Unmarshal(b[footerOffset:footerOffset+int(postScript.GetFooterLength())], footer); err != nil {
+		t.Fatal(err)
+	}
+	metadataOffset := footerOffset - int(postScript.GetMetadataLength())
+	stripe := footer.GetStripes()[0]
+	stripeFooterOffset := int(stripe.GetOffset() + stripe.GetIndexLength() + stripe.GetDataLength())
+	stripeFooter := &proto.StripeFooter{}
+	if err := gproto.Unmarshal(b[stripeFooterOffset:stripeFooterOffset+int(stripe.GetFooterLength())], stripeFooter); err != nil {
+		t.Fatal(err)
+	}
+	fn(stripeFooter)
+
+	out := append([]byte{}, b[:stripeFooterOffset]...)
+	byt, err := gproto.Marshal(stripeFooter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	stripe.FooterLength = ptrUint64(uint64(len(byt)))
+	out = append(out, b[metadataOffset:footerOffset]...)
+	if byt, err = gproto.Marshal(footer); err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	postScript.FooterLength = ptrUint64(uint64(len(byt)))
+	if byt, err = gproto.Marshal(postScript); err != nil {
+		t.Fatal(err)
+	}
+	out = append(out, byt...)
+	return append(out, byte(len(byt)))
+}
+
+func TestReaderValidateStreams(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:int,b:string,c:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := w.Write(int32(i), fmt.Sprintf("b%d", i%10), []int32{int32(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// remove returns a modification of the stripe footer removing the stream of
+	// the column of the provided kind.
+	remove := func(column uint32, kind proto.Stream_Kind) func(*proto.StripeFooter) {
+		return func(stripeFooter *proto.StripeFooter) {
+			var streams []*proto.Stream
+			for _, stream := range stripeFooter.GetStreams() {
+				if stream.GetColumn() != column || stream.GetKind() != kind {
+					streams = append(streams, stream)
+				}
+			}
+			stripeFooter.Streams = streams
+		}
+	}
+	// add returns a modification of the stripe footer adding an empty stream to
+	// the column of the provided kind.
+	add := func(column uint32, kind proto.Stream_Kind) func(*proto.StripeFooter) {
+		return func(stripeFooter *proto.StripeFooter) {
+			stripeFooter.Streams = append(stripeFooter.Streams, &proto.Stream{Kind: kind.Enum(), Column: ptrUint32(column), Length: ptrUint64(0)})
+		}
+	}
+
+	testCases := []struct {
+		modify   func(*proto.StripeFooter)
+		columns  []string
+		expected string
+	}{
+		{
+			modify:   remove(1, proto.Stream_DATA),
+			columns:  []string{"a", "b"},
+			expected: "stripe 0: missing stream col:1 kind:DATA for column of kind INT with encoding DIRECT_V2",
+		},
+		{
+			modify:   remove(2, proto.Stream_DICTIONARY_DATA),
+			columns:  []string{"b"},
+			expected: "stripe 0: missing stream col:2 kind:DICTIONARY_DATA for column of kind STRING with encoding DICTIONARY_V2",
+		},
+		{
+			modify:   remove(3, proto.Stream_LENGTH),
+			columns:  []string{"c"},
+			expected: "stripe 0: missing stream col:3 kind:LENGTH for column of kind LIST with encoding DIRECT_V2",
+		},
+		{
+			modify:   add(1, proto.Stream_SECONDARY),
+			columns:  []string{"a"},
+			expected: "stripe 0: unexpected stream col:1 kind:SECONDARY for column of kind INT with encoding DIRECT_V2",
+		},
+		{
+			modify:   add(2, proto.Stream_LENGTH),
+			columns:  []string{"b"},
+			expected: "stripe 0: stream col:2 kind:LENGTH is repeated",
+		},
+		{
+			modify:   add(5, proto.Stream_DATA),
+			columns:  []string{"a"},
+			expected: "stripe 0: stream col:5 kind:DATA is of a column not in the schema of 5 columns",
+		},
+		{
+			modify: func(stripeFooter *proto.StripeFooter) {
+				stripeFooter.Columns = stripeFooter.Columns[:4]
+			},
+			columns:  []string{"a"},
+			expected: "stripe 0: stripe footer has 4 column encodings, expected 5",
+		},
+		{
+			// Only the streams of the columns read are required. The last
+			// stream is removed so that the offsets of the others are kept.
+			modify:  remove(4, proto.Stream_DATA),
+			columns: []string{"a", "b"},
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := NewReader(bytes.NewReader(withStripeFooter(t, buf.Bytes(), tc.modify)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select(tc.columns...)
+		var rows int
+		for c.Stripes() {
+			for c.Next() {
+				rows++
+			}
+		}
+		err = c.Err()
+		if err == io.EOF {
+			err = nil
+		}
+		if tc.expected == "" {
+			if err != nil || rows != 100 {
+				t.Errorf("Test failed, expected 100 rows got %d and error %v", rows, err)
+			}
+		} else if err == nil || err.Error() != tc.expected {
+			t.Errorf("Test failed, expected error %q got %v", tc.expected, err)
+		}
+	}
+
+}
+
+func TestReaderEncodingPerStripe(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint,s:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The strings of the second stripe are unique and so directly encoded,
+	// whereas those of the others repeat and are dictionary encoded. The
+	// integers of the last stripe are written as in version 0.11 files.
+	cardinalities := []int{10, 1000, 5}
+	var expected [][]interface{}
+	for stripe, cardinality := range cardinalities {
+		for i := 0; i < 1000; i++ {
+			row := []interface{}{int64(stripe*1000 + i), fmt.Sprintf("value %d of stripe %d", i%cardinality, stripe)}
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+			expected = append(expected, row)
+		}
+		// The writers of the next stripe are created by Flush.
+		if stripe == len(cardinalities)-2 {
+			w.version = Version0_11
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripes, err := r.getStripes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodings := [][]proto.ColumnEncoding_Kind{
+		{proto.ColumnEncoding_DIRECT_V2, proto.ColumnEncoding_DICTIONARY_V2},
+		{proto.ColumnEncoding_DIRECT_V2, proto.ColumnEncoding_DIRECT_V2},
+		{proto.ColumnEncoding_DIRECT, proto.ColumnEncoding_DICTIONARY},
+	}
+	for i, stripe := range stripes {
+		stripeFooter, err := r.getStripeFooter(stripe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, kind := range encodings[i] {
+			if actual := stripeFooter.GetColumns()[j+1].GetKind(); actual != kind {
+				t.Errorf("Test failed, expected encoding %s for column %d of stripe %d got %s", kind, j+1, i, actual)
+			}
+		}
+	}
+
+	// Each stripe is decoded using its own encodings.
+	for _, limit := range []int64{-1, int64(len(expected))} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := readAllRows(r.Limit(limit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Errorf("Test failed, expected %d rows got %d differing rows", len(expected), len(rows))
+		}
+	}
+
+}
+
+func TestReaderCodecOverride(t *testing.T) {
+
+	original, err := ioutil.ReadFile("./examples/demo-12-zlib.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first rows suffice to decompress the streams of the first stripe.
+	readRows := func(r *Reader) ([][]interface{}, error) {
+		var rows [][]interface{}
+		c := r.Limit(2000).Select(r.Schema().fieldNames...)
+		for c.Stripes() {
+			for c.Next() {
+				rows = append(rows, c.Row())
+			}
+		}
+		return rows, c.Err()
+	}
+	r, err := NewReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Claim that the ZLIB file is compressed with SNAPPY, which encodes in the
+	// same number of bytes.
+	mutated := append([]byte(nil), original...)
+	psLen := int(mutated[len(mutated)-1])
+	psOffset := len(mutated) - 1 - psLen
+	ps := &proto.PostScript{}
+	if err := gproto.Unmarshal(mutated[psOffset:len(mutated)-1], ps); err != nil {
+		t.Fatal(err)
+	}
+	ps.Compression = proto.CompressionKind_SNAPPY.Enum()
+	b, err := gproto.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != psLen {
+		t.Fatalf("Test failed, expected a postscript of %d bytes got %d", psLen, len(b))
+	}
+	copy(mutated[psOffset:], b)
+
+	if _, err := NewReader(bytes.NewReader(mutated)); err == nil {
+		t.Errorf("Test failed, expected an error reading a ZLIB file as SNAPPY")
+	}
+	r, err = NewReader(bytes.NewReader(mutated), WithCompressionCodec(CompressionZlib{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(expected) || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected %d rows matching the original file got %d", len(expected), len(actual))
+	}
+
+	// Overriding the codec of an open file applies to the stripes read after.
+	r, err = NewReader(bytes.NewReader(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRows(r.WithCodec(CompressionSnappy{})); err == nil {
+		t.Errorf("Test failed, expected an error reading ZLIB stripes as SNAPPY")
+	}
+	r.currentStripeOffset = 0
+	actual, err = readRows(r.WithCodec(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected %d rows matching the original file got %d", len(expected), len(actual))
+	}
+
+	if _, err := NewReader(bytes.NewReader(original), WithCompressionCodec(nil)); err == nil {
+		t.Errorf("Test failed, expected an error for a nil codec")
+	}
+
+}
+
+func BenchmarkReaderReadBufferSize(b *testing.B) {
+	byt, err := ioutil.ReadFile("./examples/TestOrcFile.testSnappy.orc")
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range []int{4 * 1024, DefaultReadBufferSize} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			src := &latencyReaderAt{b: byt, latency: 100 * time.Microsecond}
+			for i := 0; i < b.N; i++ {
+				r, err := NewReader(src, WithReadBufferSize(size))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := readAllRows(r); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(src.reads)/float64(b.N), "reads/op")
+		})
+	}
+}
+
+func TestReaderStripeFilter(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Three stripes of the values 0 to 99, 100 to 199 and 200 to 299.
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 300; i++ {
+		if err := w.Write(int64(i)); err != nil {
+			t.Fatal(err)
+		}
+		if i%100 == 99 {
+			if _, err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		// value is the value the stripes read may contain.
+		value    int64
+		expected []int
+	}{
+		{value: 150, expected: []int{1}},
+		{value: 0, expected: []int{0}},
+		{value: 299, expected: []int{2}},
+		{value: 300, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		src := &rangeReaderAt{b: buf.Bytes()}
+		var filtered []int
+		r, err := NewReader(src, WithStripeFilter(func(stripe int, stats []ColumnStatistics) bool {
+			filtered = append(filtered, stripe)
+			ints := stats[1].Statistics().GetIntStatistics()
+			return ints.GetMinimum() <= tc.value && tc.value <= ints.GetMaximum()
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Only reads of the stripes that may contain the value follow reading the
+		// file tail.
+		src.starts, src.ends = nil, nil
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(filtered, []int{0, 1, 2}) {
+			t.Errorf("Test failed, value %d expected the filter to be called for every stripe got %v", tc.value, filtered)
+		}
+		var expected []int64
+		for _, stripe := range tc.expected {
+			for i := 0; i < 100; i++ {
+				expected = append(expected, int64(stripe*100+i))
+			}
+		}
+		if len(rows) != len(expected) {
+			t.Fatalf("Test failed, value %d expected %d rows got %d", tc.value, len(expected), len(rows))
+		}
+		for i, row := range rows {
+			if row[0] != expected[i] {
+				t.Errorf("Test failed, value %d expected row %d to be %d got %v", tc.value, i, expected[i], row[0])
+			}
+		}
+		for i, stripe := range r.footer.GetStripes() {
+			start := int64(stripe.GetOffset())
+			end := start + int64(stripe.GetIndexLength()+stripe.GetDataLength()+stripe.GetFooterLength())
+			var read bool
+			for j := range src.starts {
+				if src.starts[j] < end && src.ends[j] > start {
+					read = true
+				}
+			}
+			var included bool
+			for _, s := range tc.expected {
+				included = included || s == i
+			}
+			if read != included {
+				t.Errorf("Test failed, value %d expected stripe %d to be read %v got %v", tc.value, i, included, read)
+			}
+		}
+	}
+
+	if _, err := NewReader(bytes.NewReader(buf.Bytes()), WithStripeFilter(nil)); err == nil || err.Error() != "invalid stripe filter, must not be nil" {
+		t.Errorf("Test failed, expected an invalid stripe filter error got %v", err)
+	}
+
+}
+
+func TestReaderValidateUTF8(t *testing.T) {
+
+	schema, err := ParseSchema("struct<s:string,v:varchar(8),c:char(4),l:array<string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{"valid", "héllo", "abcd", []interface{}{"x"}},
+		{"a\xffb", "\xc3", "z\xe2\x82", []interface{}{"y", "\xff\xfe-"}},
+		{nil, nil, nil, nil},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Char values are padded to their length in runes, each invalid byte
+	// counting as a rune.
+	stored := [][]interface{}{
+		rows[0],
+		{"a\xffb", "\xc3", "z\xe2\x82 ", []interface{}{"y", "\xff\xfe-"}},
+		rows[2],
+	}
+	replaced := [][]interface{}{
+		rows[0],
+		{"a�b", "�", "z� ", []interface{}{"y", "�-"}},
+		rows[2],
+	}
+
+	testCases := []struct {
+		opts     []ReaderConfigFunc
+		columns  []string
+		expected [][]interface{}
+		err      string
+	}{
+		{expected: stored},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationOff)}, expected: stored},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationReplace)}, expected: replaced},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationError)}, err: `column s: row 1 of stripe 0 is not valid UTF-8: "a\xffb"`},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationError)}, columns: []string{"v"}, err: `column v: row 1 of stripe 0 is not valid UTF-8: "\xc3"`},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationError)}, columns: []string{"c"}, err: `column c: row 1 of stripe 0 is not valid UTF-8: "z\xe2\x82 "`},
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationError)}, columns: []string{"l"}, err: `column 5: value 2 of stripe 0 is not valid UTF-8: "\xff\xfe-"`},
+		// Values read as bytes are not validated.
+		{opts: []ReaderConfigFunc{WithValidateUTF8(UTF8ValidationError), WithStringsAsBytes(true)}, columns: []string{"s"}, expected: [][]interface{}{{[]byte("valid")}, {[]byte("a\xffb")}, {nil}}},
+	}
+
+	for i, tc := range testCases {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns := tc.columns
+		if columns == nil {
+			columns = schema.Columns()
+		}
+		c := r.Select(columns...)
+		var actual [][]interface{}
+		for c.Stripes() {
+			for c.Next() {
+				actual = append(actual, c.Row())
+			}
+		}
+		if err := c.Err(); tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("Test failed, case %d expected error %s got %v", i, tc.err, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("Test failed, case %d expected %q got %q", i, tc.expected, actual)
+		}
+	}
+
+	if _, err := NewReader(bytes.NewReader(buf.Bytes()), WithValidateUTF8(3)); err == nil || err.Error() != "invalid UTF-8 validation mode 3" {
+		t.Errorf("Test failed, expected an invalid mode error got %v", err)
+	}
+
+}
+
+func TestReaderAssumeUTCTimestamps(t *testing.T) {
+
+	schema, err := ParseSchema("struct<ts:timestamp,instant:timestamp with local time zone>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instants := []time.Time{
+		time.Date(2021, 7, 1, 12, 30, 0, 123456789, time.UTC),
+		time.Date(1960, 6, 15, 8, 0, 0, 250000000, time.UTC),
+		time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, instant := range instants {
+		if err := w.Write(instant, instant); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// As Spark does with spark.sql.orc.useUTCTimestamp set, the timestamps are
+	// stored relative to UTC but another timezone is recorded in the stripe
+	// footer, its writerTimezone field being replaced by one of the same length.
+	spark := bytes.Replace(buf.Bytes(), []byte("\x1a\x03UTC"), []byte("\x1a\x03EST"), 1)
+	if bytes.Equal(spark, buf.Bytes()) {
+		t.Fatal("Test failed, expected the writer timezone in the stripe footer")
+	}
+	est, err := time.LoadLocation("EST")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	testCases := []struct {
+		opts     []ReaderConfigFunc
+		expected func(time.Time) time.Time
+	}{
+		// The offset of EST is applied a second time.
+		{expected: func(ts time.Time) time.Time { return ts.Add(5 * time.Hour).In(est) }},
+		{opts: []ReaderConfigFunc{WithAssumeUTCTimestamps(false)}, expected: func(ts time.Time) time.Time { return ts.Add(5 * time.Hour).In(est) }},
+		{opts: []ReaderConfigFunc{WithAssumeUTCTimestamps(true)}, expected: func(ts time.Time) time.Time { return ts }},
+	}
+	for _, tc := range testCases {
+		r, err := NewReader(bytes.NewReader(spark), tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(instants) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(instants), len(rows))
+		}
+		for i, row := range rows {
+			expected := tc.expected(instants[i])
+			if ts := row[0].(time.Time); !ts.Equal(expected) || ts.Location().String() != expected.Location().String() {
+				t.Errorf("Test failed, expected timestamp %v got %v", expected, ts)
+			}
+			// Instants are always stored relative to UTC.
+			if ts := row[1].(time.Time); !ts.Equal(instants[i]) {
+				t.Errorf("Test failed, expected instant %v got %v", instants[i], ts)
+			}
+		}
+	}
+
+}
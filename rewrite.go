@@ -0,0 +1,131 @@
+package orc
+
+import (
+	"fmt"
+	"io"
+
+	gproto "github.com/golang/protobuf/proto"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// RewriteStatistics rewrites the column statistics of the ORC file rw, such as to
+// correct the statistics of a writer that recorded them wrongly. newStats is called
+// with the statistics of each column of each stripe and of the whole file, the
+// stripe being -1 for the statistics of the file, and returns the statistics to
+// store in their place, or nil to keep them. The statistics of row groups, which
+// are stored within the stripes, are left unchanged.
+//
+// Only the metadata, footer and postscript at the end of the file are rewritten,
+// so the stripes and their offsets are unchanged. The new end of the file replaces
+// the old one when it is no shorter or rw can be truncated by a Truncate method,
+// such as that of *os.File, otherwise it is written after the old one.
+func RewriteStatistics(rw io.ReadWriteSeeker, newStats func(stripe int, col int, old ColumnStatistics) ColumnStatistics) error {
+	size, err := rw.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	r, err := NewReader(&readSeekerAt{rs: rw, size: size})
+	if err != nil {
+		return err
+	}
+	replace := func(stripe int, stats []*proto.ColumnStatistics) {
+		for col, old := range stats {
+			if s := newStats(stripe, col, BaseStatistics{old}); s != nil {
+				stats[col] = s.Statistics()
+			}
+		}
+	}
+	replace(-1, r.footer.GetStatistics())
+	for stripe, stats := range r.metadata.GetStripeStats() {
+		replace(stripe, stats.GetColStats())
+	}
+
+	postScript := r.postScript
+	offset := size - int64(len(r.rawPostScript)) - 1 - int64(postScript.GetFooterLength()) - int64(postScript.GetMetadataLength())
+	metadata, err := gproto.Marshal(r.metadata)
+	if err != nil {
+		return err
+	}
+	footer, err := gproto.Marshal(r.footer)
+	if err != nil {
+		return err
+	}
+	// A file without metadata has no stripe statistics to rewrite.
+	if postScript.GetMetadataLength() == 0 {
+		metadata = nil
+	}
+	metadata = originalChunks(postScript, metadata)
+	footer = originalChunks(postScript, footer)
+	postScript.MetadataLength = ptrUint64(uint64(len(metadata)))
+	postScript.FooterLength = ptrUint64(uint64(len(footer)))
+	ps, err := gproto.Marshal(postScript)
+	if err != nil {
+		return err
+	}
+	if len(ps) >= maxPostScriptSize {
+		return fmt.Errorf("postscript of %d bytes exceeds the maximum of %d bytes", len(ps), maxPostScriptSize-1)
+	}
+	tail := append(append(append(metadata, footer...), ps...), byte(len(ps)))
+
+	// Replace the old tail if the new one is no shorter or the file can be
+	// truncated at its end, otherwise write it after the old tail.
+	truncater, canTruncate := rw.(interface{ Truncate(size int64) error })
+	if offset+int64(len(tail)) < size && !canTruncate {
+		offset = size
+	}
+	if _, err := rw.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := rw.Write(tail); err != nil {
+		return err
+	}
+	if end := offset + int64(len(tail)); end < size {
+		return truncater.Truncate(end)
+	}
+	return nil
+}
+
+// originalChunks returns b as stored in the file of the postscript, being divided
+// into original chunks of at most the compression block size when the file is
+// compressed.
+func originalChunks(postScript *proto.PostScript, b []byte) []byte {
+	if postScript.GetCompression() == proto.CompressionKind_NONE {
+		return b
+	}
+	chunkLength := int(postScript.GetCompressionBlockSize())
+	if chunkLength <= 0 || chunkLength > maxChunkLength {
+		chunkLength = maxChunkLength
+	}
+	var out []byte
+	for len(b) > 0 {
+		n := minInt(chunkLength, len(b))
+		header := uint32(n)<<1 | 1
+		out = append(out, byte(header), byte(header>>8), byte(header>>16))
+		out = append(out, b[:n]...)
+		b = b[n:]
+	}
+	return out
+}
+
+// readSeekerAt is a SizedReaderAt reading from an io.ReadSeeker of the provided
+// size, seeking to the offset of each read.
+type readSeekerAt struct {
+	rs   io.ReadSeeker
+	size int64
+}
+
+func (r *readSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.rs.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r.rs, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *readSeekerAt) Size() int64 {
+	return r.size
+}
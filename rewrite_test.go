@@ -0,0 +1,230 @@
+package orc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// memFile is an in-memory io.ReadWriteSeeker which can be truncated.
+type memFile struct {
+	b   []byte
+	off int64
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.off >= int64(len(f.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.b[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.off + int64(len(p)); end > int64(len(f.b)) {
+		f.b = append(f.b, make([]byte, end-int64(len(f.b)))...)
+	}
+	n := copy(f.b[f.off:], p)
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += int64(len(f.b))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.b = f.b[:size]
+	return nil
+}
+
+// intMinimum returns statistics of an integer column with the provided minimum.
+func intMinimum(old ColumnStatistics, minimum int64) ColumnStatistics {
+	stats := *old.Statistics()
+	intStats := *stats.GetIntStatistics()
+	intStats.Minimum = &minimum
+	stats.IntStatistics = &intStats
+	return BaseStatistics{&stats}
+}
+
+func TestRewriteStatistics(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two stripes, with minimums of 10 and 1.
+	stripeMinimums := []int64{10, 1}
+	for _, minimum := range stripeMinimums {
+		for i := minimum; i < 20; i++ {
+			if err := w.Write(i, "text"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		rw   func(b []byte) (io.ReadWriteSeeker, func() []byte)
+	}{
+		{
+			name: "truncate",
+			rw: func(b []byte) (io.ReadWriteSeeker, func() []byte) {
+				f := &memFile{b: b}
+				return f, func() []byte { return f.b }
+			},
+		},
+		{
+			name: "append",
+			rw: func(b []byte) (io.ReadWriteSeeker, func() []byte) {
+				f := &memFile{b: b}
+				return struct{ io.ReadWriteSeeker }{f}, func() []byte { return f.b }
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		rw, file := tc.rw(append([]byte(nil), buf.Bytes()...))
+
+		// Record a deliberately wrong minimum for the integer column.
+		err := RewriteStatistics(rw, func(stripe int, col int, old ColumnStatistics) ColumnStatistics {
+			if col != 1 {
+				return nil
+			}
+			return intMinimum(old, -1<<40)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(bytes.NewReader(file()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if minimum := r.footer.GetStatistics()[1].GetIntStatistics().GetMinimum(); minimum != -1<<40 {
+			t.Errorf("Test failed, %s expected the wrong minimum got %d", tc.name, minimum)
+		}
+
+		// Correct the minimum, which shortens the footer and metadata.
+		err = RewriteStatistics(rw, func(stripe int, col int, old ColumnStatistics) ColumnStatistics {
+			if col != 1 {
+				return nil
+			}
+			if stripe == -1 {
+				return intMinimum(old, 1)
+			}
+			return intMinimum(old, stripeMinimums[stripe])
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		size := len(file())
+		if tc.name == "truncate" && size != buf.Len() {
+			t.Errorf("Test failed, %s expected %d bytes got %d", tc.name, buf.Len(), size)
+		}
+		if tc.name == "append" && size <= buf.Len() {
+			t.Errorf("Test failed, %s expected more than %d bytes got %d", tc.name, buf.Len(), size)
+		}
+
+		r, err = NewReader(bytes.NewReader(file()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if minimum := r.footer.GetStatistics()[1].GetIntStatistics().GetMinimum(); minimum != 1 {
+			t.Errorf("Test failed, %s expected file minimum 1 got %d", tc.name, minimum)
+		}
+		for i, stats := range r.metadata.GetStripeStats() {
+			if minimum := stats.GetColStats()[1].GetIntStatistics().GetMinimum(); minimum != stripeMinimums[i] {
+				t.Errorf("Test failed, %s expected stripe %d minimum %d got %d", tc.name, i, stripeMinimums[i], minimum)
+			}
+		}
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 29 || rows[0][0] != int64(10) || rows[10][0] != int64(1) {
+			t.Errorf("Test failed, %s expected the rows to be unchanged got %v", tc.name, rows)
+		}
+	}
+
+}
+
+func TestRewriteStatisticsCompressed(t *testing.T) {
+
+	b, err := ioutil.ReadFile("./examples/TestOrcFile.testStripeLevelStats.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.postScript.GetCompression() == proto.CompressionKind_NONE {
+		t.Fatal("Test failed, expected a compressed file")
+	}
+	expected, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &memFile{b: b}
+	var stripes int
+	err = RewriteStatistics(f, func(stripe int, col int, old ColumnStatistics) ColumnStatistics {
+		if col != 1 {
+			return nil
+		}
+		stripes++
+		return intMinimum(old, int64(stripe))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = NewReader(bytes.NewReader(f.b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripes != len(r.footer.GetStripes())+1 {
+		t.Errorf("Test failed, expected statistics for %d stripes and the file got %d", len(r.footer.GetStripes()), stripes)
+	}
+	if minimum := r.footer.GetStatistics()[1].GetIntStatistics().GetMinimum(); minimum != -1 {
+		t.Errorf("Test failed, expected file minimum -1 got %d", minimum)
+	}
+	for i, stats := range r.metadata.GetStripeStats() {
+		if minimum := stats.GetColStats()[1].GetIntStatistics().GetMinimum(); minimum != int64(i) {
+			t.Errorf("Test failed, expected stripe %d minimum %d got %d", i, i, minimum)
+		}
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), len(actual))
+	}
+
+}
@@ -0,0 +1,213 @@
+package orc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RowToJSON returns a row of the columns of schema, a struct such as the schema of
+// a file for a row of every top level column, as a JSON object with a field for
+// each column in the order of the schema. Nulls are written as null, timestamps
+// in RFC 3339, dates as "2006-01-02", binary values in base64 and decimals as
+// strings holding their exact value. Structs are written as objects with their
+// fields in the order of the schema, maps as lists of objects with "_key" and
+// "_value" fields and unions as objects with "tag" and "value" fields, following
+// the output of the Java orc-tools data command. NaN and infinite floating point
+// values are written as the strings "NaN", "Infinity" and "-Infinity".
+func RowToJSON(schema *TypeDescription, row []interface{}) ([]byte, error) {
+	if schema.category != CategoryStruct {
+		return nil, fmt.Errorf("schema of a row must be a struct and not %s", schema.category)
+	}
+	return ColumnsToJSON(schema.fieldNames, schema.children, row)
+}
+
+// ColumnsToJSON returns a row of the columns of the provided names and types, such
+// as those returned by Cursor.Columns, as a JSON object as written by RowToJSON.
+func ColumnsToJSON(names []string, columns []*TypeDescription, row []interface{}) ([]byte, error) {
+	if len(names) != len(columns) || len(row) != len(columns) {
+		return nil, fmt.Errorf("row of %d values does not match %d columns of %d names", len(row), len(columns), len(names))
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		appendJSONString(&buf, name)
+		buf.WriteByte(':')
+		if err := appendJSONValue(&buf, columns[i], row[i]); err != nil {
+			return nil, fmt.Errorf("column %s: %v", name, err)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// appendJSONValue writes v, a value of a column of type td, as JSON.
+func appendJSONValue(buf *bytes.Buffer, td *TypeDescription, v interface{}) error {
+	if v == nil {
+		buf.WriteString("null")
+		return nil
+	}
+	var ok bool
+	switch td.category {
+	case CategoryBoolean:
+		var b bool
+		if b, ok = v.(bool); ok {
+			buf.WriteString(strconv.FormatBool(b))
+		}
+	case CategoryByte:
+		var i int8
+		if i, ok = v.(int8); ok {
+			buf.WriteString(strconv.Itoa(int(i)))
+		}
+	case CategoryShort, CategoryInt, CategoryLong:
+		var i int64
+		if i, ok = v.(int64); ok {
+			buf.WriteString(strconv.FormatInt(i, 10))
+		}
+	case CategoryFloat:
+		var f Float
+		if f, ok = v.(Float); ok {
+			appendJSONFloat(buf, float64(f), 32)
+		}
+	case CategoryDouble:
+		var f Double
+		if f, ok = v.(Double); ok {
+			appendJSONFloat(buf, float64(f), 64)
+		}
+	case CategoryString, CategoryVarchar, CategoryChar:
+		// The values are []byte when read using WithStringsAsBytes.
+		switch s := v.(type) {
+		case string:
+			appendJSONString(buf, s)
+			ok = true
+		case []byte:
+			appendJSONString(buf, string(s))
+			ok = true
+		}
+	case CategoryBinary:
+		var b []byte
+		if b, ok = v.([]byte); ok {
+			appendJSONString(buf, base64.StdEncoding.EncodeToString(b))
+		}
+	case CategoryDecimal:
+		var d Decimal
+		if d, ok = v.(Decimal); ok {
+			appendJSONString(buf, d.String())
+		}
+	case CategoryDate:
+		var d Date
+		if d, ok = v.(Date); ok {
+			appendJSONString(buf, d.Format("2006-01-02"))
+		}
+	case CategoryTimestamp, CategoryTimestampInstant:
+		var ts time.Time
+		if ts, ok = v.(time.Time); ok {
+			appendJSONString(buf, ts.Format(time.RFC3339Nano))
+		}
+	case CategoryList:
+		var l []interface{}
+		if l, ok = v.([]interface{}); ok {
+			buf.WriteByte('[')
+			for i, e := range l {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := appendJSONValue(buf, td.children[0], e); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+		}
+	case CategoryMap:
+		var m []MapEntry
+		if m, ok = v.([]MapEntry); ok {
+			buf.WriteByte('[')
+			for i, e := range m {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(`{"_key":`)
+				if err := appendJSONValue(buf, td.children[0], e.Key); err != nil {
+					return err
+				}
+				buf.WriteString(`,"_value":`)
+				if err := appendJSONValue(buf, td.children[1], e.Value); err != nil {
+					return err
+				}
+				buf.WriteByte('}')
+			}
+			buf.WriteByte(']')
+		}
+	case CategoryStruct:
+		var s Struct
+		if s, ok = v.(Struct); ok {
+			buf.WriteByte('{')
+			for i, name := range td.fieldNames {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				appendJSONString(buf, name)
+				buf.WriteByte(':')
+				if err := appendJSONValue(buf, td.children[i], s[name]); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		}
+	case CategoryUnion:
+		var u UnionValue
+		if u, ok = v.(UnionValue); ok {
+			if u.Tag < 0 || u.Tag >= len(td.children) {
+				return fmt.Errorf("union tag %d out of range", u.Tag)
+			}
+			fmt.Fprintf(buf, `{"tag":%d,"value":`, u.Tag)
+			if err := appendJSONValue(buf, td.children[u.Tag], u.Value); err != nil {
+				return err
+			}
+			buf.WriteByte('}')
+		}
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("unexpected %T value of a %s column", v, td.category)
+	}
+	return nil
+}
+
+// appendJSONFloat writes f, a floating point value of the provided size in bits,
+// as a JSON number, or as a string if it is NaN or infinite.
+func appendJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`"Infinity"`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Infinity"`)
+	default:
+		buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+}
+
+// appendJSONString writes s as a JSON string without escaping HTML characters.
+func appendJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string cannot fail.
+	_ = enc.Encode(s)
+	// Remove the newline written by Encode.
+	buf.Truncate(buf.Len() - 1)
+}
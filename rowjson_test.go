@@ -0,0 +1,147 @@
+package orc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRowToJSON(t *testing.T) {
+
+	testCases := []struct {
+		example  string
+		expected string
+	}{
+		{
+			example:  "./examples/TestOrcFile.test1.orc",
+			expected: "./examples/expected/TestOrcFile.test1.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := Open(tc.example)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		c := r.Select(r.Schema().Columns()...)
+		for c.Stripes() {
+			for c.Next() {
+				row, err := RowToJSON(r.Schema(), c.Row())
+				if err != nil {
+					t.Fatal(err)
+				}
+				buf.Write(row)
+				buf.WriteByte('\n')
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+		expected, err := ioutil.ReadFile(tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(expected, buf.Bytes()) {
+			t.Errorf("Test failed, rows of %s do not match %s, got:\n%s", tc.example, tc.expected, buf.String())
+		}
+	}
+
+}
+
+func TestRowToJSONValues(t *testing.T) {
+
+	schema, err := ParseSchema("struct<a:binary,b:float,c:double,d:tinyint,e:string,f:decimal(38,10),g:date,h:timestamp,i:uniontype<int,string>,j:map<string,int>,k:array<struct<x:int>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		column   int
+		value    interface{}
+		expected string
+	}{
+		{column: 0, value: []byte{0, 1, 2, 0xff}, expected: `"AAEC/w=="`},
+		{column: 1, value: Float(0.1), expected: `0.1`},
+		{column: 1, value: Float(float32(math.Inf(-1))), expected: `"-Infinity"`},
+		{column: 2, value: Double(math.NaN()), expected: `"NaN"`},
+		{column: 3, value: int8(-3), expected: `-3`},
+		{column: 4, value: "\"quoted\"\n&", expected: `"\"quoted\"\n&"`},
+		{column: 4, value: []byte("bytes"), expected: `"bytes"`},
+		{column: 5, value: Decimal{big.NewInt(-12345678901234567), 10}, expected: `"-1234567.8901234567"`},
+		{column: 6, value: Date{time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)}, expected: `"2020-03-01"`},
+		{column: 7, value: time.Date(2020, 3, 2, 10, 30, 0, 500, time.UTC), expected: `"2020-03-02T10:30:00.0000005Z"`},
+		{column: 8, value: UnionValue{Tag: 1, Value: "s"}, expected: `{"tag":1,"value":"s"}`},
+		{column: 9, value: []MapEntry{{Key: "k", Value: int64(1)}}, expected: `[{"_key":"k","_value":1}]`},
+		{column: 10, value: []interface{}{Struct{"x": int64(2)}, nil}, expected: `[{"x":2},null]`},
+		{column: 10, value: nil, expected: `null`},
+	}
+
+	for _, tc := range testCases {
+		row := make([]interface{}, len(schema.children))
+		row[tc.column] = tc.value
+		b, err := RowToJSON(schema, row)
+		if err != nil {
+			t.Errorf("Test failed, %v returned an error: %v", tc.value, err)
+			continue
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Errorf("Test failed, %s is not a valid object: %v", b, err)
+			continue
+		}
+		if actual := string(m[schema.fieldNames[tc.column]]); actual != tc.expected {
+			t.Errorf("Test failed, expected %s got %s", tc.expected, actual)
+		}
+	}
+
+	if _, err := RowToJSON(schema, make([]interface{}, 2)); err == nil {
+		t.Errorf("Test failed, expected an error for a row of the wrong length")
+	}
+
+	row := make([]interface{}, len(schema.children))
+	row[4] = int64(1)
+	if _, err := RowToJSON(schema, row); err == nil || err.Error() != "column e: unexpected int64 value of a string column" {
+		t.Errorf("Test failed, expected an unexpected value error got %v", err)
+	}
+
+	if _, err := RowToJSON(schema.children[0], nil); err == nil {
+		t.Errorf("Test failed, expected an error for a schema that is not a struct")
+	}
+
+}
+
+func TestValueMarshalJSON(t *testing.T) {
+
+	testCases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: Decimal{big.NewInt(-8361232), 4}, expected: `"-836.1232"`},
+		{value: Date{time.Date(1900, 12, 25, 0, 0, 0, 0, time.UTC)}, expected: `"1900-12-25"`},
+		{value: Float(1.5), expected: `1.5`},
+		{value: Float(float32(math.NaN())), expected: `"NaN"`},
+		{value: Double(math.Inf(1)), expected: `"Infinity"`},
+		{value: Double(0.1), expected: `0.1`},
+		{value: UnionValue{Tag: 0, Value: int64(3)}, expected: `{"tag":0,"value":3}`},
+		{value: []byte{0xff}, expected: `"/w=="`},
+		{value: time.Date(2020, 3, 2, 10, 30, 0, 0, time.UTC), expected: `"2020-03-02T10:30:00Z"`},
+	}
+
+	for _, tc := range testCases {
+		b, err := json.Marshal(tc.value)
+		if err != nil {
+			t.Errorf("Test failed, %v returned an error: %v", tc.value, err)
+			continue
+		}
+		if actual := string(b); actual != tc.expected {
+			t.Errorf("Test failed, expected %s got %s", tc.expected, actual)
+		}
+	}
+
+}
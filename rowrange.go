@@ -0,0 +1,70 @@
+package orc
+
+import "sort"
+
+// RowRange is a range of the rows of a file, from the row numbered Start within
+// the file up to but excluding the row numbered End, as numbered by
+// Cursor.RowNumber.
+type RowRange struct {
+	Start int64
+	End   int64
+}
+
+// SplitRanges divides the rows of the file into up to n contiguous RowRanges of
+// roughly equal numbers of rows, such as for distributing a scan between n
+// workers. Each range starts at a stripe if one starts within a quarter of the
+// average number of rows of a range of where the range would ideally start, and
+// otherwise at the row group nearest to it, so that workers read whole stripes
+// where possible. Fewer than n ranges are returned if the file has fewer row
+// groups, and none if it has no rows. A worker reads a range by excluding the
+// stripes outside of it with WithStripeFilter and skipping to Start with
+// Cursor.Skip. The ranges ignore both WithStripeFilter and Limit.
+func (r *Reader) SplitRanges(n int) []RowRange {
+	stripes := r.footer.GetStripes()
+	// starts are the numbers of the first rows of the stripes followed by the
+	// number of rows of the file.
+	starts := make([]int64, len(stripes)+1)
+	for i, stripe := range stripes {
+		starts[i+1] = starts[i] + int64(stripe.GetNumberOfRows())
+	}
+	total := starts[len(stripes)]
+	if total == 0 {
+		return nil
+	}
+	if n < 1 {
+		n = 1
+	}
+	stride := int64(r.footer.GetRowIndexStride())
+	tolerance := total / (4 * int64(n))
+
+	var ranges []RowRange
+	var start int64
+	for i := 1; i < n; i++ {
+		ideal := total * int64(i) / int64(n)
+		// The stripe is the index of the last stripe starting at or before
+		// the ideal row.
+		stripe := sort.Search(len(starts), func(j int) bool { return starts[j] > ideal }) - 1
+		boundary := nearestRow(ideal, starts[stripe], starts[stripe+1])
+		if (ideal-boundary > tolerance || boundary-ideal > tolerance) && stride > 0 {
+			// Row groups start every stride rows from the start of each
+			// stripe.
+			groupStart := starts[stripe] + (ideal-starts[stripe])/stride*stride
+			boundary = nearestRow(ideal, groupStart, minInt64(groupStart+stride, starts[stripe+1]))
+		}
+		if boundary <= start || boundary >= total {
+			continue
+		}
+		ranges = append(ranges, RowRange{Start: start, End: boundary})
+		start = boundary
+	}
+	return append(ranges, RowRange{Start: start, End: total})
+}
+
+// nearestRow returns whichever of the rows before and after is nearest to row,
+// preferring before if they are as near.
+func nearestRow(row, before, after int64) int64 {
+	if after-row < row-before {
+		return after
+	}
+	return before
+}
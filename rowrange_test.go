@@ -0,0 +1,136 @@
+package orc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestReaderSplitRanges(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stripes of unequal sizes, starting at rows 0, 25000 and 30000.
+	stripeRows := []int{25000, 5000, 42000}
+	var id int64
+	for _, rows := range stripeRows {
+		for i := 0; i < rows; i++ {
+			if err := w.Write(id); err != nil {
+				t.Fatal(err)
+			}
+			id++
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := id
+
+	testCases := []struct {
+		n        int
+		expected []RowRange
+	}{
+		{n: 0, expected: []RowRange{{0, 72000}}},
+		{n: 1, expected: []RowRange{{0, 72000}}},
+		// The first range ends at the stripe starting near its ideal end of
+		// row 24000, the second at the row group nearest to row 48000.
+		{n: 3, expected: []RowRange{{0, 25000}, {25000, 50000}, {50000, 72000}}},
+		{n: 4, expected: []RowRange{{0, 20000}, {20000, 40000}, {40000, 50000}, {50000, 72000}}},
+		// There are only 9 row groups.
+		{n: 100},
+	}
+	for _, tc := range testCases {
+		ranges := r.SplitRanges(tc.n)
+		if tc.expected != nil && !reflect.DeepEqual(ranges, tc.expected) {
+			t.Errorf("Test failed, %d ranges expected %v got %v", tc.n, tc.expected, ranges)
+		}
+		var start int64
+		for _, rr := range ranges {
+			if rr.Start != start || rr.End <= rr.Start {
+				t.Errorf("Test failed, %d ranges expected a range starting at %d got %v", tc.n, start, ranges)
+			}
+			// The row groups all start at multiples of the row index stride
+			// but for that of the stripe starting at row 25000.
+			if rr.Start != 25000 && rr.Start%int64(DefaultRowIndexStride) != 0 {
+				t.Errorf("Test failed, %d ranges expected a range starting at a stripe or row group got %v", tc.n, rr)
+			}
+			start = rr.End
+		}
+		if start != total {
+			t.Errorf("Test failed, %d ranges expected to cover %d rows got %v", tc.n, total, ranges)
+		}
+	}
+	if ranges := r.SplitRanges(100); len(ranges) != 9 {
+		t.Errorf("Test failed, expected 9 ranges got %d", len(ranges))
+	}
+
+	// Each range is read by excluding the stripes outside it and skipping to its
+	// start.
+	starts := []int64{0, 25000, 30000, 72000}
+	for _, rr := range r.SplitRanges(4) {
+		rr := rr
+		filter := WithStripeFilter(func(stripe int, stats []ColumnStatistics) bool {
+			return starts[stripe+1] > rr.Start && starts[stripe] < rr.End
+		})
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select("id")
+		var rows int64
+		for c.Stripes() {
+			if rows == 0 {
+				// Skip to the start of the range within the first stripe.
+				for i := range stripeRows {
+					if starts[i] <= rr.Start && rr.Start < starts[i+1] {
+						c.Skip(int(rr.Start - starts[i]))
+					}
+				}
+			}
+			for c.Next() && c.RowNumber() < rr.End {
+				if id := c.Row()[0].(int64); id != rr.Start+rows {
+					t.Fatalf("Test failed, range %v expected id %d got %d", rr, rr.Start+rows, id)
+				}
+				rows++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if rows != rr.End-rr.Start {
+			t.Errorf("Test failed, range %v expected %d rows got %d", rr, rr.End-rr.Start, rows)
+		}
+	}
+
+	// A file without rows has no ranges.
+	buf.Reset()
+	w, err = NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err = NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranges := r.SplitRanges(4); ranges != nil {
+		t.Errorf("Test failed, expected no ranges got %v", ranges)
+	}
+
+}
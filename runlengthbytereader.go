@@ -1,6 +1,9 @@
 package orc
 
-import "io"
+import (
+	"io"
+	"math/bits"
+)
 
 // RunLengthByteReader reads a byte run length encoded stream from ByteReader r.
 type RunLengthByteReader struct {
@@ -62,6 +65,41 @@ func (b *RunLengthByteReader) Byte() byte {
 	return result
 }
 
+// Skip skips the next n bytes. The bytes of a repeated run are skipped without
+// reading them, so skipping is proportional to the number of runs rather than
+// bytes.
+func (b *RunLengthByteReader) Skip(n uint64) error {
+	_, err := b.skipBytes(n)
+	return err
+}
+
+// skipBytes skips the next n bytes, returning the number of bits set in them.
+func (b *RunLengthByteReader) skipBytes(n uint64) (uint64, error) {
+	var ones uint64
+	for n > 0 {
+		if b.used == b.numLiterals {
+			if err := b.readValues(); err != nil {
+				b.err = err
+				return ones, err
+			}
+		}
+		k := uint64(b.numLiterals - b.used)
+		if k > n {
+			k = n
+		}
+		if b.repeat {
+			ones += k * uint64(bits.OnesCount8(b.literals[0]))
+		} else {
+			for _, byt := range b.literals[b.used : b.used+int(k)] {
+				ones += uint64(bits.OnesCount8(byt))
+			}
+		}
+		b.used += int(k)
+		n -= k
+	}
+	return ones, nil
+}
+
 func (b *RunLengthByteReader) readValues() error {
 	control, err := b.ReadByte()
 	if err != nil {
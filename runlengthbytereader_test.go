@@ -63,6 +63,39 @@ func TestRunLengthByteReader(t *testing.T) {
 
 }
 
+func TestRunLengthByteReaderSkip(t *testing.T) {
+	// 100 zeros, the literals 0x44 and 0x45, then 4 values of 0x01.
+	input := []byte{0x61, 0x00, 0xfe, 0x44, 0x45, 0x01, 0x01}
+	var values []byte
+	bs := NewRunLengthByteReader(bytes.NewReader(input))
+	for bs.Next() {
+		values = append(values, bs.Byte())
+	}
+
+	for skip := 0; skip <= len(values); skip++ {
+		bs := NewRunLengthByteReader(bytes.NewReader(input))
+		// Skip after a call to Next, which reads ahead a byte of the stream.
+		if skip%2 == 1 && !bs.Next() {
+			t.Fatal(bs.Err())
+		}
+		if err := bs.Skip(uint64(skip)); err != nil {
+			t.Fatal(err)
+		}
+		var output []byte
+		for bs.Next() {
+			output = append(output, bs.Byte())
+		}
+		if !bytes.Equal(output, values[skip:]) {
+			t.Errorf("Test failed, skipping %d expected %v got %v", skip, values[skip:], output)
+		}
+	}
+
+	bs = NewRunLengthByteReader(bytes.NewReader(input))
+	if err := bs.Skip(uint64(len(values) + 1)); err != io.EOF {
+		t.Errorf("Test failed, expected io.EOF got %v", err)
+	}
+}
+
 func BenchmarkRunLengthByteReader(b *testing.B) {
 	input := bytes.Repeat([]byte{0x61, 0x00}, b.N)
 	bs := NewRunLengthByteReader(bytes.NewReader(input))
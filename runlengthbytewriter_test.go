@@ -39,6 +39,36 @@ func TestRunLengthByteWriter(t *testing.T) {
 				}
 			},
 		},
+		{
+			// The maximum run length is 130.
+			input: bytes.Repeat([]byte{0x01}, 131),
+			expect: func(output []byte) {
+				expected := []byte{0x7f, 0x01, 0xff, 0x01}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, got %v expected %v", output, expected)
+				}
+			},
+		},
+		{
+			// The maximum literal length is 128.
+			input: sequentialBytes(129),
+			expect: func(output []byte) {
+				expected := append(append([]byte{0x80}, sequentialBytes(128)...), 0xff, 0x80)
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, got %v expected %v", output, expected)
+				}
+			},
+		},
+		{
+			// A literal sequence followed by a run.
+			input: []byte{0x01, 0x02, 0x03, 0x03, 0x03},
+			expect: func(output []byte) {
+				expected := []byte{0xfe, 0x01, 0x02, 0x00, 0x03}
+				if !reflect.DeepEqual(expected, output) {
+					t.Errorf("Test failed, got %v expected %v", output, expected)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -84,3 +114,11 @@ func TestWriteReadBytes(t *testing.T) {
 		index++
 	}
 }
+
+func sequentialBytes(n int) []byte {
+	byt := make([]byte, n)
+	for i := range byt {
+		byt[i] = byte(i)
+	}
+	return byt
+}
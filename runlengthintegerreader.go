@@ -4,6 +4,9 @@ import (
 	"io"
 )
 
+// RunLengthIntegerReader reads integers encoded by version 1 of the run length
+// encoding, which files of version 0.11 use for their DIRECT and DICTIONARY
+// column encodings.
 type RunLengthIntegerReader struct {
 	r             io.ByteReader
 	signed        bool
@@ -92,6 +95,9 @@ func (r *RunLengthIntegerReader) ReadByte() (byte, error) {
 }
 
 func (r *RunLengthIntegerReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
 	return r.used != r.numLiterals || r.available() == nil
 }
 
@@ -100,6 +106,12 @@ func (r *RunLengthIntegerReader) Int() int64 {
 	if r.used == r.numLiterals {
 		err := r.readValues()
 		if err != nil {
+			// A stream ending within a run is corrupt.
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			r.err = err
+			r.numLiterals, r.used = 0, 0
 			return 0
 		}
 	}
@@ -2,8 +2,11 @@ package orc
 
 import (
 	"bytes"
+	"io"
 	"reflect"
 	"testing"
+
+	"code.simon-critchley.co.uk/orc/proto"
 )
 
 func progression(add int64) func(prev int64) int64 {
@@ -63,6 +66,52 @@ func TestRunLengthIntegerReader(t *testing.T) {
 				}
 			},
 		},
+		{
+			// A signed run of 3 values with a delta of -2 from the zigzag encoded
+			// base -1.
+			signed: true,
+			input:  []byte{0x00, 0xfe, 0x01},
+			expect: func(output []int64) {
+				expected := []int64{-1, -3, -5}
+				if !reflect.DeepEqual(output, expected) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// A signed run of 5 values with the largest delta from a base of
+			// -1000, whose varint spans two bytes.
+			signed: true,
+			input:  []byte{0x02, 0x7f, 0xcf, 0x0f},
+			expect: func(output []int64) {
+				expected := makeInt64Slice(-1000, progression(127), 5)
+				if !reflect.DeepEqual(output, expected) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// A signed run with the smallest delta followed by signed literals.
+			signed: true,
+			input:  []byte{0x01, 0x80, 0x80, 0x02, 0xfd, 0x01, 0x02, 0x7f},
+			expect: func(output []int64) {
+				expected := append(makeInt64Slice(128, progression(-128), 4), -1, 1, -64)
+				if !reflect.DeepEqual(output, expected) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
+		{
+			// An unsigned literal of the largest value followed by a run.
+			signed: false,
+			input:  []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0x00, 0x01, 0x05},
+			expect: func(output []int64) {
+				expected := []int64{-1, 5, 6, 7}
+				if !reflect.DeepEqual(output, expected) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -73,5 +122,105 @@ func TestRunLengthIntegerReader(t *testing.T) {
 			output = append(output, v)
 		}
 		tc.expect(output)
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Errorf("Test failed, %v returned an error: %v", tc.input, err)
+		}
+	}
+}
+
+func TestRunLengthIntegerReaderTruncated(t *testing.T) {
+	testCases := []struct {
+		input    []byte
+		expected []int64
+	}{
+		// A run without its base.
+		{input: []byte{0x61, 0x00}},
+		// A run with an unterminated varint base.
+		{input: []byte{0x61, 0x00, 0x80}},
+		// Literals ending before the last of them.
+		{input: []byte{0xfb, 0x02, 0x03}},
+		// A run followed by a run header alone.
+		{input: []byte{0x00, 0x01, 0x02, 0x00}, expected: []int64{1, 2, 3}},
 	}
+
+	for _, tc := range testCases {
+		r := NewRunLengthIntegerReader(bytes.NewReader(tc.input), true)
+		var output []int64
+		for r.Next() {
+			v := r.Int()
+			if r.Err() != nil {
+				break
+			}
+			output = append(output, v)
+		}
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("Test failed, %v expected %v got %v", tc.input, tc.expected, output)
+		}
+		if err := r.Err(); err != io.ErrUnexpectedEOF {
+			t.Errorf("Test failed, %v expected an unexpected EOF error got %v", tc.input, err)
+		}
+		if r.Next() {
+			t.Errorf("Test failed, %v expected no values after an error", tc.input)
+		}
+	}
+}
+
+func TestReadVersion11Integers(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Runs with positive and negative deltas, signed literals and values needing
+	// varints of every length.
+	var values []int64
+	values = append(values, makeInt64Slice(1000, progression(-3), 200)...)
+	values = append(values, makeInt64Slice(-5, progression(127), 130)...)
+	values = append(values, makeInt64Slice(7, nil, 10)...)
+	values = append(values, -1, 1, -64, 64, 1<<62, -1<<63, 1<<63-1, 0)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithFormatVersion("0.11"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.getStreams(1); err != nil {
+		t.Fatal(err)
+	}
+	encoding, err := r.getColumn(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := encoding.GetKind(); kind != proto.ColumnEncoding_DIRECT {
+		t.Errorf("Test failed, expected DIRECT encoding got %s", kind)
+	}
+	r, err = NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := make([]int64, len(rows))
+	for i, row := range rows {
+		actual[i] = row[0].(int64)
+	}
+	if !reflect.DeepEqual(actual, values) {
+		t.Errorf("Test failed, expected %v got %v", values, actual)
+	}
+
 }
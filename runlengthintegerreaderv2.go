@@ -8,6 +8,7 @@ import (
 
 var (
 	ErrEOFUnsignedVInt = errors.New("EOF while reading unsigned vint")
+	ErrVarintOverflow  = errors.New("varint overflows a 64 bit integer")
 	ErrCorrupt         = errors.New("ORC file is corrupt")
 )
 
@@ -114,6 +115,12 @@ func (r *RunLengthIntegerReaderV2) readValues(ignoreEOF bool) error {
 		return fmt.Errorf("Read past end of RLE integer from from %v", r)
 	}
 	r.currentEncoding = RLEEncodingType((uint64(firstByte) >> 6) & 0x03)
+	return r.readRun(firstByte)
+}
+
+// readRun reads the values of the run starting with firstByte, encoded as the
+// current encoding.
+func (r *RunLengthIntegerReaderV2) readRun(firstByte byte) error {
 	switch r.currentEncoding {
 	case RLEV2IntShortRepeat:
 		return r.readShortRepeatValues(firstByte)
@@ -259,8 +266,23 @@ func (r *RunLengthIntegerReaderV2) readShortRepeatValues(firstByte byte) error {
 	return nil
 }
 func (r *RunLengthIntegerReaderV2) readDirectValues(firstByte byte) error {
+	l, err := r.decodeDirectValues(r.literals, r.numLiterals, firstByte)
+	if err != nil {
+		return err
+	}
+	r.numLiterals += l
+	return nil
+}
 
-	// extract the number of fixed bits
+// decodeDirectValues decodes the values of the direct run starting with firstByte
+// into buffer from offset, returning the length of the run. The buffer must have
+// room for MaxScope values from offset.
+func (r *RunLengthIntegerReaderV2) decodeDirectValues(buffer []int64, offset int, firstByte byte) (int, error) {
+
+	// extract the number of fixed bits, the five bit value is an index into
+	// the fixed bit sizes rather than the width itself, e.g. 24 maps to 26.
+	// Widths that are not a multiple of 8 are read MSB first across byte
+	// boundaries with any padding bits at the end of the run discarded.
 	fbo := (uint64(firstByte) >> 1) & 0x1f
 	fb := uint64(decodeBitWidth(int(fbo)))
 
@@ -268,30 +290,70 @@ func (r *RunLengthIntegerReaderV2) readDirectValues(firstByte byte) error {
 	l := int((int64(firstByte) & 0x01) << 8)
 	nextByte, err := r.ReadByte()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	l |= int(nextByte)
 	// runs are one off
 	l++
 
 	// write the unpacked values and zigzag decode to result buffer
-	err = readInts(r.literals, r.numLiterals, l, int(fb), r)
+	err = readInts(buffer, offset, l, int(fb), r)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if r.signed {
-		for i := 0; i < l; i++ {
-			r.literals[r.numLiterals] = zigzagDecode(uint64(r.literals[r.numLiterals]))
-			r.numLiterals++
+		for i := offset; i < offset+l; i++ {
+			buffer[i] = zigzagDecode(uint64(buffer[i]))
 		}
-	} else {
-
-		r.numLiterals += l
 	}
 
-	return nil
+	return l, nil
+}
+
+// readInt64s reads up to len(dst) values into dst, returning the number of values
+// read. Direct runs are decoded straight into dst where it has room for them, and
+// the values of other runs are copied from the buffer of the reader. An error,
+// such as io.EOF at the end of the stream, is returned when fewer than len(dst)
+// values are read.
+func (r *RunLengthIntegerReaderV2) readInt64s(dst []int64) (int, error) {
+	var n int
+	for n < len(dst) {
+		if r.used < r.numLiterals {
+			k := copy(dst[n:], r.literals[r.used:r.numLiterals])
+			r.used += k
+			n += k
+			continue
+		}
+		if r.err != nil {
+			return n, r.err
+		}
+		firstByte, err := r.ReadByte()
+		if err != nil {
+			r.err = err
+			return n, err
+		}
+		r.numLiterals = 0
+		r.used = 0
+		r.isRepeating = false
+		r.currentEncoding = RLEEncodingType((uint64(firstByte) >> 6) & 0x03)
+		if r.currentEncoding == RLEV2IntDirect && len(dst)-n >= MaxScope {
+			l, err := r.decodeDirectValues(dst, n, firstByte)
+			if err != nil {
+				r.err = err
+				return n, err
+			}
+			n += l
+			continue
+		}
+		if err := r.readRun(firstByte); err != nil {
+			r.err = err
+			return n, err
+		}
+	}
+	return n, nil
 }
+
 func (r *RunLengthIntegerReaderV2) readPatchedBaseValues(firstByte byte) error {
 	// extract the number of fixed bits
 	fixedBits := decodeBitWidth(int(uint64(firstByte) >> 1 & 0x1f))
@@ -359,26 +421,16 @@ func (r *RunLengthIntegerReaderV2) readPatchedBaseValues(firstByte byte) error {
 		return err
 	}
 
-	var patchIndex int
-	var currentGap int64
-	var currentPatch int64
+	if patchListLength == 0 {
+		return errors.New("patched base run has an empty patch list")
+	}
 
 	patchMask := int64((1 << uint(patchWidth)) - 1)
 
-	currentGap = int64(uint64(unpackedPatch[patchIndex]) >> uint64(patchWidth))
-	currentPatch = unpackedPatch[patchIndex] & patchMask
-	var actualGap int64
-
-	// special case: gap is >255 then patch value will be 0.
-	// if gap is <=255 then patch value cannot be 0
-	for currentGap == 255 && currentPatch == 0 {
-		actualGap += 255
-		patchIndex++
-		currentGap = int64(uint64(unpackedPatch[patchIndex]) >> uint64(patchWidth))
-		currentPatch = unpackedPatch[patchIndex] & patchMask
+	patchIndex, actualGap, currentPatch, err := nextPatch(unpackedPatch, 0, patchWidth, patchMask)
+	if err != nil {
+		return err
 	}
-	// add the left over gap
-	actualGap += currentGap
 	// unpack data blob, patch it (if required), add base to get final result
 	for i := 0; i < len(unpacked); i++ {
 		if i == int(actualGap) {
@@ -394,21 +446,13 @@ func (r *RunLengthIntegerReaderV2) readPatchedBaseValues(firstByte byte) error {
 
 			if patchIndex < int(patchListLength) {
 				// read the next gap and patch
-				currentGap = int64(uint64(unpackedPatch[patchIndex]) >> uint64(patchWidth))
-				currentPatch = unpackedPatch[patchIndex] & patchMask
-				actualGap = 0
-				// special case: gap is >255 then patch will be 0. if gap is
-				// <=255 then patch cannot be 0
-				for currentGap == 255 && currentPatch == 0 {
-					actualGap += 255
-					patchIndex++
-					currentGap = int64(uint64(unpackedPatch[patchIndex]) >> uint64(patchWidth))
-					currentPatch = unpackedPatch[patchIndex] & patchMask
+				var gap int64
+				patchIndex, gap, currentPatch, err = nextPatch(unpackedPatch, patchIndex, patchWidth, patchMask)
+				if err != nil {
+					return err
 				}
-				// add the left over gap
-				actualGap += currentGap
 				// next gap is relative to the current gap
-				actualGap += int64(i)
+				actualGap = gap + int64(i)
 			}
 		} else {
 			// no patching required. add base to unpacked value to get final value
@@ -420,6 +464,24 @@ func (r *RunLengthIntegerReaderV2) readPatchedBaseValues(firstByte byte) error {
 	return nil
 }
 
+// nextPatch returns the gap and patch of the entry of the patch list at index,
+// along with the index of the entry holding the patch. A gap greater than 255 is
+// encoded as entries with a gap of 255 and a patch of 0, each extending the gap of
+// the following entry by 255, as a patch of a value can never be 0.
+func nextPatch(unpackedPatch []int64, index int, patchWidth int, patchMask int64) (int, int64, int64, error) {
+	var actualGap int64
+	for ; index < len(unpackedPatch); index++ {
+		currentGap := int64(uint64(unpackedPatch[index]) >> uint64(patchWidth))
+		currentPatch := unpackedPatch[index] & patchMask
+		if currentGap == 255 && currentPatch == 0 {
+			actualGap += 255
+			continue
+		}
+		return index, actualGap + currentGap, currentPatch, nil
+	}
+	return index, 0, 0, errors.New("patched base run has a patch list ending in a gap")
+}
+
 func (r *RunLengthIntegerReaderV2) Err() error {
 	return r.err
 }
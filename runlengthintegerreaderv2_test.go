@@ -34,6 +34,17 @@ func TestRunLengthIntegerReaderV2(t *testing.T) {
 				}
 			},
 		},
+		{
+			// Patched Base example from the specification
+			signed: false,
+			input:  []byte{0x8e, 0x13, 0x2b, 0x21, 0x07, 0xd0, 0x1e, 0x00, 0x14, 0x70, 0x28, 0x32, 0x3c, 0x46, 0x50, 0x5a, 0x64, 0x6e, 0x78, 0x82, 0x8c, 0x96, 0xa0, 0xaa, 0xb4, 0xbe, 0xfc, 0xe8},
+			expect: func(output []int64) {
+				expected := []int64{2030, 2000, 2020, 1000000, 2040, 2050, 2060, 2070, 2080, 2090, 2100, 2110, 2120, 2130, 2140, 2150, 2160, 2170, 2180, 2190}
+				if !reflect.DeepEqual(output, expected) {
+					t.Errorf("Test failed, expected %v to equal %v", output, expected)
+				}
+			},
+		},
 		// {
 		// 	// Patched Base
 		// 	signed: false,
@@ -134,3 +145,181 @@ func TestRunLengthIntegerReaderV2(t *testing.T) {
 	}
 
 }
+
+func TestRunLengthIntegerReaderV2Delta(t *testing.T) {
+	repeated := func(value int64, n int) []int64 {
+		values := make([]int64, n)
+		for i := range values {
+			values[i] = value
+		}
+		return values
+	}
+
+	testCases := []struct {
+		signed   bool
+		input    []byte
+		expected []int64
+	}{
+		{
+			// Delta example from the specification
+			signed:   false,
+			input:    []byte{0xc6, 0x09, 0x02, 0x02, 0x22, 0x42, 0x42, 0x46},
+			expected: []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29},
+		},
+		{
+			// Fixed run written as a delta of 0
+			signed:   false,
+			input:    []byte{0xc0, 0x63, 0x07, 0x00},
+			expected: repeated(7, 100),
+		},
+		{
+			// Fixed runs of 512 and 88 constant timestamp seconds
+			signed:   true,
+			input:    []byte{0xc1, 0xff, 0x80, 0x92, 0xfd, 0x7b, 0x00, 0xc0, 0x57, 0x80, 0x92, 0xfd, 0x7b, 0x00},
+			expected: repeated(130000000, 600),
+		},
+		{
+			// Increasing fixed delta
+			signed:   false,
+			input:    []byte{0xc0, 0x09, 0x01, 0x02},
+			expected: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		},
+		{
+			// Decreasing fixed delta
+			signed:   true,
+			input:    []byte{0xc0, 0x04, 0x14, 0x03},
+			expected: []int64{10, 8, 6, 4, 2},
+		},
+		{
+			// Runs of length 2 with a fixed delta and with only a delta base
+			signed:   false,
+			input:    []byte{0xc0, 0x01, 0x05, 0x02, 0xc2, 0x01, 0x05, 0x02},
+			expected: []int64{5, 6, 5, 6},
+		},
+		{
+			// Decreasing sequence with a negative delta base and unsigned deltas
+			// of width 3, followed by a fixed run whose header must not be
+			// consumed by the padding of the deltas
+			signed:   false,
+			input:    []byte{0xc4, 0x04, 0x64, 0x13, 0xa8, 0x80, 0xc0, 0x03, 0x07, 0x00},
+			expected: []int64{100, 90, 85, 83, 82, 7, 7, 7, 7},
+		},
+		{
+			// Decreasing sequence with aligned deltas of width 4
+			signed:   false,
+			input:    []byte{0xc6, 0x04, 0x64, 0x13, 0x52, 0x10},
+			expected: []int64{100, 90, 85, 83, 82},
+		},
+		{
+			// Non-increasing sequence of signed values with repeats
+			signed:   true,
+			input:    []byte{0xce, 0x06, 0xc8, 0x01, 0x63, 0x01, 0x1d, 0x19, 0x00, 0x01},
+			expected: []int64{100, 50, 49, 20, -5, -5, -6},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewRunLengthIntegerReaderV2(bytes.NewReader(tc.input), tc.signed, false)
+		var output []int64
+		for r.Next() {
+			output = append(output, r.Int())
+		}
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("Test failed, expected %v to equal %v", output, tc.expected)
+		}
+	}
+
+}
+
+func TestRunLengthIntegerReaderV2PatchedBaseErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			// The 10 value example of the specification without its patch.
+			name:  "empty patch list",
+			input: []byte{0x8e, 0x09, 0x2b, 0x20, 0x07, 0xd0, 0x1e, 0x00, 0x14, 0x70, 0x28, 0x32, 0x3c, 0x46, 0x50, 0x5a},
+		},
+		{
+			// A patch gap width of 8 and a single entry with a gap of 255 and a
+			// patch of 0, which only extends the gap of a following entry.
+			name:  "patch list ending in a gap",
+			input: []byte{0x8e, 0x09, 0x2b, 0xe1, 0x07, 0xd0, 0x1e, 0x00, 0x14, 0x70, 0x28, 0x32, 0x3c, 0x46, 0x50, 0x5a, 0xff, 0x00, 0x00},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewRunLengthIntegerReaderV2(bytes.NewReader(tc.input), false, false)
+		for r.Next() {
+			r.Int()
+		}
+		if err := r.Err(); err == nil || err == io.EOF {
+			t.Errorf("Test failed, %s expected an error got %v", tc.name, err)
+		}
+	}
+}
+
+func TestRunLengthIntegerReaderV2Direct(t *testing.T) {
+	testCases := []struct {
+		signed   bool
+		input    []byte
+		expected []int64
+	}{
+		{
+			// Direct, width 3
+			signed:   false,
+			input:    []byte{0x44, 0x0a, 0x05, 0x39, 0x77, 0xfa, 0x80},
+			expected: []int64{0, 1, 2, 3, 4, 5, 6, 7, 7, 6, 5},
+		},
+		{
+			// Direct, width 5
+			signed:   false,
+			input:    []byte{0x48, 0x06, 0x0f, 0xe2, 0x04, 0xf8, 0x40},
+			expected: []int64{1, 31, 17, 0, 9, 30, 2},
+		},
+		{
+			// Direct, width 7 with zigzag encoded values
+			signed:   true,
+			input:    []byte{0x4c, 0x08, 0xff, 0xf8, 0x00, 0x10, 0x50, 0x72, 0x18, 0x1a},
+			expected: []int64{-64, 63, 0, -1, 1, -33, 50, 12, -7},
+		},
+		{
+			// Direct, width 26 which is encoded using the fixed bit size mapping
+			signed:   false,
+			input:    []byte{0x70, 0x02, 0x80, 0x00, 0x00, 0xff, 0xff, 0xff, 0xf0, 0x00, 0x00, 0x14},
+			expected: []int64{1<<25 + 3, 1<<26 - 1, 5},
+		},
+		{
+			// Direct, width 16 example from the specification
+			signed:   false,
+			input:    []byte{0x5e, 0x03, 0x5c, 0xa1, 0xab, 0x1e, 0xde, 0xad, 0xbe, 0xef},
+			expected: []int64{23713, 43806, 57005, 48879},
+		},
+		{
+			// Consecutive runs of width 3 and 5, the padding bits at the end
+			// of the first run must be discarded.
+			signed:   false,
+			input:    []byte{0x44, 0x0a, 0x05, 0x39, 0x77, 0xfa, 0x80, 0x48, 0x06, 0x0f, 0xe2, 0x04, 0xf8, 0x40},
+			expected: []int64{0, 1, 2, 3, 4, 5, 6, 7, 7, 6, 5, 1, 31, 17, 0, 9, 30, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := NewRunLengthIntegerReaderV2(bytes.NewReader(tc.input), tc.signed, false)
+		var output []int64
+		for r.Next() {
+			output = append(output, r.Int())
+		}
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(output, tc.expected) {
+			t.Errorf("Test failed, expected %v to equal %v", output, tc.expected)
+		}
+	}
+
+}
@@ -2,6 +2,7 @@ package orc
 
 import (
 	"bytes"
+	"io"
 	"math/rand"
 	// "reflect"
 	"testing"
@@ -105,3 +106,39 @@ func TestWriteReadRunLengthIntegerWriterRun(t *testing.T) {
 		index++
 	}
 }
+
+func TestWriteReadRunLengthIntegerWriterBoundaries(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, input := range boundaryInt64s(rnd) {
+		for _, signed := range []bool{true, false} {
+			var buf bytes.Buffer
+			w := NewRunLengthIntegerWriter(&buf, signed)
+			for _, v := range input {
+				if err := w.WriteInt(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			r := NewRunLengthIntegerReader(&buf, signed)
+			var output []int64
+			for r.Next() {
+				output = append(output, r.Int())
+			}
+			if err := r.Err(); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if len(output) != len(input) {
+				t.Errorf("Test failed, signed %t expected %d values got %d", signed, len(input), len(output))
+				continue
+			}
+			for i := range input {
+				if output[i] != input[i] {
+					t.Errorf("Test failed, signed %t expected %v got %v at index %v", signed, input[i], output[i], i)
+					break
+				}
+			}
+		}
+	}
+}
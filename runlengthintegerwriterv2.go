@@ -34,6 +34,9 @@ type RunLengthIntegerWriterV2 struct {
 	minRepeatSize        int
 	maxScope             int
 	maxShortRepeatLength int
+	// disabled holds the encodings, indexed by RLEEncodingType, that are not
+	// used. DIRECT is used in their place.
+	disabled [RLEV2IntDelta + 1]bool
 }
 
 func NewRunLengthIntegerWriterV2(w io.ByteWriter, signed bool) *RunLengthIntegerWriterV2 {
@@ -211,8 +214,26 @@ func (i *RunLengthIntegerWriterV2) WriteInt(val int64) error {
 	return nil
 }
 
+// disableEncodings prevents the provided encodings from being used, writing the
+// values that they would otherwise encode with DIRECT encoding. DIRECT encoding
+// itself cannot be disabled.
+func (i *RunLengthIntegerWriterV2) disableEncodings(encodings ...RLEEncodingType) {
+	for _, encoding := range encodings {
+		if encoding != RLEV2IntDirect {
+			i.disabled[encoding] = true
+		}
+	}
+}
+
 func (i *RunLengthIntegerWriterV2) writeValues() error {
 	if i.numLiterals != 0 {
+		if i.disabled[i.encoding] {
+			if err := i.writeDirectFallback(); err != nil {
+				return err
+			}
+			i.clear()
+			return nil
+		}
 		switch i.encoding {
 		case RLEV2IntShortRepeat:
 			err := i.writeShortRepeatValues()
@@ -240,6 +261,29 @@ func (i *RunLengthIntegerWriterV2) writeValues() error {
 	return nil
 }
 
+// writeDirectFallback writes the literals with DIRECT encoding in place of the
+// disabled encoding determined for them, resetting the same run length as the
+// disabled encoding would have.
+func (i *RunLengthIntegerWriterV2) writeDirectFallback() error {
+	resetFixed := i.encoding == RLEV2IntShortRepeat ||
+		(i.encoding == RLEV2IntDelta && i.isFixedDelta && i.fixedRunLength > MinRepeatSize)
+	fixedRunLength, variableRunLength := i.fixedRunLength, i.variableRunLength
+	i.computeZigZagLiterals()
+	i.zzBits100p = percentileBits(i.zigzagLiterals, 0, i.numLiterals, 1.0)
+	i.variableRunLength = i.numLiterals
+	i.encoding = RLEV2IntDirect
+	if err := i.writeDirectValues(); err != nil {
+		return err
+	}
+	if resetFixed {
+		i.fixedRunLength = 0
+		i.variableRunLength = variableRunLength
+	} else {
+		i.fixedRunLength = fixedRunLength
+	}
+	return nil
+}
+
 func (i *RunLengthIntegerWriterV2) Close() error {
 	return i.Flush()
 }
@@ -365,8 +409,9 @@ func (i *RunLengthIntegerWriterV2) determineEncoding() error {
 	diffBitsLH := i.zzBits100p - i.zzBits90p
 
 	// if the difference between 90th percentile and 100th percentile fixed
-	// bits is > 1 then we need patch the values
-	if diffBitsLH > 1 {
+	// bits is > 1 then we need patch the values, unless PATCHED_BASE has been
+	// disabled in favour of the faster DIRECT encoding
+	if !i.disabled[RLEV2IntPatchedBase] && diffBitsLH > 1 {
 
 		// patching is done only on base reduced values.
 		// remove base from literals
@@ -2,6 +2,8 @@ package orc
 
 import (
 	"bytes"
+	"io"
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -172,3 +174,239 @@ func TestWriteReadRunLengthIntegerWriterV2Run(t *testing.T) {
 		index++
 	}
 }
+
+func TestWriteReadRunLengthIntegerWriterV2Boundaries(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	encodings := [][]RLEEncodingType{
+		nil,
+		{RLEV2IntShortRepeat, RLEV2IntDelta},
+		{RLEV2IntShortRepeat, RLEV2IntDelta, RLEV2IntPatchedBase},
+	}
+	for _, input := range boundaryInt64s(rnd) {
+		for _, signed := range []bool{true, false} {
+			for _, disabled := range encodings {
+				var buf bytes.Buffer
+				w := NewRunLengthIntegerWriterV2(&buf, signed)
+				w.disableEncodings(disabled...)
+				for _, v := range input {
+					if err := w.WriteInt(v); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+				r := NewRunLengthIntegerReaderV2(&buf, signed, false)
+				var output []int64
+				for r.Next() {
+					output = append(output, r.Int())
+				}
+				if err := r.Err(); err != nil && err != io.EOF {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(output, input) {
+					t.Errorf("Test failed, signed %t disabling %v did not round trip %d values starting %v", signed, disabled, len(input), input[0])
+				}
+			}
+		}
+	}
+}
+
+func TestRunLengthIntegerWriterV2DisableEncodings(t *testing.T) {
+	// The input includes runs suited to each of the encodings, separated by
+	// repeated values: small values with outliers for PATCHED_BASE, short
+	// repeats, a long fixed run and an increasing sequence for DELTA and random
+	// values for DIRECT.
+	var input []int64
+	for i := 0; i < 200; i++ {
+		value := int64(i % 13)
+		if i%50 == 0 {
+			value = 1 << 40
+		}
+		input = append(input, value)
+	}
+	for i := 0; i < 5; i++ {
+		input = append(input, 7)
+	}
+	for i := 0; i < 100; i++ {
+		input = append(input, 3)
+	}
+	for i := 0; i < 100; i++ {
+		input = append(input, int64(i*5))
+	}
+	input = append(input, 9, 9, 9, 9)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		input = append(input, rnd.Int63()-rnd.Int63())
+	}
+
+	all := []RLEEncodingType{RLEV2IntShortRepeat, RLEV2IntDirect, RLEV2IntPatchedBase, RLEV2IntDelta}
+	testCases := [][]RLEEncodingType{
+		nil,
+		{RLEV2IntShortRepeat},
+		{RLEV2IntDelta},
+		{RLEV2IntPatchedBase},
+		all,
+	}
+
+	for _, disabled := range testCases {
+		var buf bytes.Buffer
+		w := NewRunLengthIntegerWriterV2(&buf, true)
+		w.disableEncodings(disabled...)
+		for _, v := range input {
+			if err := w.WriteInt(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r := NewRunLengthIntegerReaderV2(&buf, true, false)
+		used := make(map[RLEEncodingType]bool)
+		var output []int64
+		for r.Next() {
+			output = append(output, r.Int())
+			used[r.currentEncoding] = true
+		}
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(output, input) {
+			t.Errorf("Test failed, disabling %v did not round trip", disabled)
+		}
+		for _, encoding := range all {
+			isDisabled := encoding != RLEV2IntDirect && containsEncoding(disabled, encoding)
+			if used[encoding] == isDisabled {
+				t.Errorf("Test failed, disabling %v expected %s to be used: %t", disabled, encoding, !isDisabled)
+			}
+		}
+	}
+}
+
+func TestRunLengthIntegerWriterV2PatchGaps(t *testing.T) {
+	// Runs of 512 small values with outliers at the provided positions. Gaps
+	// between patches greater than 255 are written as entries of the patch list
+	// with a gap of 255 and a patch of 0.
+	testCases := []struct {
+		outliers        []int
+		outlier         int64
+		patchListLength int
+	}{
+		{outliers: []int{0, 255}, outlier: 1 << 20, patchListLength: 2},
+		{outliers: []int{0, 256}, outlier: 1 << 20, patchListLength: 3},
+		{outliers: []int{0, 510}, outlier: 1 << 20, patchListLength: 3},
+		{outliers: []int{0, 511}, outlier: 1 << 20, patchListLength: 4},
+		{outliers: []int{300, 511}, outlier: 1 << 20, patchListLength: 3},
+		{outliers: []int{3, 100, 200, 300, 400, 500}, outlier: 1 << 20, patchListLength: 6},
+		// Patches requiring 64 bits are reduced to a width of 56 bits so that
+		// each entry of the patch list fits in 64 bits along with its gap.
+		{outliers: []int{10, 400}, outlier: math.MaxInt64, patchListLength: 3},
+		{outliers: []int{1, 2, 511}, outlier: 1<<63 - 1<<50, patchListLength: 4},
+	}
+
+	for _, tc := range testCases {
+		input := make([]int64, 512)
+		for i := range input {
+			input[i] = int64(i * 7 % 16)
+		}
+		for _, i := range tc.outliers {
+			input[i] = tc.outlier
+		}
+		var buf bytes.Buffer
+		w := NewRunLengthIntegerWriterV2(&buf, false)
+		for _, v := range input {
+			if err := w.WriteInt(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+		if encoding := RLEEncodingType(encoded[0] >> 6 & 0x03); encoding != RLEV2IntPatchedBase {
+			t.Errorf("Test failed, outliers at %v expected %s got %s", tc.outliers, RLEV2IntPatchedBase, encoding)
+			continue
+		}
+		if patchListLength := int(encoded[3] & 0x1f); patchListLength != tc.patchListLength {
+			t.Errorf("Test failed, outliers at %v expected a patch list of length %d got %d", tc.outliers, tc.patchListLength, patchListLength)
+		}
+		r := NewRunLengthIntegerReaderV2(bytes.NewReader(encoded), false, false)
+		var output []int64
+		for r.Next() {
+			output = append(output, r.Int())
+		}
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(output, input) {
+			t.Errorf("Test failed, outliers at %v did not round trip", tc.outliers)
+		}
+	}
+}
+
+func FuzzRunLengthIntegerV2PatchedBase(f *testing.F) {
+	f.Add(int64(1), uint16(512), int64(1<<20), []byte{0, 255})
+	f.Add(int64(2), uint16(512), int64(math.MaxInt64), []byte{10, 255, 255})
+	f.Add(int64(3), uint16(100), int64(math.MinInt64), []byte{1, 1, 1})
+	f.Add(int64(4), uint16(20), int64(-1<<40), []byte{19})
+	f.Fuzz(func(t *testing.T, seed int64, length uint16, outlier int64, gaps []byte) {
+		// Small values with outliers at the gaps between them, suited to
+		// PATCHED_BASE, which is the only encoding other than DIRECT enabled.
+		rnd := rand.New(rand.NewSource(seed))
+		input := make([]int64, int(length)%2048+1)
+		for i := range input {
+			input[i] = rnd.Int63n(64) - 32
+		}
+		var position int
+		for _, gap := range gaps {
+			position += int(gap) + 1
+			if position >= len(input) {
+				break
+			}
+			input[position] = outlier
+		}
+		for _, signed := range []bool{true, false} {
+			var buf bytes.Buffer
+			w := NewRunLengthIntegerWriterV2(&buf, signed)
+			w.disableEncodings(RLEV2IntShortRepeat, RLEV2IntDelta)
+			for _, v := range input {
+				if !signed {
+					v &= math.MaxInt64
+				}
+				if err := w.WriteInt(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			r := NewRunLengthIntegerReaderV2(&buf, signed, false)
+			var index int
+			for r.Next() {
+				expected := input[index]
+				if !signed {
+					expected &= math.MaxInt64
+				}
+				if v := r.Int(); v != expected {
+					t.Fatalf("Test failed, signed %t expected %v got %v at index %v", signed, expected, v, index)
+				}
+				index++
+			}
+			if err := r.Err(); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if index != len(input) {
+				t.Fatalf("Test failed, signed %t expected %d values got %d", signed, len(input), index)
+			}
+		}
+	})
+}
+
+func containsEncoding(encodings []RLEEncodingType, encoding RLEEncodingType) bool {
+	for _, e := range encodings {
+		if e == encoding {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,308 @@
+package orc
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLRows is the subset of the methods of *sql.Rows implemented by Rows, so that
+// code reading rows can accept either of them. ColumnTypes is not included as
+// sql.ColumnType cannot be created outside of database/sql.
+type SQLRows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+var _ SQLRows = (*sql.Rows)(nil)
+var _ SQLRows = (*Rows)(nil)
+
+// errRowsClosed is returned by the methods of Rows once it has been closed.
+var errRowsClosed = errors.New("rows are closed")
+
+// Rows reads the rows of a Cursor through the methods of *sql.Rows, iterating
+// through every stripe of the Cursor.
+type Rows struct {
+	c      *Cursor
+	closed bool
+}
+
+// NewRows returns Rows reading the columns selected from the Cursor.
+func NewRows(c *Cursor) *Rows {
+	return &Rows{c: c}
+}
+
+// Columns returns the names of the selected columns.
+func (r *Rows) Columns() ([]string, error) {
+	if r.closed {
+		return nil, errRowsClosed
+	}
+	fields, _ := r.c.Columns()
+	return fields, nil
+}
+
+// ColumnTypes returns the types of the selected columns.
+func (r *Rows) ColumnTypes() ([]*ColumnType, error) {
+	if r.closed {
+		return nil, errRowsClosed
+	}
+	fields, columns := r.c.Columns()
+	types := make([]*ColumnType, len(columns))
+	for i, column := range columns {
+		types[i] = &ColumnType{name: fields[i], td: column, stringsAsBytes: r.c.Reader.stringsAsBytes}
+	}
+	return types, nil
+}
+
+// Next prepares the next row to be read by Scan, preparing the next stripe once
+// the rows of the current stripe have been read. It returns false once there are
+// no more rows or an error occurs, which is returned by Err.
+func (r *Rows) Next() bool {
+	if r.closed {
+		return false
+	}
+	for !r.c.Next() {
+		if r.c.Err() != nil || !r.c.Stripes() {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan copies the values of the current row into the values pointed at by dest,
+// converting them as sql.Rows.Scan does for values returned by a database driver:
+// integers as int64, floating point values as float64, dates and timestamps as
+// time.Time and decimals as strings. The values of lists, maps, structs and unions
+// are only assigned to *interface{} destinations and sql.Scanner implementations.
+func (r *Rows) Scan(dest ...interface{}) error {
+	if r.closed {
+		return errRowsClosed
+	}
+	row := r.c.Row()
+	if row == nil {
+		return errors.New("Scan called without calling Next")
+	}
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+	fields, _ := r.c.Columns()
+	for i, value := range row {
+		if err := convertAssign(dest[i], driverValue(value)); err != nil {
+			return fmt.Errorf("converting column %s: %v", fields[i], err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error, if any, which occurred whilst reading the rows.
+func (r *Rows) Err() error {
+	return r.c.Err()
+}
+
+// Close closes the Rows, after which Next returns false. Close is idempotent.
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+// driverValue returns a value read from a column converted to the type returned
+// for it by a database driver.
+func driverValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int8:
+		return int64(v)
+	case Float:
+		return float64(v)
+	case Double:
+		return float64(v)
+	case Date:
+		return v.Time
+	case Decimal:
+		return v.String()
+	}
+	return value
+}
+
+// convertAssign assigns src, as returned by driverValue, to the value pointed at
+// by dest.
+func convertAssign(dest, src interface{}) error {
+	switch d := dest.(type) {
+	case *interface{}:
+		*d = src
+		return nil
+	case sql.Scanner:
+		return d.Scan(src)
+	case *[]byte:
+		switch s := src.(type) {
+		case nil:
+			*d = nil
+			return nil
+		case []byte:
+			*d = append([]byte(nil), s...)
+			return nil
+		case string:
+			*d = []byte(s)
+			return nil
+		}
+	case *string:
+		switch s := src.(type) {
+		case string:
+			*d = s
+			return nil
+		case []byte:
+			*d = string(s)
+			return nil
+		case time.Time:
+			*d = s.Format(time.RFC3339Nano)
+			return nil
+		case int64, float64, bool:
+			*d = fmt.Sprint(s)
+			return nil
+		}
+	case *time.Time:
+		if s, ok := src.(time.Time); ok {
+			*d = s
+			return nil
+		}
+	case *bool:
+		if s, ok := src.(bool); ok {
+			*d = s
+			return nil
+		}
+	}
+
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("destination not a non-nil pointer")
+	}
+	if src == nil {
+		return fmt.Errorf("converting NULL to %s is unsupported", dv.Elem().Kind())
+	}
+	dv = dv.Elem()
+	switch dv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch s := src.(type) {
+		case int64:
+			i = s
+		case string:
+			var err error
+			if i, err = strconv.ParseInt(s, 10, dv.Type().Bits()); err != nil {
+				return fmt.Errorf("converting %q to %s: %v", s, dv.Kind(), err)
+			}
+		default:
+			return fmt.Errorf("unsupported conversion of %T to %s", src, dv.Kind())
+		}
+		if dv.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, dv.Kind())
+		}
+		dv.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s, ok := src.(int64)
+		if !ok {
+			return fmt.Errorf("unsupported conversion of %T to %s", src, dv.Kind())
+		}
+		if s < 0 || dv.OverflowUint(uint64(s)) {
+			return fmt.Errorf("value %d overflows %s", s, dv.Kind())
+		}
+		dv.SetUint(uint64(s))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch s := src.(type) {
+		case float64:
+			dv.SetFloat(s)
+		case int64:
+			dv.SetFloat(float64(s))
+		case string:
+			f, err := strconv.ParseFloat(s, dv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("converting %q to %s: %v", s, dv.Kind(), err)
+			}
+			dv.SetFloat(f)
+		default:
+			return fmt.Errorf("unsupported conversion of %T to %s", src, dv.Kind())
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported conversion of %T to %T", src, dest)
+}
+
+// ColumnType describes a column read by Rows, with the methods of sql.ColumnType.
+type ColumnType struct {
+	name           string
+	td             *TypeDescription
+	stringsAsBytes bool
+}
+
+// Name returns the name of the column.
+func (c *ColumnType) Name() string {
+	return c.name
+}
+
+// DatabaseTypeName returns the upper case name of the category of the column, such
+// as "BIGINT", "VARCHAR" or "ARRAY", without any precision, scale or length.
+func (c *ColumnType) DatabaseTypeName() string {
+	return strings.ToUpper(c.td.category.name)
+}
+
+// Length returns the maximum length of char and varchar columns, or math.MaxInt64
+// for string and binary columns. The ok result is false for other columns.
+func (c *ColumnType) Length() (length int64, ok bool) {
+	switch c.td.category {
+	case CategoryChar, CategoryVarchar:
+		return int64(c.td.maxLength), true
+	case CategoryString, CategoryBinary:
+		return math.MaxInt64, true
+	}
+	return 0, false
+}
+
+// DecimalSize returns the precision and scale of decimal columns. The ok result is
+// false for other columns.
+func (c *ColumnType) DecimalSize() (precision, scale int64, ok bool) {
+	if c.td.category != CategoryDecimal {
+		return 0, 0, false
+	}
+	return int64(c.td.precision), int64(c.td.scale), true
+}
+
+// Nullable returns true, as every ORC column may hold null values.
+func (c *ColumnType) Nullable() (nullable, ok bool) {
+	return true, true
+}
+
+// ScanType returns the type of the values of the column as converted by Scan, so
+// that a value of the type may be used as a destination of Scan for values other
+// than nulls. The values of lists, maps, structs and unions are only assigned to
+// *interface{} destinations, and so their type is that of interface{}.
+func (c *ColumnType) ScanType() reflect.Type {
+	switch c.td.category {
+	case CategoryBoolean:
+		return reflect.TypeOf(false)
+	case CategoryByte, CategoryShort, CategoryInt, CategoryLong:
+		return reflect.TypeOf(int64(0))
+	case CategoryFloat, CategoryDouble:
+		return reflect.TypeOf(float64(0))
+	case CategoryString, CategoryVarchar, CategoryChar:
+		if c.stringsAsBytes {
+			return reflect.TypeOf([]byte(nil))
+		}
+		return reflect.TypeOf("")
+	case CategoryBinary:
+		return reflect.TypeOf([]byte(nil))
+	case CategoryDate, CategoryTimestamp, CategoryTimestampInstant:
+		return reflect.TypeOf(time.Time{})
+	case CategoryDecimal:
+		return reflect.TypeOf("")
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
@@ -0,0 +1,234 @@
+package orc
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// report formats the rows of a query for a report. It was written against
+// *sql.Rows and reads ORC files with only the type of its argument changed.
+func report(rows SQLRows) (string, error) {
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintln(&b, strings.Join(columns, "\t"))
+	for rows.Next() {
+		var (
+			id    int
+			name  sql.NullString
+			price string
+			score sql.NullFloat64
+			day   time.Time
+			flag  bool
+			tags  interface{}
+		)
+		if err := rows.Scan(&id, &name, &price, &score, &day, &flag, &tags); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%d\t%s\t%s\t%.2f\t%s\t%t\t%v\n", id, name.String, price, score.Float64, day.Format("2006-01-02"), flag, tags)
+	}
+	return b.String(), rows.Err()
+}
+
+func TestRows(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:int,name:varchar(10),price:decimal(8,2),score:double,day:date,flag:boolean,tags:array<string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{int64(1), "apple", "1.50", 0.5, day, true, []interface{}{"a", "b"}},
+		{int64(2), nil, "-20.00", nil, day.AddDate(0, 0, 1), false, nil},
+		{int64(3), "cherry", "0.05", 3.25, day.AddDate(0, 0, 2), true, []interface{}{}},
+	}
+	for i, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+		// Write each row to a stripe of its own.
+		if i < len(rows)-1 {
+			if _, err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := report(NewRows(r.Select(schema.Columns()...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "id\tname\tprice\tscore\tday\tflag\ttags\n" +
+		"1\tapple\t1.50\t0.50\t2021-06-01\ttrue\t[a b]\n" +
+		"2\t\t-20.00\t0.00\t2021-06-02\tfalse\t<nil>\n" +
+		"3\tcherry\t0.05\t3.25\t2021-06-03\ttrue\t[]\n"
+	if actual != expected {
+		t.Errorf("Test failed, expected report:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	r, err = NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlRows := NewRows(r.Select("name", "price", "id", "tags"))
+	types, err := sqlRows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedTypes := []struct {
+		name      string
+		typeName  string
+		length    int64
+		hasLength bool
+		precision int64
+		scale     int64
+		isDecimal bool
+		scanType  reflect.Type
+	}{
+		{name: "name", typeName: "VARCHAR", length: 10, hasLength: true, scanType: reflect.TypeOf("")},
+		{name: "price", typeName: "DECIMAL", precision: 8, scale: 2, isDecimal: true, scanType: reflect.TypeOf("")},
+		{name: "id", typeName: "INT", scanType: reflect.TypeOf(int64(0))},
+		{name: "tags", typeName: "ARRAY", scanType: reflect.TypeOf((*interface{})(nil)).Elem()},
+	}
+	for i, ct := range types {
+		e := expectedTypes[i]
+		length, hasLength := ct.Length()
+		precision, scale, isDecimal := ct.DecimalSize()
+		nullable, ok := ct.Nullable()
+		if ct.Name() != e.name || ct.DatabaseTypeName() != e.typeName || length != e.length || hasLength != e.hasLength || precision != e.precision || scale != e.scale || isDecimal != e.isDecimal || !nullable || !ok || ct.ScanType() != e.scanType {
+			t.Errorf("Test failed, expected column type %+v got %s %s %d %t %d %d %t %t %t %v", e, ct.Name(), ct.DatabaseTypeName(), length, hasLength, precision, scale, isDecimal, nullable, ok, ct.ScanType())
+		}
+	}
+
+	// Values are converted to the destinations as by sql.Rows.Scan.
+	if !sqlRows.Next() {
+		t.Fatal("Test failed, expected a row")
+	}
+	var name []byte
+	var price float64
+	var id int8
+	var tags []interface{}
+	if err := sqlRows.Scan(&name, &price, &id, &tags); err == nil || err.Error() != "converting column tags: unsupported conversion of []interface {} to *[]interface {}" {
+		t.Errorf("Test failed, expected an unsupported conversion error got %v", err)
+	}
+	if string(name) != "apple" || price != 1.5 || id != 1 {
+		t.Errorf("Test failed, expected apple 1.5 1 got %s %v %d", name, price, id)
+	}
+	if !sqlRows.Next() {
+		t.Fatal("Test failed, expected a row")
+	}
+	var s string
+	var v interface{}
+	if err := sqlRows.Scan(&s, &v, &v, &v); err == nil || err.Error() != "converting column name: converting NULL to string is unsupported" {
+		t.Errorf("Test failed, expected a NULL conversion error got %v", err)
+	}
+	if err := sqlRows.Scan(&v); err == nil || err.Error() != "expected 4 destination arguments in Scan, not 1" {
+		t.Errorf("Test failed, expected a destination count error got %v", err)
+	}
+	if err := sqlRows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sqlRows.Next() {
+		t.Errorf("Test failed, expected no rows once closed")
+	}
+	if _, err := sqlRows.Columns(); err == nil {
+		t.Errorf("Test failed, expected an error from Columns once closed")
+	}
+
+	var b int8
+	for _, tc := range []struct {
+		src      interface{}
+		expected string
+	}{
+		{int64(math.MaxInt8 + 1), "value 128 overflows int8"},
+		{"x", `converting "x" to int8: strconv.ParseInt: parsing "x": invalid syntax`},
+		{1.5, "unsupported conversion of float64 to int8"},
+	} {
+		if err := convertAssign(&b, tc.src); err == nil || err.Error() != tc.expected {
+			t.Errorf("Test failed, expected error %q got %v", tc.expected, err)
+		}
+	}
+
+}
+
+func TestRowsScanType(t *testing.T) {
+
+	schema, err := ParseSchema("struct<b:boolean,t:tinyint,s:smallint,i:int,l:bigint,f:float,d:double,str:string,vc:varchar(5),c:char(3),day:date,ts:timestamp,dec:decimal(8,2),list:array<int>,map:map<string,int>,st:struct<x:int>,u:uniontype<int,string>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	row := []interface{}{
+		true, int8(-1), 2, 3, int64(4), float32(1.5), 2.25, "str", "vc", "abc",
+		day, day.Add(time.Hour), "1.50", []interface{}{1}, []MapEntry{{"a", 1}}, []interface{}{1}, UnionValue{1, "one"},
+	}
+	if err := w.Write(row...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stringsAsBytes := range []bool{false, true} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), WithStringsAsBytes(stringsAsBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows := NewRows(r.Select(schema.Columns()...))
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The values of every column are scanned into a value of its ScanType.
+		dest := make([]interface{}, len(types))
+		for i, ct := range types {
+			dest[i] = reflect.New(ct.ScanType()).Interface()
+		}
+		if !rows.Next() {
+			t.Fatal("Test failed, expected a row")
+		}
+		if err := rows.Scan(dest...); err != nil {
+			t.Errorf("Test failed, expected to scan every column into its ScanType got %v", err)
+			continue
+		}
+		if v := *dest[1].(*int64); v != -1 {
+			t.Errorf("Test failed, expected tinyint -1 got %v", v)
+		}
+		if v := *dest[5].(*float64); v != 1.5 {
+			t.Errorf("Test failed, expected float 1.5 got %v", v)
+		}
+		if v := *dest[10].(*time.Time); !v.Equal(day) {
+			t.Errorf("Test failed, expected date %v got %v", day, v)
+		}
+		if v := *dest[12].(*string); v != "1.50" {
+			t.Errorf("Test failed, expected decimal 1.50 got %v", v)
+		}
+	}
+
+}
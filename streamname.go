@@ -85,6 +85,37 @@ func (w writerMap) add(id int, t TreeWriter) {
 	w[id] = t
 }
 
+// size returns the total length of the streams of every TreeWriter, including
+// any bytes yet to be flushed to a stream and any values buffered by the
+// TreeWriter that are yet to be written to a stream.
+func (w writerMap) size() int64 {
+	var total int64
+	for _, t := range w {
+		for _, stream := range t.Streams() {
+			total += int64(stream.buffer.Len() + stream.buffer.Writer.Buffered())
+		}
+		if b, ok := t.(interface{ bufferedSize() int64 }); ok {
+			total += b.bufferedSize()
+		}
+	}
+	return total
+}
+
+// memoryUsage returns the approximate number of bytes held in memory by the
+// streams of every TreeWriter along with any values they have buffered.
+func (w writerMap) memoryUsage() int64 {
+	var total int64
+	for _, t := range w {
+		for _, stream := range t.Streams() {
+			total += stream.buffer.memoryUsage()
+		}
+		if b, ok := t.(interface{ bufferedMemoryUsage() int64 }); ok {
+			total += b.bufferedMemoryUsage()
+		}
+	}
+	return total
+}
+
 func (w writerMap) encodings() []*proto.ColumnEncoding {
 	encodings := make([]*proto.ColumnEncoding, len(w))
 	for i := range encodings {
@@ -0,0 +1,166 @@
+package orc
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// sortedRow is a row buffered by a Writer using WithStripeSortColumn along with
+// the key it is sorted by.
+type sortedRow struct {
+	key    interface{}
+	values []interface{}
+}
+
+// sortKey returns the value used to order rows by v, a value of a column of the
+// provided category. A nil key denotes a null value.
+func sortKey(category Category, v interface{}) (interface{}, error) {
+	floating := category == CategoryFloat || category == CategoryDouble
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case int:
+		if floating {
+			return float64(t), nil
+		}
+		return int64(t), nil
+	case int8:
+		return sortKey(category, int(t))
+	case int16:
+		return sortKey(category, int(t))
+	case int32:
+		return sortKey(category, int(t))
+	case int64:
+		if floating {
+			return float64(t), nil
+		}
+		return t, nil
+	case float32:
+		return float64(t), nil
+	case float64:
+		return t, nil
+	case Float:
+		return float64(t), nil
+	case Double:
+		return float64(t), nil
+	case string:
+		if category == CategoryDecimal {
+			r, ok := new(big.Rat).SetString(t)
+			if !ok {
+				return nil, fmt.Errorf("invalid decimal value %q for sorting", t)
+			}
+			return r, nil
+		}
+		return t, nil
+	case Decimal:
+		return t.Rat(), nil
+	case []byte, bool, time.Time:
+		return t, nil
+	}
+	return nil, fmt.Errorf("values of type %T cannot be sorted", v)
+}
+
+// compareSortKeys returns -1, 0 or 1 as the non-nil key a is ordered before, the
+// same as or after the non-nil key b. NaN is ordered after every other number
+// and keys of differing types are ordered by the name of their type.
+func compareSortKeys(a, b interface{}) int {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return compareOrdered(x < y, x > y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case math.IsNaN(x) && math.IsNaN(y):
+				return 0
+			case math.IsNaN(x):
+				return 1
+			case math.IsNaN(y):
+				return -1
+			}
+			return compareOrdered(x < y, x > y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return compareOrdered(x < y, x > y)
+		}
+	case *big.Rat:
+		if y, ok := b.(*big.Rat); ok {
+			return x.Cmp(y)
+		}
+	case []byte:
+		if y, ok := b.([]byte); ok {
+			return bytes.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareOrdered(!x && y, x && !y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return compareOrdered(x.Before(y), x.After(y))
+		}
+	}
+	ta, tb := reflect.TypeOf(a).String(), reflect.TypeOf(b).String()
+	return compareOrdered(ta < tb, ta > tb)
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// sortRows sorts rows by their keys, retaining the order of rows with equal keys.
+// Null keys are ordered first unless nullsLast is set.
+func sortRows(rows []sortedRow, nullsLast bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].key, rows[j].key
+		switch {
+		case a == nil && b == nil:
+			return false
+		case a == nil:
+			return !nullsLast
+		case b == nil:
+			return nullsLast
+		}
+		return compareSortKeys(a, b) < 0
+	})
+}
+
+// estimateSize returns the approximate number of bytes of memory used by the
+// value v of a row.
+func estimateSize(v interface{}) int64 {
+	const interfaceSize = 16
+	switch t := v.(type) {
+	case string:
+		return interfaceSize + int64(len(t))
+	case []byte:
+		return interfaceSize + int64(len(t))
+	case []interface{}:
+		size := int64(interfaceSize)
+		for _, e := range t {
+			size += estimateSize(e)
+		}
+		return size
+	case []MapEntry:
+		size := int64(interfaceSize)
+		for _, e := range t {
+			size += estimateSize(e.Key) + estimateSize(e.Value)
+		}
+		return size
+	case UnionValue:
+		return interfaceSize + estimateSize(t.Value)
+	}
+	return interfaceSize
+}
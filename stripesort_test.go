@@ -0,0 +1,81 @@
+package orc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortRows(t *testing.T) {
+
+	testCases := []struct {
+		category  Category
+		input     []interface{}
+		nullsLast bool
+		expected  []interface{}
+	}{
+		{
+			category: CategoryInt,
+			input:    []interface{}{int64(3), nil, 1, int32(-2), nil},
+			expected: []interface{}{nil, nil, int32(-2), 1, int64(3)},
+		},
+		{
+			category:  CategoryDouble,
+			input:     []interface{}{math.NaN(), 2.5, nil, Double(-1), 1},
+			nullsLast: true,
+			expected:  []interface{}{Double(-1), 1, 2.5, math.NaN(), nil},
+		},
+		{
+			category: CategoryString,
+			input:    []interface{}{"b", "ab", "", "a"},
+			expected: []interface{}{"", "a", "ab", "b"},
+		},
+		{
+			category: CategoryDecimal,
+			input:    []interface{}{"10.5", "9.75", "-1", "10.25"},
+			expected: []interface{}{"-1", "9.75", "10.25", "10.5"},
+		},
+		{
+			category: CategoryBoolean,
+			input:    []interface{}{true, false, nil, true},
+			expected: []interface{}{nil, false, true, true},
+		},
+		{
+			category: CategoryTimestamp,
+			input:    []interface{}{time.Unix(10, 0), time.Unix(-10, 0), time.Unix(0, 1)},
+			expected: []interface{}{time.Unix(-10, 0), time.Unix(0, 1), time.Unix(10, 0)},
+		},
+	}
+
+	for _, tc := range testCases {
+		var rows []sortedRow
+		for _, v := range tc.input {
+			key, err := sortKey(tc.category, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows = append(rows, sortedRow{key: key, values: []interface{}{v}})
+		}
+		sortRows(rows, tc.nullsLast)
+		for i, row := range rows {
+			v := row.values[0]
+			if f, ok := v.(float64); ok && math.IsNaN(f) {
+				if e, ok := tc.expected[i].(float64); ok && math.IsNaN(e) {
+					continue
+				}
+			}
+			if !reflect.DeepEqual(v, tc.expected[i]) {
+				t.Errorf("Test failed, expected %v at %d got %v", tc.expected[i], i, v)
+			}
+		}
+	}
+
+	if _, err := sortKey(CategoryInt, struct{}{}); err == nil {
+		t.Errorf("Test failed, expected error for an unsortable value")
+	}
+	if _, err := sortKey(CategoryDecimal, "1.2.3"); err == nil {
+		t.Errorf("Test failed, expected error for an invalid decimal")
+	}
+
+}
@@ -0,0 +1,104 @@
+package orc
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// TranscodeOptions are the options of Transcode.
+type TranscodeOptions struct {
+	// DropColumns are the names of the top level columns of the source file
+	// that are not written to the destination.
+	DropColumns []string
+	// SuppressMetadata are the keys of the user metadata of the source file
+	// that are not carried over to the destination.
+	SuppressMetadata []string
+}
+
+// Schema returns the schema of the file written by Transcode from r, that of r
+// without the dropped columns, for creating the destination Writer with
+// SetSchema. It returns an error if a dropped column does not exist.
+func (o TranscodeOptions) Schema(r *Reader) (*TypeDescription, error) {
+	schema := r.Schema()
+	dropped := make(map[string]bool, len(o.DropColumns))
+	for _, column := range o.DropColumns {
+		dropped[column] = true
+	}
+	var buf bytes.Buffer
+	buf.WriteString("struct<")
+	var written int
+	for i, name := range schema.fieldNames {
+		if dropped[name] {
+			delete(dropped, name)
+			continue
+		}
+		if written > 0 {
+			buf.WriteString(",")
+		}
+		printFieldName(&buf, name)
+		buf.WriteString(":")
+		schema.children[i].printToBuffer(&buf)
+		written++
+	}
+	buf.WriteString(">")
+	for _, column := range o.DropColumns {
+		if dropped[column] {
+			return nil, fmt.Errorf("unknown column %q to drop", column)
+		}
+	}
+	return ParseSchema(buf.String())
+}
+
+// Transcode writes the rows of src to dst without the dropped columns, one stripe
+// of dst for each stripe of src, and adds the user metadata of src other than the
+// suppressed keys to that of dst. The schema of dst must be that returned by
+// TranscodeOptions.Schema. The values are decoded and written again, so the
+// statistics, encodings and compression of the written stripes are those of
+// dst, and binary columns, which the Writer does not support, must be dropped.
+// Transcode does not close dst.
+func Transcode(dst *Writer, src *Reader, opts TranscodeOptions) error {
+	schema, err := opts.Schema(src)
+	if err != nil {
+		return err
+	}
+	if dst.schema.String() != schema.String() {
+		return fmt.Errorf("destination schema %s is not the transcoded schema %s", dst.schema, schema)
+	}
+
+	suppressed := make(map[string]bool, len(opts.SuppressMetadata))
+	for _, key := range opts.SuppressMetadata {
+		suppressed[key] = true
+	}
+	for key, value := range src.UserMetadata() {
+		if suppressed[key] {
+			continue
+		}
+		if err := dst.AddUserMetadata(key, value); err != nil {
+			return err
+		}
+	}
+
+	c := src.Select(schema.fieldNames...)
+	for c.Stripes() {
+		var rows int
+		for c.Next() {
+			values := c.Row()
+			for i, value := range values {
+				values[i] = writableValue(schema.children[i], value)
+			}
+			if err := dst.Write(values...); err != nil {
+				return fmt.Errorf("row %d: %v", c.RowNumber(), err)
+			}
+			rows++
+		}
+		if err := c.Err(); err != nil {
+			return err
+		}
+		if rows > 0 {
+			if _, err := dst.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return c.Err()
+}
@@ -0,0 +1,161 @@
+package orc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	gproto "github.com/golang/protobuf/proto"
+)
+
+func TestTranscode(t *testing.T) {
+
+	src, err := Open("./examples/TestOrcFile.testSnappy.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	opts := TranscodeOptions{DropColumns: []string{"string1"}}
+	schema, err := opts.Schema(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := schema.String(); s != "struct<int1:int>" {
+		t.Errorf("Test failed, expected schema struct<int1:int> got %s", s)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Transcode(w, src, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := len(dst.Footer().GetStripes()), len(src.Footer().GetStripes()); actual != expected {
+		t.Errorf("Test failed, expected %d stripes got %d", expected, actual)
+	}
+	// The statistics of the remaining column are those of the source file.
+	if actual, expected := dst.Footer().GetStatistics()[1], src.Footer().GetStatistics()[1]; !gproto.Equal(actual.GetIntStatistics(), expected.GetIntStatistics()) || actual.GetNumberOfValues() != expected.GetNumberOfValues() {
+		t.Errorf("Test failed, expected statistics %v got %v", expected, actual)
+	}
+	if n := len(dst.Footer().GetStatistics()); n != 2 {
+		t.Errorf("Test failed, expected statistics of 2 columns got %d", n)
+	}
+
+	actual, err := readAllRows(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err = Open("./examples/TestOrcFile.testSnappy.orc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	c := src.Select("int1")
+	var expected [][]interface{}
+	for c.Stripes() {
+		for c.Next() {
+			expected = append(expected, c.Row())
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(expected) != 10000 || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected %d rows equal to those of the source got %d", len(expected), len(actual))
+	}
+
+}
+
+func TestTranscodeMetadata(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:int,point:struct<x:int,y:int>,old:string,tags:map<string,array<int>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"owner", "secret"} {
+		if err := w.AddUserMetadata(key, []byte(key+" value")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := [][]interface{}{
+		{int64(1), []interface{}{int64(2), int64(3)}, "x", []MapEntry{{Key: "a", Value: []interface{}{int64(4)}}}},
+		{nil, nil, nil, nil},
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	src, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := TranscodeOptions{DropColumns: []string{"old"}, SuppressMetadata: []string{"secret"}}
+	transcoded, err := opts.Schema(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	dst, err := NewWriter(&out, SetSchema(transcoded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Transcode(dst, src, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata := r.UserMetadata(); !reflect.DeepEqual(metadata, map[string][]byte{"owner": []byte("owner value")}) {
+		t.Errorf("Test failed, expected the owner metadata only got %v", metadata)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{
+		{int64(1), Struct{"x": int64(2), "y": int64(3)}, []MapEntry{{Key: "a", Value: []interface{}{int64(4)}}}},
+		{nil, nil, nil},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, actual)
+	}
+
+	src, err = NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (TranscodeOptions{DropColumns: []string{"missing"}}).Schema(src); err == nil || err.Error() != `unknown column "missing" to drop` {
+		t.Errorf("Test failed, expected an unknown column error got %v", err)
+	}
+	dst, err = NewWriter(&out, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Transcode(dst, src, opts); err == nil || err.Error() != "destination schema "+schema.String()+" is not the transcoded schema "+transcoded.String() {
+		t.Errorf("Test failed, expected a schema mismatch error got %v", err)
+	}
+
+}
@@ -7,8 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
+	"math/big"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"code.simon-critchley.co.uk/orc/proto"
 )
@@ -24,6 +28,121 @@ type TreeReader interface {
 	Err() error
 }
 
+// DecodeError is returned by a TreeReader when a stream contains a value that
+// is inconsistent with the remaining streams of the column, for example a
+// negative length or a length exceeding the bytes left in the data stream.
+type DecodeError struct {
+	msg string
+}
+
+func (e *DecodeError) Error() string {
+	return e.msg
+}
+
+func newDecodeError(format string, args ...interface{}) error {
+	return &DecodeError{fmt.Sprintf(format, args...)}
+}
+
+// readLength reads exactly l bytes from r, returning a DecodeError rather than
+// allocating or reading when l is negative or exceeds the bytes remaining in r.
+func readLength(r io.Reader, l int64) ([]byte, error) {
+	if l < 0 {
+		return nil, newDecodeError("invalid negative length: %v", l)
+	}
+	if l == 0 {
+		return []byte{}, nil
+	}
+	if r == nil {
+		return nil, newDecodeError("length %v read for missing data stream", l)
+	}
+	lr, ok := r.(interface {
+		Len() int
+	})
+	if ok && l > int64(lr.Len()) {
+		return nil, newDecodeError("length %v exceeds remaining data stream size: %v", l, lr.Len())
+	}
+	// The bytes remaining in streams that are decompressed as they are read are
+	// not known, so their bytes are read before they are allocated, such that a
+	// corrupt length allocates no more than the bytes left in the stream.
+	if !ok {
+		var buf bytes.Buffer
+		n, err := io.CopyN(&buf, r, l)
+		if err != nil {
+			return nil, newDecodeError("read unexpected number of bytes: %v expected: %v", n, l)
+		}
+		return buf.Bytes(), nil
+	}
+	byt := make([]byte, l)
+	n, err := io.ReadFull(r, byt)
+	if err != nil {
+		return nil, newDecodeError("read unexpected number of bytes: %v expected: %v", n, l)
+	}
+	return byt, nil
+}
+
+// readChildLength validates a length read from the length stream of a compound
+// type such as a list or map, returning a DecodeError if it is negative.
+func readChildLength(length IntegerReader) (int, error) {
+	l := length.Int()
+	if l < 0 || int64(int(l)) != l {
+		return 0, newDecodeError("invalid length: %v", l)
+	}
+	return int(l), nil
+}
+
+// skipValues skips the next n values of the TreeReader r. The readers of list, map
+// and struct columns skip the values of their children by the number of values
+// those children hold rather than decoding them, whereas the values of other
+// readers are read and discarded.
+func skipValues(r TreeReader, n int) error {
+	if s, ok := r.(interface{ skip(n int) error }); ok {
+		return s.skip(n)
+	}
+	for i := 0; i < n; i++ {
+		if !r.Next() {
+			return skipErr(r, n, i)
+		}
+		r.Value()
+	}
+	if err := r.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+// skipErr returns the error of the reader r having only i of the n values being
+// skipped.
+func skipErr(r interface{ Err() error }, n, i int) error {
+	if err := r.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	return newDecodeError("skipped values: %v exceed remaining values: %v", n, i)
+}
+
+// skipPresent calls Next on the reader r for each of its next n values, returning
+// the sum of the lengths read from length of those values that are present.
+func skipPresent(r interface {
+	Next() bool
+	IsPresent() bool
+	Err() error
+}, length IntegerReader, n int) (int, error) {
+	var total int
+	for i := 0; i < n; i++ {
+		if !r.Next() {
+			return 0, skipErr(r, n, i)
+		}
+		if !r.IsPresent() {
+			continue
+		}
+		l, err := readChildLength(length)
+		if err != nil {
+			return 0, err
+		}
+		total += l
+	}
+	return total, nil
+}
+
 // BaseTreeReader wraps a *BooleanReader and is used for reading the Present stream
 // in all TreeReader implementations.
 type BaseTreeReader struct {
@@ -54,6 +173,19 @@ func (b BaseTreeReader) IsPresent() bool {
 	return true
 }
 
+// countPresent skips the next n values of the present stream without reading
+// them individually, returning the number of them that are present.
+func (b BaseTreeReader) countPresent(n int) (int, error) {
+	if b.BooleanReader == nil {
+		return n, nil
+	}
+	present, err := b.BooleanReader.skipBits(uint64(n))
+	if err == io.EOF {
+		return 0, newDecodeError("skipped values: %v exceed remaining values", n)
+	}
+	return int(present), err
+}
+
 // Err returns the last error to occur.
 func (b BaseTreeReader) Err() error {
 	if b.BooleanReader != nil {
@@ -130,11 +262,37 @@ const (
 	TimestampBaseSeconds int64 = 1420070400
 )
 
+// timestampBase returns the number of seconds since the Unix epoch of midnight
+// on 1 January 2015 in the provided location, the base value for timestamps
+// written in that location.
+func timestampBase(loc *time.Location) int64 {
+	return time.Date(2015, time.January, 1, 0, 0, 0, 0, loc).Unix()
+}
+
+// decodeNanos decodes a nanosecond value from the secondary stream of a timestamp
+// column. The low 3 bits encode the number of trailing decimal zeros removed from
+// the value, where a value of n > 0 indicates that n+1 zeros were removed.
+func decodeNanos(serialized int64) int64 {
+	zeros := serialized & 0x07
+	nanos := serialized >> 3
+	if zeros != 0 {
+		for i := int64(0); i <= zeros; i++ {
+			nanos *= 10
+		}
+	}
+	return nanos
+}
+
 // TimestampTreeReader is a TreeReader implementation that reads timestamp type columns.
 type TimestampTreeReader struct {
 	BaseTreeReader
 	data      IntegerReader
 	secondary IntegerReader
+	location  *time.Location
+	base      int64
+	// julianGregorian is whether the timestamps were recorded in the hybrid
+	// Julian and Gregorian calendar.
+	julianGregorian bool
 }
 
 // Next implements the TreeReader interface.
@@ -148,9 +306,20 @@ func (t *TimestampTreeReader) Next() bool {
 	return t.data.Next() && t.secondary.Next()
 }
 
-// ValueTimestamp returns the next timestamp value.
+// Timestamp returns the next timestamp value in the location of the writer.
 func (t *TimestampTreeReader) Timestamp() time.Time {
-	return time.Unix(TimestampBaseSeconds+t.data.Int(), t.secondary.Int())
+	seconds := t.base + t.data.Int()
+	nanos := decodeNanos(t.secondary.Int())
+	// Writers store the seconds of timestamps before the Unix epoch with a
+	// millisecond component truncated towards zero, so they are one greater
+	// than the floor of the timestamp.
+	if seconds < 0 && nanos > 999999 {
+		seconds--
+	}
+	if t.julianGregorian {
+		return julianGregorianTime(time.Unix(seconds, nanos).In(t.location))
+	}
+	return time.Unix(seconds, nanos).In(t.location)
 }
 
 // Value implements the TreeReader interface.
@@ -170,25 +339,35 @@ func (t *TimestampTreeReader) Err() error {
 }
 
 // NewTimestampTreeReader returns a new TimestampTreeReader along with any error that occurs.
-func NewTimestampTreeReader(present, data, secondary io.Reader, encoding *proto.ColumnEncoding) (*TimestampTreeReader, error) {
+// The location is the timezone of the writer recorded in the stripe footer, which
+// determines the base value of the data stream.
+func NewTimestampTreeReader(present, data, secondary io.Reader, encoding *proto.ColumnEncoding, location *time.Location) (*TimestampTreeReader, error) {
 	dataReader, err := createIntegerReader(encoding.GetKind(), data, true, false)
 	if err != nil {
 		return nil, err
 	}
-	secondaryReader, err := createIntegerReader(encoding.GetKind(), secondary, true, false)
+	secondaryReader, err := createIntegerReader(encoding.GetKind(), secondary, false, false)
 	if err != nil {
 		return nil, err
 	}
+	if location == nil {
+		location = time.UTC
+	}
 	return &TimestampTreeReader{
 		BaseTreeReader: NewBaseTreeReader(present),
 		data:           dataReader,
 		secondary:      secondaryReader,
+		location:       location,
+		base:           timestampBase(location),
 	}, nil
 }
 
 // DateTreeReader is a TreeReader implementation that can read date column types.
 type DateTreeReader struct {
 	*IntegerTreeReader
+	// julianGregorian is whether the dates were recorded in the hybrid Julian
+	// and Gregorian calendar.
+	julianGregorian bool
 }
 
 // Date is a date value represented by an underlying time.Time.
@@ -196,13 +375,26 @@ type Date struct {
 	time.Time
 }
 
+// MarshalJSON implements the json.Marshaler interface, encoding the date as a
+// string such as "2006-01-02" rather than as the timestamp of its time.Time.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.Format("2006-01-02") + `"`), nil
+}
+
 // Date returns the next date value as a time.Time.
 func (d *DateTreeReader) Date() Date {
-	return Date{time.Unix(86400*d.Int(), 0).In(time.UTC)}
+	days := d.Int()
+	if d.julianGregorian {
+		days = julianGregorianDays(days)
+	}
+	return Date{time.Unix(86400*days, 0).In(time.UTC)}
 }
 
 // Value implements the TreeReader interface.
 func (d *DateTreeReader) Value() interface{} {
+	if !d.BaseTreeReader.IsPresent() {
+		return nil
+	}
 	return d.Date()
 }
 
@@ -212,13 +404,17 @@ func NewDateTreeReader(present, data io.Reader, encoding *proto.ColumnEncoding)
 	if err != nil {
 		return nil, err
 	}
-	return &DateTreeReader{reader}, nil
+	return &DateTreeReader{IntegerTreeReader: reader}, nil
 }
 
 // IntegerReader is an interface that provides methods for reading a string stream.
 type StringTreeReader interface {
 	TreeReader
 	String() string
+	// Bytes returns the bytes of the current value without copying them. The
+	// returned slice shares the memory of the decompressed stream and must not
+	// be modified, but remains valid after subsequent calls of Next.
+	Bytes() []byte
 }
 
 // NewStringTreeReader returns a StringTreeReader implementation along with any error that occurs.s
@@ -232,13 +428,24 @@ func NewStringTreeReader(present, data, length, dictionary io.Reader, encoding *
 	return nil, fmt.Errorf("unsupported column encoding: %s", encoding.GetKind())
 }
 
+// stringDirectBatchSize is the number of values of a direct encoded string column
+// whose bytes are read from the data stream at a time.
+const stringDirectBatchSize = 1024
+
 // StringDirectTreeReader is a StringTreeReader implementation that can read direct
-// encoded string type columns.
+// encoded string type columns. The bytes of a batch of values are read from the
+// data stream at once, being sliced from the stream without copying when it is
+// held in memory, and only converted to a string when the value is requested.
 type StringDirectTreeReader struct {
 	BaseTreeReader
 	length IntegerReader
 	data   io.Reader
-	err    error
+	// batch holds the bytes of the current batch of values, where the value at
+	// index i spans the offsets i to i+1.
+	batch   []byte
+	offsets []int
+	index   int
+	err     error
 }
 
 func NewStringDirectTreeReader(present, data, length io.Reader, kind proto.ColumnEncoding_Kind) (*StringDirectTreeReader, error) {
@@ -263,25 +470,70 @@ func (s *StringDirectTreeReader) Next() bool {
 	if !s.BaseTreeReader.IsPresent() {
 		return true
 	}
-	return s.length.Next()
+	s.index++
+	if s.index+1 < len(s.offsets) {
+		return true
+	}
+	return s.readBatch()
 }
 
-func (s *StringDirectTreeReader) String() string {
-	l := int(s.length.Int())
-	byt := make([]byte, l, l)
-	if l == 0 {
-		return ""
+// readBatch reads the lengths of the next batch of values followed by their bytes,
+// returning false if there are no more values or an error occurs.
+func (s *StringDirectTreeReader) readBatch() bool {
+	s.offsets = append(s.offsets[:0], 0)
+	s.index = 0
+	var total int64
+	for len(s.offsets) <= stringDirectBatchSize && s.length.Next() {
+		l := s.length.Int()
+		if l < 0 {
+			s.err = newDecodeError("invalid negative length: %v", l)
+			return false
+		}
+		if l > math.MaxInt32 || total+l > math.MaxInt32 {
+			s.err = newDecodeError("length %v exceeds the maximum size of a batch of values", l)
+			return false
+		}
+		total += l
+		s.offsets = append(s.offsets, int(total))
 	}
-	n, err := s.data.Read(byt)
-	if err != nil {
-		s.err = err
-		return ""
+	if len(s.offsets) == 1 {
+		return false
 	}
-	if n != l {
-		s.err = fmt.Errorf("read unexpected number of bytes: %v expected: %v", n, l)
-		return ""
+	if total == 0 {
+		s.batch = []byte{}
+		return true
+	}
+	if s.data == nil {
+		s.err = newDecodeError("length %v read for missing data stream", total)
+		return false
+	}
+	// Streams held in memory are sliced without copying.
+	if buf, ok := s.data.(*bytes.Buffer); ok {
+		if total > int64(buf.Len()) {
+			s.err = newDecodeError("length %v exceeds remaining data stream size: %v", total, buf.Len())
+			return false
+		}
+		s.batch = buf.Next(int(total))
+		return true
+	}
+	// The bytes of each batch are allocated afresh as the values of previous
+	// batches may still be referenced.
+	s.batch, s.err = readLength(s.data, total)
+	return s.err == nil
+}
+
+// Bytes returns the bytes of the current value, which share the memory of the
+// data stream and must not be modified.
+func (s *StringDirectTreeReader) Bytes() []byte {
+	if s.index+1 >= len(s.offsets) {
+		return nil
 	}
-	return string(byt)
+	start, end := s.offsets[s.index], s.offsets[s.index+1]
+	return s.batch[start:end:end]
+}
+
+func (s *StringDirectTreeReader) String() string {
+	return string(s.Bytes())
 }
 
 func (s *StringDirectTreeReader) Value() interface{} {
@@ -295,19 +547,158 @@ func (s *StringDirectTreeReader) Err() error {
 	if s.err != nil {
 		return s.err
 	}
-	if err := s.length.Err(); err != nil {
+	// The lengths of a batch are read until the end of the length stream, which
+	// is only an error once a value is sought beyond the batch.
+	if err := s.length.Err(); err != nil && (err != io.EOF || s.index+1 >= len(s.offsets)) {
 		return err
 	}
 	return s.BaseTreeReader.Err()
 }
 
+// stringBytesTreeReader is a TreeReader returning the values of a string column
+// as []byte values rather than strings, avoiding copying them.
+type stringBytesTreeReader struct {
+	reader interface {
+		StringTreeReader
+		IsPresent() bool
+	}
+}
+
+// newStringBytesTreeReader returns a TreeReader returning the values of reader as
+// []byte values, or reader itself if it cannot report whether values are null.
+func newStringBytesTreeReader(reader StringTreeReader) TreeReader {
+	if r, ok := reader.(interface {
+		StringTreeReader
+		IsPresent() bool
+	}); ok {
+		return &stringBytesTreeReader{r}
+	}
+	return reader
+}
+
+func (s *stringBytesTreeReader) Next() bool {
+	return s.reader.Next()
+}
+
+// Value returns the bytes of the current value or nil if it is null.
+func (s *stringBytesTreeReader) Value() interface{} {
+	if !s.reader.IsPresent() {
+		return nil
+	}
+	return s.reader.Bytes()
+}
+
+func (s *stringBytesTreeReader) Err() error {
+	return s.reader.Err()
+}
+
+// utf8TreeReader is a TreeReader returning the values of a string column that are
+// not valid UTF-8 either with the invalid bytes replaced or as an error, per its
+// UTF8Validation.
+type utf8TreeReader struct {
+	reader interface {
+		StringTreeReader
+		IsPresent() bool
+	}
+	mode   UTF8Validation
+	column string
+	// unit names the values of the column in errors, being the rows of the
+	// stripe for the fields of the root struct.
+	unit   string
+	stripe int
+	values int
+	value  interface{}
+	err    error
+}
+
+// newUTF8TreeReader returns a TreeReader validating the values of reader, the
+// reader of the column of the provided type in the stripe at index stripe, or
+// reader itself if it cannot report whether values are null.
+func newUTF8TreeReader(reader StringTreeReader, mode UTF8Validation, schema *TypeDescription, stripe int) TreeReader {
+	r, ok := reader.(interface {
+		StringTreeReader
+		IsPresent() bool
+	})
+	if !ok {
+		return reader
+	}
+	// Name the column by its fields where it is nested only within structs.
+	var fields []string
+	for t := schema; t.parent != nil; t = t.parent {
+		parent := t.parent
+		if len(parent.fieldNames) != len(parent.children) {
+			fields = nil
+			break
+		}
+		for i, child := range parent.children {
+			if child == t {
+				fields = append([]string{parent.fieldNames[i]}, fields...)
+			}
+		}
+	}
+	name := strings.Join(fields, ".")
+	if name == "" {
+		name = fmt.Sprint(schema.getID())
+	}
+	unit := "value"
+	if schema.parent != nil && schema.parent.parent == nil {
+		unit = "row"
+	}
+	return &utf8TreeReader{reader: r, mode: mode, column: name, unit: unit, stripe: stripe}
+}
+
+// Next returns true if another value is available, validating it.
+func (u *utf8TreeReader) Next() bool {
+	if u.err != nil || !u.reader.Next() {
+		return false
+	}
+	index := u.values
+	u.values++
+	if !u.reader.IsPresent() {
+		u.value = nil
+		return true
+	}
+	b := u.reader.Bytes()
+	switch {
+	case utf8.Valid(b):
+		u.value = u.reader.String()
+	case u.mode == UTF8ValidationReplace:
+		u.value = strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	default:
+		u.err = fmt.Errorf("column %s: %s %d of stripe %d is not valid UTF-8: %q", u.column, u.unit, index, u.stripe, b)
+		return false
+	}
+	return true
+}
+
+// Value returns the current value, which is valid UTF-8, or nil if it is null.
+func (u *utf8TreeReader) Value() interface{} {
+	return u.value
+}
+
+func (u *utf8TreeReader) Err() error {
+	if u.err != nil {
+		return u.err
+	}
+	return u.reader.Err()
+}
+
+// StringDictionaryTreeReader is a StringTreeReader implementation that can read
+// dictionary encoded string type columns. Only the offsets of the entries of the
+// dictionary are decoded up front, each entry being converted to a string when it
+// is first referenced and reused for subsequent references.
 type StringDictionaryTreeReader struct {
 	BaseTreeReader
 	dictionaryOffsets []int
 	dictionaryLength  []int
 	reader            IntegerReader
 	dictionaryBytes   []byte
-	err               error
+	// dictionaryStrings holds the entries converted to strings so far, where
+	// the empty string is also used for those yet to be converted.
+	dictionaryStrings []string
+	// index is the index within the dictionary of the current value.
+	index int
+	err   error
 }
 
 func NewStringDictionaryTreeReader(present, data, length, dictionary io.Reader, encoding *proto.ColumnEncoding) (*StringDictionaryTreeReader, error) {
@@ -335,6 +726,11 @@ func NewStringDictionaryTreeReader(present, data, length, dictionary io.Reader,
 }
 
 func (s *StringDictionaryTreeReader) readDictionaryStream(dictionary io.Reader) error {
+	// Streams held in memory are used without copying.
+	if buf, ok := dictionary.(*bytes.Buffer); ok {
+		s.dictionaryBytes = buf.Bytes()
+		return nil
+	}
 	var buf bytes.Buffer
 	_, err := io.Copy(&buf, dictionary)
 	if err != nil {
@@ -349,12 +745,23 @@ func (s *StringDictionaryTreeReader) readDictionaryLength(length io.Reader, enco
 	if err != nil {
 		return err
 	}
+	// Every entry is distinct, so there is at most one more entry than there are
+	// bytes in the dictionary.
+	size := int(encoding.GetDictionarySize())
+	if size < 0 || size > len(s.dictionaryBytes)+1 {
+		size = len(s.dictionaryBytes) + 1
+	}
+	s.dictionaryLength = make([]int, 0, size)
+	s.dictionaryOffsets = make([]int, 0, size)
 	var offset int
 	for lreader.Next() {
-		l := int(lreader.Int())
-		s.dictionaryLength = append(s.dictionaryLength, l)
+		l := lreader.Int()
+		if l < 0 || l > int64(len(s.dictionaryBytes)-offset) {
+			return newDecodeError("invalid dictionary length: %v at offset: %v, dictionary size: %v", l, offset, len(s.dictionaryBytes))
+		}
+		s.dictionaryLength = append(s.dictionaryLength, int(l))
 		s.dictionaryOffsets = append(s.dictionaryOffsets, offset)
-		offset += l
+		offset += int(l)
 	}
 	if err := lreader.Err(); err != nil && err != io.EOF {
 		return err
@@ -372,7 +779,11 @@ func (s *StringDictionaryTreeReader) Next() bool {
 	if !s.BaseTreeReader.IsPresent() {
 		return true
 	}
-	return s.reader.Next()
+	if !s.reader.Next() {
+		return false
+	}
+	s.index = int(s.reader.Int())
+	return s.reader.Err() == nil
 }
 
 func (s *StringDictionaryTreeReader) getIndexLength(i int) (int, int) {
@@ -387,21 +798,37 @@ func (s *StringDictionaryTreeReader) getIndexLength(i int) (int, int) {
 	return s.dictionaryOffsets[i], s.dictionaryLength[i]
 }
 
+// Bytes returns the bytes of the current value within the dictionary, which are
+// shared by every occurrence of the value and must not be modified.
+func (s *StringDictionaryTreeReader) Bytes() []byte {
+	if len(s.dictionaryBytes) == 0 {
+		return []byte{}
+	}
+	offset, length := s.getIndexLength(s.index)
+	if offset > len(s.dictionaryBytes) || offset+length > len(s.dictionaryBytes) {
+		s.err = fmt.Errorf("invalid offset:%v or length:%v, greater than dictionary size:%v", offset, length, len(s.dictionaryBytes))
+		return nil
+	}
+	return s.dictionaryBytes[offset : offset+length : offset+length]
+}
+
 func (s *StringDictionaryTreeReader) String() string {
 	if len(s.dictionaryBytes) == 0 {
 		return ""
 	}
-	v := s.reader.Value()
-	if v == nil {
-		return ""
+	i := s.index
+	if i >= 0 && i < len(s.dictionaryStrings) && s.dictionaryStrings[i] != "" {
+		return s.dictionaryStrings[i]
 	}
-	i := v.(int64)
-	offset, length := s.getIndexLength(int(i))
-	if offset > len(s.dictionaryBytes) || offset+length > len(s.dictionaryBytes) {
-		s.err = fmt.Errorf("invalid offset:%v or length:%v, greater than dictionary size:%v", offset, length, len(s.dictionaryBytes))
+	str := string(s.Bytes())
+	if s.err != nil {
 		return ""
 	}
-	return string(s.dictionaryBytes[offset : offset+length])
+	if s.dictionaryStrings == nil {
+		s.dictionaryStrings = make([]string, len(s.dictionaryLength))
+	}
+	s.dictionaryStrings[i] = str
+	return str
 }
 
 func (s *StringDictionaryTreeReader) Value() interface{} {
@@ -443,6 +870,22 @@ func (b *BooleanTreeReader) Value() interface{} {
 	return b.Bool()
 }
 
+// skip skips the next n values, skipping the values that are present from the
+// data stream.
+func (b *BooleanTreeReader) skip(n int) error {
+	present, err := b.BaseTreeReader.countPresent(n)
+	if err != nil {
+		return err
+	}
+	if _, err := b.BooleanReader.skipBits(uint64(present)); err != nil {
+		if err == io.EOF {
+			return newDecodeError("skipped values: %v exceed remaining values", present)
+		}
+		return err
+	}
+	return nil
+}
+
 func (b *BooleanTreeReader) Err() error {
 	if err := b.BooleanReader.Err(); err != nil {
 		return err
@@ -499,17 +942,21 @@ type MapTreeReader struct {
 	length IntegerReader
 	key    TreeReader
 	value  TreeReader
+	err    error
 }
 
 // Next returns true if another row is available.
 func (m *MapTreeReader) Next() bool {
+	if m.err != nil {
+		return false
+	}
 	if !m.BaseTreeReader.Next() {
 		return false
 	}
 	if !m.BaseTreeReader.IsPresent() {
 		return true
 	}
-	return m.length.Next() && m.key.Next() && m.value.Next()
+	return m.length.Next()
 }
 
 // MapEntry is an individual entry in a Map.
@@ -518,24 +965,57 @@ type MapEntry struct {
 	Value interface{} `json:"value"`
 }
 
-// Map returns the next available row of MapEntries.
+// Map returns the next available row of MapEntries, in the order in which they
+// were written. ORC does not record whether the keys of a map are sorted.
 func (m *MapTreeReader) Map() []MapEntry {
-	l := int(m.length.Int())
-	kv := make([]MapEntry, l)
+	l, err := readChildLength(m.length)
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	kv := make([]MapEntry, 0, minInt(l, MaxScope))
 	for i := 0; i < l; i++ {
-		kv[i] = MapEntry{
+		if !m.key.Next() || !m.value.Next() {
+			if err := m.childErr(); err != nil {
+				m.err = err
+			} else {
+				m.err = newDecodeError("map length: %v exceeds remaining child values: %v", l, i)
+			}
+			return nil
+		}
+		kv = append(kv, MapEntry{
 			Key:   m.key.Value(),
 			Value: m.value.Value(),
-		}
-		if i-1 == l {
-			break
-		}
-		m.key.Next()
-		m.value.Next()
+		})
 	}
 	return kv
 }
 
+// skip skips the next n maps along with their keys and values.
+func (m *MapTreeReader) skip(n int) error {
+	total, err := skipPresent(m, m.length, n)
+	if err == nil {
+		err = skipValues(m.key, total)
+	}
+	if err == nil {
+		err = skipValues(m.value, total)
+	}
+	if err != nil {
+		m.err = err
+	}
+	return err
+}
+
+func (m *MapTreeReader) childErr() error {
+	if err := m.key.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	if err := m.value.Err(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
 // Value implements the TreeReader interface, returning the next available row.
 func (m *MapTreeReader) Value() interface{} {
 	if !m.BaseTreeReader.IsPresent() {
@@ -544,6 +1024,17 @@ func (m *MapTreeReader) Value() interface{} {
 	return m.Map()
 }
 
+// Err implements the TreeReader interface.
+func (m *MapTreeReader) Err() error {
+	if m.err != nil {
+		return m.err
+	}
+	if err := m.length.Err(); err != nil {
+		return err
+	}
+	return m.BaseTreeReader.Err()
+}
+
 // NewMapTreeReader returns a new instance of a MapTreeReader.
 func NewMapTreeReader(present, length io.Reader, key, value TreeReader, encoding *proto.ColumnEncoding) (*MapTreeReader, error) {
 	lengthReader, err := createIntegerReader(encoding.GetKind(), length, false, false)
@@ -551,10 +1042,10 @@ func NewMapTreeReader(present, length io.Reader, key, value TreeReader, encoding
 		return nil, err
 	}
 	return &MapTreeReader{
-		NewBaseTreeReader(present),
-		lengthReader,
-		key,
-		value,
+		BaseTreeReader: NewBaseTreeReader(present),
+		length:         lengthReader,
+		key:            key,
+		value:          value,
 	}, nil
 }
 
@@ -566,6 +1057,9 @@ type ListTreeReader struct {
 }
 
 func (r *ListTreeReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
 	if !r.BaseTreeReader.Next() {
 		return false
 	}
@@ -576,23 +1070,40 @@ func (r *ListTreeReader) Next() bool {
 }
 
 func (r *ListTreeReader) List() []interface{} {
-	l := int(r.length.Int())
-	ls := make([]interface{}, l, l)
-	if l == 0 {
-		return ls
+	l, err := readChildLength(r.length)
+	if err != nil {
+		r.err = err
+		return nil
 	}
-	for i := range ls {
+	// Grow the list as values are read so that a corrupt length
+	// cannot cause an arbitrarily large allocation.
+	ls := make([]interface{}, 0, minInt(l, MaxScope))
+	for i := 0; i < l; i++ {
 		if !r.value.Next() {
-			if err := r.Err(); err != nil {
+			if err := r.value.Err(); err != nil && err != io.EOF {
 				r.err = err
+			} else {
+				r.err = newDecodeError("list length: %v exceeds remaining child values: %v", l, i)
 			}
-			break
+			return nil
 		}
-		ls[i] = r.value.Value()
+		ls = append(ls, r.value.Value())
 	}
 	return ls
 }
 
+// skip skips the next n lists along with their values.
+func (r *ListTreeReader) skip(n int) error {
+	total, err := skipPresent(r, r.length, n)
+	if err == nil {
+		err = skipValues(r.value, total)
+	}
+	if err != nil {
+		r.err = err
+	}
+	return err
+}
+
 func (r *ListTreeReader) Value() interface{} {
 	if !r.BaseTreeReader.IsPresent() {
 		return nil
@@ -652,6 +1163,21 @@ func (s *StructTreeReader) Struct() Struct {
 	return st
 }
 
+// skip skips the next n structs along with the values of their fields.
+func (s *StructTreeReader) skip(n int) error {
+	// The fields have values only for the structs that are present.
+	present, err := s.BaseTreeReader.countPresent(n)
+	if err != nil {
+		return err
+	}
+	for _, child := range s.children {
+		if err := skipValues(child, present); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *StructTreeReader) Value() interface{} {
 	if !s.BaseTreeReader.IsPresent() {
 		return nil
@@ -686,21 +1212,15 @@ func (r *FloatTreeReader) Next() bool {
 	if !r.BaseTreeReader.Next() {
 		return false
 	}
-	if !r.BaseTreeReader.IsPresent() {
-		return true
-	}
-	return r.BaseTreeReader.Next()
+	return true
 }
 
+// Float returns the next Float value.
 func (r *FloatTreeReader) Float() Float {
 	bs := make([]byte, r.bytesPerValue, r.bytesPerValue)
-	n, err := r.Reader.Read(bs)
+	n, err := io.ReadFull(r.Reader, bs)
 	if err != nil {
-		r.err = err
-		return 0
-	}
-	if n != r.bytesPerValue {
-		r.err = fmt.Errorf("read unexpected number of bytes: %v, expected:%v", n, r.bytesPerValue)
+		r.err = fmt.Errorf("read unexpected number of bytes: %v, expected:%v: %v", n, r.bytesPerValue, err)
 		return 0
 	}
 	return Float(math.Float32frombits(binary.LittleEndian.Uint32(bs)))
@@ -709,16 +1229,20 @@ func (r *FloatTreeReader) Float() Float {
 // Double is ORC double type i.e. a float64.
 type Double float64
 
+// MarshalJSON implements the json.Marshaler interface, encoding NaN and infinite
+// values as the strings "NaN", "Infinity" and "-Infinity".
+func (d Double) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	appendJSONFloat(&buf, float64(d), 64)
+	return buf.Bytes(), nil
+}
+
 // Double returns the next Double value.
 func (r *FloatTreeReader) Double() Double {
 	bs := make([]byte, r.bytesPerValue, r.bytesPerValue)
-	n, err := r.Reader.Read(bs)
+	n, err := io.ReadFull(r.Reader, bs)
 	if err != nil {
-		r.err = err
-		return 0
-	}
-	if n != r.bytesPerValue {
-		r.err = fmt.Errorf("read unexpected number of bytes: %v, expected:%v", n, r.bytesPerValue)
+		r.err = fmt.Errorf("read unexpected number of bytes: %v, expected:%v: %v", n, r.bytesPerValue, err)
 		return 0
 	}
 	return Double(math.Float64frombits(binary.LittleEndian.Uint64(bs)))
@@ -758,6 +1282,9 @@ type BinaryTreeReader struct {
 }
 
 func (r *BinaryTreeReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
 	if !r.BaseTreeReader.Next() {
 		return false
 	}
@@ -768,13 +1295,10 @@ func (r *BinaryTreeReader) Next() bool {
 }
 
 func (r *BinaryTreeReader) Binary() []byte {
-	l := int(r.length.Int())
-	b := make([]byte, l, l)
-	n, err := r.data.Read(b)
+	b, err := readLength(r.data, r.length.Int())
 	if err != nil {
 		r.err = err
-	} else if n != l {
-		r.err = fmt.Errorf("read unexpected number of bytes: %v, expected:%v", n, l)
+		return nil
 	}
 	return b
 }
@@ -849,6 +1373,7 @@ func (u *UnionTreeReader) Value() interface{} {
 	i := int(u.data.Byte())
 	if i >= len(u.children) {
 		u.err = fmt.Errorf("unexpected tag offset: %v expected < %v", i, len(u.children))
+		return nil
 	}
 	if u.children[i].Next() {
 		return UnionValue{
@@ -883,7 +1408,13 @@ type DecimalTreeReader struct {
 	scale     int
 }
 
-// NewDecimalTreeReader returns a new instances of a DecimalTreeReader or an error if one occurs.
+// hive11DecimalScale is the scale that values of decimal columns written by Hive 0.11,
+// which have no fixed precision and scale, are truncated to. This matches the
+// default behaviour of the C++ reader.
+const hive11DecimalScale = 6
+
+// NewDecimalTreeReader returns a new instances of a DecimalTreeReader or an error if one
+// occurs. A precision of zero indicates a column written by Hive 0.11.
 func NewDecimalTreeReader(present, data, secondary io.Reader, encoding *proto.ColumnEncoding, precision, scale int) (*DecimalTreeReader, error) {
 	ireader, err := createIntegerReader(encoding.GetKind(), secondary, true, false)
 	if err != nil {
@@ -918,6 +1449,26 @@ func (d *DecimalTreeReader) Next() bool {
 		Abs: i,
 		Exp: d.secondary.Int(),
 	}
+	if d.precision == 0 && d.nextVal.Exp > hive11DecimalScale {
+		d.nextVal.Abs.Quo(d.nextVal.Abs, pow10(d.nextVal.Exp-hive11DecimalScale))
+		d.nextVal.Exp = hive11DecimalScale
+	}
+	// Older writers recorded some values with a scale other than that of the
+	// column, which are rescaled to the scale of the column.
+	if d.precision != 0 && d.nextVal.Exp != int64(d.scale) {
+		scale := int64(d.scale)
+		if d.nextVal.Exp < scale {
+			d.nextVal.Abs.Mul(d.nextVal.Abs, pow10(scale-d.nextVal.Exp))
+		} else {
+			abs, rem := new(big.Int).QuoRem(d.nextVal.Abs, pow10(d.nextVal.Exp-scale), new(big.Int))
+			if rem.Sign() != 0 {
+				d.err = fmt.Errorf("decimal value %s cannot be represented with scale %d", d.nextVal, d.scale)
+				return false
+			}
+			d.nextVal.Abs = abs
+		}
+		d.nextVal.Exp = scale
+	}
 	return true
 }
 
@@ -944,3 +1495,111 @@ func (d *DecimalTreeReader) Err() error {
 	}
 	return d.BaseTreeReader.Err()
 }
+
+// StreamKind is the kind of a stream within a stripe.
+type StreamKind = proto.Stream_Kind
+
+// RawColumn is the value of a column with an unknown type when the Reader is
+// configured using WithRawUnknownColumns. It contains the column encoding and
+// the decompressed bytes of each of the column's streams within the stripe.
+type RawColumn struct {
+	Encoding *proto.ColumnEncoding
+	Streams  map[StreamKind][]byte
+}
+
+// RawTreeReader is a TreeReader that returns the raw streams of a column with
+// an unknown type. As the rows cannot be decoded, the same RawColumn is
+// returned for every row within a stripe.
+type RawTreeReader struct {
+	column RawColumn
+}
+
+// NewRawTreeReader returns a new RawTreeReader for the streams of the column
+// with the provided id or an error if one occurs.
+func NewRawTreeReader(id int, m streamMap, encoding *proto.ColumnEncoding) (*RawTreeReader, error) {
+	streams := make(map[StreamKind][]byte)
+	for name, r := range m {
+		if name.columnID != id {
+			continue
+		}
+		byt, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		streams[name.kind] = byt
+	}
+	return &RawTreeReader{
+		column: RawColumn{
+			Encoding: encoding,
+			Streams:  streams,
+		},
+	}, nil
+}
+
+// Next returns true, the number of rows is determined by the other columns.
+func (r *RawTreeReader) Next() bool {
+	return true
+}
+
+// Value returns the RawColumn for the current stripe.
+func (r *RawTreeReader) Value() interface{} {
+	return r.column
+}
+
+// Err returns the last error to have occurred.
+func (r *RawTreeReader) Err() error {
+	return nil
+}
+
+// columnHook holds a ColumnHook along with the number of values of the column that
+// it has been called with so far.
+type columnHook struct {
+	column string
+	fn     ColumnHook
+	values int
+}
+
+// hookTreeReader is a TreeReader that passes each value of the wrapped TreeReader
+// through a ColumnHook.
+type hookTreeReader struct {
+	TreeReader
+	hook  *columnHook
+	value interface{}
+	err   error
+}
+
+func newHookTreeReader(reader TreeReader, hook *columnHook) *hookTreeReader {
+	return &hookTreeReader{
+		TreeReader: reader,
+		hook:       hook,
+	}
+}
+
+// Next returns true if another value is available, calling the hook with it.
+func (h *hookTreeReader) Next() bool {
+	if h.err != nil || !h.TreeReader.Next() {
+		return false
+	}
+	rowIndex := h.hook.values
+	h.hook.values++
+	value, err := h.hook.fn(rowIndex, h.TreeReader.Value())
+	if err != nil {
+		h.err = fmt.Errorf("column hook for %s failed at row %d: %v", h.hook.column, rowIndex, err)
+		return false
+	}
+	h.value = value
+	return true
+}
+
+// Value returns the current value as returned by the hook.
+func (h *hookTreeReader) Value() interface{} {
+	return h.value
+}
+
+// Err returns the last error to have occurred.
+func (h *hookTreeReader) Err() error {
+	if h.err != nil {
+		return h.err
+	}
+	return h.TreeReader.Err()
+}
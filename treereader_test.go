@@ -0,0 +1,876 @@
+package orc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"code.simon-critchley.co.uk/orc/proto"
+)
+
+// lengthStream returns an RLEv2 encoded unsigned integer stream of the provided lengths.
+func lengthStream(t *testing.T, lengths ...int64) *bytes.Buffer {
+	var buf bytes.Buffer
+	w := NewRunLengthIntegerWriterV2(&buf, false)
+	for _, l := range lengths {
+		if err := w.WriteInt(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestTreeReaderCorruptLength(t *testing.T) {
+
+	kind := proto.ColumnEncoding_DIRECT_V2
+	encoding := &proto.ColumnEncoding{Kind: &kind}
+
+	testCases := []struct {
+		name   string
+		reader func(lengths ...int64) (TreeReader, error)
+	}{
+		{
+			name: "string",
+			reader: func(lengths ...int64) (TreeReader, error) {
+				return NewStringDirectTreeReader(nil, bytes.NewBufferString("abcdef"), lengthStream(t, lengths...), kind)
+			},
+		},
+		{
+			name: "binary",
+			reader: func(lengths ...int64) (TreeReader, error) {
+				return NewBinaryTreeReader(nil, bytes.NewBufferString("abcdef"), lengthStream(t, lengths...), encoding)
+			},
+		},
+		{
+			name: "list",
+			reader: func(lengths ...int64) (TreeReader, error) {
+				child, err := NewStringDirectTreeReader(nil, bytes.NewBufferString("abcdef"), lengthStream(t, 1, 2, 3), kind)
+				if err != nil {
+					return nil, err
+				}
+				return NewListTreeReader(nil, lengthStream(t, lengths...), child, encoding)
+			},
+		},
+	}
+
+	corrupt := [][]int64{
+		// Negative length, as a result of zigzag misconfiguration.
+		{2, -3},
+		// Length exceeding the remaining data.
+		{2, 100},
+	}
+
+	for _, tc := range testCases {
+		for _, lengths := range corrupt {
+			r, err := tc.reader(lengths...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for r.Next() {
+				r.Value()
+			}
+			if _, ok := r.Err().(*DecodeError); !ok {
+				t.Errorf("Test failed, expected DecodeError for %s with lengths %v got %v", tc.name, lengths, r.Err())
+			}
+		}
+	}
+
+}
+
+func TestReadLengthStreamBound(t *testing.T) {
+
+	testCases := []struct {
+		name     string
+		data     func() io.Reader
+		length   int64
+		expected string
+	}{
+		{
+			name:   "bounded",
+			data:   func() io.Reader { return &boundedReader{r: strings.NewReader("abcdef"), n: 6} },
+			length: math.MaxInt32,
+		},
+		{
+			name:   "unbounded",
+			data:   func() io.Reader { return iotest.OneByteReader(strings.NewReader("abcdef")) },
+			length: math.MaxInt32,
+		},
+		{
+			name:     "bounded",
+			data:     func() io.Reader { return &boundedReader{r: strings.NewReader("abcdefgh"), n: 6} },
+			length:   6,
+			expected: "abcdef",
+		},
+		{
+			name:     "unbounded",
+			data:     func() io.Reader { return iotest.OneByteReader(strings.NewReader("abcdef")) },
+			length:   6,
+			expected: "abcdef",
+		},
+	}
+
+	for _, tc := range testCases {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		b, err := readLength(tc.data(), tc.length)
+		runtime.ReadMemStats(&after)
+		if tc.expected == "" {
+			if _, ok := err.(*DecodeError); !ok {
+				t.Errorf("Test failed, expected DecodeError for %s stream got %v", tc.name, err)
+			}
+		} else if err != nil || string(b) != tc.expected {
+			t.Errorf("Test failed, expected %q from %s stream got %q (%v)", tc.expected, tc.name, b, err)
+		}
+		// A corrupt length allocates no more than the bytes of the stream.
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+			t.Errorf("Test failed, %s stream allocated %d bytes for length %d", tc.name, allocated, tc.length)
+		}
+	}
+
+}
+
+func TestStringDictionaryTreeReaderCorruptLength(t *testing.T) {
+
+	kind := proto.ColumnEncoding_DICTIONARY_V2
+	encoding := &proto.ColumnEncoding{Kind: &kind}
+
+	for _, lengths := range [][]int64{{2, -1}, {2, 5}} {
+		_, err := NewStringDictionaryTreeReader(nil, lengthStream(t, 0, 1), lengthStream(t, lengths...), bytes.NewBufferString("abc"), encoding)
+		if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("Test failed, expected DecodeError for lengths %v got %v", lengths, err)
+		}
+	}
+
+}
+
+func TestStringDictionaryTreeReaderLazy(t *testing.T) {
+
+	kind := proto.ColumnEncoding_DICTIONARY_V2
+	// A dictionary size greater than the number of entries is not trusted.
+	size := uint32(1000)
+	encoding := &proto.ColumnEncoding{Kind: &kind, DictionarySize: &size}
+
+	dictionary := bytes.NewBufferString("abbccc")
+	r, err := NewStringDictionaryTreeReader(nil, lengthStream(t, 2, 0, 2, 0, 2), lengthStream(t, 1, 2, 3), dictionary, encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for r.Next() {
+		values = append(values, r.String())
+	}
+	if err := r.Err(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, []string{"ccc", "a", "ccc", "a", "ccc"}) {
+		t.Errorf("Test failed, got %q", values)
+	}
+	// Only the referenced entries are converted to strings.
+	if !reflect.DeepEqual(r.dictionaryStrings, []string{"a", "", "ccc"}) {
+		t.Errorf("Test failed, expected the referenced entries to be converted got %q", r.dictionaryStrings)
+	}
+	if cap(r.dictionaryOffsets) > len("abbccc")+1 {
+		t.Errorf("Test failed, expected at most %d offsets to be allocated got %d", len("abbccc")+1, cap(r.dictionaryOffsets))
+	}
+
+	r, err = NewStringDictionaryTreeReader(nil, lengthStream(t, 0, 3), lengthStream(t, 1, 2, 3), bytes.NewBufferString("abbccc"), encoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values = values[:0]
+	for r.Next() {
+		values = append(values, r.String())
+	}
+	if r.Err() == nil || !reflect.DeepEqual(values, []string{"a", ""}) {
+		t.Errorf("Test failed, expected an error for an index beyond the dictionary got %q and %v", values, r.Err())
+	}
+
+}
+
+func TestListTreeReaderStrings(t *testing.T) {
+
+	schema, err := ParseSchema("struct<direct:array<string>,dictionary:array<string>,nested:array<array<string>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each row has a different number of elements of varying lengths, including
+	// empty and null lists, empty strings and null elements.
+	var expected [][]interface{}
+	for i := 0; i < 200; i++ {
+		var direct, dictionary, nested interface{}
+		if i%7 != 3 {
+			values := make([]interface{}, i%5)
+			for j := range values {
+				values[j] = strings.Repeat("x", (i+j)%4) + fmt.Sprint(i, ".", j)
+			}
+			direct = values
+		}
+		if i%11 != 5 {
+			values := make([]interface{}, (i*3)%4)
+			for j := range values {
+				if (i+j)%6 == 0 {
+					continue
+				}
+				values[j] = []string{"", "a", "bb", "ccc"}[(i+j)%4]
+			}
+			dictionary = values
+		}
+		if i%13 != 7 {
+			lists := make([]interface{}, i%3)
+			for j := range lists {
+				values := make([]interface{}, (i+j)%4)
+				for k := range values {
+					values[k] = fmt.Sprint(i, ".", j, ".", k)
+				}
+				lists[j] = values
+			}
+			nested = lists
+		}
+		row := []interface{}{direct, dictionary, nested}
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, row)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check that both string encodings are covered.
+	for field, kind := range map[string]proto.ColumnEncoding_Kind{
+		"direct":     proto.ColumnEncoding_DIRECT_V2,
+		"dictionary": proto.ColumnEncoding_DICTIONARY_V2,
+	} {
+		column, err := schema.GetField(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.Select(field).Stripes() {
+			t.Fatal("Test failed, expected a stripe")
+		}
+		if encoding, err := r.getColumn(column.children[0].getID()); err != nil || encoding.GetKind() != kind {
+			t.Errorf("Test failed, expected %s elements to be %s encoded got %v", field, kind, encoding)
+		}
+		r.currentStripeOffset = 0
+	}
+
+	c := r.Select("direct", "dictionary", "nested")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			if row := c.Row(); !reflect.DeepEqual(row, expected[i]) {
+				t.Errorf("Test failed, expected %v at row %d got %v", expected[i], i, row)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+}
+
+func TestTimestampNanos(t *testing.T) {
+
+	// The low 3 bits of each serialized value are 0 when no trailing zeros were
+	// removed and otherwise one less than the number removed.
+	testCases := []struct {
+		nanos      int64
+		serialized int64
+	}{
+		{nanos: 0, serialized: 0},
+		{nanos: 1, serialized: 1 << 3},
+		{nanos: 10, serialized: 10 << 3},
+		{nanos: 999999999, serialized: 999999999 << 3},
+		{nanos: 123456780, serialized: 123456780 << 3},
+		{nanos: 100, serialized: 1<<3 | 1},
+		{nanos: 999999900, serialized: 9999999<<3 | 1},
+		{nanos: 1000, serialized: 1<<3 | 2},
+		{nanos: 10000, serialized: 1<<3 | 3},
+		{nanos: 100000, serialized: 1<<3 | 4},
+		{nanos: 1000000, serialized: 1<<3 | 5},
+		{nanos: 10000000, serialized: 1<<3 | 6},
+		{nanos: 100000000, serialized: 1<<3 | 7},
+		{nanos: 900000000, serialized: 9<<3 | 7},
+		{nanos: 120000000, serialized: 12<<3 | 6},
+		{nanos: 100100000, serialized: 1001<<3 | 4},
+	}
+
+	for _, tc := range testCases {
+		if serialized := encodeNanos(tc.nanos); serialized != tc.serialized {
+			t.Errorf("Test failed, expected %d to encode as %d got %d", tc.nanos, tc.serialized, serialized)
+		}
+		if nanos := decodeNanos(tc.serialized); nanos != tc.nanos {
+			t.Errorf("Test failed, expected %d to decode as %d got %d", tc.serialized, tc.nanos, nanos)
+		}
+	}
+
+	// Every shift encodes the least and greatest values of a second with it.
+	for shift := int64(0); shift < 8; shift++ {
+		scale := int64(1)
+		if shift > 0 {
+			for i := int64(0); i <= shift; i++ {
+				scale *= 10
+			}
+		}
+		for _, nanos := range []int64{scale, 999999999 / scale * scale} {
+			serialized := encodeNanos(nanos)
+			if serialized&0x07 != shift {
+				t.Errorf("Test failed, expected %d to encode with shift %d got %d", nanos, shift, serialized&0x07)
+			}
+			if decoded := decodeNanos(serialized); decoded != nanos {
+				t.Errorf("Test failed, expected %d to round trip got %d", nanos, decoded)
+			}
+		}
+	}
+
+	// The secondary streams written by the Java implementation are re-encoded
+	// identically.
+	shifts := make(map[int64]bool)
+	for _, file := range []string{
+		"TestOrcFile.testTimestamp.orc",
+		"TestOrcFile.testDate1900.orc",
+		"TestOrcFile.testDate2038.orc",
+		"TestOrcFile.testUnionAndTimestamp.orc",
+	} {
+		r, err := Open("./examples/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		column, fields := 0, []string(nil)
+		if r.Schema().getCategory() == CategoryStruct {
+			column, fields = 1, r.Schema().fieldNames
+		}
+		c := r.Select(fields...)
+		for c.Stripes() {
+			encoding, err := r.getColumn(column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			secondary, err := createIntegerReader(encoding.GetKind(), c.streams.get(streamName{column, proto.Stream_SECONDARY}), false, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for secondary.Next() {
+				serialized := secondary.Int()
+				shifts[serialized&0x07] = true
+				if nanos := decodeNanos(serialized); nanos > 999999999 || encodeNanos(nanos) != serialized {
+					t.Errorf("Test failed, %s: %d decoded as %d", file, serialized, nanos)
+				}
+			}
+			if err := secondary.Err(); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, shift := range []int64{0, 2, 4, 5, 6, 7} {
+		if !shifts[shift] {
+			t.Errorf("Test failed, expected a nanosecond value with shift %d", shift)
+		}
+	}
+
+}
+
+func TestDecimalTreeReaderScale(t *testing.T) {
+
+	// Values recorded with a scale other than that of the column are rescaled to
+	// the scale of the column.
+	testCases := []struct {
+		value    int64
+		scale    int64
+		expected string
+	}{
+		{value: 12345, scale: 2, expected: "123.45"},
+		{value: 1230, scale: 3, expected: "1.23"},
+		{value: 5, scale: 0, expected: "5.00"},
+		{value: -7, scale: 1, expected: "-0.70"},
+		{value: 12, scale: -1, expected: "120.00"},
+		{value: -1000000, scale: 6, expected: "-1.00"},
+	}
+
+	var data bytes.Buffer
+	var secondary bytes.Buffer
+	scales := NewRunLengthIntegerWriterV2(&secondary, true)
+	for _, tc := range testCases {
+		if err := encodeBase128Varint(&data, big.NewInt(tc.value)); err != nil {
+			t.Fatal(err)
+		}
+		if err := scales.WriteInt(tc.scale); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The last value cannot be represented with the scale of the column.
+	if err := encodeBase128Varint(&data, big.NewInt(12345)); err != nil {
+		t.Fatal(err)
+	}
+	if err := scales.WriteInt(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := scales.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	encoding := &proto.ColumnEncoding{Kind: proto.ColumnEncoding_DIRECT_V2.Enum()}
+	d, err := NewDecimalTreeReader(nil, &data, &secondary, encoding, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tc := range testCases {
+		if !d.Next() {
+			t.Fatalf("Test failed, expected value %d got error %v", i, d.Err())
+		}
+		if v := d.Decimal(); v.String() != tc.expected || v.Exp != 2 {
+			t.Errorf("Test failed, expected %s with scale 2 got %s with scale %d", tc.expected, v, v.Exp)
+		}
+	}
+	if d.Next() {
+		t.Errorf("Test failed, expected no value got %s", d.Decimal())
+	}
+	expected := "decimal value 12.345 cannot be represented with scale 2"
+	if err := d.Err(); err == nil || err.Error() != expected {
+		t.Errorf("Test failed, expected error %q got %v", expected, err)
+	}
+
+}
+
+func TestReaderStringsAsBytes(t *testing.T) {
+
+	schema, err := ParseSchema("struct<direct:string,dictionary:string,list:array<varchar(20)>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf,
+		SetSchema(schema),
+		WithColumnEncoding("direct", proto.ColumnEncoding_DIRECT_V2),
+		WithColumnEncoding("dictionary", proto.ColumnEncoding_DICTIONARY_V2),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two stripes of several batches of values, including nulls and empty strings.
+	var expected [][]interface{}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3*stringDirectBatchSize+10; j++ {
+			var direct, dictionary, list interface{}
+			if j%7 != 0 {
+				direct = strings.Repeat("x", j%5) + fmt.Sprint(i, ".", j)
+			}
+			if j%11 != 0 {
+				dictionary = []string{"", "a", "bb"}[j%3]
+			}
+			if j%13 != 0 {
+				list = []interface{}{fmt.Sprint(j), "", nil}
+			}
+			expected = append(expected, []interface{}{direct, dictionary, list})
+			if err := w.Write(direct, dictionary, list); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	toStrings := func(v interface{}) interface{} {
+		switch t := v.(type) {
+		case []byte:
+			return string(t)
+		case []interface{}:
+			values := make([]interface{}, len(t))
+			for i, value := range t {
+				if b, ok := value.([]byte); ok {
+					values[i] = string(b)
+				}
+			}
+			return values
+		}
+		return v
+	}
+
+	// The rows are all retained before being compared, as the bytes of each
+	// value remain valid after reading further rows, both when the streams
+	// are held in memory and when they are decompressed as they are read.
+	for _, limit := range []int64{-1, int64(len(expected))} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()), WithStringsAsBytes(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Limit(limit).Select("direct", "dictionary", "list")
+		var rows [][]interface{}
+		for c.Stripes() {
+			for c.Next() {
+				rows = append(rows, c.Row())
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(expected) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(expected), len(rows))
+		}
+		for i, row := range rows {
+			for j, value := range row {
+				if _, ok := value.(string); ok {
+					t.Fatalf("Test failed, expected []byte values got %q", value)
+				}
+				if actual := toStrings(value); !reflect.DeepEqual(actual, expected[i][j]) {
+					t.Errorf("Test failed, limit %d expected %#v at row %d column %d got %#v", limit, expected[i][j], i, j, actual)
+				}
+			}
+		}
+	}
+
+	// Values are sliced from a data stream held in memory without being copied or
+	// allocated.
+	kind := proto.ColumnEncoding_DIRECT_V2
+	data := []byte("abcdef")
+	r, err := NewStringDirectTreeReader(nil, bytes.NewBuffer(data), lengthStream(t, 1, 2, 0, 3), kind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values [][]byte
+	for r.Next() {
+		values = append(values, r.Bytes())
+	}
+	if !reflect.DeepEqual(values, [][]byte{[]byte("a"), []byte("bc"), {}, []byte("def")}) {
+		t.Errorf("Test failed, got %q", values)
+	}
+	if &values[1][0] != &data[1] {
+		t.Errorf("Test failed, expected the bytes of the values to be sliced from the data stream")
+	}
+	if cap(values[0]) != 1 {
+		t.Errorf("Test failed, expected the capacity of a value to be its length got %d", cap(values[0]))
+	}
+
+}
+
+func BenchmarkStringDirectTreeReader(b *testing.B) {
+
+	// A long string for each value.
+	var data bytes.Buffer
+	var lengths bytes.Buffer
+	lw := NewRunLengthIntegerWriterV2(&lengths, false)
+	rnd := rand.New(rand.NewSource(1))
+	const values = 10000
+	for i := 0; i < values; i++ {
+		l := 200 + rnd.Intn(100)
+		for j := 0; j < l; j++ {
+			data.WriteByte(byte('a' + rnd.Intn(26)))
+		}
+		if err := lw.WriteInt(int64(l)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := lw.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, asBytes := range []bool{false, true} {
+		b.Run(fmt.Sprintf("bytes=%t", asBytes), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(data.Len()))
+			for i := 0; i < b.N; i++ {
+				r, err := NewStringDirectTreeReader(nil, bytes.NewBuffer(data.Bytes()), bytes.NewReader(lengths.Bytes()), proto.ColumnEncoding_DIRECT_V2)
+				if err != nil {
+					b.Fatal(err)
+				}
+				var n int
+				for r.Next() {
+					if asBytes {
+						n += len(r.Bytes())
+					} else {
+						n += len(r.String())
+					}
+				}
+				if n != data.Len() {
+					b.Fatalf("read %d bytes, expected %d", n, data.Len())
+				}
+			}
+		})
+	}
+
+}
+
+func TestTreeReaderLargeValues(t *testing.T) {
+
+	// A 5 MiB value, half random and so stored in original chunks and half
+	// repeated and so deflated, between two small values, spanning many 256 KiB
+	// compression chunks.
+	const blockSize = 256 << 10
+	large := make([]byte, 5<<20)
+	rand.New(rand.NewSource(1)).Read(large[:len(large)/2])
+	copy(large[len(large)/2:], bytes.Repeat([]byte("orc "), len(large)/8))
+	values := [][]byte{[]byte("head"), large, []byte("tail")}
+	var data []byte
+	var lengths []int64
+	for _, v := range values {
+		data = append(data, v...)
+		lengths = append(lengths, int64(len(v)))
+	}
+	encoded := encodeChunks(data, blockSize, deflate)
+	var originals int
+	if err := WalkChunks(bytes.NewReader(encoded), func(compressedOffset int64, chunkLen int, isOriginal bool) error {
+		if isOriginal {
+			originals++
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if originals == 0 || originals == len(data)/blockSize {
+		t.Fatalf("Test failed, expected both original and compressed chunks got %d original", originals)
+	}
+
+	kind := proto.ColumnEncoding_DIRECT_V2
+	encoding := &proto.ColumnEncoding{Kind: &kind}
+	testCases := []struct {
+		name   string
+		reader func(data io.Reader) (TreeReader, error)
+		value  func(r TreeReader) []byte
+	}{
+		{
+			name: "binary",
+			reader: func(data io.Reader) (TreeReader, error) {
+				return NewBinaryTreeReader(nil, data, lengthStream(t, lengths...), encoding)
+			},
+			value: func(r TreeReader) []byte {
+				return r.Value().([]byte)
+			},
+		},
+		{
+			name: "string",
+			reader: func(data io.Reader) (TreeReader, error) {
+				return NewStringDirectTreeReader(nil, data, lengthStream(t, lengths...), kind)
+			},
+			value: func(r TreeReader) []byte {
+				return []byte(r.Value().(string))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		// The data stream is decompressed as it is read, a few bytes at a time.
+		r, err := tc.reader(CompressionZlib{}.Decoder(iotest.HalfReader(bytes.NewReader(encoded))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var i int
+		for r.Next() {
+			if i >= len(values) {
+				t.Fatalf("Test failed, %s: expected %d values", tc.name, len(values))
+			}
+			if v := tc.value(r); !bytes.Equal(v, values[i]) {
+				t.Errorf("Test failed, %s: value %d of %d bytes does not match, got %d bytes", tc.name, i, len(values[i]), len(v))
+			}
+			i++
+		}
+		if err := r.Err(); err != nil && err != io.EOF {
+			t.Fatalf("Test failed, %s: %v", tc.name, err)
+		}
+		if i != len(values) {
+			t.Errorf("Test failed, %s: expected %d values got %d", tc.name, len(values), i)
+		}
+
+		// A stream truncated within the large value is an error rather than a
+		// truncated value.
+		r, err = tc.reader(CompressionZlib{}.Decoder(bytes.NewReader(encoded[:len(encoded)/2])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r.Next() {
+			if v := tc.value(r); len(v) > 0 && len(v) != len("head") {
+				t.Errorf("Test failed, %s: expected no truncated value got %d bytes", tc.name, len(v))
+			}
+		}
+		if _, ok := r.Err().(*DecodeError); !ok {
+			t.Errorf("Test failed, %s: expected DecodeError got %v", tc.name, r.Err())
+		}
+	}
+
+	// The value is read whole from a file, whether its streams are read up front
+	// or as the rows are read. There is no writer of binary columns.
+	schema, err := ParseSchema("struct<string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithColumnEncoding("string1", proto.ColumnEncoding_DIRECT_V2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range values {
+		if err := w.Write(string(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, limit := range []int64{-1, int64(len(values))} {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Limit(limit).Select("string1")
+		var i int
+		for c.Stripes() {
+			for c.Next() {
+				if v, ok := c.Row()[0].(string); !ok || v != string(values[i]) {
+					t.Errorf("Test failed, limit %d: string value %d does not match", limit, i)
+				}
+				i++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if i != len(values) {
+			t.Errorf("Test failed, limit %d: expected %d rows got %d", limit, len(values), i)
+		}
+	}
+
+}
+
+func BenchmarkStringDictionaryTreeReader(b *testing.B) {
+
+	// A stripe of a dictionary encoded column whose every value is distinct, of
+	// which only the first row group is read.
+	schema, err := ParseSchema("struct<string1:string>")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithColumnEncoding("string1", proto.ColumnEncoding_DICTIONARY_V2))
+	if err != nil {
+		b.Fatal(err)
+	}
+	const rows = 200000
+	for i := 0; i < rows; i++ {
+		if err := w.Write(fmt.Sprintf("%0100d", i*7919%rows)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		c := r.Limit(int64(DefaultRowIndexStride)).Select("string1")
+		var n int
+		for c.Stripes() {
+			for c.Next() {
+				n++
+			}
+		}
+		if err := c.Err(); err != nil {
+			b.Fatal(err)
+		}
+		if n != int(DefaultRowIndexStride) {
+			b.Fatalf("read %d rows, expected %d", n, DefaultRowIndexStride)
+		}
+	}
+
+}
+
+func TestReadContextTimezone(t *testing.T) {
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSchema("struct<list:array<struct<ts:timestamp>>,map:map<string,array<timestamp>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2020, 6, 1, 12, 30, 0, 0, loc)
+	if err := w.Write([]interface{}{[]interface{}{ts}}, []MapEntry{{"a", []interface{}{ts}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The timezone of the writer recorded in the stripe footer is used when
+	// decoding the timestamps nested within the columns.
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{{[]interface{}{Struct{"ts": ts}}, []MapEntry{{"a", []interface{}{ts}}}}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected %v got %v", expected, rows)
+	}
+
+	// The location of the context is used by each nested TreeReader.
+	if r, err = NewReader(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	streams, err := r.getStreams(r.schema.getID(), 1, 2, 3, 4, 5, 6, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := r.newReadContext(streams)
+	ctx.location = time.UTC
+	reader, err := createTreeReader(r.schema, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reader.Next() {
+		t.Fatalf("Test failed, expected a row got error %v", reader.Err())
+	}
+	row := reader.Value().(Struct)
+	for _, value := range []interface{}{row["list"].([]interface{})[0].(Struct)["ts"], row["map"].([]MapEntry)[0].Value.([]interface{})[0]} {
+		if v := value.(time.Time); v.Location() != time.UTC {
+			t.Errorf("Test failed, expected a timestamp in UTC got %v", v)
+		}
+	}
+
+}
@@ -2,13 +2,93 @@ package orc
 
 import (
 	"fmt"
+	"time"
 
 	"code.simon-critchley.co.uk/orc/proto"
 )
 
-func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeReader, error) {
+// readContext holds the streams of a stripe along with the options of the Reader
+// used to decode them, which are passed down the TreeReaders created for the
+// columns of the stripe and their children.
+type readContext struct {
+	streams streamMap
+	// encodings are the encodings of the columns of the stripe.
+	encodings map[int]*proto.ColumnEncoding
+	types     []*proto.Type
+	// writerTimezone is the timezone recorded in the stripe footer, which is
+	// loaded as location when first needed.
+	writerTimezone string
+	location       *time.Location
+	// assumeUTCTimestamps is whether timestamps are read as written in UTC
+	// regardless of writerTimezone.
+	assumeUTCTimestamps bool
+	calendar            Calendar
+	columnHooks         map[int]*columnHook
+	stringsAsBytes      bool
+	rawUnknownColumns   bool
+	utf8Validation      UTF8Validation
+	// stripe is the index of the stripe within the file.
+	stripe int
+}
+
+// newReadContext returns the readContext of the current stripe of the Reader,
+// whose streams are provided.
+func (r *Reader) newReadContext(streams streamMap) *readContext {
+	return &readContext{
+		streams:             streams,
+		encodings:           r.columns,
+		types:               r.footer.GetTypes(),
+		writerTimezone:      r.writerTimezone,
+		assumeUTCTimestamps: r.assumeUTCTimestamps,
+		calendar:            r.Calendar(),
+		columnHooks:         r.columnHooks,
+		stringsAsBytes:      r.stringsAsBytes,
+		rawUnknownColumns:   r.rawUnknownColumns,
+		utf8Validation:      r.utf8Validation,
+		stripe:              r.currentStripeOffset - 1,
+	}
+}
+
+// encoding returns the encoding of the column with the provided ID.
+func (ctx *readContext) encoding(id int) (*proto.ColumnEncoding, error) {
+	if encoding, ok := ctx.encodings[id]; ok && encoding != nil {
+		return encoding, nil
+	}
+	return nil, fmt.Errorf("column: %v does not exist", id)
+}
+
+// writerLocation returns the timezone of the writer of the stripe, defaulting to
+// UTC if none was recorded.
+func (ctx *readContext) writerLocation() (*time.Location, error) {
+	if ctx.location != nil {
+		return ctx.location, nil
+	}
+	if ctx.writerTimezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(ctx.writerTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid writer timezone %q: %v", ctx.writerTimezone, err)
+	}
+	ctx.location = loc
+	return loc, nil
+}
+
+func createTreeReader(schema *TypeDescription, ctx *readContext) (TreeReader, error) {
+	reader, err := createColumnTreeReader(schema, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if hook, ok := ctx.columnHooks[schema.getID()]; ok {
+		return newHookTreeReader(reader, hook), nil
+	}
+	return reader, nil
+}
+
+func createColumnTreeReader(schema *TypeDescription, ctx *readContext) (TreeReader, error) {
 	id := schema.getID()
-	encoding, err := r.getColumn(id)
+	m := ctx.streams
+	encoding, err := ctx.encoding(id)
 	if err != nil {
 		return nil, err
 	}
@@ -46,26 +126,55 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 			encoding,
 		)
 	case CategoryString, CategoryVarchar, CategoryChar:
-		return NewStringTreeReader(
+		reader, err := NewStringTreeReader(
 			m.get(streamName{id, proto.Stream_PRESENT}),
 			m.get(streamName{id, proto.Stream_DATA}),
 			m.get(streamName{id, proto.Stream_LENGTH}),
 			m.get(streamName{id, proto.Stream_DICTIONARY_DATA}),
 			encoding,
 		)
+		if err != nil {
+			return nil, err
+		}
+		if ctx.stringsAsBytes {
+			return newStringBytesTreeReader(reader), nil
+		}
+		if ctx.utf8Validation != UTF8ValidationOff {
+			return newUTF8TreeReader(reader, ctx.utf8Validation, schema, ctx.stripe), nil
+		}
+		return reader, nil
 	case CategoryDate:
-		return NewDateTreeReader(
+		reader, err := NewDateTreeReader(
 			m.get(streamName{id, proto.Stream_PRESENT}),
 			m.get(streamName{id, proto.Stream_DATA}),
 			encoding,
 		)
-	case CategoryTimestamp:
-		return NewTimestampTreeReader(
+		if err != nil {
+			return nil, err
+		}
+		reader.julianGregorian = ctx.calendar == CalendarJulianGregorian
+		return reader, nil
+	case CategoryTimestamp, CategoryTimestampInstant:
+		// Instants are stored relative to the base in UTC rather than in
+		// the timezone of the writer.
+		location := time.UTC
+		if category == CategoryTimestamp && !ctx.assumeUTCTimestamps {
+			if location, err = ctx.writerLocation(); err != nil {
+				return nil, err
+			}
+		}
+		reader, err := NewTimestampTreeReader(
 			m.get(streamName{id, proto.Stream_PRESENT}),
 			m.get(streamName{id, proto.Stream_DATA}),
 			m.get(streamName{id, proto.Stream_SECONDARY}),
 			encoding,
+			location,
 		)
+		if err != nil {
+			return nil, err
+		}
+		reader.julianGregorian = ctx.calendar == CalendarJulianGregorian
+		return reader, nil
 	case CategoryBinary:
 		return NewBinaryTreeReader(
 			m.get(streamName{id, proto.Stream_PRESENT}),
@@ -79,14 +188,14 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 			m.get(streamName{id, proto.Stream_DATA}),
 			m.get(streamName{id, proto.Stream_SECONDARY}),
 			encoding,
-			schema.precision,
+			int(ctx.types[id].GetPrecision()),
 			schema.scale,
 		)
 	case CategoryList:
 		if len(schema.children) != 1 {
 			return nil, fmt.Errorf("expect 1 child for list type, got: %v", len(schema.children))
 		}
-		valueReader, err := createTreeReader(schema.children[0], m, r)
+		valueReader, err := createTreeReader(schema.children[0], ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -100,11 +209,11 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 		if len(schema.children) != 2 {
 			return nil, fmt.Errorf("expect 2 children for map type, got: %v", len(schema.children))
 		}
-		keyReader, err := createTreeReader(schema.children[0], m, r)
+		keyReader, err := createTreeReader(schema.children[0], ctx)
 		if err != nil {
 			return nil, err
 		}
-		valueReader, err := createTreeReader(schema.children[1], m, r)
+		valueReader, err := createTreeReader(schema.children[1], ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -118,7 +227,7 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 	case CategoryStruct:
 		children := make(map[string]TreeReader)
 		for i := range schema.children {
-			child, err := createTreeReader(schema.children[i], m, r)
+			child, err := createTreeReader(schema.children[i], ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -131,7 +240,7 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 	case CategoryUnion:
 		children := make([]TreeReader, len(schema.children))
 		for i := range schema.children {
-			child, err := createTreeReader(schema.children[i], m, r)
+			child, err := createTreeReader(schema.children[i], ctx)
 			if err != nil {
 				return nil, err
 			}
@@ -143,6 +252,9 @@ func createTreeReader(schema *TypeDescription, m streamMap, r *Reader) (TreeRead
 			children,
 		)
 	default:
+		if category.name == categoryUnknownName && ctx.rawUnknownColumns {
+			return NewRawTreeReader(id, m, encoding)
+		}
 		return nil, fmt.Errorf("unsupported type: %s", category)
 	}
 }
@@ -6,6 +6,9 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"code.simon-critchley.co.uk/orc/proto"
 )
@@ -43,8 +46,8 @@ type BaseTreeWriter struct {
 	statistics        ColumnStatistics
 	positionRecorders PositionRecorders
 	indexEntries      []*proto.RowIndexEntry
+	presentPositions  [][]uint64
 	streams           []Stream
-	numValues         uint64
 	hasNull           bool
 }
 
@@ -65,34 +68,34 @@ func NewBaseTreeWriter(category Category, codec CompressionCodec) BaseTreeWriter
 	return b
 }
 
-func (b *BaseTreeWriter) positions() []uint64 {
+func (b *BaseTreeWriter) positions(recorders PositionRecorders) []uint64 {
 	var positions []uint64
-	for _, recorder := range b.positionRecorders {
+	for _, recorder := range recorders {
 		switch b.codec.(type) {
 		case CompressionNone:
 			positions = append(positions, recorder.Positions()...)
 		}
 	}
-	if !b.hasNull {
-		return positions[1:]
-	}
 	return positions
 }
 
+// RecordPositions records the positions of the streams at the start of the next
+// row group. The positions of the present stream are kept separately as they are
+// only included in the row index if the stripe contains a null.
 func (b *BaseTreeWriter) RecordPositions() {
+	b.presentPositions = append(b.presentPositions, b.positions(b.positionRecorders[:1]))
 	b.indexEntries = append(b.indexEntries, &proto.RowIndexEntry{
-		Positions:  b.positions(),
+		Positions:  b.positions(b.positionRecorders[1:]),
 		Statistics: b.currentStatistics.Statistics(),
 	})
 	b.currentStatistics = NewColumnStatistics(b.category)
-	b.numValues = 0
 }
 
 // Write checks whether i is nil and writes an appropriate true or false value to
-// the underlying isPresent stream.
+// the underlying isPresent stream. The present stream is written for every value,
+// and is omitted from the stripe on Close if none of its values are null.
 func (b *BaseTreeWriter) Write(i interface{}) error {
 	// Add the value to the statistics
-	b.numValues++
 	b.statistics.Add(i)
 	b.currentStatistics.Add(i)
 	// isPresent is optional, therefore, support nil BooleanWriter
@@ -100,23 +103,16 @@ func (b *BaseTreeWriter) Write(i interface{}) error {
 		return nil
 	}
 	if i == nil {
-		// The stream has nulls, therefore, set hasNull to
-		// true and write the prior values to the stream.
 		b.hasNull = true
-		for j := uint64(1); j < b.numValues; j++ {
-			err := b.present.WriteBool(true)
-			if err != nil {
-				return err
-			}
-		}
-		// If interface value is nil, then write false to isPresent stream.
-		return b.present.WriteBool(false)
-	}
-	if b.hasNull {
-		// Write to the stream only if it has nulls.
-		return b.present.WriteBool(true)
 	}
-	return nil
+	return b.present.WriteBool(i != nil)
+}
+
+// writePresent writes a true value to the present stream for a value that is
+// added to the statistics by the embedding TreeWriter, so that it is not boxed in
+// an interface.
+func (b *BaseTreeWriter) writePresent() error {
+	return b.present.WriteBool(true)
 }
 
 // Close flushes the underlying BufferedWriter returning an error if one occurs.
@@ -124,11 +120,10 @@ func (b *BaseTreeWriter) Close() error {
 	if err := b.present.Close(); err != nil {
 		return err
 	}
-	// If the column has no nulls then reset the
-	// underlying buffer.
-	if !b.statistics.Statistics().GetHasNull() {
-		b.buffer.Reset()
-		b.buffer.written = 0
+	// If the column has no nulls then reset the underlying
+	// buffer, omitting the present stream from the stripe.
+	if !b.hasNull {
+		b.buffer.discard()
 	}
 	return b.buffer.Close()
 }
@@ -166,9 +161,23 @@ func (b *BaseTreeWriter) Streams() []Stream {
 	return b.streams
 }
 
+// RowIndex returns the row index of the stripe, with the positions of the present
+// stream preceding those of the other streams when the stripe contains a null.
 func (b *BaseTreeWriter) RowIndex() *proto.RowIndex {
+	if !b.hasNull {
+		return &proto.RowIndex{
+			Entry: b.indexEntries,
+		}
+	}
+	entries := make([]*proto.RowIndexEntry, len(b.indexEntries))
+	for i, entry := range b.indexEntries {
+		entries[i] = &proto.RowIndexEntry{
+			Positions:  append(append([]uint64{}, b.presentPositions[i]...), entry.Positions...),
+			Statistics: entry.Statistics,
+		}
+	}
 	return &proto.RowIndex{
-		Entry: b.indexEntries,
+		Entry: entries,
 	}
 }
 
@@ -194,6 +203,35 @@ func createIntegerWriter(kind proto.ColumnEncoding_Kind, w io.ByteWriter, signed
 	}
 }
 
+// encodingOptions configures the choice of encoding of a column.
+type encodingOptions struct {
+	// dictionaryThreshold is the maximum ratio of distinct values to values
+	// for which a string column is dictionary encoded, 0 disabling dictionary
+	// encoding.
+	dictionaryThreshold float64
+	// disabled holds the sub-encodings of the run length encoding version 2
+	// that are not used for the integers of the column.
+	disabled []RLEEncodingType
+	// unsortedDictionary is whether the dictionary of a string column is
+	// written in the order in which values first occur rather than sorted.
+	unsortedDictionary bool
+}
+
+// disableEncodings prevents iw from using the provided encodings, which only
+// applies to the run length encoding version 2.
+func disableEncodings(iw IntegerWriter, encodings []RLEEncodingType) {
+	if rle, ok := iw.(*RunLengthIntegerWriterV2); ok {
+		rle.disableEncodings(encodings...)
+	}
+}
+
+// encodingConfigurer is implemented by TreeWriters whose choice of encoding is
+// configured by the Writer, following the EncodingStrategy and the options that
+// override it.
+type encodingConfigurer interface {
+	configureEncoding(opts encodingOptions)
+}
+
 // IntegerTreeWriter is a TreeWriter implementation that writes an integer type column.
 type IntegerTreeWriter struct {
 	BaseTreeWriter
@@ -203,12 +241,11 @@ type IntegerTreeWriter struct {
 }
 
 // NewIntegerTreeWriter returns a new IntegerTreeWriter.
-func NewIntegerTreeWriter(category Category, codec CompressionCodec) (*IntegerTreeWriter, error) {
+func NewIntegerTreeWriter(category Category, codec CompressionCodec, version Version) (*IntegerTreeWriter, error) {
 	base := NewBaseTreeWriter(category, codec)
 	data := base.AddStream(proto.Stream_DATA.Enum())
 	base.AddPositionRecorder(data)
-	// TODO: Inherit column encoding kind from orc.Writer ORC file version.
-	columnEncoding := proto.ColumnEncoding_DIRECT_V2
+	columnEncoding := version.directEncoding()
 	iwriter, err := createIntegerWriter(columnEncoding, data.buffer, true)
 	if err != nil {
 		return nil, err
@@ -223,6 +260,10 @@ func NewIntegerTreeWriter(category Category, codec CompressionCodec) (*IntegerTr
 	}, nil
 }
 
+func (w *IntegerTreeWriter) configureEncoding(opts encodingOptions) {
+	disableEncodings(w.IntegerWriter, opts.disabled)
+}
+
 // WriteInt writes an integer value returning an error if one occurs.
 func (w *IntegerTreeWriter) WriteInt(value int64) error {
 	return w.IntegerWriter.WriteInt(value)
@@ -264,6 +305,29 @@ func (w *IntegerTreeWriter) Write(value interface{}) error {
 	}
 }
 
+// writeInt64s writes values, or a null where nulls is true, without boxing them.
+func (w *IntegerTreeWriter) writeInt64s(values []int64, nulls []bool) error {
+	statistics, _ := w.statistics.(*IntegerStatistics)
+	current, _ := w.currentStatistics.(*IntegerStatistics)
+	for i, v := range values {
+		if nulls != nil && nulls[i] {
+			if err := w.BaseTreeWriter.Write(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		statistics.addInt(v)
+		current.addInt(v)
+		if err := w.writePresent(); err != nil {
+			return err
+		}
+		if err := w.WriteInt(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close closes the underlying writers returning an error if one occurs.
 func (w *IntegerTreeWriter) Close() error {
 	if err := w.BaseTreeWriter.Close(); err != nil {
@@ -291,6 +355,43 @@ func (w *IntegerTreeWriter) Encoding() *proto.ColumnEncoding {
 	return w.encoding
 }
 
+// DateTreeWriter is a TreeWriter implementation that writes a date column as the
+// number of days since the epoch.
+type DateTreeWriter struct {
+	*IntegerTreeWriter
+}
+
+// NewDateTreeWriter returns a new DateTreeWriter.
+func NewDateTreeWriter(category Category, codec CompressionCodec, version Version) (*DateTreeWriter, error) {
+	iwriter, err := NewIntegerTreeWriter(category, codec, version)
+	if err != nil {
+		return nil, err
+	}
+	return &DateTreeWriter{iwriter}, nil
+}
+
+// Write writes a value returning an error if one occurs. It accepts a Date, a
+// time.Time, of which only the year, month and day in its location are written,
+// or a nil value for writing nulls.
+func (d *DateTreeWriter) Write(value interface{}) error {
+	switch t := value.(type) {
+	case nil:
+		return d.IntegerTreeWriter.Write(nil)
+	case Date:
+		return d.IntegerTreeWriter.Write(daysSinceEpoch(t.Time))
+	case time.Time:
+		return d.IntegerTreeWriter.Write(daysSinceEpoch(t))
+	default:
+		return fmt.Errorf("expected Date or time.Time, received: %T", value)
+	}
+}
+
+// daysSinceEpoch returns the number of days from the epoch to the date of t.
+func daysSinceEpoch(t time.Time) int64 {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
 // StructTreeWriter is a TreeWriter implementation that can write a struct column type.
 type StructTreeWriter struct {
 	BaseTreeWriter
@@ -313,6 +414,10 @@ func (s *StructTreeWriter) Write(value interface{}) error {
 	if err := s.BaseTreeWriter.Write(value); err != nil {
 		return err
 	}
+	// A null struct contributes no values to its children.
+	if value == nil {
+		return nil
+	}
 	values, ok := value.([]interface{})
 	if !ok {
 		return fmt.Errorf("wrong type for struct tree reader, expected: %T, got: %T", []interface{}{}, value)
@@ -367,6 +472,7 @@ func (s *StructTreeWriter) Encoding() *proto.ColumnEncoding {
 }
 
 func (s *StructTreeWriter) RecordPositions() {
+	s.BaseTreeWriter.RecordPositions()
 	for _, child := range s.children {
 		child.RecordPositions()
 	}
@@ -394,7 +500,7 @@ func (b *BooleanTreeWriter) Write(value interface{}) error {
 		return b.BaseTreeWriter.Write(value)
 	}
 	if bv, ok := value.(bool); ok {
-		if err := b.BaseTreeWriter.Write(true); err != nil {
+		if err := b.BaseTreeWriter.Write(bv); err != nil {
 			return err
 		}
 		return b.BooleanWriter.WriteBool(bv)
@@ -402,6 +508,29 @@ func (b *BooleanTreeWriter) Write(value interface{}) error {
 	return fmt.Errorf("expected bool or nil value, received %T", value)
 }
 
+// writeBools writes values, or a null where nulls is true, without boxing them.
+func (b *BooleanTreeWriter) writeBools(values []bool, nulls []bool) error {
+	statistics, _ := b.statistics.(*BucketStatistics)
+	current, _ := b.currentStatistics.(*BucketStatistics)
+	for i, v := range values {
+		if nulls != nil && nulls[i] {
+			if err := b.BaseTreeWriter.Write(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		statistics.addBool(v)
+		current.addBool(v)
+		if err := b.writePresent(); err != nil {
+			return err
+		}
+		if err := b.BooleanWriter.WriteBool(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (b *BooleanTreeWriter) Close() error {
 	if err := b.BaseTreeWriter.Close(); err != nil {
 		return err
@@ -428,6 +557,76 @@ func (b *BooleanTreeWriter) Encoding() *proto.ColumnEncoding {
 	}
 }
 
+// ByteTreeWriter is a TreeWriter implementation that writes a tinyint column
+// using byte run length encoding.
+type ByteTreeWriter struct {
+	BaseTreeWriter
+	*RunLengthByteWriter
+	*BufferedWriter
+}
+
+// NewByteTreeWriter returns a new ByteTreeWriter or an error if one occurs.
+func NewByteTreeWriter(category Category, codec CompressionCodec) (*ByteTreeWriter, error) {
+	base := NewBaseTreeWriter(category, codec)
+	data := base.AddStream(proto.Stream_DATA.Enum())
+	base.AddPositionRecorder(data)
+	return &ByteTreeWriter{
+		BaseTreeWriter:      base,
+		RunLengthByteWriter: NewRunLengthByteWriter(data.buffer),
+		BufferedWriter:      data.buffer,
+	}, nil
+}
+
+// Write writes a value returning an error if one occurs. It accepts an int8,
+// a byte or a nil value for writing nulls to the stream.
+func (b *ByteTreeWriter) Write(value interface{}) error {
+	switch t := value.(type) {
+	case nil:
+		return b.BaseTreeWriter.Write(value)
+	case int8:
+		if err := b.BaseTreeWriter.Write(int64(t)); err != nil {
+			return err
+		}
+		return b.RunLengthByteWriter.WriteByte(byte(t))
+	case byte:
+		if err := b.BaseTreeWriter.Write(int64(int8(t))); err != nil {
+			return err
+		}
+		return b.RunLengthByteWriter.WriteByte(t)
+	default:
+		return fmt.Errorf("cannot write %T to tinyint column type", t)
+	}
+}
+
+// Close closes the underlying writers returning an error if one occurs.
+func (b *ByteTreeWriter) Close() error {
+	if err := b.BaseTreeWriter.Close(); err != nil {
+		return err
+	}
+	if err := b.RunLengthByteWriter.Close(); err != nil {
+		return err
+	}
+	return b.BufferedWriter.Close()
+}
+
+// Flush flushes the underlying writers returning an error if one occurs.
+func (b *ByteTreeWriter) Flush() error {
+	if err := b.BaseTreeWriter.Flush(); err != nil {
+		return err
+	}
+	if err := b.RunLengthByteWriter.Flush(); err != nil {
+		return err
+	}
+	return b.BufferedWriter.Flush()
+}
+
+// Encoding returns the column encoding used for the ByteTreeWriter.
+func (b *ByteTreeWriter) Encoding() *proto.ColumnEncoding {
+	return &proto.ColumnEncoding{
+		Kind: proto.ColumnEncoding_DIRECT.Enum(),
+	}
+}
+
 // FloatTreeWriter is a TreeWriter that writes to a Float or Double column type.
 type FloatTreeWriter struct {
 	BaseTreeWriter
@@ -452,36 +651,81 @@ func (f *FloatTreeWriter) Write(value interface{}) error {
 	if err := f.BaseTreeWriter.Write(value); err != nil {
 		return err
 	}
+	if value == nil {
+		return nil
+	}
 	if f.bytesPerValue == 8 {
 		return f.WriteDouble(value)
 	}
 	return f.WriteFloat(value)
 }
 
-func (f *FloatTreeWriter) WriteDouble(value interface{}) error {
-	if val, ok := value.(float64); ok {
-		byt := make([]byte, f.bytesPerValue)
-		binary.LittleEndian.PutUint64(byt, math.Float64bits(val))
-		_, err := f.BufferedWriter.Write(byt)
-		if err != nil {
+// writeFloat64s writes values, or a null where nulls is true, without boxing them.
+// The values of a float column are converted to float32.
+func (f *FloatTreeWriter) writeFloat64s(values []float64, nulls []bool) error {
+	statistics, _ := f.statistics.(*DoubleStatistics)
+	current, _ := f.currentStatistics.(*DoubleStatistics)
+	var byt [8]byte
+	for i, v := range values {
+		if nulls != nil && nulls[i] {
+			if err := f.BaseTreeWriter.Write(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.bytesPerValue == 8 {
+			binary.LittleEndian.PutUint64(byt[:], math.Float64bits(v))
+		} else {
+			f := narrowFloat(v)
+			v = widenFloat(f)
+			binary.LittleEndian.PutUint32(byt[:], math.Float32bits(f))
+		}
+		statistics.addFloat(v)
+		current.addFloat(v)
+		if err := f.writePresent(); err != nil {
 			return err
 		}
-		return nil
+		if _, err := f.BufferedWriter.Write(byt[:f.bytesPerValue]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteDouble writes the raw IEEE 754 bits of a float64 or Double value to the
+// data stream in little endian byte order.
+func (f *FloatTreeWriter) WriteDouble(value interface{}) error {
+	var val float64
+	switch t := value.(type) {
+	case float64:
+		val = t
+	case Double:
+		val = float64(t)
+	default:
+		return fmt.Errorf("expected float64 value, received: %T", value)
 	}
-	return fmt.Errorf("expected float64 value, received: %T", value)
+	byt := make([]byte, f.bytesPerValue)
+	binary.LittleEndian.PutUint64(byt, math.Float64bits(val))
+	_, err := f.BufferedWriter.Write(byt)
+	return err
 }
 
+// WriteFloat writes the raw IEEE 754 bits of a float32 or Float value to the
+// data stream in little endian byte order.
 func (f *FloatTreeWriter) WriteFloat(value interface{}) error {
-	if val, ok := value.(float32); ok {
-		byt := make([]byte, f.bytesPerValue)
-		binary.LittleEndian.PutUint32(byt, math.Float32bits(val))
-		_, err := f.BufferedWriter.Write(byt)
-		if err != nil {
-			return err
-		}
-		return nil
+	var val float32
+	switch t := value.(type) {
+	case float32:
+		val = t
+	case Float:
+		val = float32(t)
+	default:
+		return fmt.Errorf("expected float32 value, received: %T", value)
 	}
-	return fmt.Errorf("expected float32 value, received: %T", value)
+	byt := make([]byte, f.bytesPerValue)
+	binary.LittleEndian.PutUint32(byt, math.Float32bits(val))
+	_, err := f.BufferedWriter.Write(byt)
+	return err
 }
 
 func (f *FloatTreeWriter) Close() error {
@@ -504,6 +748,243 @@ func (f *FloatTreeWriter) Encoding() *proto.ColumnEncoding {
 	}
 }
 
+// DecimalTreeWriter is a TreeWriter that writes to a Decimal column type. The
+// unscaled values are written to the data stream as zigzag encoded varints
+// and the scale of each value to the secondary stream.
+type DecimalTreeWriter struct {
+	BaseTreeWriter
+	data      *BufferedWriter
+	secondary *BufferedWriter
+	scales    IntegerWriter
+	precision int
+	scale     int
+	round     bool
+	encoding  *proto.ColumnEncoding
+}
+
+// NewDecimalTreeWriter returns a new DecimalTreeWriter or an error if one occurs. If
+// round is true then values with more fractional digits than scale are rounded,
+// otherwise an error is returned when writing them.
+func NewDecimalTreeWriter(category Category, codec CompressionCodec, version Version, precision, scale int, round bool) (*DecimalTreeWriter, error) {
+	base := NewBaseTreeWriter(category, codec)
+	data := base.AddStream(proto.Stream_DATA.Enum())
+	base.AddPositionRecorder(data)
+	secondary := base.AddStream(proto.Stream_SECONDARY.Enum())
+	base.AddPositionRecorder(secondary)
+	columnEncoding := version.directEncoding()
+	iwriter, err := createIntegerWriter(columnEncoding, secondary.buffer, true)
+	if err != nil {
+		return nil, err
+	}
+	return &DecimalTreeWriter{
+		BaseTreeWriter: base,
+		data:           data.buffer,
+		secondary:      secondary.buffer,
+		scales:         iwriter,
+		precision:      precision,
+		scale:          scale,
+		round:          round,
+		encoding: &proto.ColumnEncoding{
+			Kind: columnEncoding.Enum(),
+		},
+	}, nil
+}
+
+func (d *DecimalTreeWriter) configureEncoding(opts encodingOptions) {
+	disableEncodings(d.scales, opts.disabled)
+}
+
+// Write writes a value returning an error if one occurs. It accepts a Decimal,
+// *big.Rat, a string such as "-12345.678" or a nil value for writing nulls.
+func (d *DecimalTreeWriter) Write(value interface{}) error {
+	if value == nil {
+		return d.BaseTreeWriter.Write(value)
+	}
+	dec, err := toDecimal(value, d.precision, d.scale, d.round)
+	if err != nil {
+		return err
+	}
+	if err := d.BaseTreeWriter.Write(dec); err != nil {
+		return err
+	}
+	if err := encodeBase128Varint(d.data, dec.Abs); err != nil {
+		return err
+	}
+	return d.scales.WriteInt(dec.Exp)
+}
+
+// Close closes the underlying writers returning an error if one occurs.
+func (d *DecimalTreeWriter) Close() error {
+	if err := d.BaseTreeWriter.Close(); err != nil {
+		return err
+	}
+	if err := d.scales.Close(); err != nil {
+		return err
+	}
+	if err := d.secondary.Close(); err != nil {
+		return err
+	}
+	return d.data.Close()
+}
+
+// Flush flushes the underlying writers returning an error if one occurs.
+func (d *DecimalTreeWriter) Flush() error {
+	if err := d.BaseTreeWriter.Flush(); err != nil {
+		return err
+	}
+	if err := d.scales.Flush(); err != nil {
+		return err
+	}
+	if err := d.secondary.Flush(); err != nil {
+		return err
+	}
+	return d.data.Flush()
+}
+
+// Encoding returns the column encoding used for the DecimalTreeWriter.
+func (d *DecimalTreeWriter) Encoding() *proto.ColumnEncoding {
+	return d.encoding
+}
+
+// TimestampTreeWriter is a TreeWriter that writes to a Timestamp column type. The
+// seconds relative to 1 January 2015 in the writer timezone are written to the
+// data stream and the nanoseconds to the secondary stream.
+type TimestampTreeWriter struct {
+	BaseTreeWriter
+	data      *BufferedWriter
+	secondary *BufferedWriter
+	seconds   IntegerWriter
+	nanos     IntegerWriter
+	base      int64
+	encoding  *proto.ColumnEncoding
+}
+
+// NewTimestampTreeWriter returns a new TimestampTreeWriter or an error if one occurs.
+// The location is the timezone of the writer, which must match the timezone recorded
+// in the stripe footer.
+func NewTimestampTreeWriter(category Category, codec CompressionCodec, version Version, location *time.Location) (*TimestampTreeWriter, error) {
+	base := NewBaseTreeWriter(category, codec)
+	data := base.AddStream(proto.Stream_DATA.Enum())
+	base.AddPositionRecorder(data)
+	secondary := base.AddStream(proto.Stream_SECONDARY.Enum())
+	base.AddPositionRecorder(secondary)
+	columnEncoding := version.directEncoding()
+	seconds, err := createIntegerWriter(columnEncoding, data.buffer, true)
+	if err != nil {
+		return nil, err
+	}
+	nanos, err := createIntegerWriter(columnEncoding, secondary.buffer, false)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampTreeWriter{
+		BaseTreeWriter: base,
+		data:           data.buffer,
+		secondary:      secondary.buffer,
+		seconds:        seconds,
+		nanos:          nanos,
+		base:           timestampBase(location),
+		encoding: &proto.ColumnEncoding{
+			Kind: columnEncoding.Enum(),
+		},
+	}, nil
+}
+
+func (t *TimestampTreeWriter) configureEncoding(opts encodingOptions) {
+	disableEncodings(t.seconds, opts.disabled)
+	disableEncodings(t.nanos, opts.disabled)
+}
+
+// Write writes a value returning an error if one occurs. It accepts a time.Time
+// or a nil value for writing nulls.
+func (t *TimestampTreeWriter) Write(value interface{}) error {
+	if value == nil {
+		return t.BaseTreeWriter.Write(value)
+	}
+	ts, ok := value.(time.Time)
+	if !ok {
+		return fmt.Errorf("expected time.Time, received: %T", value)
+	}
+	if err := t.BaseTreeWriter.Write(ts); err != nil {
+		return err
+	}
+	return t.WriteTimestamp(ts)
+}
+
+// WriteTimestamp writes a timestamp value returning an error if one occurs.
+func (t *TimestampTreeWriter) WriteTimestamp(ts time.Time) error {
+	seconds := ts.Unix()
+	nanos := int64(ts.Nanosecond())
+	// Match the Java writer, which truncates the milliseconds since the Unix
+	// epoch towards zero, so timestamps before the epoch with a millisecond
+	// component are stored one second greater than their floor.
+	if seconds < 0 && nanos > 999999 {
+		seconds++
+	}
+	if err := t.seconds.WriteInt(seconds - t.base); err != nil {
+		return err
+	}
+	return t.nanos.WriteInt(encodeNanos(nanos))
+}
+
+// encodeNanos encodes a nanosecond value for the secondary stream of a timestamp
+// column, removing trailing decimal zeros when there are at least two of them and
+// recording the number removed minus one in the low 3 bits.
+func encodeNanos(nanos int64) int64 {
+	if nanos == 0 {
+		return 0
+	}
+	if nanos%100 != 0 {
+		return nanos << 3
+	}
+	nanos /= 100
+	zeros := int64(1)
+	for nanos%10 == 0 && zeros < 7 {
+		nanos /= 10
+		zeros++
+	}
+	return nanos<<3 | zeros
+}
+
+// Close closes the underlying writers returning an error if one occurs.
+func (t *TimestampTreeWriter) Close() error {
+	if err := t.BaseTreeWriter.Close(); err != nil {
+		return err
+	}
+	if err := t.seconds.Close(); err != nil {
+		return err
+	}
+	if err := t.nanos.Close(); err != nil {
+		return err
+	}
+	if err := t.data.Close(); err != nil {
+		return err
+	}
+	return t.secondary.Close()
+}
+
+// Flush flushes the underlying writers returning an error if one occurs.
+func (t *TimestampTreeWriter) Flush() error {
+	if err := t.BaseTreeWriter.Flush(); err != nil {
+		return err
+	}
+	if err := t.seconds.Flush(); err != nil {
+		return err
+	}
+	if err := t.nanos.Flush(); err != nil {
+		return err
+	}
+	if err := t.data.Flush(); err != nil {
+		return err
+	}
+	return t.secondary.Flush()
+}
+
+// Encoding returns the column encoding used for the TimestampTreeWriter.
+func (t *TimestampTreeWriter) Encoding() *proto.ColumnEncoding {
+	return t.encoding
+}
+
 const (
 	// InitialDictionarySize is the initial size used when creating the dictionary.
 	InitialDictionarySize = 4096
@@ -511,6 +992,10 @@ const (
 	DictionaryEncodingThreshold = 0.49
 )
 
+// stringHeaderSize is the number of bytes used by each string header held in a
+// slice of strings.
+const stringHeaderSize = 16
+
 // StringTreeWriter is a TreeWriter implementation that writes to a string type column. It dynamically selects
 // the most appropriate encoding format between direct and dictionary encoding based on the cardinality of the
 // values up to the first call to Flush.
@@ -523,14 +1008,17 @@ type StringTreeWriter struct {
 	dictionaryEncodedData IntegerWriter
 	dictionary            *DictionaryV2
 	bufferedValues        []string
+	bufferedBytes         int64
 	numValues             int
 	modeSelected          bool
 	isDictionaryEncoded   bool
 	dictionarySize        uint32
+	encodingOptions       encodingOptions
+	version               Version
 }
 
 // NewStringTreeWriter returns a new StringTreeWriter or an error if one occurs.
-func NewStringTreeWriter(category Category, codec CompressionCodec) (*StringTreeWriter, error) {
+func NewStringTreeWriter(category Category, codec CompressionCodec, version Version) (*StringTreeWriter, error) {
 	base := NewBaseTreeWriter(category, codec)
 	data := base.AddStream(proto.Stream_DATA.Enum())
 	base.AddPositionRecorder(data)
@@ -542,21 +1030,36 @@ func NewStringTreeWriter(category Category, codec CompressionCodec) (*StringTree
 		lengths:        lengths.buffer,
 		bufferedValues: make([]string, 0),
 		dictionary:     NewDictionaryV2(),
+		version:        version,
+		encodingOptions: encodingOptions{
+			dictionaryThreshold: DictionaryEncodingThreshold,
+		},
 	}
 	return s, nil
 }
 
+func (s *StringTreeWriter) configureEncoding(opts encodingOptions) {
+	s.encodingOptions = opts
+}
+
 // WriteString writes a string value to the StringTreeWriter returning an error if one occurs.
 func (s *StringTreeWriter) WriteString(value string) error {
 	s.numValues++
 	s.bufferedValues = append(s.bufferedValues, value)
-	s.dictionary.add(value)
+	s.bufferedBytes += int64(len(value))
+	// The dictionary is only built when it may be used.
+	if s.encodingOptions.dictionaryThreshold > 0 {
+		s.dictionary.add(value)
+	}
 	return nil
 }
 
 // Write writes the provided value to the underlying writers. It returns an
-// error if the value is not a string type or if an error occurs during writing.
+// error if the value is not a string or nil or if an error occurs during writing.
 func (s *StringTreeWriter) Write(value interface{}) error {
+	if value == nil {
+		return s.BaseTreeWriter.Write(value)
+	}
 	if str, ok := value.(string); ok {
 		if err := s.BaseTreeWriter.Write(value); err != nil {
 			return err
@@ -566,10 +1069,119 @@ func (s *StringTreeWriter) Write(value interface{}) error {
 	return fmt.Errorf("expected string value, received: %T", value)
 }
 
+// writeStrings writes values, or a null where nulls is true, without boxing them.
+func (s *StringTreeWriter) writeStrings(values []string, nulls []bool) error {
+	statistics, _ := s.statistics.(*StringStatistics)
+	current, _ := s.currentStatistics.(*StringStatistics)
+	for i, v := range values {
+		if nulls != nil && nulls[i] {
+			if err := s.BaseTreeWriter.Write(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		statistics.addString(v)
+		current.addString(v)
+		if err := s.writePresent(); err != nil {
+			return err
+		}
+		if err := s.WriteString(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CharTreeWriter is a TreeWriter implementation that writes a char or varchar
+// column, enforcing the maximum length of the column in Unicode code points. Char
+// values shorter than the maximum length are padded with spaces.
+type CharTreeWriter struct {
+	*StringTreeWriter
+	maxLength int
+	truncate  bool
+}
+
+// NewCharTreeWriter returns a new CharTreeWriter for a column of the provided
+// maximum length. Longer values are truncated if truncate is true, otherwise they
+// are rejected with an error.
+func NewCharTreeWriter(category Category, codec CompressionCodec, version Version, maxLength int, truncate bool) (*CharTreeWriter, error) {
+	if maxLength <= 0 {
+		return nil, fmt.Errorf("invalid maximum length %d for %s column", maxLength, category)
+	}
+	s, err := NewStringTreeWriter(category, codec, version)
+	if err != nil {
+		return nil, err
+	}
+	return &CharTreeWriter{
+		StringTreeWriter: s,
+		maxLength:        maxLength,
+		truncate:         truncate,
+	}, nil
+}
+
+// Write writes the provided value, padded or truncated to the maximum length of the
+// column, to the underlying StringTreeWriter.
+func (c *CharTreeWriter) Write(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return c.StringTreeWriter.Write(value)
+	}
+	n := utf8.RuneCountInString(str)
+	if n > c.maxLength {
+		if !c.truncate {
+			return fmt.Errorf("value of length %d exceeds the maximum length %d of %s column", n, c.maxLength, c.category)
+		}
+		// Truncate at the byte offset of the first code point beyond the
+		// maximum length.
+		var i int
+		for offset := range str {
+			if i == c.maxLength {
+				str = str[:offset]
+				break
+			}
+			i++
+		}
+		n = c.maxLength
+	}
+	if c.category == CategoryChar && n < c.maxLength {
+		str += strings.Repeat(" ", c.maxLength-n)
+	}
+	return c.StringTreeWriter.Write(str)
+}
+
+// writeStrings writes values, or a null where nulls is true, padding or truncating
+// each of them as Write does.
+func (c *CharTreeWriter) writeStrings(values []string, nulls []bool) error {
+	for i, v := range values {
+		var err error
+		if nulls != nil && nulls[i] {
+			err = c.Write(nil)
+		} else {
+			err = c.Write(v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *StringTreeWriter) Flush() error {
 	return nil
 }
 
+// bufferedSize returns the number of bytes of the string values buffered until
+// the encoding of the column is determined when the writer is closed.
+func (s *StringTreeWriter) bufferedSize() int64 {
+	return s.bufferedBytes
+}
+
+// bufferedMemoryUsage returns the approximate number of bytes held in memory by
+// the buffered string values and the dictionary built from them.
+func (s *StringTreeWriter) bufferedMemoryUsage() int64 {
+	return s.bufferedBytes + int64(cap(s.bufferedValues))*stringHeaderSize + s.dictionary.memoryUsage()
+}
+
 // Close closes the underlying writes returning an error if one occurs.
 func (s *StringTreeWriter) Close() error {
 	if err := s.flushBufferedValues(); err != nil {
@@ -602,16 +1214,18 @@ func (s *StringTreeWriter) flushDictionaryValues() error {
 	s.AddPositionRecorder(dictionaryData)
 	s.dictionaryData = dictionaryData.buffer
 	// Create an IntegerWriter for the dictionary encoded column and write the buffered values.
-	s.dictionaryEncodedData, err = createIntegerWriter(proto.ColumnEncoding_DICTIONARY_V2, s.data, false)
+	s.dictionaryEncodedData, err = createIntegerWriter(s.version.dictionaryEncoding(), s.data, false)
 	if err != nil {
 		return err
 	}
-	s.lengthsIntWriter, err = createIntegerWriter(proto.ColumnEncoding_DICTIONARY_V2, s.lengths, false)
+	s.lengthsIntWriter, err = createIntegerWriter(s.version.dictionaryEncoding(), s.lengths, false)
 	if err != nil {
 		return err
 	}
+	disableEncodings(s.dictionaryEncodedData, s.encodingOptions.disabled)
+	disableEncodings(s.lengthsIntWriter, s.encodingOptions.disabled)
 	// Prepare the dictionary.
-	s.dictionary.prepare()
+	s.dictionary.prepare(!s.encodingOptions.unsortedDictionary)
 	err = s.dictionary.forEach(func(value string) error {
 		_, err := s.dictionaryData.Write([]byte(value))
 		if err != nil {
@@ -642,10 +1256,11 @@ func (s *StringTreeWriter) flushDictionaryValues() error {
 
 func (s *StringTreeWriter) flushDirectValues() error {
 	var err error
-	s.lengthsIntWriter, err = createIntegerWriter(proto.ColumnEncoding_DIRECT_V2, s.lengths, false)
+	s.lengthsIntWriter, err = createIntegerWriter(s.version.directEncoding(), s.lengths, false)
 	if err != nil {
 		return err
 	}
+	disableEncodings(s.lengthsIntWriter, s.encodingOptions.disabled)
 	for _, value := range s.bufferedValues {
 		_, err := s.data.Write([]byte(value))
 		if err != nil {
@@ -673,36 +1288,45 @@ func (s *StringTreeWriter) useDictionaryEncoding() bool {
 	// TODO: find better way to determine whether dictionary encoding should be
 	// used. Currently this method is creating a new dictionary and using
 	// it to check the cardinality against the threshold value.
-	s.isDictionaryEncoded = float64(s.dictionary.size())/float64(s.numValues) <= DictionaryEncodingThreshold
+	if s.encodingOptions.dictionaryThreshold <= 0 {
+		s.isDictionaryEncoded = false
+		return false
+	}
+	s.isDictionaryEncoded = float64(s.dictionary.size())/float64(s.numValues) <= s.encodingOptions.dictionaryThreshold
 	return s.isDictionaryEncoded
 }
 
-// Encoding returns the column encoding for the writer, either DICTIONARY_V2 or DIRECT_V2.
+// Encoding returns the column encoding for the writer, either dictionary or direct
+// encoding using the run length encoding version of the ORC file version.
 func (s *StringTreeWriter) Encoding() *proto.ColumnEncoding {
 	if s.isDictionaryEncoded {
 		return &proto.ColumnEncoding{
-			Kind:           proto.ColumnEncoding_DICTIONARY_V2.Enum(),
+			Kind:           s.version.dictionaryEncoding().Enum(),
 			DictionarySize: &s.dictionarySize,
 		}
 	}
 	return &proto.ColumnEncoding{
-		Kind: proto.ColumnEncoding_DIRECT_V2.Enum(),
+		Kind: s.version.directEncoding().Enum(),
 	}
 }
 
+// ListTreeWriter is a TreeWriter that writes to a List column type. The number
+// of elements of each list is written to the length stream and the elements
+// themselves to the child TreeWriter.
 type ListTreeWriter struct {
 	BaseTreeWriter
-	lengths IntegerWriter
-	child   TreeWriter
-	data    *BufferedWriter
+	lengths  IntegerWriter
+	child    TreeWriter
+	data     *BufferedWriter
+	encoding *proto.ColumnEncoding
 }
 
-func NewListTreeWriter(category Category, codec CompressionCodec, child TreeWriter) (*ListTreeWriter, error) {
+// NewListTreeWriter returns a new ListTreeWriter or an error if one occurs.
+func NewListTreeWriter(category Category, codec CompressionCodec, version Version, child TreeWriter) (*ListTreeWriter, error) {
 	base := NewBaseTreeWriter(category, codec)
 	data := base.AddStream(proto.Stream_LENGTH.Enum())
 	base.AddPositionRecorder(data)
-	// TODO: Inherit column encoding kind from orc.Writer ORC file version.
-	columnEncoding := proto.ColumnEncoding_DIRECT_V2
+	columnEncoding := version.directEncoding()
 	iwriter, err := createIntegerWriter(columnEncoding, data.buffer, false)
 	if err != nil {
 		return nil, err
@@ -712,36 +1336,50 @@ func NewListTreeWriter(category Category, codec CompressionCodec, child TreeWrit
 		lengths:        iwriter,
 		child:          child,
 		data:           data.buffer,
+		encoding: &proto.ColumnEncoding{
+			Kind: columnEncoding.Enum(),
+		},
 	}
 	return l, nil
 }
 
+func (l *ListTreeWriter) configureEncoding(opts encodingOptions) {
+	disableEncodings(l.lengths, opts.disabled)
+}
+
+// Write writes a value returning an error if one occurs. It accepts any slice type
+// or a nil value for writing nulls. A null list contributes no values to the child
+// column whereas an empty list is written with a length of zero.
 func (l *ListTreeWriter) Write(value interface{}) error {
+	if value == nil {
+		return l.BaseTreeWriter.Write(value)
+	}
+	s := reflect.ValueOf(value)
+	if s.Kind() != reflect.Slice {
+		return fmt.Errorf("expected slice, received: %T", value)
+	}
 	if err := l.BaseTreeWriter.Write(value); err != nil {
 		return err
 	}
-	if value == nil {
-		return nil
+	if err := l.lengths.WriteInt(int64(s.Len())); err != nil {
+		return err
 	}
-	switch reflect.TypeOf(value).Kind() {
-	case reflect.Slice:
-		s := reflect.ValueOf(value)
-		err := l.lengths.WriteInt(int64(s.Len()))
+	for i := 0; i < s.Len(); i++ {
+		err := l.child.Write(s.Index(i).Interface())
 		if err != nil {
 			return err
 		}
-		for i := 0; i < s.Len(); i++ {
-			err := l.child.Write(s.Index(i).Interface())
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	default:
-		return fmt.Errorf("expected slice, received: %T", value)
 	}
+	return nil
 }
 
+// RecordPositions records the positions of the ListTreeWriter and its child.
+func (l *ListTreeWriter) RecordPositions() {
+	l.BaseTreeWriter.RecordPositions()
+	l.child.RecordPositions()
+}
+
+// Flush flushes the ListTreeWriter and its child returning an error if one occurs.
 func (l *ListTreeWriter) Flush() error {
 	if err := l.lengths.Flush(); err != nil {
 		return err
@@ -755,6 +1393,7 @@ func (l *ListTreeWriter) Flush() error {
 	return l.BaseTreeWriter.Flush()
 }
 
+// Close closes the ListTreeWriter and its child returning an error if one occurs.
 func (l *ListTreeWriter) Close() error {
 	if err := l.lengths.Close(); err != nil {
 		return err
@@ -768,70 +1407,103 @@ func (l *ListTreeWriter) Close() error {
 	return l.BaseTreeWriter.Close()
 }
 
+// Encoding returns the column encoding used for the ListTreeWriter.
 func (l *ListTreeWriter) Encoding() *proto.ColumnEncoding {
-	return &proto.ColumnEncoding{
-		Kind: proto.ColumnEncoding_DIRECT_V2.Enum(),
-	}
+	return l.encoding
 }
 
+// MapTreeWriter is a TreeWriter that writes to a Map column type. The number of
+// entries of each map is written to the length stream and the keys and values
+// to two child TreeWriters.
 type MapTreeWriter struct {
 	BaseTreeWriter
-	lengths IntegerWriter
-	keys    TreeWriter
-	values  TreeWriter
-	data    *BufferedWriter
+	lengths  IntegerWriter
+	keys     TreeWriter
+	values   TreeWriter
+	data     *BufferedWriter
+	encoding *proto.ColumnEncoding
 }
 
-func NewMapTreeWriter(category Category, codec CompressionCodec, keyWriter, valueWriter TreeWriter) (*MapTreeWriter, error) {
+// NewMapTreeWriter returns a new MapTreeWriter or an error if one occurs.
+func NewMapTreeWriter(category Category, codec CompressionCodec, version Version, keyWriter, valueWriter TreeWriter) (*MapTreeWriter, error) {
 	base := NewBaseTreeWriter(category, codec)
 	data := base.AddStream(proto.Stream_LENGTH.Enum())
 	base.AddPositionRecorder(data)
-	// TODO: Inherit column encoding kind from orc.Writer ORC file version.
-	columnEncoding := proto.ColumnEncoding_DIRECT_V2
-	iwriter, err := createIntegerWriter(columnEncoding, data.buffer, true)
+	columnEncoding := version.directEncoding()
+	iwriter, err := createIntegerWriter(columnEncoding, data.buffer, false)
 	if err != nil {
 		return nil, err
 	}
-	l := &MapTreeWriter{
-		BaseTreeWriter: NewBaseTreeWriter(category, codec),
+	m := &MapTreeWriter{
+		BaseTreeWriter: base,
 		lengths:        iwriter,
 		keys:           keyWriter,
 		values:         valueWriter,
 		data:           data.buffer,
+		encoding: &proto.ColumnEncoding{
+			Kind: columnEncoding.Enum(),
+		},
 	}
-	return l, nil
+	return m, nil
+}
+
+func (m *MapTreeWriter) configureEncoding(opts encodingOptions) {
+	disableEncodings(m.lengths, opts.disabled)
 }
 
+// Write writes a value returning an error if one occurs. It accepts a []MapEntry,
+// whose entries are written in order, any map type or a nil value for writing nulls.
 func (m *MapTreeWriter) Write(value interface{}) error {
-	if err := m.BaseTreeWriter.Write(value); err != nil {
-		return err
-	}
 	if value == nil {
-		return nil
+		return m.BaseTreeWriter.Write(value)
 	}
-	switch reflect.TypeOf(value).Kind() {
-	case reflect.Map:
-		mm := reflect.ValueOf(value)
-		err := m.lengths.WriteInt(int64(mm.Len()))
-		if err != nil {
+	if entries, ok := value.([]MapEntry); ok {
+		if err := m.BaseTreeWriter.Write(value); err != nil {
 			return err
 		}
-		for _, k := range mm.MapKeys() {
-			err := m.keys.Write(k.Interface())
-			if err != nil {
-				return err
-			}
-			err = m.values.Write(mm.MapIndex(k))
-			if err != nil {
+		if err := m.lengths.WriteInt(int64(len(entries))); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := m.writeEntry(entry.Key, entry.Value); err != nil {
 				return err
 			}
 		}
 		return nil
-	default:
+	}
+	mm := reflect.ValueOf(value)
+	if mm.Kind() != reflect.Map {
 		return fmt.Errorf("received type: %T not compatible with map column type", value)
 	}
+	if err := m.BaseTreeWriter.Write(value); err != nil {
+		return err
+	}
+	if err := m.lengths.WriteInt(int64(mm.Len())); err != nil {
+		return err
+	}
+	for _, k := range mm.MapKeys() {
+		if err := m.writeEntry(k.Interface(), mm.MapIndex(k).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MapTreeWriter) writeEntry(key, value interface{}) error {
+	if err := m.keys.Write(key); err != nil {
+		return err
+	}
+	return m.values.Write(value)
+}
+
+// RecordPositions records the positions of the MapTreeWriter and its children.
+func (m *MapTreeWriter) RecordPositions() {
+	m.BaseTreeWriter.RecordPositions()
+	m.keys.RecordPositions()
+	m.values.RecordPositions()
 }
 
+// Flush flushes the MapTreeWriter and its children returning an error if one occurs.
 func (m *MapTreeWriter) Flush() error {
 	if err := m.lengths.Flush(); err != nil {
 		return err
@@ -848,6 +1520,7 @@ func (m *MapTreeWriter) Flush() error {
 	return m.BaseTreeWriter.Flush()
 }
 
+// Close closes the MapTreeWriter and its children returning an error if one occurs.
 func (m *MapTreeWriter) Close() error {
 	if err := m.lengths.Close(); err != nil {
 		return err
@@ -864,8 +1537,100 @@ func (m *MapTreeWriter) Close() error {
 	return m.BaseTreeWriter.Close()
 }
 
+// Encoding returns the column encoding used for the MapTreeWriter.
 func (m *MapTreeWriter) Encoding() *proto.ColumnEncoding {
+	return m.encoding
+}
+
+// UnionTreeWriter is a TreeWriter that writes to a Union type column. The tag
+// of each value is written to the data stream using byte run length encoding
+// and the value itself to the child TreeWriter of the selected variant.
+type UnionTreeWriter struct {
+	BaseTreeWriter
+	tags     *RunLengthByteWriter
+	children []TreeWriter
+	data     *BufferedWriter
+}
+
+// NewUnionTreeWriter returns a new UnionTreeWriter or an error if one occurs.
+func NewUnionTreeWriter(category Category, codec CompressionCodec, children []TreeWriter) (*UnionTreeWriter, error) {
+	base := NewBaseTreeWriter(category, codec)
+	data := base.AddStream(proto.Stream_DATA.Enum())
+	base.AddPositionRecorder(data)
+	return &UnionTreeWriter{
+		BaseTreeWriter: base,
+		tags:           NewRunLengthByteWriter(data.buffer),
+		children:       children,
+		data:           data.buffer,
+	}, nil
+}
+
+// Write writes a value returning an error if one occurs. It accepts a UnionValue,
+// whose Tag selects the child the Value is written to, or a nil value for writing
+// nulls. A null union contributes no values to any of its children.
+func (u *UnionTreeWriter) Write(value interface{}) error {
+	if value == nil {
+		return u.BaseTreeWriter.Write(value)
+	}
+	uv, ok := value.(UnionValue)
+	if !ok {
+		return fmt.Errorf("expected %T or nil value, received: %T", UnionValue{}, value)
+	}
+	if uv.Tag < 0 || uv.Tag >= len(u.children) {
+		return fmt.Errorf("union tag %d out of range, expected < %d", uv.Tag, len(u.children))
+	}
+	if err := u.BaseTreeWriter.Write(value); err != nil {
+		return err
+	}
+	if err := u.tags.WriteByte(byte(uv.Tag)); err != nil {
+		return err
+	}
+	return u.children[uv.Tag].Write(uv.Value)
+}
+
+// RecordPositions records the positions of the UnionTreeWriter and its children.
+func (u *UnionTreeWriter) RecordPositions() {
+	u.BaseTreeWriter.RecordPositions()
+	for _, child := range u.children {
+		child.RecordPositions()
+	}
+}
+
+// Flush flushes the UnionTreeWriter and its children returning an error if one occurs.
+func (u *UnionTreeWriter) Flush() error {
+	if err := u.tags.Flush(); err != nil {
+		return err
+	}
+	for _, child := range u.children {
+		if err := child.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := u.data.Flush(); err != nil {
+		return err
+	}
+	return u.BaseTreeWriter.Flush()
+}
+
+// Close closes the UnionTreeWriter and its children returning an error if one occurs.
+func (u *UnionTreeWriter) Close() error {
+	if err := u.tags.Close(); err != nil {
+		return err
+	}
+	for _, child := range u.children {
+		if err := child.Close(); err != nil {
+			return err
+		}
+	}
+	if err := u.data.Close(); err != nil {
+		return err
+	}
+	return u.BaseTreeWriter.Close()
+}
+
+// Encoding returns the column encoding used for the UnionTreeWriter.
+func (u *UnionTreeWriter) Encoding() *proto.ColumnEncoding {
 	return &proto.ColumnEncoding{
-		Kind: proto.ColumnEncoding_DIRECT_V2.Enum(),
+		Kind: proto.ColumnEncoding_DIRECT.Enum(),
 	}
 }
@@ -2,14 +2,18 @@ package orc
 
 import (
 	"fmt"
+	"time"
 )
 
-func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers writerMap) (TreeWriter, error) {
+func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers writerMap, w *Writer) (TreeWriter, error) {
 
 	id := schema.getID()
 	var treeWriter TreeWriter
 	var err error
 	category := schema.getCategory()
+	if !w.version.supports(category) {
+		return nil, fmt.Errorf("type %s is not supported by file version %s", category, w.version.name)
+	}
 	switch category {
 	case CategoryFloat:
 		treeWriter, err = NewFloatTreeWriter(category, codec, 4)
@@ -26,11 +30,36 @@ func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers w
 		if err != nil {
 			return nil, err
 		}
+	case CategoryByte:
+		treeWriter, err = NewByteTreeWriter(category, codec)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryDecimal:
+		treeWriter, err = NewDecimalTreeWriter(category, codec, w.version, schema.precision, schema.scale, w.decimalRounding)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryTimestamp:
+		treeWriter, err = NewTimestampTreeWriter(category, codec, w.version, w.timezone)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryTimestampInstant:
+		treeWriter, err = NewTimestampTreeWriter(category, codec, w.version, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryDate:
+		treeWriter, err = NewDateTreeWriter(category, codec, w.version)
+		if err != nil {
+			return nil, err
+		}
 	case CategoryStruct:
 		// Create a TreeWriter for each child of the struct column.
 		var children []TreeWriter
 		for _, child := range schema.children {
-			childWriter, err := createTreeWriter(codec, child, writers)
+			childWriter, err := createTreeWriter(codec, child, writers, w)
 			if err != nil {
 				return nil, err
 			}
@@ -41,12 +70,17 @@ func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers w
 			return nil, err
 		}
 	case CategoryShort, CategoryInt, CategoryLong:
-		treeWriter, err = NewIntegerTreeWriter(category, codec)
+		treeWriter, err = NewIntegerTreeWriter(category, codec, w.version)
 		if err != nil {
 			return nil, err
 		}
-	case CategoryVarchar, CategoryString:
-		treeWriter, err = NewStringTreeWriter(category, codec)
+	case CategoryString:
+		treeWriter, err = NewStringTreeWriter(category, codec, w.version)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryVarchar, CategoryChar:
+		treeWriter, err = NewCharTreeWriter(category, codec, w.version, schema.maxLength, w.truncateStrings)
 		if err != nil {
 			return nil, err
 		}
@@ -54,11 +88,11 @@ func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers w
 		if len(schema.children) != 1 {
 			return nil, fmt.Errorf("unexpected number of children for list column, expected 1 got %v", len(schema.children))
 		}
-		child, err := createTreeWriter(codec, schema.children[0], writers)
+		child, err := createTreeWriter(codec, schema.children[0], writers, w)
 		if err != nil {
 			return nil, err
 		}
-		treeWriter, err = NewListTreeWriter(category, codec, child)
+		treeWriter, err = NewListTreeWriter(category, codec, w.version, child)
 		if err != nil {
 			return nil, err
 		}
@@ -66,21 +100,41 @@ func createTreeWriter(codec CompressionCodec, schema *TypeDescription, writers w
 		if len(schema.children) != 2 {
 			return nil, fmt.Errorf("unexpected number of children for map column, expected 2 got %v", len(schema.children))
 		}
-		keyWriter, err := createTreeWriter(codec, schema.children[0], writers)
+		keyWriter, err := createTreeWriter(codec, schema.children[0], writers, w)
 		if err != nil {
 			return nil, err
 		}
-		valueWriter, err := createTreeWriter(codec, schema.children[1], writers)
+		valueWriter, err := createTreeWriter(codec, schema.children[1], writers, w)
 		if err != nil {
 			return nil, err
 		}
-		treeWriter, err = NewMapTreeWriter(category, codec, keyWriter, valueWriter)
+		treeWriter, err = NewMapTreeWriter(category, codec, w.version, keyWriter, valueWriter)
+		if err != nil {
+			return nil, err
+		}
+	case CategoryUnion:
+		if len(schema.children) < 2 {
+			return nil, fmt.Errorf("unexpected number of children for union column, expected at least 2 got %v", len(schema.children))
+		}
+		// Create a TreeWriter for each variant of the union column.
+		var children []TreeWriter
+		for _, child := range schema.children {
+			childWriter, err := createTreeWriter(codec, child, writers, w)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, childWriter)
+		}
+		treeWriter, err = NewUnionTreeWriter(category, codec, children)
 		if err != nil {
 			return nil, err
 		}
 	default:
 		return nil, fmt.Errorf("unsupported type: %s", category)
 	}
+	if c, ok := treeWriter.(encodingConfigurer); ok {
+		c.configureEncoding(w.encodingOptions(id))
+	}
 	writers.add(id, treeWriter)
 	// Return the TreeWriter
 	return treeWriter, nil
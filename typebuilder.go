@@ -0,0 +1,187 @@
+package orc
+
+import "fmt"
+
+// TypeBuilder builds a TypeDescription programmatically, such as
+//
+//	StructType().Field("id", BigIntType()).Field("tags", ListType(StringType())).Build()
+//
+// the equivalent of ParseSchema("struct<id:bigint,tags:array<string>>"). The
+// constructors are named after the types with a Type suffix as Struct, Decimal and
+// others name the types of the values read. Nothing is validated until Build, so
+// a TypeBuilder may be used in several schemas and as several fields of one.
+type TypeBuilder struct {
+	category   Category
+	children   []*TypeBuilder
+	fieldNames []string
+	maxLength  int
+	precision  int
+	scale      int
+}
+
+func primitiveType(category Category) *TypeBuilder {
+	return &TypeBuilder{category: category}
+}
+
+// BooleanType returns a TypeBuilder of a boolean type.
+func BooleanType() *TypeBuilder { return primitiveType(CategoryBoolean) }
+
+// TinyIntType returns a TypeBuilder of a tinyint type.
+func TinyIntType() *TypeBuilder { return primitiveType(CategoryByte) }
+
+// SmallIntType returns a TypeBuilder of a smallint type.
+func SmallIntType() *TypeBuilder { return primitiveType(CategoryShort) }
+
+// IntType returns a TypeBuilder of an int type.
+func IntType() *TypeBuilder { return primitiveType(CategoryInt) }
+
+// BigIntType returns a TypeBuilder of a bigint type.
+func BigIntType() *TypeBuilder { return primitiveType(CategoryLong) }
+
+// FloatType returns a TypeBuilder of a float type.
+func FloatType() *TypeBuilder { return primitiveType(CategoryFloat) }
+
+// DoubleType returns a TypeBuilder of a double type.
+func DoubleType() *TypeBuilder { return primitiveType(CategoryDouble) }
+
+// StringType returns a TypeBuilder of a string type.
+func StringType() *TypeBuilder { return primitiveType(CategoryString) }
+
+// BinaryType returns a TypeBuilder of a binary type.
+func BinaryType() *TypeBuilder { return primitiveType(CategoryBinary) }
+
+// DateType returns a TypeBuilder of a date type.
+func DateType() *TypeBuilder { return primitiveType(CategoryDate) }
+
+// TimestampType returns a TypeBuilder of a timestamp type.
+func TimestampType() *TypeBuilder { return primitiveType(CategoryTimestamp) }
+
+// TimestampInstantType returns a TypeBuilder of a timestamp with local time zone
+// type.
+func TimestampInstantType() *TypeBuilder { return primitiveType(CategoryTimestampInstant) }
+
+// DecimalType returns a TypeBuilder of a decimal type of the provided precision,
+// from 1 to 38, and scale, from 0 to the precision.
+func DecimalType(precision, scale int) *TypeBuilder {
+	return &TypeBuilder{category: CategoryDecimal, precision: precision, scale: scale}
+}
+
+// CharType returns a TypeBuilder of a char type of the provided length, which must
+// be greater than 0.
+func CharType(length int) *TypeBuilder {
+	return &TypeBuilder{category: CategoryChar, maxLength: length}
+}
+
+// VarcharType returns a TypeBuilder of a varchar type of the provided maximum
+// length, which must be greater than 0.
+func VarcharType(length int) *TypeBuilder {
+	return &TypeBuilder{category: CategoryVarchar, maxLength: length}
+}
+
+// ListType returns a TypeBuilder of a list of elements of the provided type.
+func ListType(elem *TypeBuilder) *TypeBuilder {
+	return &TypeBuilder{category: CategoryList, children: []*TypeBuilder{elem}}
+}
+
+// MapType returns a TypeBuilder of a map of the provided key and value types.
+func MapType(key, value *TypeBuilder) *TypeBuilder {
+	return &TypeBuilder{category: CategoryMap, children: []*TypeBuilder{key, value}}
+}
+
+// UnionType returns a TypeBuilder of a union of the provided variants, of which
+// there must be at least one.
+func UnionType(variants ...*TypeBuilder) *TypeBuilder {
+	return &TypeBuilder{category: CategoryUnion, children: variants}
+}
+
+// StructType returns a TypeBuilder of a struct, to which fields are added by Field.
+func StructType() *TypeBuilder {
+	return &TypeBuilder{category: CategoryStruct}
+}
+
+// Field adds a field of the provided name and type to a struct, returning the
+// TypeBuilder of the struct. Field names must be unique within a struct.
+func (b *TypeBuilder) Field(name string, fieldType *TypeBuilder) *TypeBuilder {
+	b.fieldNames = append(b.fieldNames, name)
+	b.children = append(b.children, fieldType)
+	return b
+}
+
+// Build validates the type and returns a TypeDescription of it, with the column
+// IDs assigned in pre-order from 0 at the root, for use with SetSchema and
+// wherever else a schema returned by ParseSchema is accepted.
+func (b *TypeBuilder) Build() (*TypeDescription, error) {
+	td, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	td.assignIDs(0)
+	return td, nil
+}
+
+// build returns a new TypeDescription of the type.
+func (b *TypeBuilder) build() (*TypeDescription, error) {
+	if b.category != CategoryStruct && len(b.fieldNames) > 0 {
+		return nil, fmt.Errorf("Can only add fields to struct type and not %s", b.category)
+	}
+	td, err := NewTypeDescription(SetCategory(b.category))
+	if err != nil {
+		return nil, err
+	}
+	switch b.category {
+	case CategoryDecimal:
+		if err := SetDecimal(b.precision, b.scale)(td); err != nil {
+			return nil, err
+		}
+	case CategoryChar, CategoryVarchar:
+		if b.maxLength < 1 {
+			return nil, fmt.Errorf("length %d of %s must be greater than zero", b.maxLength, b.category)
+		}
+		if err := td.withMaxLength(b.maxLength); err != nil {
+			return nil, err
+		}
+	case CategoryList, CategoryMap, CategoryUnion:
+		if b.category == CategoryUnion && len(b.children) == 0 {
+			return nil, fmt.Errorf("uniontype must have at least one variant")
+		}
+		for i, child := range b.children {
+			if child == nil {
+				return nil, fmt.Errorf("child %d of %s is nil", i, b.category)
+			}
+			ct, err := child.build()
+			if err != nil {
+				return nil, fmt.Errorf("child %d of %s: %v", i, b.category, err)
+			}
+			if b.category == CategoryUnion {
+				err = td.addUnionChild(ct)
+			} else {
+				err = td.addChild(ct)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case CategoryStruct:
+		seen := make(map[string]bool, len(b.fieldNames))
+		for i, name := range b.fieldNames {
+			if name == "" {
+				return nil, fmt.Errorf("field %d of struct has no name", i)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate field %s of struct", name)
+			}
+			seen[name] = true
+			if b.children[i] == nil {
+				return nil, fmt.Errorf("field %s of struct has no type", name)
+			}
+			ft, err := b.children[i].build()
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", name, err)
+			}
+			if err := td.addField(name, ft); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return td, nil
+}
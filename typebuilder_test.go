@@ -0,0 +1,131 @@
+package orc
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTypeBuilder(t *testing.T) {
+
+	testCases := []struct {
+		builder  *TypeBuilder
+		expected string
+	}{
+		{
+			builder:  StructType().Field("id", BigIntType()).Field("tags", ListType(StringType())),
+			expected: "struct<id:bigint,tags:array<string>>",
+		},
+		{
+			builder: StructType().
+				Field("b", BooleanType()).
+				Field("t", TinyIntType()).
+				Field("s", SmallIntType()).
+				Field("i", IntType()).
+				Field("f", FloatType()).
+				Field("d", DoubleType()).
+				Field("bin", BinaryType()).
+				Field("day", DateType()).
+				Field("ts", TimestampType()).
+				Field("tsi", TimestampInstantType()),
+			expected: "struct<b:boolean,t:tinyint,s:smallint,i:int,f:float,d:double,bin:binary,day:date,ts:timestamp,tsi:timestamp with local time zone>",
+		},
+		{
+			builder: StructType().
+				Field("price", DecimalType(10, 2)).
+				Field("code", CharType(3)).
+				Field("name", VarcharType(20)).
+				Field("attrs", MapType(StringType(), UnionType(IntType(), StructType().Field("x", DoubleType())))).
+				Field("first name", StructType()),
+			expected: "struct<price:decimal(10,2),code:char(3),name:varchar(20),attrs:map<string,uniontype<int,struct<x:double>>>,`first name`:struct<>>",
+		},
+		{
+			builder:  ListType(MapType(IntType(), ListType(DecimalType(38, 0)))),
+			expected: "array<map<int,array<decimal(38,0)>>>",
+		},
+	}
+	for _, tc := range testCases {
+		td, err := tc.builder.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := ParseSchema(tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected.assignIDs(0)
+		if !reflect.DeepEqual(td, expected) {
+			t.Errorf("Test failed, expected %s got %s", expected.ToJSON(), td.ToJSON())
+		}
+		if !reflect.DeepEqual(td.Types(), expected.Types()) {
+			t.Errorf("Test failed, expected types %v got %v", expected.Types(), td.Types())
+		}
+	}
+
+	// A TypeBuilder may be used more than once, each use building a new type.
+	point := StructType().Field("x", IntType()).Field("y", IntType())
+	td, err := StructType().Field("from", point).Field("to", point).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from, to := td.children[0], td.children[1]; from == to || from.getID() != 1 || to.getID() != 4 || to.maxId != 6 {
+		t.Errorf("Test failed, expected distinct fields of IDs 1 and 4 got %d and %d", from.getID(), to.getID())
+	}
+
+	// The built schema is written and read as a parsed one.
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(td))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]interface{}{int64(1), int64(2)}, []interface{}{int64(3), int64(4)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema().String(); s != td.String() {
+		t.Errorf("Test failed, expected schema %s got %s", td, s)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]interface{}{{Struct{"x": int64(1), "y": int64(2)}, Struct{"x": int64(3), "y": int64(4)}}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected rows %v got %v", expected, rows)
+	}
+
+}
+
+func TestTypeBuilderErrors(t *testing.T) {
+
+	testCases := []struct {
+		builder  *TypeBuilder
+		expected string
+	}{
+		{DecimalType(0, 0), "precision 0 is out of range of 1 .. 38"},
+		{DecimalType(39, 0), "precision 39 is out of range of 1 .. 38"},
+		{DecimalType(5, 6), "scale is out of range at 6"},
+		{DecimalType(5, -1), "scale is out of range at -1"},
+		{CharType(0), "length 0 of char must be greater than zero"},
+		{StructType().Field("v", VarcharType(-1)), "field v: length -1 of varchar must be greater than zero"},
+		{StructType().Field("a", IntType()).Field("a", StringType()), "duplicate field a of struct"},
+		{StructType().Field("", IntType()), "field 0 of struct has no name"},
+		{StructType().Field("a", nil), "field a of struct has no type"},
+		{ListType(StructType().Field("a", IntType()).Field("a", IntType())), "child 0 of array: duplicate field a of struct"},
+		{MapType(StringType(), nil), "child 1 of map is nil"},
+		{UnionType(), "uniontype must have at least one variant"},
+		{IntType().Field("a", IntType()), "Can only add fields to struct type and not int"},
+	}
+	for _, tc := range testCases {
+		if _, err := tc.builder.Build(); err == nil || err.Error() != tc.expected {
+			t.Errorf("Test failed, expected error %q got %v", tc.expected, err)
+		}
+	}
+
+}
@@ -2,7 +2,9 @@ package orc
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -31,15 +33,19 @@ var (
 	CategoryString    = Category{"string", true, proto.Type_STRING.Enum()}
 	CategoryDate      = Category{"date", true, proto.Type_DATE.Enum()}
 	CategoryTimestamp = Category{"timestamp", true, proto.Type_TIMESTAMP.Enum()}
-	CategoryBinary    = Category{"binary", true, proto.Type_BINARY.Enum()}
-	CategoryDecimal   = Category{"decimal", true, proto.Type_DECIMAL.Enum()}
-	CategoryVarchar   = Category{"varchar", true, proto.Type_VARCHAR.Enum()}
-	CategoryChar      = Category{"char", true, proto.Type_CHAR.Enum()}
-	CategoryList      = Category{"array", false, proto.Type_LIST.Enum()}
-	CategoryMap       = Category{"map", false, proto.Type_MAP.Enum()}
-	CategoryStruct    = Category{"struct", false, proto.Type_STRUCT.Enum()}
-	CategoryUnion     = Category{"uniontype", false, proto.Type_UNION.Enum()}
-	Categories        = []Category{
+	// CategoryTimestampInstant is the category of timestamp with local time zone
+	// columns, whose values are instants stored in UTC regardless of the
+	// timezone of the writer.
+	CategoryTimestampInstant = Category{"timestamp with local time zone", true, proto.Type_TIMESTAMP_INSTANT.Enum()}
+	CategoryBinary           = Category{"binary", true, proto.Type_BINARY.Enum()}
+	CategoryDecimal          = Category{"decimal", true, proto.Type_DECIMAL.Enum()}
+	CategoryVarchar          = Category{"varchar", true, proto.Type_VARCHAR.Enum()}
+	CategoryChar             = Category{"char", true, proto.Type_CHAR.Enum()}
+	CategoryList             = Category{"array", false, proto.Type_LIST.Enum()}
+	CategoryMap              = Category{"map", false, proto.Type_MAP.Enum()}
+	CategoryStruct           = Category{"struct", false, proto.Type_STRUCT.Enum()}
+	CategoryUnion            = Category{"uniontype", false, proto.Type_UNION.Enum()}
+	Categories               = []Category{
 		CategoryBoolean,
 		CategoryByte,
 		CategoryShort,
@@ -50,6 +56,7 @@ var (
 		CategoryString,
 		CategoryDate,
 		CategoryTimestamp,
+		CategoryTimestampInstant,
 		CategoryBinary,
 		CategoryDecimal,
 		CategoryVarchar,
@@ -61,95 +68,198 @@ var (
 	}
 )
 
+// categoryUnknownName is the name of the Category of columns with a type kind
+// that is not supported, see WithRawUnknownColumns.
+const categoryUnknownName = "unknown"
+
+// unknownCategory returns a Category for an unsupported type kind.
+func unknownCategory(kind proto.Type_Kind) Category {
+	return Category{categoryUnknownName, true, &kind}
+}
+
+// SchemaError is the error returned by ParseSchema for a schema that cannot be
+// parsed.
+type SchemaError struct {
+	// Offset is the byte offset within the schema of the problem.
+	Offset int
+	Err    error
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// categoryAliases are the alternative names of categories accepted when parsing
+// a schema, as accepted by Hive.
+var categoryAliases = map[string]Category{
+	"integer": CategoryInt,
+	"dec":     CategoryDecimal,
+	"numeric": CategoryDecimal,
+}
+
+// stringPosition parses a schema from the byte offset position of value.
 type stringPosition struct {
 	value    string
 	position int
-	length   int
 }
 
 func NewStringPosition(value string) *stringPosition {
-	value = strings.ToLower(value)
-	value = strings.NewReplacer("\n", "", " ", "", "\t", "").Replace(value)
-	return &stringPosition{
-		value,
-		0,
-		utf8.RuneCountInString(value),
-	}
+	return &stringPosition{value: value}
 }
 
+// String returns the value with a caret marking the current position.
 func (s stringPosition) String() string {
-	var buf bytes.Buffer
-	buf.WriteString(`\'`)
-	buf.WriteString(string([]rune(s.value)[0:s.position]))
-	buf.WriteString(`^`)
-	buf.WriteString(string([]rune(s.value)[s.position]))
-	buf.WriteString(`\'`)
-	return buf.String()
+	return s.value[:s.position] + "^" + s.value[s.position:]
 }
 
-func (s *stringPosition) parseCategory() (Category, error) {
+// errorf returns a SchemaError at the byte offset.
+func (s *stringPosition) errorf(offset int, format string, args ...interface{}) error {
+	return &SchemaError{Offset: offset, Err: fmt.Errorf(format, args...)}
+}
+
+// describe returns a description of the input at the current position for errors.
+func (s *stringPosition) describe() string {
+	if s.position >= len(s.value) {
+		return "the end of the schema"
+	}
+	r, _ := utf8.DecodeRuneInString(s.value[s.position:])
+	return fmt.Sprintf("%q", r)
+}
+
+// skipSpace advances past any white space.
+func (s *stringPosition) skipSpace() {
+	for s.position < len(s.value) {
+		r, size := utf8.DecodeRuneInString(s.value[s.position:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		s.position += size
+	}
+}
+
+// parseWord returns the letters from the current position.
+func (s *stringPosition) parseWord() string {
 	start := s.position
-	for s.position < s.length {
-		ch := []rune(s.value)[s.position]
-		if !unicode.IsLetter(rune(ch)) {
+	for s.position < len(s.value) {
+		r, size := utf8.DecodeRuneInString(s.value[s.position:])
+		if !unicode.IsLetter(r) {
 			break
 		}
-		s.position++
+		s.position += size
 	}
+	return s.value[start:s.position]
+}
 
-	if s.position != start {
-		word := strings.ToLower(string([]rune(s.value)[start:s.position]))
-		for _, cat := range Categories {
-			if cat.name == word {
-				return cat, nil
+func (s *stringPosition) parseCategory() (Category, error) {
+	s.skipSpace()
+	start := s.position
+	word := strings.ToLower(s.parseWord())
+	if word == CategoryTimestamp.name {
+		// The words of "timestamp with local time zone" are separated by
+		// any white space.
+		end := s.position
+		words := strings.Fields(CategoryTimestampInstant.name)[1:]
+		for _, w := range words {
+			s.skipSpace()
+			if !strings.EqualFold(s.parseWord(), w) {
+				s.position = end
+				return CategoryTimestamp, nil
 			}
 		}
+		return CategoryTimestampInstant, nil
 	}
-	return Category{}, fmt.Errorf("can't parse category at %v", s.value)
+	for _, cat := range Categories {
+		if cat.name == word {
+			return cat, nil
+		}
+	}
+	if cat, ok := categoryAliases[word]; ok {
+		return cat, nil
+	}
+	if word == "" {
+		return Category{}, s.errorf(start, "expected a type, got %s", s.describe())
+	}
+	return Category{}, s.errorf(start, "unknown type %q", s.value[start:s.position])
 }
 
 func (s *stringPosition) parseInt() (int, error) {
+	s.skipSpace()
 	start := s.position
 	var result int
-	for s.position < s.length {
-		ch := []rune(s.value)[s.position]
-		if !unicode.IsDigit(ch) {
-			break
+	for s.position < len(s.value) && s.value[s.position] >= '0' && s.value[s.position] <= '9' {
+		result = result*10 + int(s.value[s.position]-'0')
+		if result > math.MaxInt32 {
+			return 0, s.errorf(start, "integer %s is too large", s.value[start:s.position+1])
 		}
-		result = result*10 + int(ch-'0')
 		s.position++
 	}
 	if s.position == start {
-		return 0, fmt.Errorf("Missing integer at %v", s)
+		return 0, s.errorf(start, "expected an integer, got %s", s.describe())
 	}
 	return result, nil
 }
 
+// parseName parses a field name, which is either a sequence of letters, digits and
+// underscores or any characters quoted by back-ticks, a back-tick within them
+// being escaped by another.
 func (s *stringPosition) parseName() (string, error) {
+	s.skipSpace()
 	start := s.position
-	for s.position < s.length {
-		ch := []rune(s.value)[s.position]
-		if (!unicode.IsLetter(ch) && !unicode.IsDigit(ch)) && ch != ',' && ch != '_' {
+	if s.position < len(s.value) && s.value[s.position] == '`' {
+		s.position++
+		var name strings.Builder
+		for {
+			i := strings.IndexByte(s.value[s.position:], '`')
+			if i < 0 {
+				return "", s.errorf(start, "unterminated quoted field name")
+			}
+			name.WriteString(s.value[s.position : s.position+i])
+			s.position += i + 1
+			if s.position < len(s.value) && s.value[s.position] == '`' {
+				name.WriteByte('`')
+				s.position++
+				continue
+			}
 			break
 		}
-		s.position++
+		if name.Len() == 0 {
+			return "", s.errorf(start, "empty field name")
+		}
+		return name.String(), nil
+	}
+	for s.position < len(s.value) {
+		r, size := utf8.DecodeRuneInString(s.value[s.position:])
+		if !isFieldNameRune(r) {
+			break
+		}
+		s.position += size
 	}
 	if s.position == start {
-		return "", fmt.Errorf("Missing name at %v", s)
+		return "", s.errorf(start, "expected a field name, got %s", s.describe())
 	}
-	return string([]rune(s.value)[start:s.position]), nil
+	return s.value[start:s.position], nil
+}
+
+// isFieldNameRune returns whether r may appear in a field name without quoting.
+func isFieldNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
 }
 
 func (s *stringPosition) requireChar(required rune) error {
-	if s.position >= s.length || []rune(s.value)[s.position] != required {
-		return fmt.Errorf("Missing required char '%s' at position %v", string(required), s.position)
+	s.skipSpace()
+	if !s.consumeChar(required) {
+		return s.errorf(s.position, "expected %q, got %s", required, s.describe())
 	}
-	s.position += 1
 	return nil
 }
 
 func (s *stringPosition) consumeChar(ch rune) bool {
-	result := s.position < s.length && []rune(s.value)[s.position] == ch
+	s.skipSpace()
+	result := s.position < len(s.value) && rune(s.value[s.position]) == ch
 	if result {
 		s.position += 1
 	}
@@ -185,6 +295,10 @@ func (s *stringPosition) parseStruct(ty *TypeDescription) error {
 	if err != nil {
 		return err
 	}
+	// A struct may have no fields.
+	if s.consumeChar('>') {
+		return nil
+	}
 	consume := true
 	for consume {
 		fieldName, err := s.parseName()
@@ -233,17 +347,22 @@ func (s *stringPosition) parseType() (*TypeDescription, error) {
 		CategoryLong.name,
 		CategoryShort.name,
 		CategoryString.name,
-		CategoryTimestamp.name:
+		CategoryTimestamp.name,
+		CategoryTimestampInstant.name:
 	case CategoryChar.name,
 		CategoryVarchar.name:
 		err = s.requireChar('(')
 		if err != nil {
 			return nil, err
 		}
+		start := s.position
 		length, err := s.parseInt()
 		if err != nil {
 			return nil, err
 		}
+		if length < 1 {
+			return nil, s.errorf(start, "length %d of %s must be greater than zero", length, result.category)
+		}
 		err = result.withMaxLength(length)
 		if err != nil {
 			return nil, err
@@ -253,29 +372,24 @@ func (s *stringPosition) parseType() (*TypeDescription, error) {
 			return nil, err
 		}
 	case CategoryDecimal.name:
-		err = s.requireChar('(')
-		if err != nil {
-			return nil, err
+		// The precision and scale are optional, the scale being 0 if only
+		// the precision is given.
+		if !s.consumeChar('(') {
+			break
 		}
+		start := s.position
 		precision, err := s.parseInt()
 		if err != nil {
 			return nil, err
 		}
-		err = s.requireChar(',')
-		if err != nil {
-			return nil, err
-		}
-		scale, err := s.parseInt()
-		if err != nil {
-			return nil, err
-		}
-		err = result.withScale(scale)
-		if err != nil {
-			return nil, err
+		scale := 0
+		if s.consumeChar(',') {
+			if scale, err = s.parseInt(); err != nil {
+				return nil, err
+			}
 		}
-		err = result.withPrecision(precision)
-		if err != nil {
-			return nil, err
+		if err := SetDecimal(precision, scale)(result); err != nil {
+			return nil, &SchemaError{Offset: start, Err: err}
 		}
 		err = s.requireChar(')')
 		if err != nil {
@@ -290,7 +404,9 @@ func (s *stringPosition) parseType() (*TypeDescription, error) {
 		if err != nil {
 			return nil, err
 		}
-		result.children = append(result.children, k)
+		if err := result.addChild(k); err != nil {
+			return nil, err
+		}
 		err = s.requireChar('>')
 		if err != nil {
 			return nil, err
@@ -304,7 +420,9 @@ func (s *stringPosition) parseType() (*TypeDescription, error) {
 		if err != nil {
 			return nil, err
 		}
-		result.children = append(result.children, t)
+		if err := result.addChild(t); err != nil {
+			return nil, err
+		}
 		err = s.requireChar(',')
 		if err != nil {
 			return nil, err
@@ -313,7 +431,9 @@ func (s *stringPosition) parseType() (*TypeDescription, error) {
 		if err != nil {
 			return nil, err
 		}
-		result.children = append(result.children, t)
+		if err := result.addChild(t); err != nil {
+			return nil, err
+		}
 		err = s.requireChar('>')
 		if err != nil {
 			return nil, err
@@ -380,6 +500,20 @@ func SetCategory(category Category) TypeDescriptionTransformFunc {
 	}
 }
 
+// SetDecimal sets the precision and scale of a decimal type, returning an error for
+// other types or if the scale exceeds the precision.
+func SetDecimal(precision, scale int) TypeDescriptionTransformFunc {
+	return func(t *TypeDescription) error {
+		// Reset the scale so that the precision is not checked against the
+		// default scale.
+		t.scale = 0
+		if err := t.withPrecision(precision); err != nil {
+			return err
+		}
+		return t.withScale(scale)
+	}
+}
+
 func AddField(field string, fns ...TypeDescriptionTransformFunc) TypeDescriptionTransformFunc {
 	return func(t *TypeDescription) error {
 		ft, err := NewTypeDescription(fns...)
@@ -460,7 +594,7 @@ func (t *TypeDescription) withPrecision(precision int) error {
 	if t.category.name != CategoryDecimal.name {
 		return fmt.Errorf("precision is only allowed on decimal and not %s", t.category.name)
 	} else if precision < 1 || precision > maxPrecision || t.scale > precision {
-		return fmt.Errorf("precision %v is out of range of 1 .. %v", precision, t.scale)
+		return fmt.Errorf("precision %v is out of range of 1 .. %v", precision, maxPrecision)
 	}
 	t.precision = precision
 	return nil
@@ -470,6 +604,27 @@ func (t *TypeDescription) Columns() []string {
 	return t.fieldNames
 }
 
+// Category returns the category of the type.
+func (t *TypeDescription) Category() Category {
+	return t.category
+}
+
+// Children returns the types of the fields of a struct, the element of a list, the
+// key and value of a map or the variants of a union.
+func (t *TypeDescription) Children() []*TypeDescription {
+	return t.children
+}
+
+// Precision returns the precision of a decimal type.
+func (t *TypeDescription) Precision() int {
+	return t.precision
+}
+
+// Scale returns the scale of a decimal type.
+func (t *TypeDescription) Scale() int {
+	return t.scale
+}
+
 func (t *TypeDescription) getID() int {
 	if t.id == -1 {
 		root := t
@@ -546,7 +701,7 @@ func (t *TypeDescription) printToBuffer(buf *bytes.Buffer) {
 			if i != 0 {
 				buf.WriteString(`,`)
 			}
-			buf.WriteString(t.fieldNames[i])
+			printFieldName(buf, t.fieldNames[i])
 			buf.WriteString(`:`)
 			child.printToBuffer(buf)
 		}
@@ -554,6 +709,18 @@ func (t *TypeDescription) printToBuffer(buf *bytes.Buffer) {
 	}
 }
 
+// printFieldName writes the name of a field, quoting it with back-ticks unless it
+// consists of only letters, digits and underscores.
+func printFieldName(buf *bytes.Buffer, name string) {
+	if name != "" && strings.IndexFunc(name, func(r rune) bool { return !isFieldNameRune(r) }) < 0 {
+		buf.WriteString(name)
+		return
+	}
+	buf.WriteString("`")
+	buf.WriteString(strings.Replace(name, "`", "``", -1))
+	buf.WriteString("`")
+}
+
 func (t *TypeDescription) String() string {
 	var buf bytes.Buffer
 	t.printToBuffer(&buf)
@@ -595,7 +762,7 @@ func (t *TypeDescription) printJSONToBuffer(prefix string, buf *bytes.Buffer, in
 	case CategoryStruct.name:
 		buf.WriteString(", \"fields\": {")
 		for i, child := range t.children {
-			child.printJSONToBuffer("\""+t.fieldNames[i]+"\": ", buf, indent)
+			child.printJSONToBuffer(quoteJSON(t.fieldNames[i])+": ", buf, indent)
 			if i != len(t.children)-1 {
 				buf.WriteString(`,`)
 			}
@@ -620,6 +787,218 @@ func (t *TypeDescription) MarshalJSON() ([]byte, error) {
 	return []byte(t.ToJSON()), nil
 }
 
+// quoteJSON returns s as a JSON string.
+func quoteJSON(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string cannot fail.
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// typeDescriptionJSON is a TypeDescription as encoded by ToJSON.
+type typeDescriptionJSON struct {
+	Category  *string           `json:"category"`
+	ID        *int              `json:"id"`
+	Max       *int              `json:"max"`
+	Precision *int              `json:"precision"`
+	Scale     *int              `json:"scale"`
+	Length    *int              `json:"length"`
+	Children  []json.RawMessage `json:"children"`
+	Fields    json.RawMessage   `json:"fields"`
+}
+
+// UnmarshalJSON sets t to the type encoded by ToJSON in data, the IDs of which
+// are assigned from 0 at t. The precision and scale of decimals and the length of
+// chars and varchars default as they do for ParseSchema when omitted, as do the
+// ids, which must otherwise match those assigned. An error is returned for
+// unknown keys, such as the attributes of the Java implementation which have no
+// equivalent here, and for keys, children or fields not of the category of the
+// type.
+func (t *TypeDescription) UnmarshalJSON(data []byte) error {
+	td, err := unmarshalTypeDescription(data)
+	if err != nil {
+		return err
+	}
+	td.assignIDs(0)
+	if err := td.checkJSONIDs(data); err != nil {
+		return err
+	}
+	*t = *td
+	for _, child := range t.children {
+		child.parent = t
+	}
+	return nil
+}
+
+// unmarshalTypeDescription returns the type encoded by ToJSON in data.
+func unmarshalTypeDescription(data []byte) (*TypeDescription, error) {
+	var v typeDescriptionJSON
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if v.Category == nil {
+		return nil, fmt.Errorf("missing category")
+	}
+	category, ok := categoryNamed(*v.Category)
+	if !ok {
+		return nil, fmt.Errorf("unknown category %q", *v.Category)
+	}
+	td, err := NewTypeDescription(SetCategory(category))
+	if err != nil {
+		return nil, err
+	}
+	if (v.Precision != nil || v.Scale != nil) && category != CategoryDecimal {
+		return nil, fmt.Errorf("precision and scale are only allowed on decimal and not %s", category)
+	}
+	if v.Length != nil && category != CategoryChar && category != CategoryVarchar {
+		return nil, fmt.Errorf("length is only allowed on char and varchar and not %s", category)
+	}
+	if v.Children != nil && category != CategoryList && category != CategoryMap && category != CategoryUnion {
+		return nil, fmt.Errorf("children are only allowed on array, map and uniontype and not %s", category)
+	}
+	if v.Fields != nil && category != CategoryStruct {
+		return nil, fmt.Errorf("fields are only allowed on struct and not %s", category)
+	}
+	switch category {
+	case CategoryDecimal:
+		precision, scale := defaultPrecision, defaultScale
+		if v.Precision != nil {
+			precision = *v.Precision
+			// As for ParseSchema, a precision without a scale has a scale of 0.
+			scale = 0
+		}
+		if v.Scale != nil {
+			scale = *v.Scale
+		}
+		if err := SetDecimal(precision, scale)(td); err != nil {
+			return nil, err
+		}
+	case CategoryChar, CategoryVarchar:
+		if v.Length != nil {
+			if *v.Length < 1 {
+				return nil, fmt.Errorf("length %d of %s must be greater than zero", *v.Length, category)
+			}
+			if err := td.withMaxLength(*v.Length); err != nil {
+				return nil, err
+			}
+		}
+	case CategoryList, CategoryMap, CategoryUnion:
+		expected := map[Category]int{CategoryList: 1, CategoryMap: 2}[category]
+		if category == CategoryUnion {
+			if len(v.Children) == 0 {
+				return nil, fmt.Errorf("uniontype must have at least one child")
+			}
+		} else if len(v.Children) != expected {
+			return nil, fmt.Errorf("%s must have %d children, not %d", category, expected, len(v.Children))
+		}
+		for i, raw := range v.Children {
+			child, err := unmarshalTypeDescription(raw)
+			if err != nil {
+				return nil, fmt.Errorf("child %d of %s: %v", i, category, err)
+			}
+			if category == CategoryUnion {
+				err = td.addUnionChild(child)
+			} else {
+				err = td.addChild(child)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	case CategoryStruct:
+		if v.Fields == nil {
+			return nil, fmt.Errorf("struct must have fields")
+		}
+		if err := unmarshalFields(td, v.Fields); err != nil {
+			return nil, err
+		}
+	}
+	return td, nil
+}
+
+// unmarshalFields adds the fields of the JSON object data to the struct td in the
+// order in which they appear.
+func unmarshalFields(td *TypeDescription, data json.RawMessage) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("fields of struct must be an object")
+	}
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		if seen[name] {
+			return fmt.Errorf("duplicate field %s of struct", name)
+		}
+		seen[name] = true
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		field, err := unmarshalTypeDescription(raw)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", name, err)
+		}
+		if err := td.addField(name, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkJSONIDs returns an error if the ids and maximum ids of the type encoded in
+// data, where present, differ from those assigned to t.
+func (t *TypeDescription) checkJSONIDs(data []byte) error {
+	var v typeDescriptionJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.ID != nil && *v.ID != t.id {
+		return fmt.Errorf("id %d of %s is not its pre-order id %d", *v.ID, t.category, t.id)
+	}
+	if v.Max != nil && *v.Max != t.maxId {
+		return fmt.Errorf("max %d of %s is not its maximum pre-order id %d", *v.Max, t.category, t.maxId)
+	}
+	if t.category == CategoryStruct {
+		dec := json.NewDecoder(bytes.NewReader(v.Fields))
+		dec.Token()
+		for i := 0; dec.More(); i++ {
+			dec.Token()
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			if err := t.children[i].checkJSONIDs(raw); err != nil {
+				return fmt.Errorf("field %s: %v", t.fieldNames[i], err)
+			}
+		}
+		return nil
+	}
+	for i, raw := range v.Children {
+		if err := t.children[i].checkJSONIDs(raw); err != nil {
+			return fmt.Errorf("child %d of %s: %v", i, t.category, err)
+		}
+	}
+	return nil
+}
+
+// categoryNamed returns the Category of the provided name.
+func categoryNamed(name string) (Category, bool) {
+	for _, category := range Categories {
+		if category.name == name {
+			return category, true
+		}
+	}
+	return Category{}, false
+}
+
 func (t *TypeDescription) GetField(fieldName string) (*TypeDescription, error) {
 	fieldNames := strings.Split(fieldName, ".")
 	root := fieldNames[0]
@@ -706,6 +1085,24 @@ func createList(child *TypeDescription) (*TypeDescription, error) {
 	return td, nil
 }
 
+// ParseSchema parses a schema such as "struct<id:bigint,tags:array<string>>", the
+// inverse of TypeDescription.String. Type names are case insensitive and may be
+// surrounded by white space, "integer" being accepted for int and "dec" and
+// "numeric" for decimal. The precision and scale of a decimal are optional,
+// defaulting to decimal(38,10), as is the scale, defaulting to 0. Field names
+// other than those of letters, digits and underscores are quoted by back-ticks,
+// such as "struct<`field name`:int>", with a back-tick in the name written twice.
+// A SchemaError holding the byte offset of the problem is returned if the schema
+// cannot be parsed.
 func ParseSchema(schema string) (*TypeDescription, error) {
-	return NewStringPosition(schema).parseType()
+	s := NewStringPosition(schema)
+	td, err := s.parseType()
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	if s.position < len(s.value) {
+		return nil, s.errorf(s.position, "unexpected %s after the type", s.describe())
+	}
+	return td, nil
 }
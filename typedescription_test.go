@@ -1,6 +1,9 @@
 package orc
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -59,6 +62,21 @@ func TestTypeDescriptionParse(t *testing.T) {
 		t.Errorf("Test failed, expected %s got %s", expected, td.ToJSON())
 	}
 
+	description = NewStringPosition(`struct<f1:TIMESTAMP WITH LOCAL TIME ZONE,f2:timestamp>`)
+
+	td, err = description.parseType()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `struct<f1:timestamp with local time zone,f2:timestamp>`
+	if td.String() != expected {
+		t.Errorf("Test failed, expected %s got %s", expected, td.String())
+	}
+	if td.children[0].getCategory() != CategoryTimestampInstant || td.children[1].getCategory() != CategoryTimestamp {
+		t.Errorf("Test failed, expected categories %s and %s", CategoryTimestampInstant, CategoryTimestamp)
+	}
+
 }
 
 func TestTypeDescriptionPrint(t *testing.T) {
@@ -120,3 +138,182 @@ func TestTypeDescriptionPrint(t *testing.T) {
 	}
 
 }
+
+func TestParseSchema(t *testing.T) {
+
+	testCases := []struct {
+		schema   string
+		expected string
+	}{
+		{
+			schema:   "struct<id:bigint,tags:array<string>,attrs:map<string,decimal(10,2)>>",
+			expected: "struct<id:bigint,tags:array<string>,attrs:map<string,decimal(10,2)>>",
+		},
+		{
+			schema:   " STRUCT < Id : BigInt ,\n\tTags : Array < String > > ",
+			expected: "struct<Id:bigint,Tags:array<string>>",
+		},
+		{
+			schema:   "struct<a:integer,b:dec(5,1),c:numeric(7),d:decimal,e:decimal( 12 , 3 )>",
+			expected: "struct<a:int,b:decimal(5,1),c:decimal(7,0),d:decimal(38,10),e:decimal(12,3)>",
+		},
+		{
+			schema:   "struct<a:boolean,b:tinyint,c:smallint,d:int,e:bigint,f:float,g:double,h:string,i:binary,j:date,k:timestamp,l:varchar(20),m:char(1)>",
+			expected: "struct<a:boolean,b:tinyint,c:smallint,d:int,e:bigint,f:float,g:double,h:string,i:binary,j:date,k:timestamp,l:varchar(20),m:char(1)>",
+		},
+		{
+			schema:   "struct<a:timestamp  WITH\nlocal time   zone,b:timestamp,c:array<timestamp with local time zone>>",
+			expected: "struct<a:timestamp with local time zone,b:timestamp,c:array<timestamp with local time zone>>",
+		},
+		{
+			schema:   "struct<`struct`:int,`field name`:string,`a:b,c<d>`:int,`back``tick`:int,`plain`:date,ünïcödé_1:int>",
+			expected: "struct<struct:int,`field name`:string,`a:b,c<d>`:int,`back``tick`:int,plain:date,ünïcödé_1:int>",
+		},
+		{
+			schema:   "uniontype<int,struct<a:map<string,uniontype<string,array<int>>>>,struct<>>",
+			expected: "uniontype<int,struct<a:map<string,uniontype<string,array<int>>>>,struct<>>",
+		},
+		{
+			schema:   "map<string,map<string,array<array<struct<x:double,y:double>>>>>",
+			expected: "map<string,map<string,array<array<struct<x:double,y:double>>>>>",
+		},
+	}
+
+	for _, tc := range testCases {
+		td, err := ParseSchema(tc.schema)
+		if err != nil {
+			t.Errorf("Test failed, schema %q: %v", tc.schema, err)
+			continue
+		}
+		if actual := td.String(); actual != tc.expected {
+			t.Errorf("Test failed, schema %q expected %s got %s", tc.schema, tc.expected, actual)
+			continue
+		}
+		// The printed schema parses to the same schema.
+		reparsed, err := ParseSchema(td.String())
+		if err != nil {
+			t.Errorf("Test failed, schema %q: %v", td.String(), err)
+			continue
+		}
+		if actual := reparsed.String(); actual != tc.expected {
+			t.Errorf("Test failed, schema %q reparsed as %s", tc.expected, actual)
+		}
+	}
+
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+
+	testCases := []struct {
+		schema   string
+		offset   int
+		expected string
+	}{
+		{schema: "", offset: 0, expected: "offset 0: expected a type, got the end of the schema"},
+		{schema: "struct<a:int", offset: 12, expected: `offset 12: expected '>', got the end of the schema`},
+		{schema: "struct<a:int;b:int>", offset: 12, expected: `offset 12: expected '>', got ';'`},
+		{schema: "struct<a:integr>", offset: 9, expected: `offset 9: unknown type "integr"`},
+		{schema: "struct<a int>", offset: 9, expected: `offset 9: expected ':', got 'i'`},
+		{schema: "struct<:int>", offset: 7, expected: `offset 7: expected a field name, got ':'`},
+		{schema: "struct<`a:int>", offset: 7, expected: "offset 7: unterminated quoted field name"},
+		{schema: "struct<``:int>", offset: 7, expected: "offset 7: empty field name"},
+		{schema: "decimal(39,2)", offset: 8, expected: "offset 8: precision 39 is out of range of 1 .. 38"},
+		{schema: "decimal(5,6)", offset: 8, expected: "offset 8: scale is out of range at 6"},
+		{schema: "varchar", offset: 7, expected: `offset 7: expected '(', got the end of the schema`},
+		{schema: "char(0)", offset: 5, expected: "offset 5: length 0 of char must be greater than zero"},
+		{schema: "varchar(99999999999)", offset: 8, expected: "offset 8: integer 9999999999 is too large"},
+		{schema: "map<string>", offset: 10, expected: `offset 10: expected ',', got '>'`},
+		{schema: "array<int>>", offset: 10, expected: `offset 10: unexpected '>' after the type`},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseSchema(tc.schema)
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) || schemaErr.Offset != tc.offset || err.Error() != tc.expected {
+			t.Errorf("Test failed, schema %q expected error %q got %v", tc.schema, tc.expected, err)
+		}
+	}
+
+}
+
+func TestTypeDescriptionJSON(t *testing.T) {
+
+	schemas := []string{
+		"int",
+		"struct<>",
+		"struct<id:bigint,tags:array<string>,attrs:map<string,uniontype<int,struct<x:double>>>>",
+		"struct<price:decimal(10,2),code:char(3),name:varchar(20),ts:timestamp,tsi:timestamp with local time zone>",
+		"struct<`first \"name\"`:string,`a<b>`:struct<`c``d`:binary>>",
+		"array<map<int,array<decimal(38,0)>>>",
+	}
+	for _, schema := range schemas {
+		expected, err := ParseSchema(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !json.Valid(data) {
+			t.Errorf("Test failed, schema %s marshalled as invalid JSON %s", schema, data)
+		}
+		var actual TypeDescription
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Fatalf("Test failed, schema %s unmarshalled from %s: %v", schema, data, err)
+		}
+		if !reflect.DeepEqual(&actual, expected) {
+			t.Errorf("Test failed, expected %s got %s", expected.ToJSON(), actual.ToJSON())
+		}
+		if actual.String() != schema {
+			t.Errorf("Test failed, expected schema %s got %s", schema, actual.String())
+		}
+	}
+
+	// The ids and the defaults of decimals, chars and varchars may be omitted.
+	data := `{"category": "struct", "fields": {"d": {"category": "decimal"}, "p": {"category": "decimal", "precision": 5}, "v": {"category": "varchar"}, "l": {"category": "array", "children": [{"category": "int"}]}}}`
+	var td TypeDescription
+	if err := json.Unmarshal([]byte(data), &td); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "struct<d:decimal(38,10),p:decimal(5,0),v:varchar(256),l:array<int>>"; td.String() != expected {
+		t.Errorf("Test failed, expected schema %s got %s", expected, td.String())
+	}
+	if l := td.children[3]; l.parent != &td || l.getID() != 4 || l.children[0].getID() != 5 {
+		t.Errorf("Test failed, expected the list of id 4 with a child of id 5 got %d", l.getID())
+	}
+
+}
+
+func TestTypeDescriptionJSONErrors(t *testing.T) {
+
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{`{"id": 0}`, "missing category"},
+		{`{"category": "integr"}`, `unknown category "integr"`},
+		{`{"category": "int", "precision": 5}`, "precision and scale are only allowed on decimal and not int"},
+		{`{"category": "string", "length": 5}`, "length is only allowed on char and varchar and not string"},
+		{`{"category": "int", "children": []}`, "children are only allowed on array, map and uniontype and not int"},
+		{`{"category": "array", "fields": {}}`, "fields are only allowed on struct and not array"},
+		{`{"category": "array"}`, "array must have 1 children, not 0"},
+		{`{"category": "map", "children": [{"category": "int"}]}`, "map must have 2 children, not 1"},
+		{`{"category": "uniontype", "children": []}`, "uniontype must have at least one child"},
+		{`{"category": "struct"}`, "struct must have fields"},
+		{`{"category": "struct", "fields": []}`, "fields of struct must be an object"},
+		{`{"category": "struct", "fields": {"a": {"category": "int"}, "a": {"category": "int"}}}`, "duplicate field a of struct"},
+		{`{"category": "struct", "fields": {"a": {"category": "decimal", "precision": 39}}}`, "field a: precision 39 is out of range of 1 .. 38"},
+		{`{"category": "array", "children": [{"category": "char", "length": 0}]}`, "child 0 of array: length 0 of char must be greater than zero"},
+		{`{"category": "int", "attributes": {}}`, `json: unknown field "attributes"`},
+		{`{"category": "struct", "id": 0, "max": 1, "fields": {"a": {"category": "int", "id": 2, "max": 2}}}`, "field a: id 2 of int is not its pre-order id 1"},
+		{`{"category": "array", "max": 2, "children": [{"category": "int"}]}`, "max 2 of array is not its maximum pre-order id 1"},
+	}
+	for _, tc := range testCases {
+		var td TypeDescription
+		if err := json.Unmarshal([]byte(tc.data), &td); err == nil || err.Error() != tc.expected {
+			t.Errorf("Test failed, %s expected error %q got %v", tc.data, tc.expected, err)
+		}
+	}
+
+}
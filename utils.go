@@ -150,31 +150,12 @@ func readVInt(signed bool, r io.ByteReader) (int64, error) {
 
 // readerSignedVInt reads a signed variable width integer from ByteReader r.
 func readSignedVInt(r io.ByteReader) (int64, error) {
-	result, err := readUnsignedVInt(r)
-	if err != nil {
-		return result, err
-	}
-	return int64((uint64(result) >> uint64(1)) ^ -(uint64(result) & uint64(1))), nil
+	return readVslong(r)
 }
 
 // readerUnsignedVInt reads an unsigned variable width integer from ByteReader r.
 func readUnsignedVInt(r io.ByteReader) (int64, error) {
-	var result int64
-	var offset int
-	b := int64(0x80)
-	for (b & 0x80) != 0 {
-		nb, err := r.ReadByte()
-		if err != nil {
-			return result, err
-		}
-		b = int64(nb)
-		if b == -1 {
-			return result, ErrEOFUnsignedVInt
-		}
-		result |= (b & 0x7f) << uint64(offset)
-		offset += 7
-	}
-	return result, nil
+	return readVulong(r)
 }
 
 func readBitPackedInts(buffer []int64, offset int, length int, bitSize int, r io.ByteReader) error {
@@ -226,6 +207,13 @@ func minInt64(a, b int64) int64 {
 	return b
 }
 
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func isSafeSubtract(left, right int64) bool {
 	return (left^right) >= 0 || (left^(left-right)) >= 0
 }
@@ -722,31 +710,33 @@ func writeVslong(w io.ByteWriter, value int64) error {
 	return writeVulong(w, (value<<1)^(value>>63))
 }
 
+// readVulong reads an unsigned base 128 varint of at most 10 bytes, the last of
+// which holds only the most significant bit of the 64 bit value.
 func readVulong(r io.ByteReader) (int64, error) {
-	var result int64
-	var offset int
-	b := int64(0x80)
-	for (b & 0x80) != 0 {
-		nb, err := r.ReadByte()
+	var result uint64
+	for shift := uint(0); ; shift += 7 {
+		b, err := r.ReadByte()
 		if err != nil {
-			return result, err
+			return int64(result), err
+		}
+		if shift == 63 && b > 1 {
+			return int64(result), ErrVarintOverflow
 		}
-		b = int64(nb)
-		if b == -1 {
-			return result, ErrEOFUnsignedVInt
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int64(result), nil
 		}
-		result |= (b & 0x7f) << uint64(offset)
-		offset += 7
 	}
-	return result, nil
 }
 
+// readVslong reads a zigzag encoded base 128 varint, decoding it as an unsigned
+// value so that math.MinInt64 and math.MaxInt64 are read exactly.
 func readVslong(r io.ByteReader) (int64, error) {
 	result, err := readVulong(r)
 	if err != nil {
 		return 0, err
 	}
-	return int64((uint64(result) >> uint64(1)) ^ -(uint64(result) & uint64(1))), nil
+	return zigzagDecode(uint64(result)), nil
 }
 
 func readInts(buffer []int64, offset, len, bitSize int, r io.ByteReader) error {
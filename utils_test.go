@@ -1,7 +1,10 @@
 package orc
 
 import (
+	"bytes"
+	"io"
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -60,13 +63,124 @@ func TestZigZagDecode(t *testing.T) {
 	}
 }
 
-func BenchmarkzigzagEncodeeger(b *testing.B) {
+func TestZigZagBoundaries(t *testing.T) {
+	testCases := []struct {
+		value   int64
+		encoded uint64
+	}{
+		{value: math.MaxInt64, encoded: math.MaxUint64 - 1},
+		{value: math.MinInt64, encoded: math.MaxUint64},
+		{value: math.MaxInt64 - 1, encoded: math.MaxUint64 - 3},
+		{value: math.MinInt64 + 1, encoded: math.MaxUint64 - 2},
+		{value: math.MaxInt32 + 1, encoded: 1 << 32},
+		{value: math.MinInt32, encoded: 1<<32 - 1},
+	}
+	for _, tc := range testCases {
+		if encoded := zigzagEncode(tc.value); encoded != tc.encoded {
+			t.Errorf("Test failed, expected %v to encode to %v got %v", tc.value, tc.encoded, encoded)
+		}
+		if value := zigzagDecode(tc.encoded); value != tc.value {
+			t.Errorf("Test failed, expected %v to decode to %v got %v", tc.encoded, tc.value, value)
+		}
+	}
+}
+
+func TestReadVulong(t *testing.T) {
+	testCases := []struct {
+		input    []byte
+		signed   bool
+		expected int64
+		err      error
+	}{
+		{input: []byte{0x00}, expected: 0},
+		{input: []byte{0xff, 0x01}, expected: 255},
+		{input: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, expected: math.MaxInt64},
+		{input: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}, expected: math.MinInt64},
+		{input: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, expected: -1},
+		{input: []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, signed: true, expected: math.MaxInt64},
+		{input: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, signed: true, expected: math.MinInt64},
+		// The tenth byte may only hold the most significant bit.
+		{input: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02}, err: ErrVarintOverflow},
+		{input: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, err: ErrVarintOverflow},
+		{input: []byte{0x80, 0x80}, err: io.EOF},
+	}
+	for _, tc := range testCases {
+		value, err := readVInt(tc.signed, bytes.NewReader(tc.input))
+		if err != tc.err {
+			t.Errorf("Test failed, %x expected error %v got %v", tc.input, tc.err, err)
+			continue
+		}
+		if err == nil && value != tc.expected {
+			t.Errorf("Test failed, %x expected %v got %v", tc.input, tc.expected, value)
+		}
+	}
+
+	// The boundaries of int64 round trip through the writer.
+	for _, v := range []int64{math.MinInt64, math.MinInt64 + 1, -1, 0, 1, math.MaxInt64 - 1, math.MaxInt64} {
+		var buf bytes.Buffer
+		if err := writeVslong(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeVulong(&buf, v); err != nil {
+			t.Fatal(err)
+		}
+		r := bytes.NewReader(buf.Bytes())
+		if signed, err := readVslong(r); err != nil || signed != v {
+			t.Errorf("Test failed, expected signed %v got %v, %v", v, signed, err)
+		}
+		if unsigned, err := readVulong(r); err != nil || unsigned != v {
+			t.Errorf("Test failed, expected unsigned %v got %v, %v", v, unsigned, err)
+		}
+	}
+}
+
+// boundaryInt64s returns sequences of values at and around the boundaries of
+// int64 suited to each of the run length encodings, along with random values.
+func boundaryInt64s(rnd *rand.Rand) [][]int64 {
+	boundaries := []int64{math.MinInt64, math.MinInt64 + 1, -1, 0, 1, math.MaxInt64 - 1, math.MaxInt64}
+	var inputs [][]int64
+	inputs = append(inputs, boundaries)
+	for _, v := range boundaries {
+		inputs = append(inputs, []int64{v})
+		for _, n := range []int{3, 10, 130, 600} {
+			run := make([]int64, n)
+			for i := range run {
+				run[i] = v
+			}
+			inputs = append(inputs, run)
+		}
+	}
+	var alternating, ascending, descending []int64
+	for i := 0; i < 100; i++ {
+		alternating = append(alternating, boundaries[i%2*(len(boundaries)-1)])
+		ascending = append(ascending, math.MaxInt64-99+int64(i))
+		descending = append(descending, math.MinInt64+99-int64(i))
+	}
+	inputs = append(inputs, alternating, ascending, descending)
+	for i := 0; i < 10; i++ {
+		values := make([]int64, 1+rnd.Intn(1000))
+		for j := range values {
+			switch rnd.Intn(10) {
+			case 0:
+				values[j] = boundaries[rnd.Intn(len(boundaries))]
+			case 1, 2, 3:
+				values[j] = int64(rnd.Uint64())
+			default:
+				values[j] = rnd.Int63n(1000) - 500
+			}
+		}
+		inputs = append(inputs, values)
+	}
+	return inputs
+}
+
+func BenchmarkZigzagEncode(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		zigzagEncode(int64(i))
 	}
 }
 
-func BenchmarkzigzagDecodeeger(b *testing.B) {
+func BenchmarkZigzagDecode(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		zigzagDecode(uint64(i))
 	}
@@ -1,8 +1,15 @@
 package orc
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	gproto "github.com/golang/protobuf/proto"
 
@@ -14,11 +21,19 @@ var (
 	DefaultStripeTargetSize     int64  = 200 * 1024 * 1024
 	DefaultCompressionChunkSize uint64 = 256 * 1024
 	DefaultRowIndexStride       uint32 = 10000
+	DefaultPaddingTolerance            = 0.05
+	// WriterGo is the code registered in the ORC specification identifying files
+	// written by this library, stored in the writer field of the footer.
+	WriterGo uint32 = 3
+	// DefaultWriterVersion is the writer version stored in the postscript, being
+	// the original version of writers other than ORC Java.
+	DefaultWriterVersion uint32 = 6
+	// ErrWriterClosed is returned when writing to a Writer that has been closed.
+	ErrWriterClosed = errors.New("writer is closed")
 )
 
 type Writer struct {
 	schema            *TypeDescription
-	streams           streamWriterMap
 	w                 io.Writer
 	treeWriter        TreeWriter
 	treeWriters       writerMap
@@ -38,6 +53,47 @@ type Writer struct {
 	indexes           map[int]*proto.RowIndex
 	indexOffset       uint64
 	chunkOffset       uint64
+	decimalRounding   bool
+	truncateStrings   bool
+	timezone          *time.Location
+	version           Version
+	rleVersion        int
+	userMetadata      map[string][]byte
+	blockSize         int64
+	paddingTolerance  float64
+	memoryLimit       int64
+	stripeMaxAge      time.Duration
+	stripeStart       time.Time
+	now               func() time.Time
+	parallelism       int
+	pending           [][]interface{}
+	sortColumn        string
+	sortIndex         int
+	sortCategory      Category
+	sortNullsLast     bool
+	sorted            []sortedRow
+	sortedSize        int64
+	sortedStart       time.Time
+	closed            bool
+	closeErr          error
+	encodingStrategy  EncodingStrategy
+	// dictionaryKeyThreshold and patchedBase are only set when overriding
+	// the encodingStrategy.
+	dictionaryKeyThreshold *float64
+	patchedBase            *bool
+	unsortedDictionaries   bool
+	columnEncodings        map[string]columnEncoding
+	columnEncodingsByID    map[int]columnEncoding
+	// streamBytes and stripeBytes are the total length of the streams of
+	// the stripes written and of the stripes themselves, whilst rowBytes and
+	// rowStreamBytes are the total estimated size of the rows buffered for
+	// sorting or encoding concurrently and of the streams they were
+	// encoded to. These are used by EstimatedSize.
+	streamBytes    int64
+	stripeBytes    int64
+	rowBytes       int64
+	rowStreamBytes int64
+	pendingSize    int64
 }
 
 func ptrInt64(i int64) *int64 {
@@ -54,6 +110,422 @@ func SetSchema(schema *TypeDescription) WriterConfigFunc {
 	}
 }
 
+// SetDecimalRounding sets whether decimal values with more fractional digits than
+// the scale of their column are rounded half away from zero. By default such
+// values are rejected with an error.
+func SetDecimalRounding(round bool) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.decimalRounding = round
+		return nil
+	}
+}
+
+// WithTruncateOverlongStrings sets whether values of char and varchar columns that
+// are longer than the maximum length of their column are truncated to it. Lengths
+// are counted in Unicode code points. By default such values are rejected with an
+// error.
+func WithTruncateOverlongStrings(truncate bool) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.truncateStrings = truncate
+		return nil
+	}
+}
+
+// WithFileVersion sets the ORC file version written, such as 0.11 for compatibility
+// with older readers. Only features supported by the version are written. By
+// default version 0.12 files are written.
+func WithFileVersion(major, minor int) WriterConfigFunc {
+	return func(w *Writer) error {
+		for _, version := range versions {
+			if uint32(major) == version.major && uint32(minor) == version.minor {
+				w.version = version
+				w.postScript.Version = []uint32{version.major, version.minor}
+				return nil
+			}
+		}
+		return fmt.Errorf("unsupported file version %d.%d", major, minor)
+	}
+}
+
+// WithFormatVersion sets the ORC file version written by its name, such as "0.11",
+// and is otherwise equivalent to WithFileVersion. Schemas containing types that
+// postdate the version are rejected by NewWriter.
+func WithFormatVersion(version string) WriterConfigFunc {
+	return func(w *Writer) error {
+		for _, v := range versions {
+			if v.name == version {
+				return WithFileVersion(int(v.major), int(v.minor))(w)
+			}
+		}
+		return fmt.Errorf("unsupported file version %s", version)
+	}
+}
+
+// WithRLEVersion sets the version of the run length encoding of integers, 1 or 2,
+// in place of that of the file version. The integer, date, decimal, timestamp and
+// nested columns of version 1 have DIRECT encodings and its string columns DIRECT
+// or DICTIONARY encodings, which readers that predate version 2, such as those of
+// Hive 0.11, understand. Such readers also need WithFileVersion(0, 11) to exclude
+// the types that postdate them. Version 2 is not supported by file version 0.11.
+func WithRLEVersion(version int) WriterConfigFunc {
+	return func(w *Writer) error {
+		if version != 1 && version != 2 {
+			return fmt.Errorf("invalid run length encoding version %d", version)
+		}
+		w.rleVersion = version
+		return nil
+	}
+}
+
+// WithWriterVersion sets the writer code stored in the footer and the writer
+// version stored in the postscript, which readers use to identify the writer of a
+// file and work around its known bugs. It is intended for testing compatibility
+// with other readers, by default files are identified as WriterGo at
+// DefaultWriterVersion.
+func WithWriterVersion(writer, version uint32) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.footer.Writer = ptrUint32(writer)
+		w.postScript.WriterVersion = ptrUint32(version)
+		return nil
+	}
+}
+
+// WithBlockSize sets the block size in bytes of the file system the file is
+// written to, such as HDFS, so that stripes are not written across block
+// boundaries. Before a stripe that would cross a boundary is written, the file is
+// padded up to the boundary if the padding is within the padding tolerance,
+// otherwise stripes are shrunk to fit the space remaining in the block. By default
+// the block size is unknown and stripes are neither padded nor shrunk.
+func WithBlockSize(bytes int64) WriterConfigFunc {
+	return func(w *Writer) error {
+		if bytes <= 0 {
+			return fmt.Errorf("invalid block size %d", bytes)
+		}
+		w.blockSize = bytes
+		return nil
+	}
+}
+
+// WithStripeTargetSize sets the number of bytes that the column buffers of the
+// current stripe may reach before the stripe is written, which is checked at the
+// end of each row group. It defaults to DefaultStripeTargetSize.
+func WithStripeTargetSize(bytes int64) WriterConfigFunc {
+	return func(w *Writer) error {
+		if bytes <= 0 {
+			return fmt.Errorf("invalid stripe target size %d", bytes)
+		}
+		w.stripeTargetSize = bytes
+		return nil
+	}
+}
+
+// WithPaddingTolerance sets the largest fraction of the block size that may be
+// padded in order to avoid a stripe crossing a block boundary. It defaults to
+// DefaultPaddingTolerance and has no effect unless WithBlockSize is set.
+func WithPaddingTolerance(fraction float64) WriterConfigFunc {
+	return func(w *Writer) error {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("invalid padding tolerance %v, expected a fraction between 0 and 1", fraction)
+		}
+		w.paddingTolerance = fraction
+		return nil
+	}
+}
+
+// WithWriterMemoryLimit sets the largest number of bytes that the column buffers
+// of the Writer may hold in memory, as reported by MemoryUsage. The current stripe
+// is written early when the limit is reached, regardless of the stripe target
+// size, resulting in smaller stripes when memory is constrained. By default the
+// memory used is only bounded by the stripe target size.
+func WithWriterMemoryLimit(bytes int64) WriterConfigFunc {
+	return func(w *Writer) error {
+		if bytes <= 0 {
+			return fmt.Errorf("invalid writer memory limit %d", bytes)
+		}
+		w.memoryLimit = bytes
+		return nil
+	}
+}
+
+// parallelBatchSize is the number of rows buffered before they are encoded by the
+// concurrent column encoders of a Writer using WithEncoderParallelism.
+const parallelBatchSize = 1024
+
+// WithEncoderParallelism sets the number of goroutines used to encode the top
+// level columns of the schema concurrently. Rows are buffered and encoded in
+// batches, with each column receiving its values in order, so that the bytes
+// written are identical to those of a single goroutine. As a result an error for
+// a value is returned by the Write call that encodes its batch or by Flush or
+// Close. By default columns are encoded on the calling goroutine as each row is
+// written.
+func WithEncoderParallelism(n int) WriterConfigFunc {
+	return func(w *Writer) error {
+		if n < 1 {
+			return fmt.Errorf("invalid encoder parallelism %d", n)
+		}
+		w.parallelism = n
+		return nil
+	}
+}
+
+// WithStripeMaxAge sets the longest wall clock time that rows are buffered for
+// before the current stripe is written, regardless of the stripe target size. The
+// age of the stripe is checked as each row is written, therefore, a stripe is
+// never written without rows and an idle Writer holds its rows until the next
+// call to Write, Flush or Close. By default stripes are only written by size.
+func WithStripeMaxAge(d time.Duration) WriterConfigFunc {
+	return func(w *Writer) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid stripe max age %v", d)
+		}
+		w.stripeMaxAge = d
+		return nil
+	}
+}
+
+// WithWriterTimezone sets the timezone recorded in each stripe footer as that of
+// the writer, which readers use to interpret the values of timestamp columns, and
+// relative to which the values are encoded. The name of the location must be that
+// of a timezone in the IANA Time Zone database, such as "America/New_York", as
+// returned by time.LoadLocation. By default the local timezone is used, as
+// determined by the TZ environment variable or /etc/localtime, or UTC if it cannot
+// be determined.
+func WithWriterTimezone(loc *time.Location) WriterConfigFunc {
+	return func(w *Writer) error {
+		if loc == nil {
+			return fmt.Errorf("invalid writer timezone, must not be nil")
+		}
+		if loc == time.Local {
+			w.timezone = localTimezone()
+			return nil
+		}
+		if _, err := time.LoadLocation(loc.String()); err != nil {
+			return fmt.Errorf("invalid writer timezone %q, must be the name of an IANA timezone: %v", loc, err)
+		}
+		w.timezone = loc
+		return nil
+	}
+}
+
+// localTimezone returns the local timezone loaded by its name in the IANA Time Zone
+// database, as the name of time.Local is always "Local", or UTC if the name cannot
+// be determined.
+func localTimezone() *time.Location {
+	name, ok := os.LookupEnv("TZ")
+	if ok {
+		name = strings.TrimPrefix(name, ":")
+	} else if target, err := os.Readlink("/etc/localtime"); err == nil {
+		name = target
+	}
+	if i := strings.LastIndex(name, "zoneinfo/"); i >= 0 {
+		name = name[i+len("zoneinfo/"):]
+	}
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WithStripeSortColumn sets a top level column of the schema by which the rows of
+// each stripe are sorted, so that the minimum and maximum statistics of the column
+// in each row group are tight and readers can skip more of them. Rows are buffered
+// in memory until their approximate size in memory reaches the stripe target size,
+// or until Flush or Close is called, then sorted and written as a stripe. Rows
+// with equal values remain in the order written. By default rows are written in
+// the order given.
+func WithStripeSortColumn(column string) WriterConfigFunc {
+	return func(w *Writer) error {
+		if column == "" {
+			return fmt.Errorf("invalid stripe sort column %q", column)
+		}
+		w.sortColumn = column
+		return nil
+	}
+}
+
+// WithStripeSortNullsLast sets whether null values of the stripe sort column are
+// ordered after every other value, rather than before them as by default. It has
+// no effect unless WithStripeSortColumn is set.
+func WithStripeSortNullsLast(nullsLast bool) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.sortNullsLast = nullsLast
+		return nil
+	}
+}
+
+// EncodingStrategy determines whether the Writer favours the speed of writing
+// or the size of the file when choosing the encoding of each column.
+type EncodingStrategy int
+
+const (
+	// EncodingCompression evaluates dictionary encoding for every string column
+	// and the PATCHED_BASE encoding for integers, producing smaller files. It is
+	// the default.
+	EncodingCompression EncodingStrategy = iota
+	// EncodingSpeed writes strings with direct encoding without building a
+	// dictionary and integers without considering the PATCHED_BASE encoding.
+	EncodingSpeed
+)
+
+// WithEncodingStrategy sets the EncodingStrategy of the writer. The individual
+// settings it determines may be overridden by WithDictionaryKeyThreshold and
+// WithPatchedBaseEncoding, whatever the order of the options.
+func WithEncodingStrategy(strategy EncodingStrategy) WriterConfigFunc {
+	return func(w *Writer) error {
+		switch strategy {
+		case EncodingCompression, EncodingSpeed:
+		default:
+			return fmt.Errorf("invalid encoding strategy %d", strategy)
+		}
+		w.encodingStrategy = strategy
+		return nil
+	}
+}
+
+// WithDictionaryKeyThreshold sets the maximum ratio of distinct values to the
+// number of values of a string column for which dictionary encoding is used, so
+// that a threshold of 0 disables dictionary encoding. It defaults to
+// DictionaryEncodingThreshold, or 0 with the EncodingSpeed strategy.
+func WithDictionaryKeyThreshold(threshold float64) WriterConfigFunc {
+	return func(w *Writer) error {
+		if threshold < 0 || threshold > 1 || math.IsNaN(threshold) {
+			return fmt.Errorf("invalid dictionary key threshold %v, must be between 0 and 1", threshold)
+		}
+		w.dictionaryKeyThreshold = &threshold
+		return nil
+	}
+}
+
+// WithSortedDictionary sets whether the dictionaries of dictionary encoded string,
+// char and varchar columns are sorted, as they are by Hive, which improves their
+// compression and allows readers to search them. Otherwise the entries of each
+// dictionary are written in the order in which the values first occur in the
+// stripe. Dictionaries are sorted by default.
+func WithSortedDictionary(sorted bool) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.unsortedDictionaries = !sorted
+		return nil
+	}
+}
+
+// WithPatchedBaseEncoding sets whether integers may be written with the PATCHED_BASE
+// encoding of the run length encoding version 2. It is enabled by default and
+// disabled with the EncodingSpeed strategy.
+func WithPatchedBaseEncoding(enabled bool) WriterConfigFunc {
+	return func(w *Writer) error {
+		w.patchedBase = &enabled
+		return nil
+	}
+}
+
+// columnEncoding is the encoding of a column set by WithColumnEncoding.
+type columnEncoding struct {
+	kind     proto.ColumnEncoding_Kind
+	disabled []RLEEncodingType
+}
+
+// WithColumnEncoding sets the encoding of the top level column with the provided
+// name, overriding the automatic selection. String, char and varchar columns may
+// be pinned to either direct or dictionary encoding. Integer columns only support
+// direct encoding, however the sub-encodings of the run length encoding version 2,
+// other than RLEV2IntDirect itself, may be disabled in favour of DIRECT. An error
+// is returned when the writer is created if the column does not exist or the
+// encoding does not apply to its type or to the file version.
+func WithColumnEncoding(column string, kind proto.ColumnEncoding_Kind, disabled ...RLEEncodingType) WriterConfigFunc {
+	return func(w *Writer) error {
+		if column == "" {
+			return fmt.Errorf("invalid column encoding column %q", column)
+		}
+		if w.columnEncodings == nil {
+			w.columnEncodings = make(map[string]columnEncoding)
+		}
+		w.columnEncodings[column] = columnEncoding{
+			kind:     kind,
+			disabled: append([]RLEEncodingType{}, disabled...),
+		}
+		return nil
+	}
+}
+
+// initColumnEncodings validates the encodings set by WithColumnEncoding against
+// the schema, indexing them by the id of their column.
+func (w *Writer) initColumnEncodings() error {
+	w.columnEncodingsByID = make(map[int]columnEncoding)
+	for column, encoding := range w.columnEncodings {
+		index := -1
+		for i, name := range w.schema.fieldNames {
+			if name == column {
+				index = i
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("unknown column encoding column %s", column)
+		}
+		child := w.schema.children[index]
+		category := child.getCategory()
+		switch category {
+		case CategoryString, CategoryVarchar, CategoryChar:
+			if encoding.kind != w.version.directEncoding() && encoding.kind != w.version.dictionaryEncoding() {
+				return fmt.Errorf("invalid encoding %s for column %s of type %s and file version %s", encoding.kind, column, category, w.version.name)
+			}
+			if len(encoding.disabled) > 0 {
+				return fmt.Errorf("invalid disabled integer encodings for column %s of type %s", column, category)
+			}
+		case CategoryShort, CategoryInt, CategoryLong:
+			if encoding.kind != w.version.directEncoding() {
+				return fmt.Errorf("invalid encoding %s for column %s of type %s and file version %s", encoding.kind, column, category, w.version.name)
+			}
+			for _, disabled := range encoding.disabled {
+				if encoding.kind != proto.ColumnEncoding_DIRECT_V2 || disabled == RLEV2IntDirect || disabled < RLEV2IntShortRepeat || disabled > RLEV2IntDelta {
+					return fmt.Errorf("invalid disabled integer encoding %s for column %s with encoding %s", disabled, column, encoding.kind)
+				}
+			}
+		default:
+			return fmt.Errorf("cannot set the encoding of column %s of type %s", column, category)
+		}
+		w.columnEncodingsByID[child.getID()] = encoding
+	}
+	return nil
+}
+
+// encodingOptions returns the encodingOptions of the column with the provided id,
+// following the EncodingStrategy unless overridden by WithDictionaryKeyThreshold,
+// WithPatchedBaseEncoding or WithColumnEncoding.
+func (w *Writer) encodingOptions(id int) encodingOptions {
+	opts := encodingOptions{
+		dictionaryThreshold: DictionaryEncodingThreshold,
+		unsortedDictionary:  w.unsortedDictionaries,
+	}
+	if w.encodingStrategy == EncodingSpeed {
+		opts.dictionaryThreshold = 0
+	}
+	if w.dictionaryKeyThreshold != nil {
+		opts.dictionaryThreshold = *w.dictionaryKeyThreshold
+	}
+	patchedBase := w.encodingStrategy != EncodingSpeed
+	if w.patchedBase != nil {
+		patchedBase = *w.patchedBase
+	}
+	if !patchedBase {
+		opts.disabled = append(opts.disabled, RLEV2IntPatchedBase)
+	}
+	if encoding, ok := w.columnEncodingsByID[id]; ok {
+		switch encoding.kind {
+		case proto.ColumnEncoding_DICTIONARY, proto.ColumnEncoding_DICTIONARY_V2:
+			// The ratio of distinct values never exceeds 1.
+			opts.dictionaryThreshold = 1
+		default:
+			opts.dictionaryThreshold = 0
+		}
+		opts.disabled = append(opts.disabled, encoding.disabled...)
+	}
+	return opts
+}
+
 // NewWriter returns a new ORC file writer that writes to the provided io.Writer.
 func NewWriter(w io.Writer, fns ...WriterConfigFunc) (*Writer, error) {
 	// Construct the initial writer config, including the initial footer,
@@ -62,18 +534,25 @@ func NewWriter(w io.Writer, fns ...WriterConfigFunc) (*Writer, error) {
 		w:                w,
 		stripeOffset:     uint64(len(magic)),
 		stripeTargetSize: DefaultStripeTargetSize,
-		streams:          make(streamWriterMap),
+		paddingTolerance: DefaultPaddingTolerance,
+		now:              time.Now,
+		timezone:         localTimezone(),
+		version:          Version0_12,
 		statistics:       make(statisticsMap),
 		indexes:          make(map[int]*proto.RowIndex),
+		userMetadata:     make(map[string][]byte),
 		footer: &proto.Footer{
 			RowIndexStride: ptrUint32(DefaultRowIndexStride),
 			Statistics:     []*proto.ColumnStatistics{},
+			Writer:         ptrUint32(WriterGo),
+			Calendar:       proto.CalendarKind_PROLEPTIC_GREGORIAN.Enum(),
 		},
 		postScript: &proto.PostScript{
 			Magic:                ptrStr(magic),
 			CompressionBlockSize: ptrUint64(DefaultCompressionChunkSize),
 			Compression:          proto.CompressionKind_NONE.Enum(),
 			Version:              []uint32{Version0_12.major, Version0_12.minor},
+			WriterVersion:        ptrUint32(DefaultWriterVersion),
 		},
 		metadata: &proto.Metadata{
 			StripeStats: []*proto.StripeStatistics{},
@@ -103,26 +582,406 @@ func (w *Writer) getCodec() (CompressionCodec, error) {
 	}
 }
 
+// Write writes a single row to the current stripe, with one value per top level
+// column of the schema. A nil value, at any level of the schema, denotes a null
+// and is recorded in the PRESENT stream of its column rather than in the data
+// streams. It returns ErrWriterClosed if the Writer has been closed.
 func (w *Writer) Write(values ...interface{}) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if w.sortColumn != "" {
+		return w.bufferSorted(values)
+	}
+	return w.write(values)
+}
+
+// write writes a row to the current stripe, writing the stripe once it is full.
+func (w *Writer) write(values []interface{}) error {
 	w.stripeRows++
 	w.totalRows++
-	err := w.treeWriter.Write(values)
+	if w.stripeRows == 1 {
+		w.stripeStart = w.now()
+	}
+	err := w.writeRow(values)
+	if err != nil {
+		return err
+	}
+	return w.rowsWritten()
+}
+
+// WriteBatch writes the rows of a ColumnBatch to the current stripe. Integer, float,
+// string and boolean values are written directly to the column encoders without
+// boxing each of them in an interface, although rows are written one at a time
+// when using WithStripeSortColumn or WithEncoderParallelism. A batch may span any
+// number of row groups and stripes, however WithStripeMaxAge and the writer memory
+// limit are only checked at the end of each row group within the batch. It returns
+// ErrWriterClosed if the Writer has been closed.
+func (w *Writer) WriteBatch(b *ColumnBatch) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	rows, err := b.check(w.schema)
 	if err != nil {
 		return err
 	}
-	if w.totalRows%uint64(w.footer.GetRowIndexStride()) == 0 {
+	if _, ok := w.treeWriter.(*StructTreeWriter); !ok || w.parallelism > 1 || w.sortColumn != "" {
+		for i := 0; i < rows; i++ {
+			values, err := b.row(w.schema, i)
+			if err != nil {
+				return err
+			}
+			if err := w.Write(values...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	stride := uint64(w.footer.GetRowIndexStride())
+	for start := 0; start < rows; {
+		// Write up to the end of the current row group.
+		end := start + int(stride-w.stripeRows%stride)
+		if end > rows {
+			end = rows
+		}
+		if w.stripeRows == 0 {
+			w.stripeStart = w.now()
+		}
+		// The TreeWriters are replaced each time a stripe is written.
+		s := w.treeWriter.(*StructTreeWriter)
+		for i := start; i < end; i++ {
+			if err := s.BaseTreeWriter.Write(true); err != nil {
+				return err
+			}
+		}
+		for i, child := range s.children {
+			if err := writeVector(child, w.schema.children[i].getCategory(), b.Columns[i], start, end); err != nil {
+				return err
+			}
+		}
+		w.stripeRows += uint64(end - start)
+		w.totalRows += uint64(end - start)
+		if err := w.rowsWritten(); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// rowsWritten completes the row group once the rows written to the current stripe
+// reach the row index stride, writing the stripe once it is full, and flushes the
+// stripe once it expires or the memory limit is reached.
+func (w *Writer) rowsWritten() error {
+	if w.stripeRows%uint64(w.footer.GetRowIndexStride()) == 0 {
+		if err := w.writePending(); err != nil {
+			return err
+		}
 		w.recordPositions()
 		if err := w.flushWriters(); err != nil {
 			return err
 		}
-		if w.streams.size() >= w.stripeTargetSize {
+		if w.stripeFull() {
 			return w.writeStripe()
 		}
 	}
+	if w.stripeExpired() || (w.memoryLimit > 0 && w.MemoryUsage() >= w.memoryLimit) {
+		_, err := w.Flush()
+		return err
+	}
+	return nil
+}
+
+// bufferSorted adds a copy of a row to the rows buffered for sorting by the stripe
+// sort column, writing the buffered rows once their size reaches the stripe target
+// size, their age reaches the stripe max age or the memory limit is reached.
+func (w *Writer) bufferSorted(values []interface{}) error {
+	if len(values) != len(w.schema.children) {
+		return fmt.Errorf("wrong number of values, expected: %v, got: %v", len(w.schema.children), len(values))
+	}
+	key, err := sortKey(w.sortCategory, values[w.sortIndex])
+	if err != nil {
+		return err
+	}
+	if len(w.sorted) == 0 {
+		w.sortedStart = w.now()
+	}
+	w.sorted = append(w.sorted, sortedRow{
+		key:    key,
+		values: append([]interface{}{}, values...),
+	})
+	for _, v := range values {
+		w.sortedSize += estimateSize(v)
+	}
+	if w.sortedSize >= w.stripeTargetSize ||
+		(w.stripeMaxAge > 0 && w.now().Sub(w.sortedStart) >= w.stripeMaxAge) ||
+		(w.memoryLimit > 0 && w.MemoryUsage() >= w.memoryLimit) {
+		_, err := w.Flush()
+		return err
+	}
+	return nil
+}
+
+// writeSorted sorts the rows buffered by bufferSorted and writes them.
+func (w *Writer) writeSorted() error {
+	rows, size := w.sorted, w.sortedSize
+	w.sorted = nil
+	w.sortedSize = 0
+	sortRows(rows, w.sortNullsLast)
+	start := w.streamSize()
+	for _, row := range rows {
+		if err := w.write(row.values); err != nil {
+			return err
+		}
+	}
+	w.addEncodedRows(size, w.streamSize()-start)
+	return nil
+}
+
+// initSort resolves the stripe sort column, if set, within the schema.
+func (w *Writer) initSort() error {
+	if w.sortColumn == "" {
+		return nil
+	}
+	for i, name := range w.schema.fieldNames {
+		if name != w.sortColumn {
+			continue
+		}
+		switch category := w.schema.children[i].getCategory(); category {
+		case CategoryStruct, CategoryList, CategoryMap, CategoryUnion:
+			return fmt.Errorf("cannot sort stripes by column %s of type %s", w.sortColumn, category)
+		default:
+			w.sortIndex = i
+			w.sortCategory = category
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown stripe sort column %s", w.sortColumn)
+}
+
+// MemoryUsage returns the approximate number of bytes held in memory by the column
+// buffers of the current stripe, including the dictionaries of string columns and
+// any rows buffered for sorting by WithStripeSortColumn. The fixed size buffers
+// used to chunk each stream are excluded.
+func (w *Writer) MemoryUsage() int64 {
+	return w.treeWriters.memoryUsage() + w.sortedSize
+}
+
+// EstimatedSize returns an estimate of the length of the file once the rows written
+// so far are flushed as a stripe, being the number of bytes already written along
+// with the estimated size of the current stripe. The size of the stripe is estimated
+// from the length of its streams and the size of any rows buffered for sorting or
+// encoding concurrently, using the ratios of these to the size of the stripes
+// already written. Once the Writer is closed it returns the length of the file.
+func (w *Writer) EstimatedSize() int64 {
+	if w.closed {
+		return int64(w.stripeOffset + w.postScript.GetMetadataLength() + w.postScript.GetFooterLength() + uint64(w.postScriptLength) + 1)
+	}
+	streams := float64(w.treeWriters.size())
+	if rows := w.sortedSize + w.pendingSize; rows > 0 {
+		streams += float64(rows) * sizeRatio(w.rowStreamBytes, w.rowBytes)
+	}
+	return int64(w.stripeOffset) + int64(streams*sizeRatio(w.stripeBytes, w.streamBytes))
+}
+
+// sizeRatio returns a divided by b, or 1 if b is zero.
+func sizeRatio(a, b int64) float64 {
+	if b == 0 {
+		return 1
+	}
+	return float64(a) / float64(b)
+}
+
+// streamSize returns the total length of the streams of the stripes written along
+// with those of the current stripe.
+func (w *Writer) streamSize() int64 {
+	return w.streamBytes + w.treeWriters.size()
+}
+
+// addEncodedRows adds buffered rows of the estimated size that were encoded to
+// streams of the provided length to the ratio used by EstimatedSize.
+func (w *Writer) addEncodedRows(size, streams int64) {
+	w.rowBytes += size
+	w.rowStreamBytes += streams
+}
+
+// NumRows returns the number of rows written, including any rows buffered for
+// sorting by WithStripeSortColumn.
+func (w *Writer) NumRows() uint64 {
+	return w.totalRows + uint64(len(w.sorted))
+}
+
+// NumStripes returns the number of stripes written.
+func (w *Writer) NumStripes() int {
+	return len(w.footer.Stripes)
+}
+
+// parallelWriter returns the root StructTreeWriter if its columns are encoded
+// concurrently.
+func (w *Writer) parallelWriter() (*StructTreeWriter, bool) {
+	if w.parallelism < 2 {
+		return nil, false
+	}
+	s, ok := w.treeWriter.(*StructTreeWriter)
+	return s, ok
+}
+
+// writeRow writes the values of a row to the root TreeWriter, or when encoding
+// concurrently adds a copy of them to the pending rows, encoding the pending rows
+// once a full batch is buffered.
+func (w *Writer) writeRow(values []interface{}) error {
+	s, ok := w.parallelWriter()
+	if !ok {
+		return w.treeWriter.Write(values)
+	}
+	if len(values) != len(s.children) {
+		return fmt.Errorf("wrong number of values, expected: %v, got: %v", len(s.children), len(values))
+	}
+	w.pending = append(w.pending, append([]interface{}{}, values...))
+	for _, v := range values {
+		w.pendingSize += estimateSize(v)
+	}
+	if len(w.pending) >= parallelBatchSize {
+		return w.writePending()
+	}
+	return nil
+}
+
+// writePending encodes any rows pending when encoding concurrently.
+func (w *Writer) writePending() error {
+	s, ok := w.parallelWriter()
+	if !ok || len(w.pending) == 0 {
+		return nil
+	}
+	rows, size := w.pending, w.pendingSize
+	w.pending = nil
+	w.pendingSize = 0
+	start := w.streamSize()
+	for _, row := range rows {
+		if err := s.BaseTreeWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	err := w.forEachColumn(s, func(i int, child TreeWriter) error {
+		for _, row := range rows {
+			if err := child.Write(row[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	w.addEncodedRows(size, w.streamSize()-start)
+	return nil
+}
+
+// forEachColumn calls fn with each child of s using up to the encoder parallelism
+// number of goroutines, returning the error of the first column to fail.
+func (w *Writer) forEachColumn(s *StructTreeWriter, fn func(i int, child TreeWriter) error) error {
+	errs := make([]error, len(s.children))
+	columns := make(chan int)
+	var wg sync.WaitGroup
+	for j := 0; j < w.parallelism; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range columns {
+				errs[i] = fn(i, s.children[i])
+			}
+		}()
+	}
+	for i := range s.children {
+		columns <- i
+	}
+	close(columns)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripeExpired returns true if the first row of the current stripe was written
+// longer ago than the stripe max age.
+func (w *Writer) stripeExpired() bool {
+	return w.stripeMaxAge > 0 && w.stripeRows > 0 && w.now().Sub(w.stripeStart) >= w.stripeMaxAge
+}
+
+// stripeFull returns true if the current stripe has reached the stripe target
+// size or, when the block size is set, if another row group is expected to take
+// the stripe beyond the end of the block it will be written to. When the space
+// remaining in the current block is within the padding tolerance the stripe is
+// instead padded to start at the next block.
+func (w *Writer) stripeFull() bool {
+	size := w.treeWriters.size()
+	if size >= w.stripeTargetSize {
+		return true
+	}
+	if w.blockSize <= 0 {
+		return false
+	}
+	remaining := w.blockSize - int64(w.stripeOffset)%w.blockSize
+	if float64(remaining) <= w.paddingTolerance*float64(w.blockSize) {
+		// The stripe will be padded to start at the next block.
+		remaining = w.blockSize
+	}
+	// Include the row indexes written ahead of the streams of the stripe.
+	err := w.treeWriters.forEach(func(id int, t TreeWriter) error {
+		size += int64(gproto.Size(t.RowIndex()))
+		return nil
+	})
+	if err != nil {
+		return false
+	}
+	rowGroups := int64(w.stripeRows / uint64(w.footer.GetRowIndexStride()))
+	return size+size/rowGroups >= remaining
+}
+
+// padStripe pads the file with zero bytes up to the next block boundary if a
+// stripe of the provided length would otherwise cross it and the padding is
+// within the padding tolerance.
+func (w *Writer) padStripe(length uint64) error {
+	if w.blockSize <= 0 {
+		return nil
+	}
+	blockSize := uint64(w.blockSize)
+	padding := blockSize - w.stripeOffset%blockSize
+	if length <= padding || float64(padding) > w.paddingTolerance*float64(blockSize) {
+		return nil
+	}
+	if _, err := w.w.Write(make([]byte, padding)); err != nil {
+		return err
+	}
+	w.stripeOffset += padding
+	return nil
+}
+
+// AddUserMetadata adds a key and binary value to the user metadata written to the
+// footer of the file. It may be called at any time before Close and if the same
+// key is added more than once the last value is written. It returns
+// ErrWriterClosed if the Writer has been closed.
+func (w *Writer) AddUserMetadata(key string, value []byte) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.userMetadata[key] = append([]byte{}, value...)
 	return nil
 }
 
 func (w *Writer) init() error {
+	if err := w.initSort(); err != nil {
+		return err
+	}
+	if err := w.initRLEVersion(); err != nil {
+		return err
+	}
+	if err := w.initColumnEncodings(); err != nil {
+		return err
+	}
 	if err := w.initOrc(); err != nil {
 		return err
 	}
@@ -132,6 +991,19 @@ func (w *Writer) init() error {
 	return nil
 }
 
+// initRLEVersion applies the run length encoding version set by WithRLEVersion to
+// the file version.
+func (w *Writer) initRLEVersion() error {
+	if w.rleVersion == 0 {
+		return nil
+	}
+	if w.rleVersion > w.version.rle {
+		return fmt.Errorf("run length encoding version %d is not supported by file version %s", w.rleVersion, w.version.name)
+	}
+	w.version.rle = w.rleVersion
+	return nil
+}
+
 func (w *Writer) initOrc() error {
 	_, err := w.w.Write([]byte(magic))
 	if err != nil {
@@ -146,7 +1018,7 @@ func (w *Writer) initWriters() error {
 	if err != nil {
 		return err
 	}
-	w.treeWriter, err = createTreeWriter(codec, w.schema, w.treeWriters)
+	w.treeWriter, err = createTreeWriter(codec, w.schema, w.treeWriters, w)
 	if err != nil {
 		return err
 	}
@@ -154,10 +1026,26 @@ func (w *Writer) initWriters() error {
 }
 
 func (w *Writer) closeWriters() error {
+	if s, ok := w.parallelWriter(); ok {
+		if err := s.BaseTreeWriter.Close(); err != nil {
+			return err
+		}
+		return w.forEachColumn(s, func(i int, child TreeWriter) error {
+			return child.Close()
+		})
+	}
 	return w.treeWriter.Close()
 }
 
 func (w *Writer) flushWriters() error {
+	if s, ok := w.parallelWriter(); ok {
+		if err := s.BaseTreeWriter.Flush(); err != nil {
+			return err
+		}
+		return w.forEachColumn(s, func(i int, child TreeWriter) error {
+			return child.Flush()
+		})
+	}
 	return w.treeWriter.Flush()
 }
 
@@ -170,8 +1058,9 @@ func (w *Writer) writePostScript() error {
 	if err != nil {
 		return err
 	}
-	if len(byt) > maxPostScriptSize {
-		return fmt.Errorf("postscript larger than max allowed size of %v bytes: %v", maxPostScriptSize, len(byt))
+	// The length of the postscript is written as a single byte.
+	if len(byt) > math.MaxUint8 {
+		return fmt.Errorf("postscript larger than max allowed size of %v bytes: %v", math.MaxUint8, len(byt))
 	}
 	_, err = w.w.Write(byt)
 	if err != nil {
@@ -182,13 +1071,65 @@ func (w *Writer) writePostScript() error {
 	if err != nil {
 		return err
 	}
+	w.postScriptLength = uint8(len(byt))
 	return nil
 }
 
+// writeTail writes the metadata, footer and postscript of the file for the
+// stripes written so far.
+func (w *Writer) writeTail() error {
+	if err := w.writeMetadata(); err != nil {
+		return err
+	}
+	if err := w.writeFooter(); err != nil {
+		return err
+	}
+	return w.writePostScript()
+}
+
+// WriteIntermediateFooter writes the metadata, footer and postscript for the
+// stripes written so far, so that the file truncated to the returned length can
+// be read as a complete ORC file whilst writing continues. Rows that have not yet
+// been written as part of a stripe, such as by calling Flush, are not included.
+// Subsequent stripes are written after the intermediate tail, which is left
+// unreferenced by the complete file written by Close.
+func (w *Writer) WriteIntermediateFooter() (int64, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	if err := w.writeTail(); err != nil {
+		return 0, err
+	}
+	w.stripeOffset += w.postScript.GetMetadataLength() + w.postScript.GetFooterLength() + uint64(w.postScriptLength) + 1
+	return int64(w.stripeOffset), nil
+}
+
+// userMetadataItems returns the user metadata ordered by key.
+func (w *Writer) userMetadataItems() []*proto.UserMetadataItem {
+	keys := make([]string, 0, len(w.userMetadata))
+	for key := range w.userMetadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]*proto.UserMetadataItem, len(keys))
+	for i, key := range keys {
+		items[i] = &proto.UserMetadataItem{
+			Name:  ptrStr(key),
+			Value: w.userMetadata[key],
+		}
+	}
+	return items
+}
+
 func (w *Writer) writeFooter() error {
-	totalRows := w.totalRows
+	// Only count the rows of the stripes written so far.
+	var totalRows uint64
+	for _, stripe := range w.footer.Stripes {
+		totalRows += stripe.GetNumberOfRows()
+	}
 	w.footer.NumberOfRows = &totalRows
 	w.footer.Statistics = w.statistics.statistics()
+	w.footer.Metadata = w.userMetadataItems()
 	byt, err := gproto.Marshal(w.footer)
 	if err != nil {
 		return err
@@ -218,21 +1159,25 @@ func (w *Writer) writeMetadata() error {
 
 func (w *Writer) writeStripe() error {
 
+	streamBytes := w.treeWriters.size()
+
 	// Close the current set of writers.
 	if err := w.closeWriters(); err != nil {
 		return err
 	}
 
-	// Write each stream to the underlying writer.
+	// Determine each stream of the stripe prior to writing so that the length
+	// of the stripe is known in advance of padding.
 	var streams []*proto.Stream
+	var indexes [][]byte
+	var buffers []*BufferedWriter
 	var stripeIndexLength uint64
 	var stripeDataLength uint64
 	stripeStatistics := make(statisticsMap)
 
-	// Iterate through the TreeWriters and write their output
-	// to the underlying writer.
+	// Iterate through the TreeWriters and marshal the rowIndex
+	// for each column.
 	err := w.treeWriters.forEach(func(id int, t TreeWriter) error {
-		// First write the rowIndex for the column.
 		rowIndex := t.RowIndex()
 		byt, err := gproto.Marshal(rowIndex)
 		if err != nil {
@@ -245,10 +1190,7 @@ func (w *Writer) writeStripe() error {
 			Length: ptrUint64(uint64(len(byt))),
 		}
 		streams = append(streams, streamInfo)
-		_, err = w.w.Write(byt)
-		if err != nil {
-			return err
-		}
+		indexes = append(indexes, byt)
 		// Add to the running stripe statistics.
 		stripeStatistics.add(id, t.Statistics())
 		return nil
@@ -258,7 +1200,7 @@ func (w *Writer) writeStripe() error {
 	}
 
 	err = w.treeWriters.forEach(func(id int, t TreeWriter) error {
-		// Then write the streams.
+		// Then the data streams.
 		for _, stream := range t.Streams() {
 			// Get the length of the stream and its kind.
 			length := stream.buffer.Len()
@@ -275,10 +1217,7 @@ func (w *Writer) writeStripe() error {
 			}
 			stripeDataLength += uint64(length)
 			streams = append(streams, streamInfo)
-			_, err := stream.buffer.WriteTo(w.w)
-			if err != nil {
-				return err
-			}
+			buffers = append(buffers, stream.buffer)
 		}
 		return nil
 	})
@@ -286,18 +1225,37 @@ func (w *Writer) writeStripe() error {
 		return err
 	}
 
-	// Create a stripe footer and write it to the underlying writer.
+	// Create the stripe footer.
 	stripeFooter := &proto.StripeFooter{
-		Streams: streams,
-		Columns: w.treeWriters.encodings(),
+		Streams:        streams,
+		Columns:        w.treeWriters.encodings(),
+		WriterTimezone: ptrStr(w.timezone.String()),
 	}
 
-	byt, err := gproto.Marshal(stripeFooter)
+	footer, err := gproto.Marshal(stripeFooter)
 	if err != nil {
 		return err
 	}
-	_, err = w.w.Write(byt)
-	if err != nil {
+	footerLength := uint64(len(footer))
+
+	// Pad to the next block boundary if the stripe would otherwise cross it.
+	if err := w.padStripe(stripeIndexLength + stripeDataLength + footerLength); err != nil {
+		return err
+	}
+
+	// Write the row indexes, the streams and the stripe footer to the
+	// underlying writer.
+	for _, byt := range indexes {
+		if _, err := w.w.Write(byt); err != nil {
+			return err
+		}
+	}
+	for _, buffer := range buffers {
+		if _, err := buffer.WriteTo(w.w); err != nil {
+			return err
+		}
+	}
+	if _, err := w.w.Write(footer); err != nil {
 		return err
 	}
 
@@ -307,7 +1265,6 @@ func (w *Writer) writeStripe() error {
 	w.stripeIndexOffset = 0
 
 	// Append stripe information to the footer
-	footerLength := uint64(len(byt))
 	offset := w.stripeOffset
 	w.footer.Stripes = append(w.footer.Stripes, &proto.StripeInformation{
 		Offset:       &offset,
@@ -318,34 +1275,98 @@ func (w *Writer) writeStripe() error {
 	})
 
 	// Update the stripe offset for the next stripe
-	w.stripeOffset += stripeDataLength + footerLength
+	w.stripeOffset += stripeIndexLength + stripeDataLength + footerLength
 
-	// Add stripe statistics to metadata
+	// Add stripe statistics to metadata, copying them as the stripe statistics
+	// are merged into, and become part of, the file statistics below.
+	colStats := stripeStatistics.statistics()
+	for i := range colStats {
+		colStats[i] = gproto.Clone(colStats[i]).(*proto.ColumnStatistics)
+	}
 	w.metadata.StripeStats = append(w.metadata.StripeStats, &proto.StripeStatistics{
-		ColStats: stripeStatistics.statistics(),
+		ColStats: colStats,
 	})
 
 	// Merge the stripe statistics with the total statistics.
 	w.statistics.merge(stripeStatistics)
 
+	w.streamBytes += streamBytes
+	w.stripeBytes += int64(stripeIndexLength + stripeDataLength + footerLength)
+
 	return w.initWriters()
 }
 
-func (w *Writer) Close() error {
+// Flush writes the rows buffered in the current stripe, if any, to the underlying
+// writer, starting a new stripe for subsequent rows. It returns the offset in
+// bytes that the file has been written up to.
+func (w *Writer) Flush() (uint64, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	if err := w.writeSorted(); err != nil {
+		return 0, err
+	}
+	if w.stripeRows == 0 {
+		return w.stripeOffset, nil
+	}
+	if err := w.writePending(); err != nil {
+		return 0, err
+	}
 	w.recordPositions()
 	if err := w.writeStripe(); err != nil {
-		return err
+		return 0, err
 	}
-	if err := w.writeMetadata(); err != nil {
-		return err
+	return w.stripeOffset, nil
+}
+
+// Close writes the final stripe along with the metadata, footer and postscript of
+// the file, returning the first error returned by the underlying writer. A Writer
+// that has not been written any rows produces a valid file of zero rows and no
+// stripes. Calling Close again has no effect and returns the result of the first
+// call, and once closed Write, Flush, AddUserMetadata and WriteIntermediateFooter
+// return ErrWriterClosed. Close does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return w.closeErr
 	}
-	if err := w.writeFooter(); err != nil {
+	w.closed = true
+	w.closeErr = w.close()
+	return w.closeErr
+}
+
+func (w *Writer) close() error {
+	if err := w.writeSorted(); err != nil {
 		return err
 	}
-	if err := w.writePostScript(); err != nil {
-		return err
+	// Write the final stripe unless it is empty, so that a file without any rows
+	// has no stripes.
+	if w.stripeRows > 0 {
+		if err := w.writePending(); err != nil {
+			return err
+		}
+		w.recordPositions()
+		if err := w.writeStripe(); err != nil {
+			return err
+		}
+	} else if len(w.footer.Stripes) == 0 {
+		// Still record the empty statistics of every column in the file footer.
+		err := w.treeWriters.forEach(func(id int, t TreeWriter) error {
+			w.statistics.add(id, t.Statistics())
+			return nil
+		})
+		if err != nil {
+			return err
+		}
 	}
-	return nil
+	return w.writeTail()
+}
+
+func ptrFloat64(f float64) *float64 {
+	return &f
+}
+
+func ptrInt32(i int32) *int32 {
+	return &i
 }
 
 func ptrUint32(u uint32) *uint32 {
@@ -4,10 +4,21 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	// "encoding/json"
+	"math"
+	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"testing/quick"
+	"time"
+
+	"code.simon-critchley.co.uk/orc/proto"
+	gproto "github.com/golang/protobuf/proto"
 )
 
 type bytesSizedReaderAt struct {
@@ -100,3 +111,3509 @@ func TestWriter(t *testing.T) {
 	}
 
 }
+
+func TestWriterBooleanAndByte(t *testing.T) {
+
+	schema, err := ParseSchema("struct<boolean1:boolean,byte1:tinyint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use a row count that is not a multiple of 8 so that the
+	// final byte of each boolean stream is padded.
+	length := 1001
+	expected := make([][]interface{}, length)
+	for i := 0; i < length; i++ {
+		var boolean1, byte1 interface{}
+		if i%7 != 0 {
+			boolean1 = i%3 == 0
+		}
+		if i%5 != 0 {
+			byte1 = int8(i)
+		}
+		expected[i] = []interface{}{boolean1, byte1}
+		err = w.Write(boolean1, byte1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Select("boolean1", "byte1")
+	row := 0
+	for c.Stripes() {
+		for c.Next() {
+			if row >= length {
+				t.Fatalf("Test failed, read more than %v rows", length)
+			}
+			if !reflect.DeepEqual(expected[row], c.Row()) {
+				t.Errorf("Test failed, expected %v got %v at row %v", expected[row], c.Row(), row)
+			}
+			row++
+		}
+	}
+
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if row != length {
+		t.Errorf("Test failed, expected %v rows got %v", length, row)
+	}
+
+}
+
+func TestWriterSuppressesPresentStream(t *testing.T) {
+
+	schema, err := ParseSchema("struct<boolean1:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		err = w.Write(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stripe := range r.footer.GetStripes() {
+		stripeFooter, err := r.getStripeFooter(stripe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetKind() == proto.Stream_PRESENT {
+				t.Errorf("Test failed, unexpected present stream for column %v", stream.GetColumn())
+			}
+		}
+	}
+
+}
+
+func TestWriterNulls(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string,double1:double,boolean1:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each column has a different null pattern, with the first null of each
+	// falling part way through a later row group of the stripe.
+	const numRows = 1000
+	rowValues := func(i int) []interface{} {
+		row := []interface{}{int64(i), fmt.Sprintf("row %d", i), Double(i) / 2, i%2 == 0}
+		if i >= 250 && i%3 == 0 {
+			row[0] = nil
+		}
+		if i%7 == 5 {
+			row[1] = nil
+		}
+		if i > 990 {
+			row[2] = nil
+		}
+		if i >= 100 && i < 200 {
+			row[3] = nil
+		}
+		return row
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.footer.RowIndexStride = ptrUint32(100)
+
+	for i := 0; i < numRows; i++ {
+		err = w.Write(rowValues(i)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Select("int1", "string1", "double1", "boolean1")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			if expected, row := rowValues(i), c.Row(); !reflect.DeepEqual(row, expected) {
+				t.Fatalf("Test failed, row %d expected %v got %v", i, expected, row)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if i != numRows {
+		t.Errorf("Test failed, expected %d rows got %d", numRows, i)
+	}
+
+}
+
+// presentStreams returns, for each stripe of r, the ids of the columns with a
+// present stream.
+func presentStreams(t *testing.T, r *Reader) [][]uint32 {
+	var columns [][]uint32
+	for _, stripe := range r.footer.GetStripes() {
+		stripeFooter, err := r.getStripeFooter(stripe)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []uint32{}
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetKind() == proto.Stream_PRESENT {
+				ids = append(ids, stream.GetColumn())
+			}
+		}
+		columns = append(columns, ids)
+	}
+	return columns
+}
+
+func TestWriterPresentStreams(t *testing.T) {
+
+	element := func(i int) interface{} {
+		switch i % 5 {
+		case 0:
+			return nil
+		case 1:
+			return []interface{}{nil, fmt.Sprintf("element %d", i)}
+		default:
+			return []interface{}{i, fmt.Sprintf("element %d", i)}
+		}
+	}
+	readElement := func(v interface{}) interface{} {
+		if v == nil {
+			return nil
+		}
+		values := v.([]interface{})
+		if values[0] != nil {
+			values = []interface{}{int64(values[0].(int)), values[1]}
+		}
+		return Struct{"int1": values[0], "string1": values[1]}
+	}
+
+	testCases := []struct {
+		name   string
+		schema string
+		// row returns the values written and read for row i.
+		row func(i int) (written, read []interface{})
+		// flushAt, if set, writes each stripe before the row.
+		flushAt []int
+		present [][]uint32
+	}{
+		{
+			name:   "no nulls",
+			schema: "struct<int1:int,string1:string>",
+			row: func(i int) ([]interface{}, []interface{}) {
+				return []interface{}{int64(i), fmt.Sprint(i)}, []interface{}{int64(i), fmt.Sprint(i)}
+			},
+			present: [][]uint32{{}},
+		},
+		{
+			name:   "all null",
+			schema: "struct<int1:int,string1:string>",
+			row: func(i int) ([]interface{}, []interface{}) {
+				return []interface{}{nil, fmt.Sprint(i)}, []interface{}{nil, fmt.Sprint(i)}
+			},
+			present: [][]uint32{{1}},
+		},
+		{
+			name:   "nulls in the last row group",
+			schema: "struct<int1:int,string1:string>",
+			row: func(i int) ([]interface{}, []interface{}) {
+				if i >= 950 && i%2 == 0 {
+					return []interface{}{int64(i), nil}, []interface{}{int64(i), nil}
+				}
+				return []interface{}{int64(i), fmt.Sprint(i)}, []interface{}{int64(i), fmt.Sprint(i)}
+			},
+			present: [][]uint32{{2}},
+		},
+		{
+			name:   "nulls in the second stripe",
+			schema: "struct<int1:int,string1:string>",
+			row: func(i int) ([]interface{}, []interface{}) {
+				if i == 600 {
+					return []interface{}{nil, fmt.Sprint(i)}, []interface{}{nil, fmt.Sprint(i)}
+				}
+				return []interface{}{int64(i), fmt.Sprint(i)}, []interface{}{int64(i), fmt.Sprint(i)}
+			},
+			flushAt: []int{500},
+			present: [][]uint32{{}, {1}},
+		},
+		{
+			name:   "nested nulls",
+			schema: "struct<list1:array<struct<int1:int,string1:string>>>",
+			row: func(i int) ([]interface{}, []interface{}) {
+				if i%7 == 3 {
+					return []interface{}{nil}, []interface{}{nil}
+				}
+				var written, read []interface{}
+				for j := 0; j < i%4; j++ {
+					written = append(written, element(i+j))
+					read = append(read, readElement(element(i+j)))
+				}
+				if written == nil {
+					return []interface{}{[]interface{}{}}, []interface{}{[]interface{}{}}
+				}
+				return []interface{}{written}, []interface{}{read}
+			},
+			present: [][]uint32{{1, 2, 3}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			schema, err := ParseSchema(tc.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, SetSchema(schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.footer.RowIndexStride = ptrUint32(100)
+
+			const numRows = 1000
+			for i := 0; i < numRows; i++ {
+				for _, n := range tc.flushAt {
+					if n == i {
+						if _, err := w.Flush(); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+				written, _ := tc.row(i)
+				if err := w.Write(written...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(&bytesSizedReaderAt{&buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if present := presentStreams(t, r); !reflect.DeepEqual(present, tc.present) {
+				t.Errorf("Test failed, expected present streams for columns %v got %v", tc.present, present)
+			}
+
+			c := r.Select(schema.Columns()...)
+			var i int
+			for c.Stripes() {
+				for c.Next() {
+					if _, expected := tc.row(i); !reflect.DeepEqual(c.Row(), expected) {
+						t.Fatalf("Test failed, row %d expected %v got %v", i, expected, c.Row())
+					}
+					i++
+				}
+			}
+			if err := c.Err(); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if i != numRows {
+				t.Errorf("Test failed, expected %d rows got %d", numRows, i)
+			}
+		})
+	}
+
+}
+
+func TestWriterFloatRoundTrip(t *testing.T) {
+
+	schema, err := ParseSchema("struct<float1:float,double1:double>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Values are compared as raw bits so negative zero and NaN
+	// payloads must be preserved exactly.
+	f := func(floatBits []uint32, doubleBits []uint64) bool {
+		length := len(floatBits)
+		if len(doubleBits) < length {
+			length = len(doubleBits)
+		}
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, SetSchema(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < length; i++ {
+			var float1, double1 interface{}
+			if i%11 != 0 {
+				float1 = math.Float32frombits(floatBits[i])
+			}
+			if i%13 != 0 {
+				double1 = math.Float64frombits(doubleBits[i])
+			}
+			err = w.Write(float1, double1)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = w.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(&bytesSizedReaderAt{&buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := r.Select("float1", "double1")
+		row := 0
+		for c.Stripes() {
+			for c.Next() {
+				if row >= length {
+					return false
+				}
+				values := c.Row()
+				if i := row % 11; i == 0 && values[0] != nil {
+					return false
+				} else if i != 0 && math.Float32bits(float32(values[0].(Float))) != floatBits[row] {
+					return false
+				}
+				if i := row % 13; i == 0 && values[1] != nil {
+					return false
+				} else if i != 0 && math.Float64bits(float64(values[1].(Double))) != doubleBits[row] {
+					return false
+				}
+				row++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return row == length
+	}
+
+	err = quick.Check(f, &quick.Config{MaxCount: 50})
+	if err != nil {
+		t.Error(err)
+	}
+
+	special := []uint64{
+		math.Float64bits(math.Copysign(0, -1)),
+		math.Float64bits(math.Inf(1)),
+		math.Float64bits(math.Inf(-1)),
+		math.Float64bits(math.NaN()),
+		0x7ff0000000000001, // Signalling NaN
+		0xfff8000000000000, // Negative NaN
+	}
+	specialFloat := []uint32{
+		math.Float32bits(float32(math.Copysign(0, -1))),
+		math.Float32bits(float32(math.Inf(1))),
+		math.Float32bits(float32(math.Inf(-1))),
+		0x7fc00000, // NaN
+		0x7f800001, // Signalling NaN
+		0xffc00000, // Negative NaN
+	}
+	// Prefix a dummy value as the first row is always null.
+	if !f(append([]uint32{0}, specialFloat...), append([]uint64{0}, special...)) {
+		t.Errorf("Test failed, special values did not round trip")
+	}
+
+}
+
+func TestWriterDecimal(t *testing.T) {
+
+	schema, err := ParseSchema("struct<decimal1:decimal(38,10)>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []interface{}{
+		"9999999999999999999999999999.9999999999",
+		"-9999999999999999999999999999.9999999999",
+		nil,
+		"-12345.678",
+		big.NewRat(1, 8),
+		Decimal{big.NewInt(-8361232), 4},
+		"0",
+	}
+	expected := []interface{}{
+		"9999999999999999999999999999.9999999999",
+		"-9999999999999999999999999999.9999999999",
+		nil,
+		"-12345.6780000000",
+		"0.1250000000",
+		"-836.1232000000",
+		"0.0000000000",
+	}
+
+	for _, v := range input {
+		err = w.Write(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Select("decimal1")
+	var actual []interface{}
+	for c.Stripes() {
+		for c.Next() {
+			if v := c.Row()[0]; v != nil {
+				actual = append(actual, v.(Decimal).String())
+			} else {
+				actual = append(actual, nil)
+			}
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Test failed, expected %v got %v", expected, actual)
+	}
+
+	stats := r.footer.GetStatistics()[1].GetDecimalStatistics()
+	if stats.GetMinimum() != "-9999999999999999999999999999.9999999999" || stats.GetMaximum() != "9999999999999999999999999999.9999999999" {
+		t.Errorf("Test failed, unexpected statistics %v", stats)
+	}
+
+}
+
+func TestWriterDecimalRounding(t *testing.T) {
+
+	schema, err := ParseSchema("struct<decimal1:decimal(5,2)>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), SetDecimalRounding(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"1.005", "-2.004"} {
+		err = w.Write(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Select("decimal1")
+	var actual []string
+	for c.Stripes() {
+		for c.Next() {
+			actual = append(actual, c.Row()[0].(Decimal).String())
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"1.01", "-2.00"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Test failed, expected %v got %v", expected, actual)
+	}
+
+}
+
+func TestDecimalTreeWriterInvalid(t *testing.T) {
+
+	testCases := []struct {
+		value interface{}
+		round bool
+	}{
+		// Exceeds the scale.
+		{value: "1.001"},
+		// Exceeds the precision.
+		{value: "1000.00"},
+		// Exceeds the precision after rounding.
+		{value: "999.995", round: true},
+		// Unsupported types.
+		{value: 1.5},
+		{value: "1.5.5"},
+	}
+
+	for _, tc := range testCases {
+		w, err := NewDecimalTreeWriter(CategoryDecimal, CompressionNone{}, Version0_12, 5, 2, tc.round)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(tc.value); err == nil {
+			t.Errorf("Test failed, expected error writing %v", tc.value)
+		}
+	}
+
+}
+
+func TestWriterTimestamp(t *testing.T) {
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	input := []interface{}{
+		// Before 1970.
+		time.Date(1969, time.July, 20, 20, 17, 40, 123456789, time.UTC),
+		time.Date(1969, time.December, 31, 23, 59, 58, 500000000, time.UTC),
+		time.Date(1960, time.March, 1, 12, 0, 0, 999999, time.UTC),
+		time.Date(1900, time.May, 5, 12, 34, 56, 100000000, time.UTC),
+		time.Date(1950, time.January, 1, 0, 0, 0, 0, time.UTC),
+		// Before 2015 with 0, 3, 6 and 9 significant sub-second digits.
+		time.Date(2014, time.December, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2014, time.June, 30, 12, 0, 0, 123000000, time.UTC),
+		time.Date(2000, time.February, 29, 23, 59, 59, 123456000, time.UTC),
+		time.Date(1999, time.January, 1, 0, 0, 0, 999999999, time.UTC),
+		nil,
+		time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2038, time.January, 19, 3, 14, 8, 1, time.UTC),
+		time.Date(2037, time.January, 1, 0, 0, 0, 999000, time.UTC),
+	}
+	// Before 1970 with the nanoseconds encoded with each number of trailing
+	// zeros removed.
+	for nanos := 100; nanos < 1e9; nanos *= 10 {
+		input = append(input, time.Date(1969, time.December, 31, 23, 59, 58, nanos, time.UTC))
+	}
+
+	testCases := []*time.Location{time.UTC, newYork}
+
+	for _, loc := range testCases {
+
+		t.Run(loc.String(), func(t *testing.T) {
+
+			schema, err := ParseSchema("struct<timestamp1:timestamp>")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(loc))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, v := range input {
+				err = w.Write(v)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = w.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(&bytesSizedReaderAt{&buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stripeFooter, err := r.getStripeFooter(r.footer.GetStripes()[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tz := stripeFooter.GetWriterTimezone(); tz != loc.String() {
+				t.Errorf("Test failed, expected writer timezone %s got %s", loc, tz)
+			}
+
+			c := r.Select("timestamp1")
+			var i int
+			for c.Stripes() {
+				for c.Next() {
+					actual := c.Row()[0]
+					if input[i] == nil {
+						if actual != nil {
+							t.Errorf("Test failed, expected nil at row %d got %v", i, actual)
+						}
+					} else if ts, ok := actual.(time.Time); !ok || !ts.Equal(input[i].(time.Time)) {
+						t.Errorf("Test failed, expected %v at row %d got %v", input[i], i, actual)
+					} else if ts.Location().String() != loc.String() {
+						t.Errorf("Test failed, expected location %s at row %d got %s", loc, i, ts.Location())
+					}
+					i++
+				}
+			}
+			if err := c.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if i != len(input) {
+				t.Errorf("Test failed, expected %d rows got %d", len(input), i)
+			}
+		})
+
+	}
+}
+
+func TestWriterTimezone(t *testing.T) {
+
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	testCases := []struct {
+		tz       string
+		opts     []WriterConfigFunc
+		expected string
+	}{
+		{
+			tz:       "America/New_York",
+			expected: "America/New_York",
+		},
+		{
+			tz:       ":Europe/London",
+			expected: "Europe/London",
+		},
+		{
+			tz:       "/usr/share/zoneinfo/Asia/Tokyo",
+			expected: "Asia/Tokyo",
+		},
+		{
+			tz:       "",
+			expected: "UTC",
+		},
+		{
+			tz:       "Nowhere/Invalid",
+			expected: "UTC",
+		},
+		{
+			tz:       "America/New_York",
+			opts:     []WriterConfigFunc{WithWriterTimezone(time.UTC)},
+			expected: "UTC",
+		},
+		{
+			tz:       "Europe/London",
+			opts:     []WriterConfigFunc{WithWriterTimezone(time.Local)},
+			expected: "Europe/London",
+		},
+	}
+
+	schema, err := ParseSchema("struct<timestamp1:timestamp>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		t.Setenv("TZ", tc.tz)
+		loc, err := time.LoadLocation(tc.expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The seconds of a timestamp are encoded relative to midnight on 1 January
+		// 2015 in the recorded timezone, as by the Java writer.
+		ts := time.Date(2015, time.January, 1, 0, 0, 1, 0, loc)
+
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, tc.opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(ts); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stripeFooter, err := r.getStripeFooter(r.footer.GetStripes()[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tz := stripeFooter.GetWriterTimezone(); tz != tc.expected {
+			t.Errorf("Test failed, TZ %q expected writer timezone %s got %s", tc.tz, tc.expected, tz)
+		}
+		c := r.Select("timestamp1")
+		for c.Stripes() {
+			byt, err := ioutil.ReadAll(c.streams.get(streamName{1, proto.Stream_DATA}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			seconds := NewRunLengthIntegerReaderV2(bytes.NewReader(byt), true, false)
+			if !seconds.Next() || seconds.Int() != 1 {
+				t.Errorf("Test failed, TZ %q expected 1 second after the base timestamp", tc.tz)
+			}
+			for c.Next() {
+				actual, ok := c.Row()[0].(time.Time)
+				if !ok || !actual.Equal(ts) || actual.Format("2006-01-02 15:04:05") != "2015-01-01 00:00:01" {
+					t.Errorf("Test failed, TZ %q expected %v got %v", tc.tz, ts, c.Row()[0])
+				}
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, loc := range []*time.Location{nil, time.FixedZone("Custom", 3600)} {
+		if _, err := NewWriter(ioutil.Discard, SetSchema(schema), WithWriterTimezone(loc)); err == nil {
+			t.Errorf("Test failed, expected error for writer timezone %v", loc)
+		}
+	}
+
+}
+
+func TestWriterTimestampInstant(t *testing.T) {
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	schema, err := ParseSchema("struct<local:timestamp,instant:timestamp with local time zone>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := schema.String(); s != "struct<local:timestamp,instant:timestamp with local time zone>" {
+		t.Errorf("Test failed, got schema %s", s)
+	}
+
+	// One second after the base timestamp in both New York and UTC.
+	local := time.Date(2015, time.January, 1, 0, 0, 1, 0, newYork)
+	instant := time.Date(2015, time.January, 1, 0, 0, 1, 0, time.UTC)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithWriterTimezone(newYork))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(local, instant); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind := r.footer.GetTypes()[2].GetKind(); kind != proto.Type_TIMESTAMP_INSTANT {
+		t.Errorf("Test failed, expected kind %v got %v", proto.Type_TIMESTAMP_INSTANT, kind)
+	}
+	if category := r.Schema().children[1].getCategory(); category != CategoryTimestampInstant {
+		t.Errorf("Test failed, expected category %s got %s", CategoryTimestampInstant, category)
+	}
+
+	c := r.Select("local", "instant")
+	for c.Stripes() {
+		// The seconds of both columns are relative to the base timestamp in the
+		// timezone of their values, so the instant is not adjusted by the
+		// writer timezone.
+		for _, id := range []int{1, 2} {
+			byt, err := ioutil.ReadAll(c.streams.get(streamName{id, proto.Stream_DATA}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			seconds := NewRunLengthIntegerReaderV2(bytes.NewReader(byt), true, false)
+			if !seconds.Next() || seconds.Int() != 1 {
+				t.Errorf("Test failed, expected column %d to be 1 second after the base timestamp", id)
+			}
+		}
+	}
+
+	// The stripes of a Reader are read once, so the rows are read by another.
+	r, err = NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = r.Select("local", "instant")
+	var rows int
+	for c.Stripes() {
+		for c.Next() {
+			row := c.Row()
+			if rows == 0 {
+				if ts, ok := row[0].(time.Time); !ok || !ts.Equal(local) || ts.Location().String() != newYork.String() {
+					t.Errorf("Test failed, expected %v got %v", local, row[0])
+				}
+				if ts, ok := row[1].(time.Time); !ok || !ts.Equal(instant) || ts.Location() != time.UTC {
+					t.Errorf("Test failed, expected %v got %v", instant, row[1])
+				}
+			} else if row[0] != nil || row[1] != nil {
+				t.Errorf("Test failed, expected nulls got %v", row)
+			}
+			rows++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if rows != 2 {
+		t.Errorf("Test failed, expected 2 rows got %d", rows)
+	}
+
+}
+
+func TestWriterDate(t *testing.T) {
+
+	schema, err := ParseSchema("struct<date1:date>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A time.Time is written as its date in its own location, which is the day
+	// before its date in UTC.
+	tokyo := time.FixedZone("JST", 9*3600)
+	input := []interface{}{
+		Date{time.Date(2017, 3, 1, 0, 0, 0, 0, time.UTC)},
+		nil,
+		Date{time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+		time.Date(2000, 1, 1, 3, 0, 0, 0, tokyo),
+		Date{time.Date(1582, 10, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	expected := []interface{}{"2017-03-01", nil, "1969-12-31", "2000-01-01", "1582-10-15"}
+	for _, v := range input {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := (&DateTreeWriter{}).Write("2017-03-01"); err == nil {
+		t.Errorf("Test failed, expected an error writing a string to a date column")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := r.Select("date1")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			var got interface{}
+			if d, ok := c.Row()[0].(Date); ok {
+				got = d.Format("2006-01-02")
+			}
+			if i >= len(expected) || got != expected[i] {
+				t.Errorf("Test failed, row %d expected %v got %v", i, expected[i], got)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+
+	ds := r.footer.GetStatistics()[1].GetDateStatistics()
+	if ds.GetMinimum() != -141427 || ds.GetMaximum() != 17226 {
+		t.Errorf("Test failed, expected min -141427 max 17226 got min %d max %d", ds.GetMinimum(), ds.GetMaximum())
+	}
+
+}
+
+func TestWriterFileVersion(t *testing.T) {
+
+	testCases := []struct {
+		major, minor int
+		encodings    []proto.ColumnEncoding_Kind
+	}{
+		{
+			major:     0,
+			minor:     11,
+			encodings: []proto.ColumnEncoding_Kind{proto.ColumnEncoding_DIRECT, proto.ColumnEncoding_DICTIONARY},
+		},
+		{
+			major:     0,
+			minor:     12,
+			encodings: []proto.ColumnEncoding_Kind{proto.ColumnEncoding_DIRECT, proto.ColumnEncoding_DIRECT_V2, proto.ColumnEncoding_DICTIONARY_V2},
+		},
+	}
+
+	for _, tc := range testCases {
+
+		t.Run(fmt.Sprintf("%d.%d", tc.major, tc.minor), func(t *testing.T) {
+
+			schema, err := ParseSchema("struct<int1:int,string1:string,string2:string,timestamp1:timestamp,decimal1:decimal(10,2)>")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, SetSchema(schema), WithFileVersion(tc.major, tc.minor))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var input [][]interface{}
+			for i := 0; i < 100; i++ {
+				row := []interface{}{
+					int64(i * 1000),
+					fmt.Sprintf("%d", i%3),
+					fmt.Sprintf("unique-%d", i),
+					time.Date(2000+i, time.January, 1, 0, 0, 0, i, time.UTC),
+					fmt.Sprintf("%d.%02d", i, i),
+				}
+				input = append(input, row)
+				err = w.Write(row...)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = w.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := NewReader(&bytesSizedReaderAt{&buf})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if version := r.postScript.GetVersion(); !reflect.DeepEqual(version, []uint32{uint32(tc.major), uint32(tc.minor)}) {
+				t.Errorf("Test failed, expected postscript version %d.%d got %v", tc.major, tc.minor, version)
+			}
+
+			for _, stripe := range r.footer.GetStripes() {
+				stripeFooter, err := r.getStripeFooter(stripe)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, stream := range stripeFooter.GetStreams() {
+					if stream.GetKind() == proto.Stream_BLOOM_FILTER {
+						t.Errorf("Test failed, unexpected %s stream for column %d", stream.GetKind(), stream.GetColumn())
+					}
+				}
+				for col, encoding := range stripeFooter.GetColumns() {
+					var ok bool
+					for _, kind := range tc.encodings {
+						if encoding.GetKind() == kind {
+							ok = true
+						}
+					}
+					if !ok {
+						t.Errorf("Test failed, unexpected encoding %s for column %d", encoding.GetKind(), col)
+					}
+				}
+			}
+
+			c := r.Select("int1", "string1", "string2", "timestamp1", "decimal1")
+			var i int
+			for c.Stripes() {
+				for c.Next() {
+					row := c.Row()
+					row[3] = row[3].(time.Time).UTC()
+					row[4] = row[4].(Decimal).String()
+					if !reflect.DeepEqual(row, input[i]) {
+						t.Errorf("Test failed, expected %v at row %d got %v", input[i], i, row)
+					}
+					i++
+				}
+			}
+			if err := c.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if i != len(input) {
+				t.Errorf("Test failed, expected %d rows got %d", len(input), i)
+			}
+		})
+
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, WithFileVersion(1, 9)); err == nil {
+		t.Errorf("Test failed, expected an error for an unsupported file version")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, WithFormatVersion("0.13")); err == nil {
+		t.Errorf("Test failed, expected an error for an unsupported file version")
+	}
+
+	for _, tc := range []struct {
+		schema  string
+		version string
+		valid   bool
+	}{
+		{schema: "struct<int1:int,string1:string>", version: "0.11", valid: true},
+		{schema: "struct<int1:int,string1:varchar(10)>", version: "0.11", valid: false},
+		{schema: "struct<list1:array<varchar(10)>>", version: "0.11", valid: false},
+		{schema: "struct<ts1:timestamp with local time zone>", version: "0.11", valid: false},
+		{schema: "struct<ts1:timestamp with local time zone>", version: "0.12", valid: true},
+		{schema: "struct<int1:int,string1:varchar(10)>", version: "0.12", valid: true},
+	} {
+		schema, err := ParseSchema(tc.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithFormatVersion(tc.version))
+		if valid := err == nil; valid != tc.valid {
+			t.Errorf("Test failed, expected schema %s to be valid for version %s: %v got %v", tc.schema, tc.version, tc.valid, err)
+		}
+		if err == nil && !reflect.DeepEqual(w.postScript.GetVersion(), []uint32{w.version.major, w.version.minor}) {
+			t.Errorf("Test failed, unexpected postscript version %v for version %s", w.postScript.GetVersion(), tc.version)
+		}
+		if err == nil && w.version.name != tc.version {
+			t.Errorf("Test failed, expected version %s got %s", tc.version, w.version.name)
+		}
+	}
+}
+
+func TestWriterRLEVersion(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string,date1:date>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A run of 5, literals ending in a value whose delta is out of range and a run
+	// with a negative delta, which begins with the last of the preceding values.
+	ints := []int64{5, 5, 5, 5, 5, -1, 1, 300, 20, 18, 16, 14}
+	data := []byte{
+		// A run of 3+2 values with delta 0 from the zigzag encoded base 5.
+		0x02, 0x00, 0x0a,
+		// 3 literals: -1, 1 and 300.
+		0xfd, 0x01, 0x02, 0xd8, 0x04,
+		// A run of 3+1 values with delta -2 from the base 20.
+		0x01, 0xfe, 0x28,
+	}
+
+	testCases := []struct {
+		fns []WriterConfigFunc
+		// direct and dictionary are the encodings expected of the integer and
+		// date columns and of the string column.
+		direct, dictionary proto.ColumnEncoding_Kind
+	}{
+		{
+			direct:     proto.ColumnEncoding_DIRECT_V2,
+			dictionary: proto.ColumnEncoding_DICTIONARY_V2,
+		},
+		{
+			fns:        []WriterConfigFunc{WithRLEVersion(1)},
+			direct:     proto.ColumnEncoding_DIRECT,
+			dictionary: proto.ColumnEncoding_DICTIONARY,
+		},
+		{
+			// The option applies whatever the order of the options.
+			fns:        []WriterConfigFunc{WithRLEVersion(1), WithFileVersion(0, 12)},
+			direct:     proto.ColumnEncoding_DIRECT,
+			dictionary: proto.ColumnEncoding_DICTIONARY,
+		},
+		{
+			fns:        []WriterConfigFunc{WithRLEVersion(2)},
+			direct:     proto.ColumnEncoding_DIRECT_V2,
+			dictionary: proto.ColumnEncoding_DICTIONARY_V2,
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema), WithDictionaryKeyThreshold(1)}, tc.fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var input [][]interface{}
+		for i, v := range ints {
+			row := []interface{}{v, fmt.Sprintf("%d", i%2), Date{time.Date(2000, 1, 1+i, 0, 0, 0, 0, time.UTC)}}
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+			input = append(input, row)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version := r.postScript.GetVersion(); !reflect.DeepEqual(version, []uint32{0, 12}) {
+			t.Errorf("Test failed, expected file version 0.12 got %v", version)
+		}
+		streams, err := r.getStreams(1, 2, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for col := 1; col <= 3; col++ {
+			encoding, err := r.getColumn(col)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := tc.direct
+			if col == 2 {
+				expected = tc.dictionary
+			}
+			if kind := encoding.GetKind(); kind != expected {
+				t.Errorf("Test failed, expected %s encoding for column %d got %s", expected, col, kind)
+			}
+		}
+		if tc.direct == proto.ColumnEncoding_DIRECT {
+			actual, err := ioutil.ReadAll(streams.get(streamName{1, proto.Stream_DATA}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(actual, data) {
+				t.Errorf("Test failed, expected the DATA stream % x got % x", data, actual)
+			}
+		}
+
+		r, err = NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, input) {
+			t.Errorf("Test failed, expected rows %v got %v", input, actual)
+		}
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithRLEVersion(3)); err == nil || err.Error() != "invalid run length encoding version 3" {
+		t.Errorf("Test failed, expected an invalid version error got %v", err)
+	}
+	schema, err = ParseSchema("struct<int1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithRLEVersion(2), WithFileVersion(0, 11)); err == nil || err.Error() != "run length encoding version 2 is not supported by file version 0.11" {
+		t.Errorf("Test failed, expected an unsupported version error got %v", err)
+	}
+	w, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithRLEVersion(1), WithFileVersion(0, 11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.version.directEncoding() != proto.ColumnEncoding_DIRECT {
+		t.Errorf("Test failed, expected DIRECT encoding for file version 0.11")
+	}
+
+}
+
+func TestWriterIdentification(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		fns           []WriterConfigFunc
+		version       []uint32
+		writer        uint32
+		writerVersion uint32
+	}{
+		{
+			version:       []uint32{0, 12},
+			writer:        WriterGo,
+			writerVersion: DefaultWriterVersion,
+		},
+		{
+			fns:           []WriterConfigFunc{WithFileVersion(0, 11)},
+			version:       []uint32{0, 11},
+			writer:        WriterGo,
+			writerVersion: DefaultWriterVersion,
+		},
+		{
+			fns:           []WriterConfigFunc{WithWriterVersion(1, 4)},
+			version:       []uint32{0, 12},
+			writer:        1,
+			writerVersion: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, tc.fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			if err := w.Write(int64(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The magic is written at the start of the file and, being the last
+		// field of the postscript, immediately before the postscript length.
+		byt := buf.Bytes()
+		if head := string(byt[:len(magic)]); head != magic {
+			t.Errorf("Test failed, expected the file to start with %q got %q", magic, head)
+		}
+		if tail := string(byt[len(byt)-1-len(magic) : len(byt)-1]); tail != magic {
+			t.Errorf("Test failed, expected the postscript to end with %q got %q", magic, tail)
+		}
+
+		r, err := NewReader(&bytesSizedReaderAt{&buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version := r.postScript.GetVersion(); !reflect.DeepEqual(version, tc.version) {
+			t.Errorf("Test failed, expected version %v got %v", tc.version, version)
+		}
+		if writer := r.footer.GetWriter(); writer != tc.writer {
+			t.Errorf("Test failed, expected writer %d got %d", tc.writer, writer)
+		}
+		if r.postScript.WriterVersion == nil || r.postScript.GetWriterVersion() != tc.writerVersion {
+			t.Errorf("Test failed, expected writer version %d got %v", tc.writerVersion, r.postScript.WriterVersion)
+		}
+	}
+
+	// The length of the postscript is written as a single byte, therefore, the
+	// largest postscript is 255 bytes.
+	for _, size := range []int{254, 255, 256} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, SetSchema(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for gproto.Size(w.postScript) < size {
+			w.postScript.Version = append(w.postScript.Version, 0)
+		}
+		if gproto.Size(w.postScript) != size {
+			t.Fatalf("Test failed, unable to construct a postscript of %d bytes", size)
+		}
+		buf.Reset()
+		err = w.writePostScript()
+		if size > 255 {
+			if err == nil {
+				t.Errorf("Test failed, expected error for a postscript of %d bytes", size)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := int(buf.Bytes()[buf.Len()-1]); n != size || buf.Len() != size+1 {
+			t.Errorf("Test failed, expected postscript length %d got %d", size, n)
+		}
+	}
+
+}
+
+func TestWriterListAndMap(t *testing.T) {
+
+	schema, err := ParseSchema("struct<middle:struct<list:array<struct<int1:int,string1:string>>>,list:array<struct<int1:int,string1:string>>,map:map<string,struct<int1:int,string1:string>>,nested:array<map<string,struct<int1:int,string1:string>>>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := [][]interface{}{
+		{
+			[]interface{}{[]interface{}{[]interface{}{1, "bye"}, []interface{}{2, "sigh"}}},
+			[]interface{}{[]interface{}{3, "good"}, []interface{}{4, "bad"}},
+			[]MapEntry{},
+			[]interface{}{[]MapEntry{{"a", []interface{}{5, "x"}}}, []MapEntry{}},
+		},
+		{
+			nil,
+			nil,
+			[]MapEntry{{"chani", []interface{}{5, "chani"}}, {"mauddib", []interface{}{1, "mauddib"}}},
+			nil,
+		},
+		{
+			[]interface{}{[]interface{}{}},
+			[]interface{}{},
+			[]MapEntry{{"k", nil}},
+			[]interface{}{nil, []MapEntry{{"b", nil}, {"c", []interface{}{7, nil}}}},
+		},
+		{
+			[]interface{}{nil},
+			[]interface{}{nil},
+			map[string]interface{}{"solo": []interface{}{9, "solo"}},
+			[]interface{}{},
+		},
+	}
+
+	expected := [][]interface{}{
+		{
+			Struct{"list": []interface{}{Struct{"int1": int64(1), "string1": "bye"}, Struct{"int1": int64(2), "string1": "sigh"}}},
+			[]interface{}{Struct{"int1": int64(3), "string1": "good"}, Struct{"int1": int64(4), "string1": "bad"}},
+			[]MapEntry{},
+			[]interface{}{[]MapEntry{{"a", Struct{"int1": int64(5), "string1": "x"}}}, []MapEntry{}},
+		},
+		{
+			nil,
+			nil,
+			[]MapEntry{{"chani", Struct{"int1": int64(5), "string1": "chani"}}, {"mauddib", Struct{"int1": int64(1), "string1": "mauddib"}}},
+			nil,
+		},
+		{
+			Struct{"list": []interface{}{}},
+			[]interface{}{},
+			[]MapEntry{{"k", nil}},
+			[]interface{}{nil, []MapEntry{{"b", nil}, {"c", Struct{"int1": int64(7), "string1": nil}}}},
+		},
+		{
+			Struct{"list": nil},
+			[]interface{}{nil},
+			[]MapEntry{{"solo", Struct{"int1": int64(9), "string1": "solo"}}},
+			[]interface{}{},
+		},
+	}
+
+	for _, row := range input {
+		err = w.Write(row...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := r.Select("middle", "list", "map", "nested")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			if row := c.Row(); !reflect.DeepEqual(row, expected[i]) {
+				t.Errorf("Test failed, expected %v at row %d got %v", expected[i], i, row)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+
+	statistics := r.footer.GetStatistics()
+	for _, tc := range []struct {
+		field           string
+		min, max, total uint64
+	}{
+		{field: "list", min: 0, max: 2, total: 3},
+		{field: "map", min: 0, max: 2, total: 4},
+		{field: "nested", min: 0, max: 2, total: 4},
+		{field: "middle.list", min: 0, max: 2, total: 2},
+	} {
+		column, err := schema.GetField(tc.field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs := statistics[column.getID()].GetCollectionStatistics()
+		if cs.GetMinChildren() != tc.min || cs.GetMaxChildren() != tc.max || cs.GetTotalChildren() != tc.total {
+			t.Errorf("Test failed, expected %s children min %d max %d total %d got %v", tc.field, tc.min, tc.max, tc.total, cs)
+		}
+	}
+}
+
+func TestWriterUnion(t *testing.T) {
+
+	// The double variant never occurs.
+	schema, err := ParseSchema("struct<id:int,union:uniontype<int,string,double>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []interface{}{
+		UnionValue{0, 1},
+		UnionValue{1, "one"},
+		nil,
+		UnionValue{1, nil},
+		UnionValue{0, -2},
+		UnionValue{1, "two"},
+		nil,
+		UnionValue{0, 3},
+	}
+
+	expected := []interface{}{
+		UnionValue{0, int64(1)},
+		UnionValue{1, "one"},
+		nil,
+		UnionValue{1, nil},
+		UnionValue{0, int64(-2)},
+		UnionValue{1, "two"},
+		nil,
+		UnionValue{0, int64(3)},
+	}
+
+	for i, value := range input {
+		err = w.Write(i, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Write(len(input), UnionValue{3, 1.0}); err == nil {
+		t.Errorf("Test failed, expected an error writing an out of range union tag")
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.schema.String() != schema.String() {
+		t.Errorf("Test failed, expected schema %s got %s", schema, r.schema)
+	}
+
+	c := r.Select("id", "union")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			row := c.Row()
+			if row[0] != int64(i) {
+				t.Errorf("Test failed, expected id %d got %v", i, row[0])
+			}
+			if !reflect.DeepEqual(row[1], expected[i]) {
+				t.Errorf("Test failed, expected %v at row %d got %v", expected[i], i, row[1])
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Errorf("Test failed, expected %d rows got %d", len(expected), i)
+	}
+
+	union, err := schema.GetField("union")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statistics := r.footer.GetStatistics()
+	for _, tc := range []struct {
+		id      int
+		hasNull bool
+	}{
+		{id: union.getID(), hasNull: true},
+		{id: union.children[0].getID(), hasNull: false},
+		{id: union.children[1].getID(), hasNull: true},
+		{id: union.children[2].getID(), hasNull: false},
+	} {
+		if cs := statistics[tc.id]; cs.GetHasNull() != tc.hasNull {
+			t.Errorf("Test failed, expected column %d hasNull %t got %v", tc.id, tc.hasNull, cs)
+		}
+	}
+}
+
+func TestWriterUnionVariants(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		valid  bool
+	}{
+		{schema: "struct<union:uniontype<int>>", valid: false},
+		{schema: "struct<union:uniontype<int,string>>", valid: true},
+		{schema: "struct<union:uniontype<int,string,uniontype<boolean,double>>>", valid: true},
+	} {
+		schema, err := ParseSchema(tc.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewWriter(&bytes.Buffer{}, SetSchema(schema))
+		if tc.valid && err != nil {
+			t.Errorf("Test failed, expected %s to be writable got %v", tc.schema, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Test failed, expected an error creating a writer for %s", tc.schema)
+		}
+	}
+}
+
+func TestWriterUserMetadata(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset := []byte{0x00, 0xff, 0x80, 0x00, 0xc3, 0x28}
+	w.AddUserMetadata("source.offset", offset)
+	// Modifying the value after it is added does not change the metadata.
+	offset[0] = 0x01
+	w.AddUserMetadata("run.id", []byte("first"))
+	for i := 0; i < 10; i++ {
+		err = w.Write(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.AddUserMetadata("run.id", []byte("second"))
+	w.AddUserMetadata("empty", nil)
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]byte{
+		"empty":         {},
+		"run.id":        []byte("second"),
+		"source.offset": {0x00, 0xff, 0x80, 0x00, 0xc3, 0x28},
+	}
+	metadata := r.UserMetadata()
+	if len(metadata) != len(expected) {
+		t.Errorf("Test failed, expected %d user metadata items got %d", len(expected), len(metadata))
+	}
+	for key, value := range expected {
+		if !bytes.Equal(metadata[key], value) {
+			t.Errorf("Test failed, expected %s to be %v got %v", key, value, metadata[key])
+		}
+	}
+
+	// The items are written to the footer ordered by key.
+	var keys []string
+	for _, item := range r.footer.GetMetadata() {
+		keys = append(keys, item.GetName())
+	}
+	if !reflect.DeepEqual(keys, []string{"empty", "run.id", "source.offset"}) {
+		t.Errorf("Test failed, unexpected user metadata keys %v", keys)
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flushing with no buffered rows does not write a stripe.
+	offset, err := w.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != uint64(len(magic)) || buf.Len() != len(magic) {
+		t.Errorf("Test failed, expected offset %d got %d with %d bytes written", len(magic), offset, buf.Len())
+	}
+
+	var row int
+	var offsets []uint64
+	for _, rows := range []int{3, 2, 0, 1} {
+		for i := 0; i < rows; i++ {
+			err = w.Write(row, fmt.Sprint("row", row))
+			if err != nil {
+				t.Fatal(err)
+			}
+			row++
+		}
+		offset, err := w.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset != uint64(buf.Len()) {
+			t.Errorf("Test failed, expected offset %d got %d", buf.Len(), offset)
+		}
+		offsets = append(offsets, offset)
+	}
+	err = w.Write(row, fmt.Sprint("row", row))
+	if err != nil {
+		t.Fatal(err)
+	}
+	row++
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stripes := r.footer.GetStripes()
+	expectedRows := []uint64{3, 2, 1, 1}
+	if len(stripes) != len(expectedRows) {
+		t.Fatalf("Test failed, expected %d stripes got %d", len(expectedRows), len(stripes))
+	}
+	expectedOffset := uint64(len(magic))
+	for i, stripe := range stripes {
+		if stripe.GetOffset() != expectedOffset {
+			t.Errorf("Test failed, expected stripe %d at offset %d got %d", i, expectedOffset, stripe.GetOffset())
+		}
+		if stripe.GetNumberOfRows() != expectedRows[i] {
+			t.Errorf("Test failed, expected stripe %d to have %d rows got %d", i, expectedRows[i], stripe.GetNumberOfRows())
+		}
+		expectedOffset += stripe.GetIndexLength() + stripe.GetDataLength() + stripe.GetFooterLength()
+	}
+	// The third flush had no rows and returned the same offset as the second.
+	expectedOffsets := []uint64{stripes[1].GetOffset(), stripes[2].GetOffset(), stripes[2].GetOffset(), stripes[3].GetOffset()}
+	if !reflect.DeepEqual(offsets, expectedOffsets) {
+		t.Errorf("Test failed, expected flush offsets %v got %v", expectedOffsets, offsets)
+	}
+	if rows := r.footer.GetNumberOfRows(); rows != uint64(row) {
+		t.Errorf("Test failed, expected %d rows got %d", row, rows)
+	}
+
+	c := r.Select("int1", "string1")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			expected := []interface{}{int64(i), fmt.Sprint("row", i)}
+			if r := c.Row(); !reflect.DeepEqual(r, expected) {
+				t.Errorf("Test failed, expected %v got %v", expected, r)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != row {
+		t.Errorf("Test failed, expected %d rows got %d", row, i)
+	}
+}
+
+func TestWriterStatistics(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:bigint,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write three stripes each with a distinct range of values, the second of
+	// which includes a null.
+	const numStripes = 3
+	for i := 0; i < numStripes; i++ {
+		for j := 0; j < 100; j++ {
+			var value interface{} = int64(i*100 + j)
+			if i == 1 && j == 50 {
+				value = nil
+			}
+			err = w.Write(value, fmt.Sprintf("stripe %d", i))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.postScript.GetMetadataLength() == 0 {
+		t.Errorf("Test failed, expected the metadata length to be set")
+	}
+	stripeStats := r.metadata.GetStripeStats()
+	if len(stripeStats) != numStripes {
+		t.Fatalf("Test failed, expected %d stripe statistics got %d", numStripes, len(stripeStats))
+	}
+
+	var numValues uint64
+	var sum int64
+	for i, stats := range stripeStats {
+		is := stats.GetColStats()[1].GetIntStatistics()
+		min, max := int64(i*100), int64(i*100+99)
+		if is.GetMinimum() != min || is.GetMaximum() != max {
+			t.Errorf("Test failed, stripe %d expected min %d max %d got min %d max %d", i, min, max, is.GetMinimum(), is.GetMaximum())
+		}
+		if hasNull := stats.GetColStats()[1].GetHasNull(); hasNull != (i == 1) {
+			t.Errorf("Test failed, stripe %d unexpected hasNull %v", i, hasNull)
+		}
+		ss := stats.GetColStats()[2].GetStringStatistics()
+		if expected := fmt.Sprintf("stripe %d", i); ss.GetMinimum() != expected || ss.GetMaximum() != expected {
+			t.Errorf("Test failed, stripe %d expected string min and max %s got %s and %s", i, expected, ss.GetMinimum(), ss.GetMaximum())
+		}
+		numValues += stats.GetColStats()[1].GetNumberOfValues()
+		sum += is.GetSum()
+	}
+
+	fileStats := r.footer.GetStatistics()
+	is := fileStats[1].GetIntStatistics()
+	if is.GetMinimum() != 0 || is.GetMaximum() != 299 || is.GetSum() != sum {
+		t.Errorf("Test failed, got file min %d max %d sum %d", is.GetMinimum(), is.GetMaximum(), is.GetSum())
+	}
+	if !fileStats[1].GetHasNull() || fileStats[2].GetHasNull() {
+		t.Errorf("Test failed, got file hasNull %v and %v", fileStats[1].GetHasNull(), fileStats[2].GetHasNull())
+	}
+	if n := fileStats[1].GetNumberOfValues(); n != numValues {
+		t.Errorf("Test failed, expected %d file values got %d", numValues, n)
+	}
+	if ss := fileStats[2].GetStringStatistics(); ss.GetMinimum() != "stripe 0" || ss.GetMaximum() != "stripe 2" {
+		t.Errorf("Test failed, got file string min %s max %s", ss.GetMinimum(), ss.GetMaximum())
+	}
+
+}
+
+func TestWriterStringStatisticsTruncation(t *testing.T) {
+
+	schema, err := ParseSchema("struct<string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first stripe holds two row groups, the first of which includes a value
+	// whose upper bound carries past the 0xFF bytes at the cut point, and the second
+	// stripe a value whose upper bound cannot be truncated.
+	carry := strings.Repeat("a", 1021) + "\xff\xff\xff" + strings.Repeat("z", 1000)
+	ones := strings.Repeat("\xff", 1500)
+	for i := 0; i < int(DefaultRowIndexStride)+1; i++ {
+		value := "0"
+		if i == 0 {
+			value = carry
+		} else if i == int(DefaultRowIndexStride) {
+			value = "1"
+		}
+		if err := w.Write(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(ones); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	type bounds struct {
+		lower      string
+		lowerExact bool
+		upper      string
+		upperExact bool
+	}
+	check := func(name string, ss *proto.StringStatistics, expected bounds) {
+		var got bounds
+		got.lower, got.lowerExact, got.upper, got.upperExact = StringBounds(ss)
+		if got != expected {
+			t.Errorf("Test failed, %s expected bounds %+v got %+v", name, expected, got)
+		}
+	}
+	carryUpper := carry[:1020] + "b"
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]bounds{
+		{{"0", true, carryUpper, false}, {"1", true, "1", true}},
+		{{ones[:1024], false, ones, true}},
+	}
+	c := r.Select("string1")
+	for i := 0; c.Stripes(); i++ {
+		byt, err := ioutil.ReadAll(c.streams.get(streamName{1, proto.Stream_ROW_INDEX}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var index proto.RowIndex
+		if err := gproto.Unmarshal(byt, &index); err != nil {
+			t.Fatal(err)
+		}
+		if len(index.GetEntry()) != len(expected[i]) {
+			t.Fatalf("Test failed, stripe %d expected %d row groups got %d", i, len(expected[i]), len(index.GetEntry()))
+		}
+		for j, entry := range index.GetEntry() {
+			check(fmt.Sprintf("stripe %d row group %d", i, j), entry.GetStatistics().GetStringStatistics(), expected[i][j])
+		}
+		for c.Next() {
+		}
+	}
+	if err := c.Err(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	stripeStats := r.metadata.GetStripeStats()
+	check("stripe 0", stripeStats[0].GetColStats()[1].GetStringStatistics(), expected[0][0])
+	check("stripe 1", stripeStats[1].GetColStats()[1].GetStringStatistics(), expected[1][0])
+	check("file", r.footer.GetStatistics()[1].GetStringStatistics(), bounds{"0", true, ones, true})
+
+}
+
+func TestWriterDoubleStatistics(t *testing.T) {
+
+	schema, err := ParseSchema("struct<double1:double>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a stripe of ordinary values, one including both infinities, one
+	// including a NaN and one of only nulls.
+	stripes := [][]interface{}{
+		{1.5, nil, -2.5},
+		{math.Inf(1), 1.0, math.Inf(-1)},
+		{1.0, math.NaN(), 2.0},
+		{nil},
+	}
+	type summary struct {
+		min, max, sum float64
+		hasMinMax     bool
+	}
+	expected := []summary{
+		{min: -2.5, max: 1.5, sum: -1, hasMinMax: true},
+		{min: math.Inf(-1), max: math.Inf(1), sum: math.NaN(), hasMinMax: true},
+		{sum: math.NaN()},
+		{sum: 0},
+	}
+	for _, values := range stripes {
+		for _, v := range values {
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(name string, stats *proto.ColumnStatistics, expected summary) {
+		ds := stats.GetDoubleStatistics()
+		min, max, hasMinMax := DoubleMinMax(ds)
+		if hasMinMax != expected.hasMinMax || min != expected.min || max != expected.max {
+			t.Errorf("Test failed, %s expected min %v max %v (set %v) got min %v max %v (set %v)", name, expected.min, expected.max, expected.hasMinMax, min, max, hasMinMax)
+		}
+		sum, hasSum := DoubleSum(ds)
+		if !hasSum || sum != expected.sum && !(math.IsNaN(sum) && math.IsNaN(expected.sum)) {
+			t.Errorf("Test failed, %s expected sum %v got %v (set %v)", name, expected.sum, sum, hasSum)
+		}
+		// The dump output agrees with the accessors.
+		suffix := fmt.Sprintf(" sum: %v", sum)
+		if hasMinMax {
+			suffix = fmt.Sprintf(" min: %v max: %v", min, max) + suffix
+		}
+		if s := formatStatistics(stats); !strings.HasSuffix(s, suffix) || !hasMinMax && strings.Contains(s, "min:") {
+			t.Errorf("Test failed, %s expected dumped statistics ending %q got %q", name, suffix, s)
+		}
+	}
+	stripeStats := r.metadata.GetStripeStats()
+	if len(stripeStats) != len(stripes) {
+		t.Fatalf("Test failed, expected %d stripe statistics got %d", len(stripes), len(stripeStats))
+	}
+	for i, stats := range stripeStats {
+		check(fmt.Sprintf("stripe %d", i), stats.GetColStats()[1], expected[i])
+	}
+	check("file", r.footer.GetStatistics()[1], summary{sum: math.NaN()})
+
+}
+
+func TestWriterBlockPadding(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:bigint,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		blockSize int64
+		tolerance float64
+	}{
+		{blockSize: 16 * 1024, tolerance: 0.05},
+		{blockSize: 16 * 1024, tolerance: 0.5},
+		{blockSize: 40 * 1024, tolerance: 1},
+	} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, SetSchema(schema), WithBlockSize(tc.blockSize), WithPaddingTolerance(tc.tolerance), WithStripeTargetSize(2*tc.blockSize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.footer.RowIndexStride = ptrUint32(100)
+
+		const numRows = 50000
+		for i := 0; i < numRows; i++ {
+			err = w.Write(int64(i)*7919, fmt.Sprintf("row %d", i))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = w.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes())})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stripes := r.footer.GetStripes()
+		if len(stripes) < 2 {
+			t.Fatalf("Test failed, expected multiple stripes got %d", len(stripes))
+		}
+		var padded bool
+		end := uint64(len(magic))
+		for i, stripe := range stripes {
+			offset := stripe.GetOffset()
+			length := stripe.GetIndexLength() + stripe.GetDataLength() + stripe.GetFooterLength()
+			if offset < end {
+				t.Fatalf("Test failed, stripe %d at offset %d overlaps the previous stripe ending at %d", i, offset, end)
+			}
+			if offset > end {
+				padded = true
+				if padding := offset - end; float64(padding) > tc.tolerance*float64(tc.blockSize) {
+					t.Errorf("Test failed, stripe %d padded by %d bytes exceeding the tolerance", i, padding)
+				}
+			}
+			// The final stripe is written on close regardless of its size.
+			if i < len(stripes)-1 && offset/uint64(tc.blockSize) != (offset+length-1)/uint64(tc.blockSize) {
+				t.Errorf("Test failed, stripe %d from %d to %d crosses a block boundary of %d", i, offset, offset+length, tc.blockSize)
+			}
+			end = offset + length
+		}
+		if !padded {
+			t.Errorf("Test failed, expected stripes to be padded for block size %d", tc.blockSize)
+		}
+
+		c := r.Select("int1", "string1")
+		var i int
+		for c.Stripes() {
+			for c.Next() {
+				expected := []interface{}{int64(i) * 7919, fmt.Sprintf("row %d", i)}
+				if row := c.Row(); !reflect.DeepEqual(row, expected) {
+					t.Fatalf("Test failed, expected %v got %v", expected, row)
+				}
+				i++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if i != numRows {
+			t.Errorf("Test failed, expected %d rows got %d", numRows, i)
+		}
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithBlockSize(0)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid block size")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithPaddingTolerance(1.5)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid padding tolerance")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithStripeTargetSize(0)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid stripe target size")
+	}
+}
+
+func TestWriterStripeMaxAge(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithStripeMaxAge(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time {
+		return clock
+	}
+
+	// Each step advances the clock before writing a row, the second row
+	// is written 30 seconds after the first and so on.
+	var i int
+	for _, step := range []time.Duration{0, 30 * time.Second, 29 * time.Second, time.Second, time.Second, time.Hour, time.Second} {
+		clock = clock.Add(step)
+		err = w.Write(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i++
+	}
+	// A long pause without any rows does not produce an empty stripe.
+	clock = clock.Add(time.Hour)
+	offset, err := w.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != uint64(buf.Len()) {
+		t.Errorf("Test failed, expected offset %d got %d", buf.Len(), offset)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []uint64
+	for _, stripe := range r.footer.GetStripes() {
+		rows = append(rows, stripe.GetNumberOfRows())
+	}
+	// The first stripe is written with the row at exactly one minute, the second
+	// with the row written after the hour long pause and the remaining row by the
+	// call to Flush.
+	expected := []uint64{4, 2, 1}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected stripes with %v rows got %v", expected, rows)
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithStripeMaxAge(0)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid stripe max age")
+	}
+}
+
+func TestWriterMemoryLimit(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:bigint,string1:string,string2:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const limit = 32 * 1024
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithWriterMemoryLimit(limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numRows = 20000
+	var maxUsage int64
+	for i := 0; i < numRows; i++ {
+		err = w.Write(int64(i), fmt.Sprintf("unique value %d", i), fmt.Sprintf("repeated %d", i%10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if usage := w.MemoryUsage(); usage > maxUsage {
+			maxUsage = usage
+		}
+	}
+	if maxUsage == 0 || maxUsage >= limit {
+		t.Errorf("Test failed, expected memory usage under %d got %d", limit, maxUsage)
+	}
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripes := len(r.footer.GetStripes()); stripes < 10 {
+		t.Errorf("Test failed, expected many small stripes got %d", stripes)
+	}
+
+	c := r.Select("int1", "string1", "string2")
+	var i int
+	for c.Stripes() {
+		for c.Next() {
+			expected := []interface{}{int64(i), fmt.Sprintf("unique value %d", i), fmt.Sprintf("repeated %d", i%10)}
+			if row := c.Row(); !reflect.DeepEqual(row, expected) {
+				t.Fatalf("Test failed, expected %v got %v", expected, row)
+			}
+			i++
+		}
+	}
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != numRows {
+		t.Errorf("Test failed, expected %d rows got %d", numRows, i)
+	}
+}
+
+// rowGroupSpread returns the sum of the difference between the maximum and minimum
+// of the integer column with the provided id in each row group of the file.
+func rowGroupSpread(t *testing.T, r *Reader, column string, id int) int64 {
+	var spread int64
+	r.currentStripeOffset = 0
+	c := r.Select(column)
+	for c.Stripes() {
+		byt, err := ioutil.ReadAll(c.streams.get(streamName{id, proto.Stream_ROW_INDEX}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var index proto.RowIndex
+		if err := gproto.Unmarshal(byt, &index); err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range index.GetEntry() {
+			is := entry.GetStatistics().GetIntStatistics()
+			spread += is.GetMaximum() - is.GetMinimum()
+		}
+		for c.Next() {
+		}
+	}
+	if err := c.Err(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	return spread
+}
+
+func TestWriterStripeSortColumn(t *testing.T) {
+
+	schema, err := ParseSchema("struct<string1:string,int1:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numRows = 3000
+	rnd := rand.New(rand.NewSource(1))
+	var input [][]interface{}
+	for i := 0; i < numRows; i++ {
+		var value interface{} = rnd.Int63n(1000000)
+		if i%50 == 0 {
+			value = nil
+		}
+		input = append(input, []interface{}{fmt.Sprintf("row %d", i), value})
+	}
+
+	write := func(fns ...WriterConfigFunc) *Reader {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.footer.RowIndexStride = ptrUint32(100)
+		// Buffer approximately 1000 rows per stripe.
+		w.stripeTargetSize = 40 * 1000
+		for _, row := range input {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(&bytesSizedReaderAt{&buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	unsorted := rowGroupSpread(t, write(), "int1", 2)
+
+	for _, nullsLast := range []bool{false, true} {
+		r := write(WithStripeSortColumn("int1"), WithStripeSortNullsLast(nullsLast))
+		if n := len(r.footer.GetStripes()); n < 2 {
+			t.Errorf("Test failed, expected multiple stripes got %d", n)
+		}
+
+		// The rows of each stripe are ordered by int1, whilst the rows of the
+		// file are those written.
+		var output []string
+		c := r.Select("string1", "int1")
+		for c.Stripes() {
+			var prev interface{}
+			var i int
+			for c.Next() {
+				row := c.Row()
+				output = append(output, fmt.Sprint(row))
+				if i > 0 {
+					if prev == nil && row[1] != nil && nullsLast {
+						t.Errorf("Test failed, expected nulls last got %v after null", row[1])
+					}
+					if prev != nil && row[1] == nil && !nullsLast {
+						t.Errorf("Test failed, expected nulls first got null after %v", prev)
+					}
+					if prev != nil && row[1] != nil && prev.(int64) > row[1].(int64) {
+						t.Errorf("Test failed, expected sorted rows got %v after %v", row[1], prev)
+					}
+				}
+				prev = row[1]
+				i++
+			}
+		}
+		if err := c.Err(); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		var expected []string
+		for _, row := range input {
+			expected = append(expected, fmt.Sprint(row))
+		}
+		sort.Strings(expected)
+		sort.Strings(output)
+		if !reflect.DeepEqual(output, expected) {
+			t.Errorf("Test failed, expected the rows written to be read")
+		}
+
+		if sorted := rowGroupSpread(t, r, "int1", 2); sorted*4 > unsorted {
+			t.Errorf("Test failed, expected tighter row group statistics when sorted got %d and %d unsorted", sorted, unsorted)
+		}
+	}
+
+	for _, column := range []string{"missing", ""} {
+		if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithStripeSortColumn(column)); err == nil {
+			t.Errorf("Test failed, expected error for stripe sort column %q", column)
+		}
+	}
+	listSchema, err := ParseSchema("struct<list1:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(listSchema), WithStripeSortColumn("list1")); err == nil {
+		t.Errorf("Test failed, expected error for a list stripe sort column")
+	}
+
+}
+
+func TestWriterIntermediateFooter(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var row int
+	write := func(rows int) {
+		for i := 0; i < rows; i++ {
+			if err := w.Write(row, fmt.Sprint("row", row)); err != nil {
+				t.Fatal(err)
+			}
+			row++
+		}
+	}
+
+	// A file with no stripes is readable.
+	empty, err := w.WriteIntermediateFooter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(5)
+	if _, err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	first, err := w.WriteIntermediateFooter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rows that have not been flushed are excluded.
+	write(3)
+	second, err := w.WriteIntermediateFooter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	write(2)
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		length  int64
+		rows    int
+		stripes int
+	}{
+		{length: empty, rows: 0, stripes: 0},
+		{length: first, rows: 5, stripes: 1},
+		{length: second, rows: 5, stripes: 1},
+		{length: int64(buf.Len()), rows: 10, stripes: 3},
+	} {
+		r, err := NewReader(&bytesSizedReaderAt{bytes.NewBuffer(buf.Bytes()[:tc.length])})
+		if err != nil {
+			t.Fatalf("Test failed, reading file truncated to %d bytes: %v", tc.length, err)
+		}
+		if stripes := len(r.footer.GetStripes()); stripes != tc.stripes {
+			t.Errorf("Test failed, expected %d stripes at length %d got %d", tc.stripes, tc.length, stripes)
+		}
+		if rows := r.footer.GetNumberOfRows(); rows != uint64(tc.rows) {
+			t.Errorf("Test failed, expected %d rows in the footer at length %d got %d", tc.rows, tc.length, rows)
+		}
+		c := r.Select("int1", "string1")
+		var i int
+		for c.Stripes() {
+			for c.Next() {
+				expected := []interface{}{int64(i), fmt.Sprint("row", i)}
+				if row := c.Row(); !reflect.DeepEqual(row, expected) {
+					t.Errorf("Test failed, expected %v got %v", expected, row)
+				}
+				i++
+			}
+		}
+		if err := c.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if i != tc.rows {
+			t.Errorf("Test failed, expected %d rows at length %d got %d", tc.rows, tc.length, i)
+		}
+	}
+}
+
+// wideRows returns a schema with the provided number of int, string and double
+// columns along with the provided number of rows of values for it.
+func wideRows(tb testing.TB, columns, rows int) (*TypeDescription, [][]interface{}) {
+	fields := make([]string, columns)
+	for i := range fields {
+		fields[i] = fmt.Sprintf("col%d:%s", i, []string{"int", "string", "double"}[i%3])
+	}
+	schema, err := ParseSchema("struct<" + strings.Join(fields, ",") + ">")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	values := make([][]interface{}, rows)
+	for i := range values {
+		values[i] = make([]interface{}, columns)
+		for j := range values[i] {
+			switch {
+			case (i+j)%17 == 0:
+				values[i][j] = nil
+			case j%3 == 0:
+				values[i][j] = i * j
+			case j%3 == 1:
+				values[i][j] = fmt.Sprint(i % (j + 1))
+			default:
+				values[i][j] = float64(i) / float64(j)
+			}
+		}
+	}
+	return schema, values
+}
+
+// writeWideFile writes the rows using the provided options, flushing a stripe
+// half way through, and returns the bytes written.
+func writeWideFile(tb testing.TB, schema *TypeDescription, rows [][]interface{}, fns ...WriterConfigFunc) []byte {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	w.footer.RowIndexStride = ptrUint32(1000)
+	for i, row := range rows {
+		if err := w.Write(row...); err != nil {
+			tb.Fatal(err)
+		}
+		if i == len(rows)/2 {
+			if _, err := w.Flush(); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriterEncoderParallelism(t *testing.T) {
+
+	wide, rows := wideRows(t, 30, 2500)
+	expected := writeWideFile(t, wide, rows)
+	for _, n := range []int{1, 2, 4, 64} {
+		if b := writeWideFile(t, wide, rows, WithEncoderParallelism(n)); !bytes.Equal(b, expected) {
+			t.Errorf("Test failed, expected identical output with encoder parallelism %d", n)
+		}
+	}
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithEncoderParallelism(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(1); err == nil {
+		t.Errorf("Test failed, expected error writing the wrong number of values")
+	}
+	// The invalid value is only encoded when the rows are closed.
+	if err := w.Write(1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err == nil {
+		t.Errorf("Test failed, expected error closing writer with an invalid value")
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, SetSchema(schema), WithEncoderParallelism(0)); err == nil {
+		t.Errorf("Test failed, expected error for an invalid encoder parallelism")
+	}
+}
+
+func BenchmarkWriterEncoderParallelism(b *testing.B) {
+	schema, rows := wideRows(b, 200, 5000)
+	for _, n := range []int{1, 2, 4} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				writeWideFile(b, schema, rows, WithEncoderParallelism(n))
+			}
+		})
+	}
+}
+
+func TestWriterCharLength(t *testing.T) {
+
+	schema, err := ParseSchema("struct<c:char(4),v:varchar(4)>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(rows [][]interface{}, fns ...WriterConfigFunc) (*Reader, error) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			if err := w.Write(row...); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return NewReader(&bytesSizedReaderAt{&buf})
+	}
+
+	// Each of the multi-byte values below is four code points but more than four
+	// bytes long, or five code points and less than twenty bytes long.
+	for _, row := range [][]interface{}{
+		{"日本語です!", "ok"},
+		{"ok", "héllo"},
+	} {
+		if _, err := write([][]interface{}{row}); err == nil {
+			t.Errorf("Test failed, expected error writing overlong value in %v", row)
+		}
+	}
+
+	input := [][]interface{}{
+		{"日本語", "日本語です"},
+		{"héllo", "ü"},
+		{"日本語です!", "héllo"},
+		{nil, nil},
+		{"", ""},
+	}
+	r, err := write(input, WithTruncateOverlongStrings(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Schema().String(); s != "struct<c:char(4),v:varchar(4)>" {
+		t.Errorf("Test failed, expected schema struct<c:char(4),v:varchar(4)> got %s", s)
+	}
+	expected := [][]interface{}{
+		{"日本語 ", "日本語で"},
+		{"héll", "ü"},
+		{"日本語で", "héll"},
+		{nil, nil},
+		{"    ", ""},
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected %q got %q", expected, rows)
+	}
+
+	stats := r.footer.GetStatistics()
+	for i, minMax := range [][2]string{{"    ", "日本語で"}, {"", "日本語で"}} {
+		ss := stats[i+1].GetStringStatistics()
+		if ss.GetMinimum() != minMax[0] || ss.GetMaximum() != minMax[1] {
+			t.Errorf("Test failed, expected column %d min %q max %q got min %q max %q", i+1, minMax[0], minMax[1], ss.GetMinimum(), ss.GetMaximum())
+		}
+	}
+
+	if _, err := NewCharTreeWriter(CategoryChar, CompressionNone{}, Version0_12, 0, false); err == nil {
+		t.Errorf("Test failed, expected error for a maximum length of 0")
+	}
+
+}
+
+// failingWriter is an io.Writer that fails once more than limit bytes have been
+// written to it.
+type failingWriter struct {
+	limit int
+	n     int
+}
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	if f.n+len(p) > f.limit {
+		return 0, fmt.Errorf("write limit of %d bytes exceeded", f.limit)
+	}
+	f.n += len(p)
+	return len(p), nil
+}
+
+func TestWriterClose(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	length := buf.Len()
+	if err := w.Close(); err != nil {
+		t.Errorf("Test failed, expected second Close to return nil got %v", err)
+	}
+	if err := w.Write(2, "b"); err != ErrWriterClosed {
+		t.Errorf("Test failed, expected Write after Close to return ErrWriterClosed got %v", err)
+	}
+	if err := w.AddUserMetadata("key", []byte("value")); err != ErrWriterClosed {
+		t.Errorf("Test failed, expected AddUserMetadata after Close to return ErrWriterClosed got %v", err)
+	}
+	if _, err := w.Flush(); err != ErrWriterClosed {
+		t.Errorf("Test failed, expected Flush after Close to return ErrWriterClosed got %v", err)
+	}
+	if _, err := w.WriteIntermediateFooter(); err != ErrWriterClosed {
+		t.Errorf("Test failed, expected WriteIntermediateFooter after Close to return ErrWriterClosed got %v", err)
+	}
+	if buf.Len() != length {
+		t.Errorf("Test failed, expected file length %d after Close got %d", length, buf.Len())
+	}
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := [][]interface{}{{int64(1), "a"}}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Test failed, expected %v got %v", expected, rows)
+	}
+	if n := len(r.footer.GetMetadata()); n != 0 {
+		t.Errorf("Test failed, expected no user metadata got %d items", n)
+	}
+
+	// A failure writing the tail is returned by every call to Close.
+	fw := &failingWriter{limit: length - 1}
+	w, err = NewWriter(fw, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	first := w.Close()
+	if first == nil {
+		t.Fatal("Test failed, expected Close to return the underlying write error")
+	}
+	if err := w.Close(); err != first {
+		t.Errorf("Test failed, expected second Close to return %v got %v", first, err)
+	}
+
+}
+
+func TestWriterCloseZeroRows(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:int,string1:string,list1:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(&bytesSizedReaderAt{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := r.footer.GetNumberOfRows(); n != 0 {
+		t.Errorf("Test failed, expected 0 rows got %d", n)
+	}
+	if n := len(r.footer.GetStripes()); n != 0 {
+		t.Errorf("Test failed, expected 0 stripes got %d", n)
+	}
+	if s := r.Schema().String(); s != schema.String() {
+		t.Errorf("Test failed, expected schema %s got %s", schema, s)
+	}
+	stats := r.footer.GetStatistics()
+	if len(stats) != 5 {
+		t.Fatalf("Test failed, expected statistics for 5 columns got %d", len(stats))
+	}
+	for i, s := range stats {
+		if s.GetNumberOfValues() != 0 || s.GetHasNull() {
+			t.Errorf("Test failed, expected empty statistics for column %d got %v", i, s)
+		}
+	}
+	rows, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Test failed, expected no rows got %v", rows)
+	}
+
+}
+
+func TestWriterEncodingStrategy(t *testing.T) {
+
+	schema, err := ParseSchema("struct<string1:string,int1:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Low cardinality strings favour dictionary encoding and small integers
+	// with occasional outliers favour the PATCHED_BASE encoding.
+	var input [][]interface{}
+	for i := 0; i < 5000; i++ {
+		value := int64(i % 100)
+		if i%97 == 0 {
+			value = 1 << 40
+		}
+		input = append(input, []interface{}{fmt.Sprintf("category %d", i%10), value})
+	}
+
+	type result struct {
+		size     int
+		encoding proto.ColumnEncoding_Kind
+		lengths  map[uint32]uint64
+		rows     [][]interface{}
+	}
+
+	write := func(fns ...WriterConfigFunc) result {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range input {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		res := result{size: buf.Len(), lengths: make(map[uint32]uint64)}
+		r, err := NewReader(&bytesSizedReaderAt{&buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stripeFooter, err := r.getStripeFooter(r.footer.GetStripes()[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.encoding = stripeFooter.GetColumns()[1].GetKind()
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetKind() == proto.Stream_DATA {
+				res.lengths[stream.GetColumn()] = stream.GetLength()
+			}
+		}
+		res.rows, err = readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	compression := write()
+	speed := write(WithEncodingStrategy(EncodingSpeed))
+	if compression.encoding != proto.ColumnEncoding_DICTIONARY_V2 {
+		t.Errorf("Test failed, expected DICTIONARY_V2 encoding by default got %s", compression.encoding)
+	}
+	if speed.encoding != proto.ColumnEncoding_DIRECT_V2 {
+		t.Errorf("Test failed, expected DIRECT_V2 encoding with EncodingSpeed got %s", speed.encoding)
+	}
+	if speed.lengths[2] <= compression.lengths[2] {
+		t.Errorf("Test failed, expected integer data of %d bytes with EncodingSpeed to exceed %d bytes", speed.lengths[2], compression.lengths[2])
+	}
+	if speed.size <= compression.size {
+		t.Errorf("Test failed, expected file of %d bytes with EncodingSpeed to exceed %d bytes", speed.size, compression.size)
+	}
+	if !reflect.DeepEqual(speed.rows, compression.rows) {
+		t.Errorf("Test failed, expected rows written with either strategy to be equal")
+	}
+
+	// The individual settings override the strategy whatever their order.
+	for _, fns := range [][]WriterConfigFunc{
+		{WithEncodingStrategy(EncodingSpeed), WithDictionaryKeyThreshold(1), WithPatchedBaseEncoding(true)},
+		{WithDictionaryKeyThreshold(1), WithPatchedBaseEncoding(true), WithEncodingStrategy(EncodingSpeed)},
+	} {
+		res := write(fns...)
+		if res.encoding != proto.ColumnEncoding_DICTIONARY_V2 {
+			t.Errorf("Test failed, expected DICTIONARY_V2 encoding got %s", res.encoding)
+		}
+		if res.lengths[2] != compression.lengths[2] {
+			t.Errorf("Test failed, expected integer data of %d bytes got %d", compression.lengths[2], res.lengths[2])
+		}
+	}
+	if res := write(WithDictionaryKeyThreshold(0)); res.encoding != proto.ColumnEncoding_DIRECT_V2 {
+		t.Errorf("Test failed, expected DIRECT_V2 encoding with a threshold of 0 got %s", res.encoding)
+	}
+
+	for _, fn := range []WriterConfigFunc{
+		WithEncodingStrategy(EncodingStrategy(2)),
+		WithDictionaryKeyThreshold(-0.1),
+		WithDictionaryKeyThreshold(1.1),
+	} {
+		if _, err := NewWriter(ioutil.Discard, SetSchema(schema), fn); err == nil {
+			t.Errorf("Test failed, expected error for invalid encoding option")
+		}
+	}
+
+}
+
+func BenchmarkWriterEncodingStrategy(b *testing.B) {
+	schema, err := ParseSchema("struct<string1:string,int1:bigint>")
+	if err != nil {
+		b.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	input := make([][]interface{}, 20000)
+	for i := range input {
+		input[i] = []interface{}{fmt.Sprintf("value %d", rnd.Intn(5000)), rnd.Int63n(1000)}
+	}
+	for _, bc := range []struct {
+		name     string
+		strategy EncodingStrategy
+	}{
+		{"compression", EncodingCompression},
+		{"speed", EncodingSpeed},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var size int
+			for n := 0; n < b.N; n++ {
+				var buf bytes.Buffer
+				w, err := NewWriter(&buf, SetSchema(schema), WithEncodingStrategy(bc.strategy))
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, row := range input {
+					if err := w.Write(row...); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+				size = buf.Len()
+			}
+			b.ReportMetric(float64(size), "bytes/file")
+		})
+	}
+}
+
+func TestWriterColumnEncoding(t *testing.T) {
+
+	schema, err := ParseSchema("struct<uuid:string,category:string,id:bigint,flag:boolean>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var input [][]interface{}
+	for i := 0; i < 3000; i++ {
+		input = append(input, []interface{}{
+			fmt.Sprintf("%08x-0000-4000-8000-%012x", i*7919, i),
+			fmt.Sprintf("category %d", i%5),
+			int64(i / 100),
+			i%2 == 0,
+		})
+	}
+
+	type result struct {
+		encodings []proto.ColumnEncoding_Kind
+		lengths   map[uint32]uint64
+		rows      [][]interface{}
+	}
+
+	write := func(fns ...WriterConfigFunc) (result, error) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+		if err != nil {
+			return result{}, err
+		}
+		for _, row := range input {
+			if err := w.Write(row...); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(&bytesSizedReaderAt{&buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stripeFooter, err := r.getStripeFooter(r.footer.GetStripes()[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := result{lengths: make(map[uint32]uint64)}
+		for _, encoding := range stripeFooter.GetColumns() {
+			res.encodings = append(res.encodings, encoding.GetKind())
+		}
+		for _, stream := range stripeFooter.GetStreams() {
+			if stream.GetKind() == proto.Stream_DATA {
+				res.lengths[stream.GetColumn()] = stream.GetLength()
+			}
+		}
+		res.rows, err = readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, nil
+	}
+
+	automatic, err := write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if automatic.encodings[1] != proto.ColumnEncoding_DIRECT_V2 || automatic.encodings[2] != proto.ColumnEncoding_DICTIONARY_V2 {
+		t.Fatalf("Test failed, expected automatic encodings DIRECT_V2 and DICTIONARY_V2 got %v", automatic.encodings)
+	}
+
+	forced, err := write(
+		WithColumnEncoding("uuid", proto.ColumnEncoding_DICTIONARY_V2),
+		WithColumnEncoding("category", proto.ColumnEncoding_DIRECT_V2),
+		WithColumnEncoding("id", proto.ColumnEncoding_DIRECT_V2, RLEV2IntShortRepeat, RLEV2IntDelta, RLEV2IntPatchedBase),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forced.encodings[1] != proto.ColumnEncoding_DICTIONARY_V2 || forced.encodings[2] != proto.ColumnEncoding_DIRECT_V2 {
+		t.Errorf("Test failed, expected forced encodings DICTIONARY_V2 and DIRECT_V2 got %v", forced.encodings)
+	}
+	if forced.encodings[3] != proto.ColumnEncoding_DIRECT_V2 {
+		t.Errorf("Test failed, expected integer encoding DIRECT_V2 got %s", forced.encodings[3])
+	}
+	// Runs of repeated integers written with DIRECT encoding alone are larger.
+	if forced.lengths[3] <= automatic.lengths[3] {
+		t.Errorf("Test failed, expected integer data of %d bytes to exceed %d bytes", forced.lengths[3], automatic.lengths[3])
+	}
+	if !reflect.DeepEqual(forced.rows, automatic.rows) {
+		t.Errorf("Test failed, expected rows written with forced encodings to be equal")
+	}
+
+	testCases := []struct {
+		name string
+		fn   WriterConfigFunc
+	}{
+		{"unknown column", WithColumnEncoding("missing", proto.ColumnEncoding_DIRECT_V2)},
+		{"empty column", WithColumnEncoding("", proto.ColumnEncoding_DIRECT_V2)},
+		{"version 1 string encoding", WithColumnEncoding("uuid", proto.ColumnEncoding_DIRECT)},
+		{"dictionary integer encoding", WithColumnEncoding("id", proto.ColumnEncoding_DICTIONARY_V2)},
+		{"disabled DIRECT", WithColumnEncoding("id", proto.ColumnEncoding_DIRECT_V2, RLEV2IntDirect)},
+		{"disabled string encoding", WithColumnEncoding("uuid", proto.ColumnEncoding_DIRECT_V2, RLEV2IntDelta)},
+		{"boolean column", WithColumnEncoding("flag", proto.ColumnEncoding_DIRECT)},
+	}
+	for _, tc := range testCases {
+		if _, err := write(tc.fn); err == nil {
+			t.Errorf("Test failed, %s: expected error", tc.name)
+		}
+	}
+	if _, err := write(WithFileVersion(0, 11), WithColumnEncoding("id", proto.ColumnEncoding_DIRECT, RLEV2IntDelta)); err == nil {
+		t.Errorf("Test failed, expected error disabling integer encodings of version 1")
+	}
+
+}
+
+func TestWriterSortedDictionary(t *testing.T) {
+
+	schema, err := ParseSchema("struct<fruit:string>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Values in neither sorted nor reverse order, including upper case, multi-byte
+	// UTF-8 and empty values, which sort by their bytes.
+	input := []string{"pear", "apple", "fig", "Zucchini", "apple", "", "élan", "fig", "banana", "pear", "apples"}
+	firstOccurrence := []string{"pear", "apple", "fig", "Zucchini", "", "élan", "banana", "apples"}
+	sorted := []string{"", "Zucchini", "apple", "apples", "banana", "fig", "pear", "élan"}
+
+	testCases := []struct {
+		fns      []WriterConfigFunc
+		expected []string
+	}{
+		{expected: sorted},
+		{fns: []WriterConfigFunc{WithSortedDictionary(true)}, expected: sorted},
+		{fns: []WriterConfigFunc{WithFormatVersion("0.11")}, expected: sorted},
+		{fns: []WriterConfigFunc{WithSortedDictionary(false)}, expected: firstOccurrence},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		fns := append([]WriterConfigFunc{SetSchema(schema), WithDictionaryKeyThreshold(1)}, tc.fns...)
+		w, err := NewWriter(&buf, fns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range input {
+			if err := w.Write(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Read the entries of the dictionary from its streams.
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams, err := r.getStreams(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoding, err := r.getColumn(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kind := encoding.GetKind(); kind != proto.ColumnEncoding_DICTIONARY && kind != proto.ColumnEncoding_DICTIONARY_V2 {
+			t.Fatalf("Test failed, expected dictionary encoding got %s", kind)
+		}
+		dictionary, err := ioutil.ReadAll(streams.get(streamName{1, proto.Stream_DICTIONARY_DATA}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths, err := createIntegerReader(encoding.GetKind(), streams.get(streamName{1, proto.Stream_LENGTH}), false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []string
+		for lengths.Next() {
+			l := int(lengths.Int())
+			entries = append(entries, string(dictionary[:l]))
+			dictionary = dictionary[l:]
+		}
+		if !reflect.DeepEqual(entries, tc.expected) {
+			t.Errorf("Test failed, expected dictionary %q got %q", tc.expected, entries)
+		}
+		if isSorted := sort.SliceIsSorted(entries, func(i, j int) bool {
+			return bytes.Compare([]byte(entries[i]), []byte(entries[j])) < 0
+		}); isSorted != reflect.DeepEqual(tc.expected, sorted) {
+			t.Errorf("Test failed, expected the dictionary to be sorted: %t", !isSorted)
+		}
+
+		r, err = NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := readAllRows(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(input) {
+			t.Fatalf("Test failed, expected %d rows got %d", len(input), len(rows))
+		}
+		for i, row := range rows {
+			if row[0] != input[i] {
+				t.Errorf("Test failed, expected %q at row %d got %v", input[i], i, row[0])
+			}
+		}
+	}
+
+}
+
+func TestWriterWriteBatch(t *testing.T) {
+
+	schema, err := ParseSchema("struct<id:bigint,byte1:tinyint,double1:double,float1:float,string1:string,char1:char(3),boolean1:boolean,list:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const rows = 2500
+	batch := &ColumnBatch{Columns: []*ColumnVector{
+		{Int64s: make([]int64, rows)},
+		{Int64s: make([]int64, rows), Nulls: make([]bool, rows)},
+		{Float64s: make([]float64, rows)},
+		{Float64s: make([]float64, rows), Nulls: make([]bool, rows)},
+		{Strings: make([]string, rows), Nulls: make([]bool, rows)},
+		{Strings: make([]string, rows)},
+		{Bools: make([]bool, rows), Nulls: make([]bool, rows)},
+		{Values: make([]interface{}, rows)},
+	}}
+	for i := 0; i < rows; i++ {
+		c := batch.Columns
+		c[0].Int64s[i] = int64(i * 3)
+		c[1].Int64s[i] = int64(i%256 - 128)
+		c[1].Nulls[i] = i%7 == 0
+		c[2].Float64s[i] = float64(i) / 4
+		c[3].Float64s[i] = float64(i) / 3
+		c[3].Nulls[i] = i%11 == 0
+		c[4].Strings[i] = fmt.Sprintf("string %d", i%13)
+		c[4].Nulls[i] = i%5 == 0
+		c[5].Strings[i] = fmt.Sprint(i % 50)
+		c[6].Bools[i] = i%3 == 0
+		c[6].Nulls[i] = i%17 == 0
+		if i%9 != 0 {
+			c[7].Values[i] = []interface{}{i, nil, -i}
+		}
+	}
+
+	newWriter := func(buf *bytes.Buffer, fns ...WriterConfigFunc) *Writer {
+		w, err := NewWriter(buf, append([]WriterConfigFunc{SetSchema(schema)}, fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.footer.RowIndexStride = ptrUint32(100)
+		w.stripeTargetSize = 10 * 1000
+		return w
+	}
+
+	var expected bytes.Buffer
+	w := newWriter(&expected)
+	for i := 0; i < rows; i++ {
+		values, err := batch.row(schema, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(values...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Batches are split into partial batches to write them across row groups and
+	// stripes, beginning at any row of a row group.
+	for _, size := range []int{rows, 1000, 150, 99, 1} {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		for start := 0; start < rows; start += size {
+			end := start + size
+			if end > rows {
+				end = rows
+			}
+			partial := &ColumnBatch{}
+			for _, c := range batch.Columns {
+				v := &ColumnVector{}
+				switch {
+				case c.Int64s != nil:
+					v.Int64s = c.Int64s[start:end]
+				case c.Float64s != nil:
+					v.Float64s = c.Float64s[start:end]
+				case c.Strings != nil:
+					v.Strings = c.Strings[start:end]
+				case c.Bools != nil:
+					v.Bools = c.Bools[start:end]
+				default:
+					v.Values = c.Values[start:end]
+				}
+				v.Nulls = c.nulls(start, end)
+				partial.Columns = append(partial.Columns, v)
+			}
+			if err := w.WriteBatch(partial); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected.Bytes()) {
+			t.Errorf("Test failed, expected batches of %d rows to be written identically to single rows", size)
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader(expected.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.footer.GetStripes()); n < 2 {
+		t.Errorf("Test failed, expected rows to span several stripes got %d", n)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != rows {
+		t.Fatalf("Test failed, expected %d rows got %d", rows, len(actual))
+	}
+	if !reflect.DeepEqual(actual[9], []interface{}{int64(27), int8(-119), Double(2.25), Float(3), "string 9", "9  ", true, nil}) {
+		t.Errorf("Test failed, got row %v", actual[9])
+	}
+	if !reflect.DeepEqual(actual[35], []interface{}{int64(105), nil, Double(8.75), Float(float32(35.0 / 3)), nil, "35 ", false, []interface{}{int64(35), nil, int64(-35)}}) {
+		t.Errorf("Test failed, got row %v", actual[35])
+	}
+
+	// Batches are written by rows when sorting stripes.
+	var sorted bytes.Buffer
+	w = newWriter(&sorted, WithStripeSortColumn("id"))
+	if err := w.WriteBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		columns []*ColumnVector
+	}{
+		{"wrong number of columns", batch.Columns[:7]},
+		{"nil column", append([]*ColumnVector{nil}, batch.Columns[1:]...)},
+		{"wrong length", append([]*ColumnVector{{Int64s: []int64{1}}}, batch.Columns[1:]...)},
+		{"wrong number of nulls", append([]*ColumnVector{{Int64s: make([]int64, rows), Nulls: []bool{true}}}, batch.Columns[1:]...)},
+		{"wrong values type", append([]*ColumnVector{{Strings: make([]string, rows)}}, batch.Columns[1:]...)},
+		{"several values set", append([]*ColumnVector{{Int64s: make([]int64, rows), Values: make([]interface{}, rows)}}, batch.Columns[1:]...)},
+	}
+	for _, tc := range testCases {
+		w := newWriter(&bytes.Buffer{})
+		if err := w.WriteBatch(&ColumnBatch{Columns: tc.columns}); err == nil {
+			t.Errorf("Test failed, %s: expected error", tc.name)
+		}
+	}
+
+	w = newWriter(&bytes.Buffer{})
+	c := *batch.Columns[1]
+	c.Int64s = append([]int64{0, 1000}, c.Int64s[2:]...)
+	if err := w.WriteBatch(&ColumnBatch{Columns: append([]*ColumnVector{batch.Columns[0], &c}, batch.Columns[2:]...)}); err == nil {
+		t.Errorf("Test failed, expected error writing an out of range tinyint")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteBatch(batch); err != ErrWriterClosed {
+		t.Errorf("Test failed, expected ErrWriterClosed got %v", err)
+	}
+
+}
+
+func BenchmarkWriterWriteBatch(b *testing.B) {
+	schema, err := ParseSchema("struct<int1:bigint,int2:int,double1:double,boolean1:boolean>")
+	if err != nil {
+		b.Fatal(err)
+	}
+	const rows = 1024
+	batch := &ColumnBatch{Columns: []*ColumnVector{
+		{Int64s: make([]int64, rows)},
+		{Int64s: make([]int64, rows)},
+		{Float64s: make([]float64, rows)},
+		{Bools: make([]bool, rows)},
+	}}
+	for i := 0; i < rows; i++ {
+		batch.Columns[0].Int64s[i] = int64(i * i)
+		batch.Columns[1].Int64s[i] = int64(i % 100)
+		batch.Columns[2].Float64s[i] = float64(i) / 7
+		batch.Columns[3].Bools[i] = i%3 == 0
+	}
+	values := make([][]interface{}, rows)
+	for i := range values {
+		values[i], err = batch.row(schema, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("rows", func(b *testing.B) {
+		w, err := NewWriter(ioutil.Discard, SetSchema(schema))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for _, row := range values {
+				if err := w.Write(row...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		w, err := NewWriter(ioutil.Discard, SetSchema(schema))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := w.WriteBatch(batch); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestWriterCompressionNone(t *testing.T) {
+
+	schema, err := ParseSchema("struct<string1:string,int1:bigint>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, SetSchema(schema), WithColumnEncoding("string1", proto.ColumnEncoding_DIRECT_V2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected [][]interface{}
+	var data []byte
+	for i := 0; i < 1000; i++ {
+		s := fmt.Sprintf("value %d", i)
+		data = append(data, s...)
+		expected = append(expected, []interface{}{s, int64(i)})
+		if err := w.Write(s, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	// Without compression each section of the file is written as is, without any
+	// compression chunk headers, so it can be unmarshalled directly.
+	psLen := int(b[len(b)-1])
+	postScript := &proto.PostScript{}
+	if err := gproto.Unmarshal(b[len(b)-1-psLen:len(b)-1], postScript); err != nil {
+		t.Fatal(err)
+	}
+	if postScript.GetCompression() != proto.CompressionKind_NONE {
+		t.Fatalf("Test failed, expected compression NONE got %s", postScript.GetCompression())
+	}
+	footerOffset := len(b) - 1 - psLen - int(postScript.GetFooterLength())
+	footer := &proto.Footer{}
+	if err := gproto.Unmarshal(b[footerOffset:footerOffset+int(postScript.GetFooterLength())], footer); err != nil {
+		t.Fatalf("Test failed, expected footer without a chunk header: %v", err)
+	}
+	if len(footer.GetStripes()) != 1 || footer.GetNumberOfRows() != 1000 {
+		t.Fatalf("Test failed, got footer with %d stripes and %d rows", len(footer.GetStripes()), footer.GetNumberOfRows())
+	}
+	stripe := footer.GetStripes()[0]
+	stripeFooterOffset := int(stripe.GetOffset() + stripe.GetIndexLength() + stripe.GetDataLength())
+	stripeFooter := &proto.StripeFooter{}
+	if err := gproto.Unmarshal(b[stripeFooterOffset:stripeFooterOffset+int(stripe.GetFooterLength())], stripeFooter); err != nil {
+		t.Fatalf("Test failed, expected stripe footer without a chunk header: %v", err)
+	}
+	offset := int(stripe.GetOffset())
+	var found bool
+	for _, stream := range stripeFooter.GetStreams() {
+		if stream.GetColumn() == 1 && stream.GetKind() == proto.Stream_DATA {
+			found = true
+			if actual := b[offset : offset+int(stream.GetLength())]; !bytes.Equal(actual, data) {
+				t.Errorf("Test failed, expected string data stream of the raw values got %q", actual[:minInt(16, len(actual))])
+			}
+		}
+		offset += int(stream.GetLength())
+	}
+	if !found {
+		t.Errorf("Test failed, expected a string data stream")
+	}
+
+	r, err := NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := readAllRows(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Test failed, expected rows to round trip")
+	}
+
+}
+
+func TestWriterEstimatedSize(t *testing.T) {
+
+	schema, err := ParseSchema("struct<int1:bigint,string1:string,double1:double,list:array<int>>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		fns  []WriterConfigFunc
+	}{
+		{name: "default"},
+		{name: "sorted", fns: []WriterConfigFunc{WithStripeSortColumn("double1")}},
+		{name: "parallel", fns: []WriterConfigFunc{WithEncoderParallelism(4)}},
+	}
+
+	for _, tc := range testCases {
+		rnd := rand.New(rand.NewSource(1))
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, append([]WriterConfigFunc{SetSchema(schema)}, tc.fns...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.footer.RowIndexStride = ptrUint32(1000)
+		w.stripeTargetSize = 200 * 1000
+		if w.EstimatedSize() != int64(len(magic)) {
+			t.Errorf("Test failed, %s: expected initial estimate %d got %d", tc.name, len(magic), w.EstimatedSize())
+		}
+		var estimate int64
+		for i := 0; i < 50000; i++ {
+			offset := w.stripeOffset
+			stripes := w.NumStripes()
+			err := w.Write(
+				rnd.Int63n(1000000),
+				fmt.Sprintf("category %d", rnd.Intn(50)),
+				rnd.Float64(),
+				[]interface{}{rnd.Intn(100), rnd.Intn(100)},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The estimate prior to writing each stripe, once the ratios are
+			// established by the first stripe, is within 10% of the stripe.
+			if n := w.NumStripes(); n > stripes && stripes > 0 {
+				expected := float64(w.stripeOffset - offset)
+				if actual := float64(estimate - int64(offset)); math.Abs(actual-expected) > expected/10 {
+					t.Errorf("Test failed, %s: expected estimate of stripe %d within 10%% of %v got %v", tc.name, n, expected, actual)
+				}
+			}
+			estimate = w.EstimatedSize()
+			if w.NumRows() != uint64(i+1) {
+				t.Fatalf("Test failed, %s: expected %d rows got %d", tc.name, i+1, w.NumRows())
+			}
+		}
+		if w.NumStripes() < 3 {
+			t.Fatalf("Test failed, %s: expected at least 3 stripes got %d", tc.name, w.NumStripes())
+		}
+		offset, err := w.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.EstimatedSize() != int64(offset) || int64(buf.Len()) != int64(offset) {
+			t.Errorf("Test failed, %s: expected estimate %d once flushed got %d", tc.name, offset, w.EstimatedSize())
+		}
+		stripes := w.NumStripes()
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if w.EstimatedSize() != int64(buf.Len()) {
+			t.Errorf("Test failed, %s: expected estimate %d once closed got %d", tc.name, buf.Len(), w.EstimatedSize())
+		}
+		if w.NumStripes() != stripes || w.NumRows() != 50000 {
+			t.Errorf("Test failed, %s: expected %d stripes and 50000 rows got %d and %d", tc.name, stripes, w.NumStripes(), w.NumRows())
+		}
+	}
+
+}